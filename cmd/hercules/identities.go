@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v4"
+	"gopkg.in/src-d/hercules.v4/internal/plumbing/identity"
+)
+
+// identitiesCmd prints the developers discovered in the repository
+var identitiesCmd = &cobra.Command{
+	Use:   "identities <repository> [cache]",
+	Short: "Print the discovered developer identities as a structured people dictionary.",
+	Long: `Discover the developers in the commit history the same way as the analyses do
+without --people-dict and print them in YAML. The identities which are merged by the fuzzy
+heuristics are listed in the header comments. The output can be reviewed, edited and passed
+to --people-dict later.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		commitsFile, _ := flags.GetString("commits")
+		threshold, _ := flags.GetFloat64("threshold")
+		disableStatus, _ := flags.GetBool("quiet")
+		signature, _ := flags.GetString("signature")
+		mailmapPath, _ := flags.GetString("mailmap")
+		cachePath := ""
+		if len(args) == 2 {
+			cachePath = args[1]
+		}
+		repository := loadRepository(args[0], cachePath, disableStatus)
+		var commits []*object.Commit
+		if commitsFile == "" {
+			commits = hercules.NewPipeline(repository).Commits()
+			for _, commit := range commits {
+				if err := hercules.LoadCommit(repository, commit); err != nil {
+					panic(err)
+				}
+			}
+		} else {
+			var err error
+			commits, err = hercules.LoadCommitsFromFile(commitsFile, repository)
+			if err != nil {
+				panic(err)
+			}
+		}
+		detector := identity.Detector{FuzzyThreshold: threshold, Signature: signature}
+		if err := detector.LoadMailmap(repository, commits, mailmapPath); err != nil {
+			panic(err)
+		}
+		detector.GeneratePeopleDict(commits)
+		detector.WriteIdentities(os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(identitiesCmd)
+	identitiesCmd.SetUsageFunc(identitiesCmd.UsageFunc())
+	idFlags := identitiesCmd.Flags()
+	idFlags.String("commits", "", "Path to the text file with the commit history to follow "+
+		"instead of the default rev-list --first-parent.")
+	identitiesCmd.MarkFlagFilename("commits")
+	idFlags.Float64("threshold", 0.8, "The minimum confidence of the fuzzy heuristics to merge "+
+		"two developers, from 0 to 1. 0 disables the heuristics.")
+	idFlags.String("signature", hercules.SignatureAuthor, "Discover the commit authors (\""+
+		hercules.SignatureAuthor+"\") or the committers (\""+hercules.SignatureCommitter+"\").")
+	idFlags.String("mailmap", "", "Path to the mailmap file which is applied on top of "+
+		"the repository's .mailmap.")
+	identitiesCmd.MarkFlagFilename("mailmap")
+	idFlags.Bool("quiet", true, "Do not print status updates to stderr.")
+}
@@ -191,14 +191,28 @@ targets can be added using the --plugin system.`,
 				panic(err)
 			}
 		}
-		cmdlineFacts["commits"] = commits
-		deployed := []hercules.LeafPipelineItem{}
-		for name, valPtr := range cmdlineDeployed {
-			if *valPtr {
-				item := pipeline.DeployItem(hercules.Registry.Summon(name)[0])
-				deployed = append(deployed, item.(hercules.LeafPipelineItem))
-			}
+		// the facts are changed in Initialize(), the submodules start from scratch
+		submoduleFacts := map[string]interface{}{}
+		for key, val := range cmdlineFacts {
+			submoduleFacts[key] = val
 		}
+		cmdlineFacts[hercules.FactSubmodulesRunner] = hercules.SubmoduleRunner(
+			func(repository *git.Repository, commits []*object.Commit) (
+				[]hercules.LeafPipelineItem, map[hercules.LeafPipelineItem]interface{}, error) {
+				facts := map[string]interface{}{}
+				for key, val := range submoduleFacts {
+					facts[key] = val
+				}
+				facts["commits"] = commits
+				submodule := hercules.NewPipeline(repository)
+				submodule.SetFeaturesFromFlags()
+				deployed := deployLeaves(submodule)
+				submodule.Initialize(facts)
+				results, err := submodule.Run(commits)
+				return deployed, results, err
+			})
+		cmdlineFacts["commits"] = commits
+		deployed := deployLeaves(pipeline)
 		pipeline.Initialize(cmdlineFacts)
 		if dryRun, _ := cmdlineFacts[hercules.ConfigPipelineDryRun].(bool); dryRun {
 			return
@@ -207,6 +221,12 @@ targets can be added using the --plugin system.`,
 		if err != nil {
 			panic(err)
 		}
+		if profile {
+			stats, ok := cmdlineFacts[hercules.FactBlobCacheStats].(*hercules.BlobCacheStats)
+			if ok {
+				fmt.Fprintf(os.Stderr, "\rblob cache: %s\n", stats)
+			}
+		}
 		if !disableStatus {
 			fmt.Fprint(os.Stderr, "\r"+strings.Repeat(" ", 80)+"\r")
 			// if not a terminal, the user will not see the output, so show the status
@@ -222,6 +242,18 @@ targets can be added using the --plugin system.`,
 	},
 }
 
+// deployLeaves inserts the leaves enabled on the command line into the pipeline.
+func deployLeaves(pipeline *hercules.Pipeline) []hercules.LeafPipelineItem {
+	deployed := []hercules.LeafPipelineItem{}
+	for name, valPtr := range cmdlineDeployed {
+		if *valPtr {
+			item := pipeline.DeployItem(hercules.Registry.Summon(name)[0])
+			deployed = append(deployed, item.(hercules.LeafPipelineItem))
+		}
+	}
+	return deployed
+}
+
 func printResults(
 	uri string, deployed []hercules.LeafPipelineItem,
 	results map[hercules.LeafPipelineItem]interface{}) {
@@ -235,6 +267,7 @@ func printResults(
 	fmt.Println("  end_unix_time:", commonResult.EndTime)
 	fmt.Println("  commits:", commonResult.CommitsNumber)
 	fmt.Println("  run_time:", commonResult.RunTime.Nanoseconds()/1e6)
+	fmt.Println("  signature:", commonResult.Signature)
 
 	for _, item := range deployed {
 		result := results[item]
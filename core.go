@@ -41,6 +41,9 @@ type LeafPipelineItem = core.LeafPipelineItem
 // MergeablePipelineItem specifies the methods to combine several analysis results together.
 type MergeablePipelineItem = core.MergeablePipelineItem
 
+// DisposablePipelineItem holds the resources which must be released when Pipeline.Run() ends.
+type DisposablePipelineItem = core.DisposablePipelineItem
+
 // CommonAnalysisResult holds the information which is always extracted at Pipeline.Run().
 type CommonAnalysisResult = core.CommonAnalysisResult
 
@@ -71,6 +74,38 @@ const (
 	// ConfigPipelineCommits is the name of the Pipeline configuration option (Pipeline.Initialize())
 	// which allows to specify the custom commit sequence. By default, Pipeline.Commits() is used.
 	ConfigPipelineCommits = core.ConfigPipelineCommits
+	// ConfigPipelineSignature is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which chooses between the commit authors and the committers, see SignatureAuthor and
+	// SignatureCommitter.
+	ConfigPipelineSignature = core.ConfigPipelineSignature
+	// ConfigPipelineIgnoredCommits is the name of the Pipeline configuration option
+	// (Pipeline.Initialize()) which marks the commits whose changes are not attributed,
+	// for example, the mass reformatting. The value is map[plumbing.Hash]bool.
+	// The changed lines keep their previous authors and ages.
+	ConfigPipelineIgnoredCommits = core.ConfigPipelineIgnoredCommits
+	// ConfigPipelineIgnoreRevsPath is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which sets the path to the file with the ignored commits in addition to IgnoreRevsFileName.
+	ConfigPipelineIgnoreRevsPath = core.ConfigPipelineIgnoreRevsPath
+	// ConfigPipelineGitBackend is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which chooses how the trees and the blobs are read, see GitBackendGoGit and GitBackendExec.
+	ConfigPipelineGitBackend = core.ConfigPipelineGitBackend
+	// FactPipelineCommitLoader is the name of the fact which provides the CommitLoader
+	// for the commits which are read before Pipeline.Run().
+	FactPipelineCommitLoader = core.FactPipelineCommitLoader
+	// FactPipelineRepository is the name of the fact which provides the analysed repository
+	// to Configure().
+	FactPipelineRepository = core.FactPipelineRepository
+	// IgnoreRevsFileName is the name of the file in the repository which lists the commits
+	// ignored by `git blame`.
+	IgnoreRevsFileName = core.IgnoreRevsFileName
+	// GitBackendGoGit is the value of ConfigPipelineGitBackend which reads the objects with go-git.
+	GitBackendGoGit = core.GitBackendGoGit
+	// GitBackendExec is the value of ConfigPipelineGitBackend which runs the local git executable.
+	GitBackendExec = core.GitBackendExec
+	// SignatureAuthor is the value of ConfigPipelineSignature which selects the commit authors.
+	SignatureAuthor = core.SignatureAuthor
+	// SignatureCommitter is the value of ConfigPipelineSignature which selects the committers.
+	SignatureCommitter = core.SignatureCommitter
 )
 
 // NewPipeline initializes a new instance of Pipeline struct.
@@ -78,12 +113,36 @@ func NewPipeline(repository *git.Repository) *Pipeline {
 	return core.NewPipeline(repository)
 }
 
+// CommitGraph is the parsed commit-graph file of the repository, see LoadCommitGraph().
+type CommitGraph = core.CommitGraph
+
+// LoadCommitGraph reads git's commit-graph of the repository which maps the commits to the parents
+// and the generation numbers. It returns nil if there is no commit-graph.
+func LoadCommitGraph(repository *git.Repository) (*CommitGraph, error) {
+	return core.LoadCommitGraph(repository)
+}
+
+// CommitLoader decodes the commit in place, see LoadCommit().
+type CommitLoader = core.CommitLoader
+
+// LoadCommit decodes the commit in place if it has only the hash and the parents,
+// which is the case for most of the commits returned by Pipeline.Commits().
+func LoadCommit(repository *git.Repository, commit *object.Commit) error {
+	return core.LoadCommit(repository, commit)
+}
+
 // LoadCommitsFromFile reads the file by the specified FS path and generates the sequence of commits
 // by interpreting each line as a Git commit hash.
 func LoadCommitsFromFile(path string, repository *git.Repository) ([]*object.Commit, error) {
 	return core.LoadCommitsFromFile(path, repository)
 }
 
+// CommitSignature returns the commit author or the committer depending on `kind` -
+// SignatureAuthor or SignatureCommitter.
+func CommitSignature(commit *object.Commit, kind string) object.Signature {
+	return core.CommitSignature(commit, kind)
+}
+
 // ForkSamePipelineItem clones items by referencing the same origin.
 func ForkSamePipelineItem(origin PipelineItem, n int) []PipelineItem {
 	return core.ForkSamePipelineItem(origin ,n)
@@ -103,6 +162,9 @@ var Registry = core.Registry
 const (
 	// DependencyAuthor is the name of the dependency provided by identity.Detector.
 	DependencyAuthor = identity.DependencyAuthor
+	// DependencyAuthors is the name of the dependency provided by identity.Detector - the author
+	// and the co-authors from the "Co-authored-by:" trailers.
+	DependencyAuthors = identity.DependencyAuthors
 	// DependencyBlobCache identifies the dependency provided by BlobCache.
 	DependencyBlobCache = plumbing.DependencyBlobCache
 	// DependencyDay is the name of the dependency which DaysSinceStart provides - the number
@@ -110,18 +172,36 @@ const (
 	DependencyDay = plumbing.DependencyDay
 	// DependencyFileDiff is the name of the dependency provided by FileDiff.
 	DependencyFileDiff = plumbing.DependencyFileDiff
+	// DependencyTeam is the name of the dependency provided by identity.Detector - the team
+	// of the author at the moment of the commit.
+	DependencyTeam = identity.DependencyTeam
+	// DependencyTeams is the name of the dependency provided by identity.Detector - the teams
+	// which correspond to DependencyAuthors.
+	DependencyTeams = identity.DependencyTeams
 	// DependencyTreeChanges is the name of the dependency provided by TreeDiff.
 	DependencyTreeChanges = plumbing.DependencyTreeChanges
+	// DependencyTreeChangeKinds is the name of the dependency provided by TreeDiff - the kinds
+	// of DependencyTreeChanges.
+	DependencyTreeChangeKinds = plumbing.DependencyTreeChangeKinds
+	// DependencyTreeCopies is the name of the dependency provided by RenameAnalysis - the list
+	// of the added files which are copies of the unchanged files or the files deleted recently.
+	DependencyTreeCopies = plumbing.DependencyTreeCopies
 	// DependencyUastChanges is the name of the dependency provided by Changes.
 	DependencyUastChanges = uast.DependencyUastChanges
 	// DependencyUasts is the name of the dependency provided by Extractor.
 	DependencyUasts = uast.DependencyUasts
+	// FactBlobCacheStats is the name of the fact with the *BlobCacheStats which BlobCache
+	// updates during Pipeline.Run().
+	FactBlobCacheStats = plumbing.FactBlobCacheStats
 	// FactCommitsByDay contains the mapping between day indices and the corresponding commits.
 	FactCommitsByDay = plumbing.FactCommitsByDay
 	// FactIdentityDetectorPeopleCount is the name of the fact which is inserted in
 	// identity.Detector.Configure(). It is equal to the overall number of unique authors
 	// (the length of ReversedPeopleDict).
 	FactIdentityDetectorPeopleCount = identity.FactIdentityDetectorPeopleCount
+	// FactIdentityDetectorCoAuthorsPolicy is the name of the fact which is inserted in
+	// identity.Detector.Configure(). It defines how the co-authors are credited.
+	FactIdentityDetectorCoAuthorsPolicy = identity.FactIdentityDetectorCoAuthorsPolicy
 	// FactIdentityDetectorPeopleDict is the name of the fact which is inserted in
 	// identity.Detector.Configure(). It corresponds to identity.Detector.PeopleDict - the mapping
 	// from the signatures to the author indices.
@@ -132,14 +212,55 @@ const (
 	FactIdentityDetectorReversedPeopleDict = identity.FactIdentityDetectorReversedPeopleDict
 )
 
+// FactSubmodulesRunner is the name of the fact with the SubmoduleRunner which analyses
+// the submodule histories in leaves.SubmodulesAnalysis.
+const FactSubmodulesRunner = leaves.FactSubmodulesRunner
+
+// SubmoduleRunner runs the analysis over the sequence of commits in a submodule repository.
+// It returns the deployed leaves and the results of Pipeline.Run().
+type SubmoduleRunner = leaves.SubmoduleRunner
+
+// BlobCacheStats are the hit and miss counters of the blob cache shared between the branches.
+type BlobCacheStats = plumbing.BlobCacheStats
+
+// ChangeKind tells whether an object.Change modifies the contents, the mode, a symlink, the type
+// or a submodule.
+type ChangeKind = plumbing.ChangeKind
+
+// ChangeKinds is the type of the dependency provided by plumbing.TreeDiff as
+// DependencyTreeChangeKinds.
+type ChangeKinds = plumbing.ChangeKinds
+
+const (
+	// ChangeKindContent is the kind of the regular file changes.
+	ChangeKindContent = plumbing.ChangeKindContent
+	// ChangeKindMode is the kind of the modifications which change only the file mode.
+	ChangeKindMode = plumbing.ChangeKindMode
+	// ChangeKindSymlink is the kind of the changes which involve only symbolic links.
+	ChangeKindSymlink = plumbing.ChangeKindSymlink
+	// ChangeKindType is the kind of the modifications between a regular file and a symlink.
+	ChangeKindType = plumbing.ChangeKindType
+	// ChangeKindSubmodule is the kind of the changes which involve the submodules.
+	ChangeKindSubmodule = plumbing.ChangeKindSubmodule
+)
+
 // FileDiffData is the type of the dependency provided by plumbing.FileDiff.
 type FileDiffData = plumbing.FileDiffData
 
+// FileCopy is the type of the items in the dependency provided by plumbing.RenameAnalysis
+// as DependencyTreeCopies.
+type FileCopy = plumbing.FileCopy
+
 // CountLines returns the number of lines in a *object.Blob.
 func CountLines(file *object.Blob) (int, error) {
 	return plumbing.CountLines(file)
 }
 
+// ClassifyChange determines the kind of an object.Change.
+func ClassifyChange(change *object.Change) ChangeKind {
+	return plumbing.ClassifyChange(change)
+}
+
 func init() {
 	// hack to link with .leaves
 	_ = leaves.BurndownAnalysis{}
@@ -109,6 +109,21 @@ func (file *File) Clone(clearStatuses bool, statuses ...Status) *File {
 	return clone
 }
 
+// Duplicate copies the file's tree to a new File with the specified `statuses` and `hash`.
+// Unlike Clone(), the statuses are updated as if the lines were inserted with their
+// current values, so the duplicated lines are counted once more.
+func (file *File) Duplicate(hash plumbing.Hash, statuses ...Status) *File {
+	clone := &File{Hash: hash, tree: file.tree.Clone(), statuses: statuses}
+	prevLine, prevValue := 0, TreeEnd
+	clone.ForEach(func(line, value int) {
+		if prevValue != TreeEnd && line > prevLine {
+			clone.updateTime(prevValue, prevValue, line-prevLine)
+		}
+		prevLine, prevValue = line, value
+	})
+	return clone
+}
+
 // Len returns the File's size - that is, the maximum key in the tree of line
 // intervals.
 func (file *File) Len() int {
@@ -308,6 +323,25 @@ func (file *File) Status(index int) interface{} {
 	return file.statuses[index].data
 }
 
+// ForEach visits each node of the underlying line interval tree in the ascending order of
+// the keys. The callback receives the index of the first line in the interval and the
+// corresponding value. The last visited node always carries TreeEnd and its line
+// equals to Len().
+func (file *File) ForEach(callback func(line, value int)) {
+	for iter := file.tree.Min(); !iter.Limit(); iter = iter.Next() {
+		item := iter.Item()
+		callback(item.Key, item.Value)
+	}
+}
+
+// Value returns the value of the specified line or TreeEnd if there is no such line.
+func (file *File) Value(line int) int {
+	if line < 0 || line >= file.Len() {
+		return TreeEnd
+	}
+	return file.tree.FindLE(line).Item().Value
+}
+
 // Dump formats the underlying line interval tree into a string.
 // Useful for error messages, panic()-s and debugging.
 func (file *File) Dump() string {
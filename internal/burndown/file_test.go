@@ -539,6 +539,53 @@ func TestFileFlatten(t *testing.T) {
 	assert.Len(t, lines, 130)
 }
 
+func TestFileForEach(t *testing.T) {
+	file, _ := fixtureFile()
+	file.Update(1, 20, 30, 0)
+	file.Update(4, 20, 10, 0)
+	// 0 0 | 20 4 | 30 1 | 60 0 | 140 -1
+	lines := []int{}
+	vals := []int{}
+	file.ForEach(func(line, value int) {
+		lines = append(lines, line)
+		vals = append(vals, value)
+	})
+	assert.Equal(t, []int{0, 20, 30, 60, 140}, lines)
+	assert.Equal(t, []int{0, 4, 1, 0, TreeEnd}, vals)
+}
+
+func TestFileValue(t *testing.T) {
+	file, _ := fixtureFile()
+	file.Update(1, 20, 30, 0)
+	file.Update(4, 20, 10, 0)
+	// 0 0 | 20 4 | 30 1 | 60 0 | 140 -1
+	assert.Equal(t, 0, file.Value(0))
+	assert.Equal(t, 0, file.Value(19))
+	assert.Equal(t, 4, file.Value(20))
+	assert.Equal(t, 1, file.Value(59))
+	assert.Equal(t, 0, file.Value(139))
+	assert.Equal(t, TreeEnd, file.Value(140))
+	assert.Equal(t, TreeEnd, file.Value(-1))
+}
+
+func TestFileDuplicate(t *testing.T) {
+	file, status := fixtureFile()
+	file.Update(1, 20, 30, 0)
+	// 0 0 | 20 1 | 50 0 | 130 -1
+	copyStatus := map[int]int64{}
+	hash := plumbing.NewHash("ffffffffffffffffffffffffffffffffffffffff")
+	clone := file.Duplicate(hash, NewStatus(copyStatus, updateStatusFile))
+	assert.Equal(t, hash, clone.Hash)
+	assert.Equal(t, plumbing.ZeroHash, file.Hash)
+	assert.Equal(t, file.Dump(), clone.Dump())
+	assert.Equal(t, map[int]int64{0: 100, 1: 30}, copyStatus)
+	assert.Equal(t, map[int]int64{0: 100, 1: 30}, status)
+	clone.Update(2, 0, 0, 10)
+	assert.Equal(t, "0 0\n20 1\n50 0\n130 -1\n", file.Dump())
+	assert.Equal(t, map[int]int64{0: 90, 1: 30, 2: 0}, copyStatus)
+	assert.Equal(t, int64(100), status[0])
+}
+
 func TestFileMergeMark(t *testing.T) {
 	file, status := fixtureFile()
 	// 0 0 | 100 -1                             [0]: 100
@@ -0,0 +1,310 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+)
+
+// CommitGraph is the parsed commit-graph file which git writes to speed up the history walks,
+// see `git commit-graph write`. It maps the commit hashes to the parents and to the generation
+// numbers without decoding the commit objects. Both the single .git/objects/info/commit-graph
+// and the split chains in .git/objects/info/commit-graphs are supported.
+type CommitGraph struct {
+	// layers are ordered from the base to the tip of the chain.
+	layers []*commitGraphLayer
+}
+
+// commitGraphLayer is one commit-graph file. The positions of the commits are global:
+// the commits in the base layers go first.
+type commitGraphLayer struct {
+	offset uint32
+	count  uint32
+	fanout []byte
+	oids   []byte
+	data   []byte
+	edges  []byte
+}
+
+const (
+	commitGraphSignature   = "CGPH"
+	commitGraphHeaderSize  = 8
+	commitGraphChunkSize   = 12
+	commitGraphDataSize    = 20 + 16
+	commitGraphParentNone  = 0x70000000
+	commitGraphParentEdges = 0x80000000
+	// commitGraphGenerationMax is the cap of the generation numbers.
+	commitGraphGenerationMax = 0x3fffffff
+
+	commitGraphChunkFanout = 0x4f494446 // "OIDF"
+	commitGraphChunkOIDs   = 0x4f49444c // "OIDL"
+	commitGraphChunkData   = 0x43444154 // "CDAT"
+	commitGraphChunkEdges  = 0x45444745 // "EDGE"
+	commitGraphChunkBase   = 0x42415345 // "BASE"
+)
+
+// LoadCommitGraph reads the commit-graph of the repository. It returns nil and no error
+// if the repository is not on disk or has no commit-graph.
+func LoadCommitGraph(repository *git.Repository) (*CommitGraph, error) {
+	if repository == nil {
+		return nil, nil
+	}
+	storage, ok := repository.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, nil
+	}
+	info := filepath.Join(storage.Filesystem().Root(), "objects", "info")
+	var files []string
+	if _, err := os.Stat(filepath.Join(info, "commit-graph")); err == nil {
+		files = []string{filepath.Join(info, "commit-graph")}
+	} else {
+		chain, err := ioutil.ReadFile(filepath.Join(info, "commit-graphs", "commit-graph-chain"))
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(chain))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				files = append(files, filepath.Join(info, "commit-graphs", "graph-"+line+".graph"))
+			}
+		}
+	}
+	graph := &CommitGraph{}
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err = graph.addLayer(contents); err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+	}
+	return graph, nil
+}
+
+// addLayer parses the commit-graph file and appends it to the chain.
+func (graph *CommitGraph) addLayer(contents []byte) error {
+	if len(contents) < commitGraphHeaderSize || string(contents[:4]) != commitGraphSignature {
+		return errors.New("not a commit-graph file")
+	}
+	if version := contents[4]; version != 1 {
+		return fmt.Errorf("unsupported commit-graph version %d", version)
+	}
+	if hashVersion := contents[5]; hashVersion != 1 {
+		return fmt.Errorf("unsupported commit-graph hash version %d", hashVersion)
+	}
+	chunks := int(contents[6])
+	if bases := int(contents[7]); bases != len(graph.layers) {
+		return fmt.Errorf("the commit-graph has %d bases, expected %d", bases, len(graph.layers))
+	}
+	layer := &commitGraphLayer{}
+	if len(graph.layers) > 0 {
+		last := graph.layers[len(graph.layers)-1]
+		layer.offset = last.offset + last.count
+	}
+	tableEnd := commitGraphHeaderSize + (chunks+1)*commitGraphChunkSize
+	if len(contents) < tableEnd {
+		return errors.New("truncated commit-graph chunk table")
+	}
+	for i := 0; i < chunks; i++ {
+		entry := contents[commitGraphHeaderSize+i*commitGraphChunkSize:]
+		id := binary.BigEndian.Uint32(entry)
+		begin := binary.BigEndian.Uint64(entry[4:])
+		end := binary.BigEndian.Uint64(entry[4+commitGraphChunkSize:])
+		if begin < uint64(tableEnd) || begin > end || end > uint64(len(contents)) {
+			return fmt.Errorf("invalid commit-graph chunk %x", id)
+		}
+		chunk := contents[begin:end]
+		switch id {
+		case commitGraphChunkFanout:
+			layer.fanout = chunk
+		case commitGraphChunkOIDs:
+			layer.oids = chunk
+		case commitGraphChunkData:
+			layer.data = chunk
+		case commitGraphChunkEdges:
+			layer.edges = chunk
+		case commitGraphChunkBase:
+			if len(chunk) != len(graph.layers)*20 {
+				return errors.New("invalid commit-graph base chunk")
+			}
+		}
+	}
+	if len(layer.fanout) != 256*4 {
+		return errors.New("invalid commit-graph fanout chunk")
+	}
+	layer.count = binary.BigEndian.Uint32(layer.fanout[255*4:])
+	if len(layer.oids) != int(layer.count)*20 || len(layer.data) != int(layer.count)*commitGraphDataSize {
+		return errors.New("invalid commit-graph commit chunks")
+	}
+	graph.layers = append(graph.layers, layer)
+	return nil
+}
+
+// Len returns the number of the commits in the graph.
+func (graph *CommitGraph) Len() int {
+	if len(graph.layers) == 0 {
+		return 0
+	}
+	last := graph.layers[len(graph.layers)-1]
+	return int(last.offset + last.count)
+}
+
+// Contains checks whether the commit is in the graph. The commits created after
+// the graph was written are not.
+func (graph *CommitGraph) Contains(hash plumbing.Hash) bool {
+	_, _, exists := graph.lookup(hash)
+	return exists
+}
+
+// ParentHashes returns the parents of the commit in the same order as object.Commit.ParentHashes.
+func (graph *CommitGraph) ParentHashes(hash plumbing.Hash) ([]plumbing.Hash, bool) {
+	layer, pos, exists := graph.lookup(hash)
+	if !exists {
+		return nil, false
+	}
+	positions, err := graph.parents(layer, pos)
+	if err != nil {
+		return nil, false
+	}
+	parents := make([]plumbing.Hash, 0, len(positions))
+	for _, parent := range positions {
+		parentHash, ok := graph.hash(parent)
+		if !ok {
+			return nil, false
+		}
+		parents = append(parents, parentHash)
+	}
+	return parents, true
+}
+
+// Generation returns the topological level of the commit: 1 for the roots, otherwise
+// the maximum generation of the parents plus one.
+func (graph *CommitGraph) Generation(hash plumbing.Hash) (uint32, bool) {
+	layer, pos, exists := graph.lookup(hash)
+	if !exists {
+		return 0, false
+	}
+	return graph.generation(layer, pos), true
+}
+
+func (graph *CommitGraph) generation(layer *commitGraphLayer, pos uint32) uint32 {
+	record := layer.data[pos*commitGraphDataSize:]
+	return binary.BigEndian.Uint32(record[28:]) >> 2
+}
+
+// lookup finds the commit in the layers: binary search in the range given by the fanout.
+func (graph *CommitGraph) lookup(hash plumbing.Hash) (*commitGraphLayer, uint32, bool) {
+	for _, layer := range graph.layers {
+		var begin uint32
+		if hash[0] > 0 {
+			begin = binary.BigEndian.Uint32(layer.fanout[(int(hash[0])-1)*4:])
+		}
+		end := binary.BigEndian.Uint32(layer.fanout[int(hash[0])*4:])
+		if end > layer.count || begin > end {
+			continue
+		}
+		for begin < end {
+			middle := (begin + end) / 2
+			switch bytes.Compare(layer.oids[middle*20:middle*20+20], hash[:]) {
+			case 0:
+				return layer, middle, true
+			case -1:
+				begin = middle + 1
+			default:
+				end = middle
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// hash returns the commit hash at the global position.
+func (graph *CommitGraph) hash(global uint32) (plumbing.Hash, bool) {
+	for _, layer := range graph.layers {
+		if global >= layer.offset && global < layer.offset+layer.count {
+			var hash plumbing.Hash
+			pos := global - layer.offset
+			copy(hash[:], layer.oids[pos*20:pos*20+20])
+			return hash, true
+		}
+	}
+	return plumbing.ZeroHash, false
+}
+
+// parents returns the global positions of the parents, the octopus merges keep the rest
+// of the parents in the edge chunk.
+func (graph *CommitGraph) parents(layer *commitGraphLayer, pos uint32) ([]uint32, error) {
+	record := layer.data[pos*commitGraphDataSize:]
+	first := binary.BigEndian.Uint32(record[20:])
+	second := binary.BigEndian.Uint32(record[24:])
+	if first == commitGraphParentNone {
+		return nil, nil
+	}
+	parents := []uint32{first}
+	if second == commitGraphParentNone {
+		return parents, nil
+	}
+	if second&commitGraphParentEdges == 0 {
+		return append(parents, second), nil
+	}
+	for edge := second &^ commitGraphParentEdges; ; edge++ {
+		if int(edge+1)*4 > len(layer.edges) {
+			return nil, errors.New("invalid commit-graph edge")
+		}
+		parent := binary.BigEndian.Uint32(layer.edges[edge*4:])
+		parents = append(parents, parent&^commitGraphParentEdges)
+		if parent&commitGraphParentEdges != 0 {
+			return parents, nil
+		}
+	}
+}
+
+// FirstParents walks the first parents from `head` while the commits are in the graph.
+// It returns the visited hashes starting with `head` and the first commit which is not
+// in the graph, plumbing.ZeroHash if the walk reached the root.
+func (graph *CommitGraph) FirstParents(head plumbing.Hash) ([]plumbing.Hash, plumbing.Hash, error) {
+	var hashes []plumbing.Hash
+	layer, pos, exists := graph.lookup(head)
+	if !exists {
+		return nil, head, nil
+	}
+	hash := head
+	for {
+		hashes = append(hashes, hash)
+		generation := graph.generation(layer, pos)
+		parents, err := graph.parents(layer, pos)
+		if err != nil {
+			return nil, plumbing.ZeroHash, err
+		}
+		if len(parents) == 0 {
+			return hashes, plumbing.ZeroHash, nil
+		}
+		var ok bool
+		if hash, ok = graph.hash(parents[0]); !ok {
+			return nil, plumbing.ZeroHash, fmt.Errorf("invalid commit-graph parent %d", parents[0])
+		}
+		if layer, pos, exists = graph.lookup(hash); !exists {
+			return hashes, hash, nil
+		}
+		// the generations decrease, otherwise the graph is corrupt and may have cycles
+		next := graph.generation(layer, pos)
+		if next >= generation && generation != 0 && generation != commitGraphGenerationMax {
+			return nil, plumbing.ZeroHash, fmt.Errorf(
+				"invalid commit-graph generation of %s", hash.String())
+		}
+	}
+}
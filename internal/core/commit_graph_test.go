@@ -0,0 +1,275 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// fixtureCommitGraph creates the repository with the merges and an octopus merge.
+// It returns the function which runs git in the repository.
+func fixtureCommitGraph(t *testing.T) (string, func(args ...string) string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root, err := ioutil.TempDir("", "hercules-graph-")
+	assert.Nil(t, err)
+	run := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{
+			"-c", "user.name=test", "-c", "user.email=test@test"}, args...)...)
+		cmd.Dir = root
+		output, err := cmd.CombinedOutput()
+		assert.Nil(t, err, string(output))
+		return strings.TrimSpace(string(output))
+	}
+	commit := func(name string) {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(root, name), []byte(name+"\n"), 0644))
+		run("add", name)
+		run("commit", "-q", "-m", name)
+	}
+	run("init", "-q")
+	commit("a")
+	commit("b")
+	base := run("symbolic-ref", "--short", "HEAD")
+	for _, branch := range []string{"x", "y", "z"} {
+		run("checkout", "-q", "-b", branch, base)
+		commit(branch)
+	}
+	run("checkout", "-q", base)
+	commit("c")
+	run("merge", "-q", "--no-edit", "x")
+	commit("d")
+	run("merge", "-q", "--no-edit", "y", "z")
+	commit("e")
+	return root, run
+}
+
+func openFixture(t *testing.T, root string) *git.Repository {
+	repository, err := git.PlainOpen(root)
+	assert.Nil(t, err)
+	return repository
+}
+
+// checkCommitGraph compares the graph with the commit objects and the generations
+// calculated from scratch.
+func checkCommitGraph(t *testing.T, repository *git.Repository, graph *CommitGraph,
+	hashes []string) {
+	generations := map[plumbing.Hash]uint32{}
+	var generation func(commit *object.Commit) uint32
+	generation = func(commit *object.Commit) uint32 {
+		if value, exists := generations[commit.Hash]; exists {
+			return value
+		}
+		var value uint32
+		for _, hash := range commit.ParentHashes {
+			parent, err := repository.CommitObject(hash)
+			assert.Nil(t, err)
+			if parentValue := generation(parent); parentValue > value {
+				value = parentValue
+			}
+		}
+		generations[commit.Hash] = value + 1
+		return value + 1
+	}
+	assert.Equal(t, len(hashes), graph.Len())
+	for _, hex := range hashes {
+		commit, err := repository.CommitObject(plumbing.NewHash(hex))
+		assert.Nil(t, err)
+		assert.True(t, graph.Contains(commit.Hash))
+		parents, exists := graph.ParentHashes(commit.Hash)
+		assert.True(t, exists)
+		if len(commit.ParentHashes) == 0 {
+			assert.Len(t, parents, 0)
+		} else {
+			assert.Equal(t, commit.ParentHashes, parents)
+		}
+		value, exists := graph.Generation(commit.Hash)
+		assert.True(t, exists)
+		assert.Equal(t, generation(commit), value, hex)
+	}
+	assert.False(t, graph.Contains(plumbing.ZeroHash))
+	_, exists := graph.ParentHashes(plumbing.ZeroHash)
+	assert.False(t, exists)
+	_, exists = graph.Generation(plumbing.ZeroHash)
+	assert.False(t, exists)
+}
+
+func commitHashes(commits []*object.Commit) []string {
+	hashes := make([]string, len(commits))
+	for i, commit := range commits {
+		hashes[i] = commit.Hash.String()
+	}
+	return hashes
+}
+
+func TestCommitGraph(t *testing.T) {
+	root, run := fixtureCommitGraph(t)
+	defer os.RemoveAll(root)
+	repository := openFixture(t, root)
+	graph, err := LoadCommitGraph(repository)
+	assert.Nil(t, err)
+	assert.Nil(t, graph)
+	expected := NewPipeline(repository).Commits()
+	assert.Equal(t, strings.Fields(run("rev-list", "--first-parent", "--reverse", "HEAD")),
+		commitHashes(expected))
+	run("commit-graph", "write", "--reachable")
+	repository = openFixture(t, root)
+	graph, err = LoadCommitGraph(repository)
+	assert.Nil(t, err)
+	assert.NotNil(t, graph)
+	assert.Len(t, graph.layers, 1)
+	checkCommitGraph(t, repository, graph, strings.Fields(run("rev-list", "--all")))
+	head, _ := repository.Head()
+	hashes, next, err := graph.FirstParents(head.Hash())
+	assert.Nil(t, err)
+	assert.Equal(t, plumbing.ZeroHash, next)
+	assert.Len(t, hashes, len(expected))
+	hashes, next, err = graph.FirstParents(plumbing.ZeroHash)
+	assert.Nil(t, err)
+	assert.Len(t, hashes, 0)
+	assert.Equal(t, plumbing.ZeroHash, next)
+	assert.Equal(t, commitHashes(expected), commitHashes(NewPipeline(repository).Commits()))
+}
+
+func TestCommitGraphChain(t *testing.T) {
+	root, run := fixtureCommitGraph(t)
+	defer os.RemoveAll(root)
+	run("commit-graph", "write", "--reachable")
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "f"), []byte("f\n"), 0644))
+	run("add", "f")
+	run("commit", "-q", "-m", "f")
+	run("commit-graph", "write", "--reachable", "--split")
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "g"), []byte("g\n"), 0644))
+	run("add", "g")
+	run("commit", "-q", "-m", "g")
+	run("commit-graph", "write", "--reachable", "--split=no-merge")
+	repository := openFixture(t, root)
+	graph, err := LoadCommitGraph(repository)
+	assert.Nil(t, err)
+	assert.NotNil(t, graph)
+	assert.True(t, len(graph.layers) > 1)
+	checkCommitGraph(t, repository, graph, strings.Fields(run("rev-list", "--all")))
+	assert.Equal(t, strings.Fields(run("rev-list", "--first-parent", "--reverse", "HEAD")),
+		commitHashes(NewPipeline(repository).Commits()))
+}
+
+func TestCommitGraphStale(t *testing.T) {
+	root, run := fixtureCommitGraph(t)
+	defer os.RemoveAll(root)
+	run("commit-graph", "write", "--reachable")
+	for _, name := range []string{"f", "g"} {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(root, name), []byte(name+"\n"), 0644))
+		run("add", name)
+		run("commit", "-q", "-m", name)
+	}
+	repository := openFixture(t, root)
+	graph, err := LoadCommitGraph(repository)
+	assert.Nil(t, err)
+	head, _ := repository.Head()
+	assert.False(t, graph.Contains(head.Hash()))
+	hashes, next, err := graph.FirstParents(head.Hash())
+	assert.Nil(t, err)
+	assert.Len(t, hashes, 0)
+	assert.Equal(t, head.Hash(), next)
+	// the new commits are walked with go-git
+	assert.Equal(t, strings.Fields(run("rev-list", "--first-parent", "--reverse", "HEAD")),
+		commitHashes(NewPipeline(repository).Commits()))
+}
+
+func TestCommitGraphCorrupt(t *testing.T) {
+	root, run := fixtureCommitGraph(t)
+	defer os.RemoveAll(root)
+	run("commit-graph", "write", "--reachable")
+	expected := strings.Fields(run("rev-list", "--first-parent", "--reverse", "HEAD"))
+	path := filepath.Join(root, ".git", "objects", "info", "commit-graph")
+	contents, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chmod(path, 0644))
+	for _, corrupt := range [][]byte{
+		[]byte("CGPH"),
+		append([]byte("XXXX"), contents[4:]...),
+		append(append([]byte{}, contents[:4]...), append([]byte{2}, contents[5:]...)...),
+		contents[:len(contents)/2],
+	} {
+		assert.Nil(t, ioutil.WriteFile(path, corrupt, 0644))
+		graph, err := LoadCommitGraph(openFixture(t, root))
+		assert.NotNil(t, err)
+		assert.Nil(t, graph)
+	}
+	// falls back to go-git
+	assert.Equal(t, expected, commitHashes(NewPipeline(openFixture(t, root)).Commits()))
+}
+
+func TestCommitGraphMemory(t *testing.T) {
+	graph, err := LoadCommitGraph(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, graph)
+	repository, err := git.Init(memory.NewStorage(), nil)
+	assert.Nil(t, err)
+	graph, err = LoadCommitGraph(repository)
+	assert.Nil(t, err)
+	assert.Nil(t, graph)
+	assert.Equal(t, 0, (&CommitGraph{}).Len())
+}
+
+func TestCommitGraphLazyCommits(t *testing.T) {
+	root, run := fixtureCommitGraph(t)
+	defer os.RemoveAll(root)
+	run("commit-graph", "write", "--reachable")
+	repository := openFixture(t, root)
+	commits := NewPipeline(repository).Commits()
+	decoded := make([]*object.Commit, len(commits))
+	for i, commit := range commits {
+		var err error
+		decoded[i], err = repository.CommitObject(commit.Hash)
+		assert.Nil(t, err)
+		assert.Len(t, commit.ParentHashes, len(decoded[i].ParentHashes))
+		for j, parent := range decoded[i].ParentHashes {
+			assert.Equal(t, parent, commit.ParentHashes[j])
+		}
+		if i < len(commits)-1 {
+			assert.Equal(t, plumbing.ZeroHash, commit.TreeHash)
+		} else {
+			// HEAD
+			assert.Equal(t, decoded[i].TreeHash, commit.TreeHash)
+		}
+	}
+	// the plan does not need the decoded commits
+	planHashes := func(plan []runAction) []string {
+		var hashes []string
+		for _, action := range plan {
+			if action.Commit != nil {
+				hashes = append(hashes, action.Commit.Hash.String())
+			}
+		}
+		return hashes
+	}
+	assert.Equal(t, planHashes(prepareRunPlan(decoded)), planHashes(prepareRunPlan(commits)))
+	pipeline := NewPipeline(repository)
+	pipeline.AddItem(&testPipelineItem{Merged: new(bool)})
+	facts := map[string]interface{}{ConfigPipelineCommits: commits}
+	pipeline.Initialize(facts)
+	result, err := pipeline.Run(commits)
+	assert.Nil(t, err)
+	for i, commit := range commits {
+		assert.Equal(t, decoded[i].TreeHash, commit.TreeHash)
+		assert.Equal(t, decoded[i].Author, commit.Author)
+	}
+	common := result[nil].(*CommonAnalysisResult)
+	assert.Equal(t, decoded[0].Author.When.Unix(), common.BeginTime)
+	// the loader fact
+	lazy := &object.Commit{Hash: commits[0].Hash}
+	assert.Nil(t, facts[FactPipelineCommitLoader].(CommitLoader)(lazy))
+	assert.Equal(t, decoded[0].Message, lazy.Message)
+	assert.NotNil(t, LoadCommit(repository, &object.Commit{Hash: plumbing.NewHash(
+		"1111111111111111111111111111111111111111")}))
+}
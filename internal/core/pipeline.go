@@ -141,6 +141,14 @@ type MergeablePipelineItem interface {
 	MergeResults(r1, r2 interface{}, c1, c2 *CommonAnalysisResult) interface{}
 }
 
+// DisposablePipelineItem holds the resources which must be released when Pipeline.Run() ends,
+// e.g. the background goroutines or the child processes.
+type DisposablePipelineItem interface {
+	PipelineItem
+	// Dispose releases the resources. Initialize() acquires them again.
+	Dispose()
+}
+
 // CommonAnalysisResult holds the information which is always extracted at Pipeline.Run().
 type CommonAnalysisResult struct {
 	// Time of the first commit in the analysed sequence.
@@ -151,6 +159,9 @@ type CommonAnalysisResult struct {
 	CommitsNumber int
 	// The duration of Pipeline.Run().
 	RunTime time.Duration
+	// Signature is either SignatureAuthor or SignatureCommitter - which commit identities
+	// and timestamps were analysed.
+	Signature string
 }
 
 // BeginTimeAsTime converts the UNIX timestamp of the beginning to Go time.
@@ -178,6 +189,10 @@ func (car *CommonAnalysisResult) Merge(other *CommonAnalysisResult) {
 	}
 	car.CommitsNumber += other.CommitsNumber
 	car.RunTime += other.RunTime
+	if car.Signature != other.Signature {
+		log.Printf("Warning: merging the results of the %s and the %s signatures\n",
+			car.Signature, other.Signature)
+	}
 }
 
 // FillMetadata copies the data to a Protobuf message.
@@ -186,6 +201,7 @@ func (car *CommonAnalysisResult) FillMetadata(meta *pb.Metadata) *pb.Metadata {
 	meta.EndUnixTime = car.EndTime
 	meta.Commits = int32(car.CommitsNumber)
 	meta.RunTime = car.RunTime.Nanoseconds() / 1e6
+	meta.Signature = car.Signature
 	return meta
 }
 
@@ -194,14 +210,23 @@ type Metadata = pb.Metadata
 
 // MetadataToCommonAnalysisResult copies the data from a Protobuf message.
 func MetadataToCommonAnalysisResult(meta *Metadata) *CommonAnalysisResult {
+	signature := meta.Signature
+	if signature == "" {
+		// written before the signature was configurable
+		signature = SignatureAuthor
+	}
 	return &CommonAnalysisResult{
 		BeginTime:     meta.BeginUnixTime,
 		EndTime:       meta.EndUnixTime,
 		CommitsNumber: int(meta.Commits),
 		RunTime:       time.Duration(meta.RunTime * 1e6),
+		Signature:     signature,
 	}
 }
 
+// CommitLoader decodes the commit in place, see LoadCommit().
+type CommitLoader func(commit *object.Commit) error
+
 // Pipeline is the core Hercules entity which carries several PipelineItems and executes them.
 // See the extended example of how a Pipeline works in doc.go
 type Pipeline struct {
@@ -222,6 +247,9 @@ type Pipeline struct {
 
 	// Feature flags which enable the corresponding items.
 	features map[string]bool
+
+	// signature is SignatureAuthor or SignatureCommitter, see ConfigPipelineSignature.
+	signature string
 }
 
 const (
@@ -236,6 +264,43 @@ const (
 	// ConfigPipelineCommits is the name of the Pipeline configuration option (Pipeline.Initialize())
 	// which allows to specify the custom commit sequence. By default, Pipeline.Commits() is used.
 	ConfigPipelineCommits = "commits"
+	// ConfigPipelineSignature is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which chooses between the commit author (SignatureAuthor, the default) and the committer
+	// (SignatureCommitter). The chosen signature provides both the identities and the timestamps.
+	ConfigPipelineSignature = "Pipeline.Signature"
+	// ConfigPipelineIgnoredCommits is the name of the Pipeline configuration option
+	// (Pipeline.Initialize()) which marks the commits whose changes are not attributed,
+	// for example, the mass reformatting. The value is map[plumbing.Hash]bool.
+	// The changed lines keep their previous authors and ages. By default, it is loaded
+	// with LoadIgnoreRevs().
+	ConfigPipelineIgnoredCommits = "Pipeline.IgnoredCommits"
+	// ConfigPipelineIgnoreRevsPath is the name of the Pipeline configuration option
+	// (Pipeline.Initialize()) which sets the path to the file with the ignored commits
+	// in addition to IgnoreRevsFileName in the repository.
+	ConfigPipelineIgnoreRevsPath = "Pipeline.IgnoreRevsPath"
+	// ConfigPipelineGitBackend is the name of the Pipeline configuration option
+	// (Pipeline.Initialize()) which chooses how the trees and the blobs are read:
+	// GitBackendGoGit (the default) or GitBackendExec.
+	ConfigPipelineGitBackend = "Pipeline.GitBackend"
+	// FactPipelineCommitLoader is the name of the fact which is set in Pipeline.Initialize()
+	// and provides the CommitLoader for the commits which are read before Run(), e.g.
+	// ConfigPipelineCommits.
+	FactPipelineCommitLoader = "Pipeline.CommitLoader"
+	// FactPipelineRepository is the name of the fact which is set in Pipeline.Initialize()
+	// and provides the analysed *git.Repository to Configure(), see LastCommitFile().
+	FactPipelineRepository = "Pipeline.Repository"
+	// IgnoreRevsFileName is the name of the file in the root of the repository which lists
+	// the commits ignored by `git blame`, see ConfigPipelineIgnoredCommits.
+	IgnoreRevsFileName = ".git-blame-ignore-revs"
+	// GitBackendGoGit is the value of ConfigPipelineGitBackend which reads the objects with go-git.
+	GitBackendGoGit = "go-git"
+	// GitBackendExec is the value of ConfigPipelineGitBackend which runs the local git executable:
+	// `git diff-tree`, `git ls-tree` and `git cat-file --batch`.
+	GitBackendExec = "exec"
+	// SignatureAuthor is the value of ConfigPipelineSignature which selects the commit authors.
+	SignatureAuthor = "author"
+	// SignatureCommitter is the value of ConfigPipelineSignature which selects the committers.
+	SignatureCommitter = "committer"
 	// DependencyCommit is the name of one of the two items in `deps` supplied to PipelineItem.Consume()
 	// which always exists. It corresponds to the currently analyzed commit.
 	DependencyCommit = "commit"
@@ -251,7 +316,17 @@ func NewPipeline(repository *git.Repository) *Pipeline {
 		items:      []PipelineItem{},
 		facts:      map[string]interface{}{},
 		features:   map[string]bool{},
+		signature:  SignatureAuthor,
+	}
+}
+
+// CommitSignature returns the commit author or the committer depending on `kind` -
+// SignatureAuthor or SignatureCommitter.
+func CommitSignature(commit *object.Commit, kind string) object.Signature {
+	if kind == SignatureCommitter {
+		return commit.Committer
 	}
+	return commit.Author
 }
 
 // GetFact returns the value of the fact with the specified name.
@@ -365,6 +440,9 @@ func (pipeline *Pipeline) Len() int {
 // Commits returns the critical path in the repository's history. It starts
 // from HEAD and traces commits backwards till the root. When it encounters
 // a merge (more than one parent), it always chooses the first parent.
+// The walk reads git's commit-graph if it exists, see LoadCommitGraph(). The commits found
+// in the graph except HEAD are not decoded: they have only the hash and the parents until
+// LoadCommit() is called, which Run() does before consuming each commit.
 func (pipeline *Pipeline) Commits() []*object.Commit {
 	result := []*object.Commit{}
 	repository := pipeline.repository
@@ -372,16 +450,47 @@ func (pipeline *Pipeline) Commits() []*object.Commit {
 	if err != nil {
 		panic(err)
 	}
-	commit, err := repository.CommitObject(head.Hash())
+	graph, err := LoadCommitGraph(repository)
 	if err != nil {
-		panic(err)
+		log.Printf("Failed to read the commit-graph: %v\n", err)
+		graph = nil
 	}
-	// the first parent matches the head
-	for ; err != io.EOF; commit, err = commit.Parents().Next() {
+	load := func(hash plumbing.Hash) *object.Commit {
+		commit, err := repository.CommitObject(hash)
 		if err != nil {
 			panic(err)
 		}
-		result = append(result, commit)
+		return commit
+	}
+	// the first parent matches the head
+	for hash := head.Hash(); hash != plumbing.ZeroHash; {
+		var hashes []plumbing.Hash
+		next := plumbing.ZeroHash
+		if graph != nil {
+			hashes, next, err = graph.FirstParents(hash)
+			if err != nil {
+				log.Printf("Failed to walk the commit-graph: %v\n", err)
+				graph = nil
+			}
+		}
+		if len(hashes) == 0 {
+			// the commits which are newer than the commit-graph
+			commit := load(hash)
+			result = append(result, commit)
+			if commit.NumParents() > 0 {
+				next = commit.ParentHashes[0]
+			}
+		}
+		for _, hash := range hashes {
+			parents, exists := graph.ParentHashes(hash)
+			if !exists || hash == head.Hash() {
+				// HEAD provides .mailmap and IgnoreRevsFileName
+				result = append(result, load(hash))
+				continue
+			}
+			result = append(result, &object.Commit{Hash: hash, ParentHashes: parents})
+		}
+		hash = next
 	}
 	// reverse the order
 	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
@@ -539,6 +648,37 @@ func (pipeline *Pipeline) Initialize(facts map[string]interface{}) {
 	if _, exists := facts[ConfigPipelineCommits]; !exists {
 		facts[ConfigPipelineCommits] = pipeline.Commits()
 	}
+	facts[FactPipelineRepository] = pipeline.repository
+	facts[FactPipelineCommitLoader] = CommitLoader(func(commit *object.Commit) error {
+		return LoadCommit(pipeline.repository, commit)
+	})
+	switch signature, _ := facts[ConfigPipelineSignature].(string); signature {
+	case "", SignatureAuthor:
+		pipeline.signature = SignatureAuthor
+	case SignatureCommitter:
+		pipeline.signature = SignatureCommitter
+	default:
+		log.Printf("Unknown signature: %s, analysing the authors\n", signature)
+		pipeline.signature = SignatureAuthor
+	}
+	facts[ConfigPipelineSignature] = pipeline.signature
+	switch backend, _ := facts[ConfigPipelineGitBackend].(string); backend {
+	case "":
+		facts[ConfigPipelineGitBackend] = GitBackendGoGit
+	case GitBackendGoGit, GitBackendExec:
+	default:
+		log.Printf("Unknown git backend: %s, using %s\n", backend, GitBackendGoGit)
+		facts[ConfigPipelineGitBackend] = GitBackendGoGit
+	}
+	if _, exists := facts[ConfigPipelineIgnoredCommits]; !exists {
+		commits, _ := facts[ConfigPipelineCommits].([]*object.Commit)
+		path, _ := facts[ConfigPipelineIgnoreRevsPath].(string)
+		ignored, err := LoadIgnoreRevs(pipeline.repository, commits, path)
+		if err != nil {
+			log.Printf("Failed to load the ignored commits from %s: %v\n", path, err)
+		}
+		facts[ConfigPipelineIgnoredCommits] = ignored
+	}
 	dumpPath, _ := facts[ConfigPipelineDumpPath].(string)
 	pipeline.resolve(dumpPath)
 	if dryRun, _ := facts[ConfigPipelineDryRun].(bool); dryRun {
@@ -567,12 +707,17 @@ func (pipeline *Pipeline) Run(commits []*object.Commit) (map[LeafPipelineItem]in
 	plan := prepareRunPlan(commits)
 	progressSteps := len(plan) + 2
 	branches := map[int][]PipelineItem{0: pipeline.items}
+	defer pipeline.dispose()
 
 	for index, step := range plan {
 		onProgress(index + 1, progressSteps)
 		firstItem := step.Items[0]
 		switch step.Action {
 		case runActionCommit:
+			if err := LoadCommit(pipeline.repository, step.Commit); err != nil {
+				log.Printf("Failed to load commit #%d %s\n", index + 1, step.Commit.Hash.String())
+				return nil, err
+			}
 			state := map[string]interface{}{
 				DependencyCommit: step.Commit,
 				DependencyIndex: index,
@@ -614,15 +759,45 @@ func (pipeline *Pipeline) Run(commits []*object.Commit) (map[LeafPipelineItem]in
 		}
 	}
 	onProgress(progressSteps, progressSteps)
+	for _, commit := range [...]*object.Commit{commits[0], commits[len(commits)-1]} {
+		if err := LoadCommit(pipeline.repository, commit); err != nil {
+			return nil, err
+		}
+	}
 	result[nil] = &CommonAnalysisResult{
-		BeginTime:     commits[0].Author.When.Unix(),
-		EndTime:       commits[len(commits)-1].Author.When.Unix(),
+		BeginTime:     CommitSignature(commits[0], pipeline.signature).When.Unix(),
+		EndTime:       CommitSignature(commits[len(commits)-1], pipeline.signature).When.Unix(),
 		CommitsNumber: len(commits),
 		RunTime:       time.Since(startRunTime),
+		Signature:     pipeline.signature,
 	}
 	return result, nil
 }
 
+// dispose calls Dispose() on each DisposablePipelineItem. The forks share the resources with
+// the original items, so it is enough to dispose the latter.
+func (pipeline *Pipeline) dispose() {
+	for _, item := range pipeline.items {
+		if casted, ok := item.(DisposablePipelineItem); ok {
+			casted.Dispose()
+		}
+	}
+}
+
+// LoadCommit decodes the commit in place if it has only the hash and the parents,
+// see Pipeline.Commits(). The other commits are not changed.
+func LoadCommit(repository *git.Repository, commit *object.Commit) error {
+	if commit.TreeHash != plumbing.ZeroHash {
+		return nil
+	}
+	decoded, err := repository.CommitObject(commit.Hash)
+	if err != nil {
+		return err
+	}
+	*commit = *decoded
+	return nil
+}
+
 // LoadCommitsFromFile reads the file by the specified FS path and generates the sequence of commits
 // by interpreting each line as a Git commit hash.
 func LoadCommitsFromFile(path string, repository *git.Repository) ([]*object.Commit, error) {
@@ -652,3 +827,72 @@ func LoadCommitsFromFile(path string, repository *git.Repository) ([]*object.Com
 	}
 	return commits, nil
 }
+
+// ParseIgnoreRevs adds the commit hashes listed in `contents` to `ignored`. The format is
+// the same as of `git blame --ignore-revs-file`: one full hash per line, "#" starts a comment.
+func ParseIgnoreRevs(contents string, ignored map[plumbing.Hash]bool) error {
+	for _, line := range strings.Split(contents, "\n") {
+		if pos := strings.IndexByte(line, '#'); pos >= 0 {
+			line = line[:pos]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		hash := plumbing.NewHash(line)
+		if len(line) != 40 || hash.String() != strings.ToLower(line) {
+			return errors.New("invalid commit hash " + line)
+		}
+		ignored[hash] = true
+	}
+	return nil
+}
+
+// LastCommitFile returns the contents of the file in the last of `commits`. The commit is
+// loaded from the repository by its hash since the commits built by hand have no object storage;
+// if `repository` is nil, the commit is read as is. The second returned value is false if there
+// are no commits, the commit cannot be loaded (which is logged) or the file does not exist.
+func LastCommitFile(repository *git.Repository, commits []*object.Commit, name string) (string, bool) {
+	if len(commits) == 0 {
+		return "", false
+	}
+	commit := commits[len(commits)-1]
+	if repository != nil {
+		var err error
+		if commit, err = repository.CommitObject(commit.Hash); err != nil {
+			log.Printf("Failed to load commit %s to read %s: %v\n",
+				commits[len(commits)-1].Hash.String(), name, err)
+			return "", false
+		}
+	}
+	file, err := commit.File(name)
+	if err != nil {
+		return "", false
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return "", false
+	}
+	return contents, true
+}
+
+// LoadIgnoreRevs reads the commits which are ignored for attribution from IgnoreRevsFileName
+// in the last of `commits` and then from the file by the specified FS path if it is not empty.
+// See LastCommitFile() about `repository`. The result is never nil.
+func LoadIgnoreRevs(repository *git.Repository, commits []*object.Commit, path string) (
+	map[plumbing.Hash]bool, error) {
+	ignored := map[plumbing.Hash]bool{}
+	if contents, exists := LastCommitFile(repository, commits, IgnoreRevsFileName); exists {
+		if err := ParseIgnoreRevs(contents, ignored); err != nil {
+			log.Printf("Skipped the rest of %s: %v\n", IgnoreRevsFileName, err)
+		}
+	}
+	if path == "" {
+		return ignored, nil
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ignored, err
+	}
+	return ignored, ParseIgnoreRevs(string(contents), ignored)
+}
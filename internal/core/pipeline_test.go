@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -106,6 +107,15 @@ func (item *testPipelineItem) Serialize(result interface{}, binary bool, writer
 	return nil
 }
 
+type disposableTestPipelineItem struct {
+	testPipelineItem
+	Disposed int
+}
+
+func (item *disposableTestPipelineItem) Dispose() {
+	item.Disposed++
+}
+
 type dependingTestPipelineItem struct {
 	DependencySatisfied  bool
 	TestNilConsumeReturn bool
@@ -327,6 +337,66 @@ func TestLoadCommitsFromFile(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestParseIgnoreRevs(t *testing.T) {
+	ignored := map[plumbing.Hash]bool{}
+	err := ParseIgnoreRevs("# reformatting\n"+
+		"cce947b98a050c6d356bc6ba95030254914027b1\n\n"+
+		"  6DB8065CDB9BB0758F36A7E75FC72AB95F9E8145 # gofmt\n", ignored)
+	assert.Nil(t, err)
+	assert.Equal(t, map[plumbing.Hash]bool{
+		plumbing.NewHash("cce947b98a050c6d356bc6ba95030254914027b1"): true,
+		plumbing.NewHash("6db8065cdb9bb0758f36a7e75fc72ab95f9e8145"): true,
+	}, ignored)
+	assert.NotNil(t, ParseIgnoreRevs("cce947b", ignored))
+	assert.NotNil(t, ParseIgnoreRevs("cce947b98a050c6d356bc6ba95030254914027bx", ignored))
+	assert.Len(t, ignored, 2)
+}
+
+func TestLoadIgnoreRevs(t *testing.T) {
+	ignored, err := LoadIgnoreRevs(nil, nil, "")
+	assert.Nil(t, err)
+	assert.Equal(t, map[plumbing.Hash]bool{}, ignored)
+	tmp, err := ioutil.TempFile("", "hercules-test-")
+	assert.Nil(t, err)
+	tmp.WriteString("cce947b98a050c6d356bc6ba95030254914027b1\n")
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	ignored, err = LoadIgnoreRevs(nil, nil, tmp.Name())
+	assert.Nil(t, err)
+	assert.Equal(t, map[plumbing.Hash]bool{
+		plumbing.NewHash("cce947b98a050c6d356bc6ba95030254914027b1"): true}, ignored)
+	ignored, err = LoadIgnoreRevs(nil, nil, "/WAT?xxx!")
+	assert.NotNil(t, err)
+	assert.NotNil(t, ignored)
+}
+
+func TestPipelineInitializeBareCommits(t *testing.T) {
+	root, run := fixtureCommitGraph(t)
+	defer os.RemoveAll(root)
+	ignoredHash := run("rev-parse", "HEAD~1")
+	assert.Nil(t, ioutil.WriteFile(
+		filepath.Join(root, IgnoreRevsFileName), []byte(ignoredHash+"\n"), 0644))
+	run("add", IgnoreRevsFileName)
+	run("commit", "-q", "-m", "ignore")
+	repository := openFixture(t, root)
+	head, err := repository.Head()
+	assert.Nil(t, err)
+	// no object storage
+	commits := []*object.Commit{{Hash: head.Hash()}}
+	facts := map[string]interface{}{ConfigPipelineCommits: commits}
+	NewPipeline(repository).Initialize(facts)
+	assert.Equal(t, map[plumbing.Hash]bool{plumbing.NewHash(ignoredHash): true},
+		facts[ConfigPipelineIgnoredCommits])
+	// the unknown commit is skipped
+	commits = []*object.Commit{{Hash: plumbing.NewHash("1111111111111111111111111111111111111111")}}
+	facts = map[string]interface{}{ConfigPipelineCommits: commits}
+	NewPipeline(repository).Initialize(facts)
+	assert.Equal(t, map[plumbing.Hash]bool{}, facts[ConfigPipelineIgnoredCommits])
+	contents, exists := LastCommitFile(nil, nil, IgnoreRevsFileName)
+	assert.False(t, exists)
+	assert.Equal(t, "", contents)
+}
+
 func TestPipelineDeps(t *testing.T) {
 	pipeline := NewPipeline(test.Repository)
 	item1 := &dependingTestPipelineItem{}
@@ -353,6 +423,23 @@ func TestPipelineDeployFeatures(t *testing.T) {
 	assert.True(t, f)
 }
 
+func TestPipelineDispose(t *testing.T) {
+	pipeline := NewPipeline(test.Repository)
+	item := &disposableTestPipelineItem{testPipelineItem: testPipelineItem{Merged: new(bool)}}
+	pipeline.AddItem(item)
+	pipeline.Initialize(map[string]interface{}{})
+	commits := make([]*object.Commit, 1)
+	commits[0], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"af9ddc0db70f09f3f27b4b98e415592a7485171c"))
+	_, err := pipeline.Run(commits)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, item.Disposed)
+	item.TestError = true
+	_, err = pipeline.Run(commits)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, item.Disposed)
+}
+
 func TestPipelineError(t *testing.T) {
 	pipeline := NewPipeline(test.Repository)
 	item := &testPipelineItem{}
@@ -383,13 +470,76 @@ func TestCommonAnalysisResultMerge(t *testing.T) {
 
 func TestCommonAnalysisResultMetadata(t *testing.T) {
 	c1 := &CommonAnalysisResult{
-		BeginTime: 1513620635, EndTime: 1513720635, CommitsNumber: 1, RunTime: 100 * 1e6}
+		BeginTime: 1513620635, EndTime: 1513720635, CommitsNumber: 1, RunTime: 100 * 1e6,
+		Signature: SignatureCommitter}
 	meta := &pb.Metadata{}
 	c1 = MetadataToCommonAnalysisResult(c1.FillMetadata(meta))
 	assert.Equal(t, c1.BeginTimeAsTime().Unix(), int64(1513620635))
 	assert.Equal(t, c1.EndTimeAsTime().Unix(), int64(1513720635))
 	assert.Equal(t, c1.CommitsNumber, 1)
 	assert.Equal(t, c1.RunTime.Nanoseconds(), int64(100*1e6))
+	assert.Equal(t, SignatureCommitter, meta.Signature)
+	assert.Equal(t, SignatureCommitter, c1.Signature)
+	c1 = MetadataToCommonAnalysisResult(&pb.Metadata{})
+	assert.Equal(t, SignatureAuthor, c1.Signature)
+}
+
+func TestCommitSignature(t *testing.T) {
+	commit := &object.Commit{
+		Author:    object.Signature{Name: "author"},
+		Committer: object.Signature{Name: "committer"},
+	}
+	assert.Equal(t, "author", CommitSignature(commit, SignatureAuthor).Name)
+	assert.Equal(t, "author", CommitSignature(commit, "").Name)
+	assert.Equal(t, "committer", CommitSignature(commit, SignatureCommitter).Name)
+}
+
+func TestPipelineSignature(t *testing.T) {
+	commit := &object.Commit{
+		Hash: plumbing.NewHash("af9ddc0db70f09f3f27b4b98e415592a7485171c"),
+		// not zero, otherwise Run() replaces the commit with the decoded one
+		TreeHash:  plumbing.NewHash("1111111111111111111111111111111111111111"),
+		Author:    object.Signature{When: time.Unix(1481719092, 0)},
+		Committer: object.Signature{When: time.Unix(1481720000, 0)},
+	}
+	commits := []*object.Commit{commit}
+	for signature, expected := range map[string]string{
+		"":                 SignatureAuthor,
+		SignatureCommitter: SignatureCommitter,
+		"whatever":         SignatureAuthor,
+	} {
+		when := map[string]int64{SignatureAuthor: 1481719092, SignatureCommitter: 1481720000}[expected]
+		pipeline := NewPipeline(test.Repository)
+		facts := map[string]interface{}{ConfigPipelineCommits: commits}
+		if signature != "" {
+			facts[ConfigPipelineSignature] = signature
+		}
+		pipeline.Initialize(facts)
+		assert.Equal(t, expected, facts[ConfigPipelineSignature])
+		result, err := pipeline.Run(commits)
+		assert.Nil(t, err)
+		common := result[nil].(*CommonAnalysisResult)
+		assert.Equal(t, expected, common.Signature)
+		assert.Equal(t, when, common.BeginTime)
+		assert.Equal(t, when, common.EndTime)
+	}
+}
+
+func TestPipelineGitBackend(t *testing.T) {
+	for backend, expected := range map[string]string{
+		"":              GitBackendGoGit,
+		GitBackendGoGit: GitBackendGoGit,
+		GitBackendExec:  GitBackendExec,
+		"libgit2":       GitBackendGoGit,
+	} {
+		pipeline := NewPipeline(test.Repository)
+		facts := map[string]interface{}{ConfigPipelineCommits: []*object.Commit{}}
+		if backend != "" {
+			facts[ConfigPipelineGitBackend] = backend
+		}
+		pipeline.Initialize(facts)
+		assert.Equal(t, expected, facts[ConfigPipelineGitBackend])
+	}
 }
 
 func TestConfigurationOptionTypeString(t *testing.T) {
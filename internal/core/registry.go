@@ -208,6 +208,23 @@ func (registry *PipelineItemRegistry) AddFlags(flagSet *pflag.FlagSet) (
 		*ptr2 = flagSet.Bool("dry-run", false, "Do not run any analyses - only resolve the DAG. "+
 			"Useful for -dump-dag.")
 		flags[ConfigPipelineDryRun] = iface
+		iface = interface{}("")
+		ptr3 := (**string)(unsafe.Pointer(uintptr(unsafe.Pointer(&iface)) + unsafe.Sizeof(&iface)))
+		*ptr3 = flagSet.String("signature", SignatureAuthor, fmt.Sprintf(
+			"Analyse the commit %s or %s: their identities and timestamps.",
+			SignatureAuthor, SignatureCommitter))
+		flags[ConfigPipelineSignature] = iface
+		iface = interface{}("")
+		ptr4 := (**string)(unsafe.Pointer(uintptr(unsafe.Pointer(&iface)) + unsafe.Sizeof(&iface)))
+		*ptr4 = flagSet.String("ignore-revs", "", "Path to the file with the commits whose "+
+			"changes are not attributed to their authors, in addition to "+IgnoreRevsFileName+".")
+		flags[ConfigPipelineIgnoreRevsPath] = iface
+		iface = interface{}("")
+		ptr5 := (**string)(unsafe.Pointer(uintptr(unsafe.Pointer(&iface)) + unsafe.Sizeof(&iface)))
+		*ptr5 = flagSet.String("git-backend", GitBackendGoGit, fmt.Sprintf(
+			"Read the trees and the blobs with %s or with the local git executable (%s).",
+			GitBackendGoGit, GitBackendExec))
+		flags[ConfigPipelineGitBackend] = iface
 	}
 	features := []string{}
 	for f := range registry.featureFlags.Choices {
@@ -27,29 +27,44 @@ func TestPipelineSerialize(t *testing.T) {
 	bdot, _ := ioutil.ReadFile(dotpath)
 	dot := string(bdot)
 	assert.Equal(t, `digraph Hercules {
-  "6 BlobCache" -> "7 [blob_cache]"
+  "10 BlobCache" -> "11 [blob_cache]"
+  "10 BlobCache" -> "12 [blob_classes]"
   "0 DaysSinceStart" -> "3 [day]"
-  "9 FileDiff" -> "11 [file_diff]"
-  "15 FileDiffRefiner" -> "16 Burndown"
+  "15 FileDiff" -> "17 [file_diff]"
+  "21 FileDiffRefiner" -> "22 Burndown"
   "1 IdentityDetector" -> "4 [author]"
-  "8 RenameAnalysis" -> "16 Burndown"
-  "8 RenameAnalysis" -> "9 FileDiff"
-  "8 RenameAnalysis" -> "10 UAST"
-  "8 RenameAnalysis" -> "13 UASTChanges"
-  "2 TreeDiff" -> "5 [changes]"
-  "10 UAST" -> "12 [uasts]"
-  "13 UASTChanges" -> "14 [changed_uasts]"
-  "4 [author]" -> "16 Burndown"
-  "7 [blob_cache]" -> "16 Burndown"
-  "7 [blob_cache]" -> "9 FileDiff"
-  "7 [blob_cache]" -> "8 RenameAnalysis"
-  "7 [blob_cache]" -> "10 UAST"
-  "14 [changed_uasts]" -> "15 FileDiffRefiner"
-  "5 [changes]" -> "6 BlobCache"
-  "5 [changes]" -> "8 RenameAnalysis"
-  "3 [day]" -> "16 Burndown"
-  "11 [file_diff]" -> "15 FileDiffRefiner"
-  "12 [uasts]" -> "13 UASTChanges"
+  "1 IdentityDetector" -> "6 [authors]"
+  "1 IdentityDetector" -> "5 [team]"
+  "1 IdentityDetector" -> "7 [teams]"
+  "13 RenameAnalysis" -> "22 Burndown"
+  "13 RenameAnalysis" -> "15 FileDiff"
+  "13 RenameAnalysis" -> "16 UAST"
+  "13 RenameAnalysis" -> "19 UASTChanges"
+  "13 RenameAnalysis" -> "14 [copies]"
+  "2 TreeDiff" -> "9 [change_kinds]"
+  "2 TreeDiff" -> "8 [changes]"
+  "16 UAST" -> "18 [uasts]"
+  "19 UASTChanges" -> "20 [changed_uasts]"
+  "4 [author]" -> "22 Burndown"
+  "6 [authors]" -> "22 Burndown"
+  "11 [blob_cache]" -> "22 Burndown"
+  "11 [blob_cache]" -> "15 FileDiff"
+  "11 [blob_cache]" -> "13 RenameAnalysis"
+  "11 [blob_cache]" -> "16 UAST"
+  "12 [blob_classes]" -> "22 Burndown"
+  "12 [blob_classes]" -> "15 FileDiff"
+  "12 [blob_classes]" -> "13 RenameAnalysis"
+  "9 [change_kinds]" -> "22 Burndown"
+  "9 [change_kinds]" -> "15 FileDiff"
+  "20 [changed_uasts]" -> "21 FileDiffRefiner"
+  "8 [changes]" -> "10 BlobCache"
+  "8 [changes]" -> "13 RenameAnalysis"
+  "14 [copies]" -> "22 Burndown"
+  "3 [day]" -> "22 Burndown"
+  "17 [file_diff]" -> "21 FileDiffRefiner"
+  "5 [team]" -> "22 Burndown"
+  "7 [teams]" -> "22 Burndown"
+  "18 [uasts]" -> "19 UASTChanges"
 }`, dot)
 }
 
@@ -67,21 +82,36 @@ func TestPipelineSerializeNoUast(t *testing.T) {
 	bdot, _ := ioutil.ReadFile(dotpath)
 	dot := string(bdot)
 	assert.Equal(t, `digraph Hercules {
-  "6 BlobCache" -> "7 [blob_cache]"
+  "10 BlobCache" -> "11 [blob_cache]"
+  "10 BlobCache" -> "12 [blob_classes]"
   "0 DaysSinceStart" -> "3 [day]"
-  "9 FileDiff" -> "10 [file_diff]"
+  "15 FileDiff" -> "16 [file_diff]"
   "1 IdentityDetector" -> "4 [author]"
-  "8 RenameAnalysis" -> "11 Burndown"
-  "8 RenameAnalysis" -> "9 FileDiff"
-  "2 TreeDiff" -> "5 [changes]"
-  "4 [author]" -> "11 Burndown"
-  "7 [blob_cache]" -> "11 Burndown"
-  "7 [blob_cache]" -> "9 FileDiff"
-  "7 [blob_cache]" -> "8 RenameAnalysis"
-  "5 [changes]" -> "6 BlobCache"
-  "5 [changes]" -> "8 RenameAnalysis"
-  "3 [day]" -> "11 Burndown"
-  "10 [file_diff]" -> "11 Burndown"
+  "1 IdentityDetector" -> "6 [authors]"
+  "1 IdentityDetector" -> "5 [team]"
+  "1 IdentityDetector" -> "7 [teams]"
+  "13 RenameAnalysis" -> "17 Burndown"
+  "13 RenameAnalysis" -> "15 FileDiff"
+  "13 RenameAnalysis" -> "14 [copies]"
+  "2 TreeDiff" -> "9 [change_kinds]"
+  "2 TreeDiff" -> "8 [changes]"
+  "4 [author]" -> "17 Burndown"
+  "6 [authors]" -> "17 Burndown"
+  "11 [blob_cache]" -> "17 Burndown"
+  "11 [blob_cache]" -> "15 FileDiff"
+  "11 [blob_cache]" -> "13 RenameAnalysis"
+  "12 [blob_classes]" -> "17 Burndown"
+  "12 [blob_classes]" -> "15 FileDiff"
+  "12 [blob_classes]" -> "13 RenameAnalysis"
+  "9 [change_kinds]" -> "17 Burndown"
+  "9 [change_kinds]" -> "15 FileDiff"
+  "8 [changes]" -> "10 BlobCache"
+  "8 [changes]" -> "13 RenameAnalysis"
+  "14 [copies]" -> "17 Burndown"
+  "3 [day]" -> "17 Burndown"
+  "16 [file_diff]" -> "17 Burndown"
+  "5 [team]" -> "17 Burndown"
+  "7 [teams]" -> "17 Burndown"
 }`, dot)
 }
 
@@ -11,6 +11,9 @@ It has these top-level messages:
 	Metadata
 	BurndownSparseMatrixRow
 	BurndownSparseMatrix
+	SurvivalCurve
+	BurndownSurvival
+	FileBlame
 	BurndownAnalysisResults
 	CompressedSparseRowMatrix
 	Couples
@@ -22,8 +25,16 @@ It has these top-level messages:
 	ShotnessAnalysisResults
 	FileHistory
 	FileHistoryResultMessage
+	PathOwnership
+	OwnershipAnalysisResults
+	ChurnStats
+	ChurnTimeline
+	ChurnAnalysisResults
 	Sentiment
 	CommentSentimentResults
+	SubmoduleBump
+	SubmoduleResults
+	SubmodulesAnalysisResults
 	AnalysisResults
 */
 package pb
@@ -58,6 +69,8 @@ type Metadata struct {
 	Commits int32 `protobuf:"varint,6,opt,name=commits,proto3" json:"commits,omitempty"`
 	// duration of the analysis in milliseconds
 	RunTime int64 `protobuf:"varint,7,opt,name=run_time,json=runTime,proto3" json:"run_time,omitempty"`
+	// "author" or "committer" - which commit signatures were analysed
+	Signature string `protobuf:"bytes,8,opt,name=signature,proto3" json:"signature,omitempty"`
 }
 
 func (m *Metadata) Reset()                    { *m = Metadata{} }
@@ -114,6 +127,13 @@ func (m *Metadata) GetRunTime() int64 {
 	return 0
 }
 
+func (m *Metadata) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
 type BurndownSparseMatrixRow struct {
 	// the first `len(column)` elements are stored,
 	// the rest `number_of_columns - len(column)` values are zeros
@@ -173,6 +193,128 @@ func (m *BurndownSparseMatrix) GetRows() []*BurndownSparseMatrixRow {
 	return nil
 }
 
+type SurvivalCurve struct {
+	// file path, developer's name or "project"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// the age of the lines in days, starts with 0
+	Days []int32 `protobuf:"varint,2,rep,packed,name=days" json:"days,omitempty"`
+	// `len(probabilities)` matches `len(days)`, the estimated chance to live longer than `days`
+	Probabilities []float32 `protobuf:"fixed32,3,rep,packed,name=probabilities" json:"probabilities,omitempty"`
+	// the number of days after which half of the lines are deleted; -1 if it was never reached
+	HalfLife float32 `protobuf:"fixed32,4,opt,name=half_life,json=halfLife,proto3" json:"half_life,omitempty"`
+}
+
+func (m *SurvivalCurve) Reset()                    { *m = SurvivalCurve{} }
+func (m *SurvivalCurve) String() string            { return proto.CompactTextString(m) }
+func (*SurvivalCurve) ProtoMessage()               {}
+func (*SurvivalCurve) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{3} }
+
+func (m *SurvivalCurve) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SurvivalCurve) GetDays() []int32 {
+	if m != nil {
+		return m.Days
+	}
+	return nil
+}
+
+func (m *SurvivalCurve) GetProbabilities() []float32 {
+	if m != nil {
+		return m.Probabilities
+	}
+	return nil
+}
+
+func (m *SurvivalCurve) GetHalfLife() float32 {
+	if m != nil {
+		return m.HalfLife
+	}
+	return 0
+}
+
+type BurndownSurvival struct {
+	Project *SurvivalCurve `protobuf:"bytes,1,opt,name=project" json:"project,omitempty"`
+	// this is included if `-burndown-files` was specified
+	Files []*SurvivalCurve `protobuf:"bytes,2,rep,name=files" json:"files,omitempty"`
+	// this is included if `-burndown-people` was specified
+	People []*SurvivalCurve `protobuf:"bytes,3,rep,name=people" json:"people,omitempty"`
+}
+
+func (m *BurndownSurvival) Reset()                    { *m = BurndownSurvival{} }
+func (m *BurndownSurvival) String() string            { return proto.CompactTextString(m) }
+func (*BurndownSurvival) ProtoMessage()               {}
+func (*BurndownSurvival) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{4} }
+
+func (m *BurndownSurvival) GetProject() *SurvivalCurve {
+	if m != nil {
+		return m.Project
+	}
+	return nil
+}
+
+func (m *BurndownSurvival) GetFiles() []*SurvivalCurve {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+func (m *BurndownSurvival) GetPeople() []*SurvivalCurve {
+	if m != nil {
+		return m.People
+	}
+	return nil
+}
+
+type FileBlame struct {
+	// file path
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// the first line of each interval, the last element is the number of lines in the file
+	Lines []int32 `protobuf:"varint,2,rep,packed,name=lines" json:"lines,omitempty"`
+	// `len(authors)` = `len(lines) - 1`, indexes in `BurndownAnalysisResults.people` or -1
+	Authors []int32 `protobuf:"varint,3,rep,packed,name=authors" json:"authors,omitempty"`
+	// `len(days)` = `len(lines) - 1`, the number of days since the beginning of the history
+	Days []int32 `protobuf:"varint,4,rep,packed,name=days" json:"days,omitempty"`
+}
+
+func (m *FileBlame) Reset()                    { *m = FileBlame{} }
+func (m *FileBlame) String() string            { return proto.CompactTextString(m) }
+func (*FileBlame) ProtoMessage()               {}
+func (*FileBlame) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{5} }
+
+func (m *FileBlame) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *FileBlame) GetLines() []int32 {
+	if m != nil {
+		return m.Lines
+	}
+	return nil
+}
+
+func (m *FileBlame) GetAuthors() []int32 {
+	if m != nil {
+		return m.Authors
+	}
+	return nil
+}
+
+func (m *FileBlame) GetDays() []int32 {
+	if m != nil {
+		return m.Days
+	}
+	return nil
+}
+
 type BurndownAnalysisResults struct {
 	// how many days are in each band [burndown_project, burndown_file, burndown_developer]
 	Granularity int32 `protobuf:"varint,1,opt,name=granularity,proto3" json:"granularity,omitempty"`
@@ -186,12 +328,24 @@ type BurndownAnalysisResults struct {
 	People []*BurndownSparseMatrix `protobuf:"bytes,5,rep,name=people" json:"people,omitempty"`
 	// rows and cols order correspond to `burndown_developer`
 	PeopleInteraction *CompressedSparseRowMatrix `protobuf:"bytes,6,opt,name=people_interaction,json=peopleInteraction" json:"people_interaction,omitempty"`
+	// this is included if `-burndown-survival` was specified
+	Survival *BurndownSurvival `protobuf:"bytes,7,opt,name=survival" json:"survival,omitempty"`
+	// this is included if `-burndown-blame` was specified
+	Blame []*FileBlame `protobuf:"bytes,8,rep,name=blame" json:"blame,omitempty"`
+	// `people_interaction` sampled the same way as `project`, included with `-burndown-people`
+	PeopleInteractionHistory []*CompressedSparseRowMatrix `protobuf:"bytes,9,rep,name=people_interaction_history,json=peopleInteractionHistory" json:"people_interaction_history,omitempty"`
+	// these two are included if `-burndown-teams` was specified
+	Teams []string `protobuf:"bytes,10,rep,name=teams" json:"teams,omitempty"`
+	// rows and cols order correspond to `teams`, the same layout as `people_interaction`
+	TeamsInteraction *CompressedSparseRowMatrix `protobuf:"bytes,11,opt,name=teams_interaction,json=teamsInteraction" json:"teams_interaction,omitempty"`
+	// the numbers of the distinct blobs which were not text: "binary", "too_large", "lfs_pointer"
+	BlobClasses map[string]int32 `protobuf:"bytes,12,rep,name=blob_classes,json=blobClasses" json:"blob_classes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 }
 
 func (m *BurndownAnalysisResults) Reset()                    { *m = BurndownAnalysisResults{} }
 func (m *BurndownAnalysisResults) String() string            { return proto.CompactTextString(m) }
 func (*BurndownAnalysisResults) ProtoMessage()               {}
-func (*BurndownAnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{3} }
+func (*BurndownAnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{6} }
 
 func (m *BurndownAnalysisResults) GetGranularity() int32 {
 	if m != nil {
@@ -235,6 +389,48 @@ func (m *BurndownAnalysisResults) GetPeopleInteraction() *CompressedSparseRowMat
 	return nil
 }
 
+func (m *BurndownAnalysisResults) GetSurvival() *BurndownSurvival {
+	if m != nil {
+		return m.Survival
+	}
+	return nil
+}
+
+func (m *BurndownAnalysisResults) GetBlame() []*FileBlame {
+	if m != nil {
+		return m.Blame
+	}
+	return nil
+}
+
+func (m *BurndownAnalysisResults) GetPeopleInteractionHistory() []*CompressedSparseRowMatrix {
+	if m != nil {
+		return m.PeopleInteractionHistory
+	}
+	return nil
+}
+
+func (m *BurndownAnalysisResults) GetTeams() []string {
+	if m != nil {
+		return m.Teams
+	}
+	return nil
+}
+
+func (m *BurndownAnalysisResults) GetTeamsInteraction() *CompressedSparseRowMatrix {
+	if m != nil {
+		return m.TeamsInteraction
+	}
+	return nil
+}
+
+func (m *BurndownAnalysisResults) GetBlobClasses() map[string]int32 {
+	if m != nil {
+		return m.BlobClasses
+	}
+	return nil
+}
+
 type CompressedSparseRowMatrix struct {
 	NumberOfRows    int32 `protobuf:"varint,1,opt,name=number_of_rows,json=numberOfRows,proto3" json:"number_of_rows,omitempty"`
 	NumberOfColumns int32 `protobuf:"varint,2,opt,name=number_of_columns,json=numberOfColumns,proto3" json:"number_of_columns,omitempty"`
@@ -247,7 +443,7 @@ type CompressedSparseRowMatrix struct {
 func (m *CompressedSparseRowMatrix) Reset()                    { *m = CompressedSparseRowMatrix{} }
 func (m *CompressedSparseRowMatrix) String() string            { return proto.CompactTextString(m) }
 func (*CompressedSparseRowMatrix) ProtoMessage()               {}
-func (*CompressedSparseRowMatrix) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{4} }
+func (*CompressedSparseRowMatrix) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{7} }
 
 func (m *CompressedSparseRowMatrix) GetNumberOfRows() int32 {
 	if m != nil {
@@ -294,7 +490,7 @@ type Couples struct {
 func (m *Couples) Reset()                    { *m = Couples{} }
 func (m *Couples) String() string            { return proto.CompactTextString(m) }
 func (*Couples) ProtoMessage()               {}
-func (*Couples) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{5} }
+func (*Couples) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{8} }
 
 func (m *Couples) GetIndex() []string {
 	if m != nil {
@@ -317,7 +513,7 @@ type TouchedFiles struct {
 func (m *TouchedFiles) Reset()                    { *m = TouchedFiles{} }
 func (m *TouchedFiles) String() string            { return proto.CompactTextString(m) }
 func (*TouchedFiles) ProtoMessage()               {}
-func (*TouchedFiles) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{6} }
+func (*TouchedFiles) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{9} }
 
 func (m *TouchedFiles) GetFiles() []int32 {
 	if m != nil {
@@ -331,12 +527,15 @@ type CouplesAnalysisResults struct {
 	PeopleCouples *Couples `protobuf:"bytes,7,opt,name=people_couples,json=peopleCouples" json:"people_couples,omitempty"`
 	// order corresponds to `people_couples::index`
 	PeopleFiles []*TouchedFiles `protobuf:"bytes,8,rep,name=people_files,json=peopleFiles" json:"people_files,omitempty"`
+	// this is included if the teams file was specified; the last row and column
+	// correspond to the developers without a team
+	TeamCouples *Couples `protobuf:"bytes,9,opt,name=team_couples,json=teamCouples" json:"team_couples,omitempty"`
 }
 
 func (m *CouplesAnalysisResults) Reset()                    { *m = CouplesAnalysisResults{} }
 func (m *CouplesAnalysisResults) String() string            { return proto.CompactTextString(m) }
 func (*CouplesAnalysisResults) ProtoMessage()               {}
-func (*CouplesAnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{7} }
+func (*CouplesAnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{10} }
 
 func (m *CouplesAnalysisResults) GetFileCouples() *Couples {
 	if m != nil {
@@ -359,6 +558,13 @@ func (m *CouplesAnalysisResults) GetPeopleFiles() []*TouchedFiles {
 	return nil
 }
 
+func (m *CouplesAnalysisResults) GetTeamCouples() *Couples {
+	if m != nil {
+		return m.TeamCouples
+	}
+	return nil
+}
+
 type UASTChange struct {
 	FileName   string `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
 	SrcBefore  string `protobuf:"bytes,2,opt,name=src_before,json=srcBefore,proto3" json:"src_before,omitempty"`
@@ -370,7 +576,7 @@ type UASTChange struct {
 func (m *UASTChange) Reset()                    { *m = UASTChange{} }
 func (m *UASTChange) String() string            { return proto.CompactTextString(m) }
 func (*UASTChange) ProtoMessage()               {}
-func (*UASTChange) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{8} }
+func (*UASTChange) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{11} }
 
 func (m *UASTChange) GetFileName() string {
 	if m != nil {
@@ -414,7 +620,7 @@ type UASTChangesSaverResults struct {
 func (m *UASTChangesSaverResults) Reset()                    { *m = UASTChangesSaverResults{} }
 func (m *UASTChangesSaverResults) String() string            { return proto.CompactTextString(m) }
 func (*UASTChangesSaverResults) ProtoMessage()               {}
-func (*UASTChangesSaverResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{9} }
+func (*UASTChangesSaverResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{12} }
 
 func (m *UASTChangesSaverResults) GetChanges() []*UASTChange {
 	if m != nil {
@@ -434,7 +640,7 @@ type ShotnessRecord struct {
 func (m *ShotnessRecord) Reset()                    { *m = ShotnessRecord{} }
 func (m *ShotnessRecord) String() string            { return proto.CompactTextString(m) }
 func (*ShotnessRecord) ProtoMessage()               {}
-func (*ShotnessRecord) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{10} }
+func (*ShotnessRecord) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{13} }
 
 func (m *ShotnessRecord) GetInternalRole() string {
 	if m != nil {
@@ -478,7 +684,7 @@ type ShotnessAnalysisResults struct {
 func (m *ShotnessAnalysisResults) Reset()                    { *m = ShotnessAnalysisResults{} }
 func (m *ShotnessAnalysisResults) String() string            { return proto.CompactTextString(m) }
 func (*ShotnessAnalysisResults) ProtoMessage()               {}
-func (*ShotnessAnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{11} }
+func (*ShotnessAnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{14} }
 
 func (m *ShotnessAnalysisResults) GetRecords() []*ShotnessRecord {
 	if m != nil {
@@ -494,7 +700,7 @@ type FileHistory struct {
 func (m *FileHistory) Reset()                    { *m = FileHistory{} }
 func (m *FileHistory) String() string            { return proto.CompactTextString(m) }
 func (*FileHistory) ProtoMessage()               {}
-func (*FileHistory) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{12} }
+func (*FileHistory) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{15} }
 
 func (m *FileHistory) GetCommits() []string {
 	if m != nil {
@@ -510,7 +716,7 @@ type FileHistoryResultMessage struct {
 func (m *FileHistoryResultMessage) Reset()                    { *m = FileHistoryResultMessage{} }
 func (m *FileHistoryResultMessage) String() string            { return proto.CompactTextString(m) }
 func (*FileHistoryResultMessage) ProtoMessage()               {}
-func (*FileHistoryResultMessage) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{13} }
+func (*FileHistoryResultMessage) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{16} }
 
 func (m *FileHistoryResultMessage) GetFiles() map[string]*FileHistory {
 	if m != nil {
@@ -519,6 +725,244 @@ func (m *FileHistoryResultMessage) GetFiles() map[string]*FileHistory {
 	return nil
 }
 
+type PathOwnership struct {
+	// file path or directory which ends with a slash
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// rows are samples, columns correspond to `OwnershipAnalysisResults.people`
+	// and the last column is the unidentified developers
+	Ownership *CompressedSparseRowMatrix `protobuf:"bytes,2,opt,name=ownership" json:"ownership,omitempty"`
+	// the minimum number of developers who own more than half of the lines in the last sample
+	BusFactor int32 `protobuf:"varint,3,opt,name=bus_factor,json=busFactor,proto3" json:"bus_factor,omitempty"`
+	// sum of the squared line shares of the developers
+	Concentration float32 `protobuf:"fixed32,4,opt,name=concentration,proto3" json:"concentration,omitempty"`
+	// the share of the lines owned by the departed developers
+	DepartedShare float32 `protobuf:"fixed32,5,opt,name=departed_share,json=departedShare,proto3" json:"departed_share,omitempty"`
+	// the departed developers own most of the lines
+	AtRisk bool `protobuf:"varint,6,opt,name=at_risk,json=atRisk,proto3" json:"at_risk,omitempty"`
+}
+
+func (m *PathOwnership) Reset()                    { *m = PathOwnership{} }
+func (m *PathOwnership) String() string            { return proto.CompactTextString(m) }
+func (*PathOwnership) ProtoMessage()               {}
+func (*PathOwnership) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{17} }
+
+func (m *PathOwnership) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *PathOwnership) GetOwnership() *CompressedSparseRowMatrix {
+	if m != nil {
+		return m.Ownership
+	}
+	return nil
+}
+
+func (m *PathOwnership) GetBusFactor() int32 {
+	if m != nil {
+		return m.BusFactor
+	}
+	return 0
+}
+
+func (m *PathOwnership) GetConcentration() float32 {
+	if m != nil {
+		return m.Concentration
+	}
+	return 0
+}
+
+func (m *PathOwnership) GetDepartedShare() float32 {
+	if m != nil {
+		return m.DepartedShare
+	}
+	return 0
+}
+
+func (m *PathOwnership) GetAtRisk() bool {
+	if m != nil {
+		return m.AtRisk
+	}
+	return false
+}
+
+type OwnershipAnalysisResults struct {
+	// how frequently we measure the ownership in days
+	Sampling int32 `protobuf:"varint,1,opt,name=sampling,proto3" json:"sampling,omitempty"`
+	// developers' names
+	People []string `protobuf:"bytes,2,rep,name=people" json:"people,omitempty"`
+	// the day of the last commit of each developer, -1 if there were no commits
+	LastActivity []int32 `protobuf:"varint,3,rep,packed,name=last_activity,json=lastActivity" json:"last_activity,omitempty"`
+	// whether each developer did not commit for longer than the departure threshold
+	Departed []bool `protobuf:"varint,4,rep,packed,name=departed" json:"departed,omitempty"`
+	// sorted by path
+	Paths []*PathOwnership `protobuf:"bytes,5,rep,name=paths" json:"paths,omitempty"`
+}
+
+func (m *OwnershipAnalysisResults) Reset()                    { *m = OwnershipAnalysisResults{} }
+func (m *OwnershipAnalysisResults) String() string            { return proto.CompactTextString(m) }
+func (*OwnershipAnalysisResults) ProtoMessage()               {}
+func (*OwnershipAnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{18} }
+
+func (m *OwnershipAnalysisResults) GetSampling() int32 {
+	if m != nil {
+		return m.Sampling
+	}
+	return 0
+}
+
+func (m *OwnershipAnalysisResults) GetPeople() []string {
+	if m != nil {
+		return m.People
+	}
+	return nil
+}
+
+func (m *OwnershipAnalysisResults) GetLastActivity() []int32 {
+	if m != nil {
+		return m.LastActivity
+	}
+	return nil
+}
+
+func (m *OwnershipAnalysisResults) GetDeparted() []bool {
+	if m != nil {
+		return m.Departed
+	}
+	return nil
+}
+
+func (m *OwnershipAnalysisResults) GetPaths() []*PathOwnership {
+	if m != nil {
+		return m.Paths
+	}
+	return nil
+}
+
+type ChurnStats struct {
+	// the number of inserted lines
+	Additions int32 `protobuf:"varint,1,opt,name=additions,proto3" json:"additions,omitempty"`
+	// the number of removed lines
+	Deletions int32 `protobuf:"varint,2,opt,name=deletions,proto3" json:"deletions,omitempty"`
+	// the number of removed lines which were replaced with new lines in the same hunk
+	Rewrites int32 `protobuf:"varint,3,opt,name=rewrites,proto3" json:"rewrites,omitempty"`
+	// the number of removed lines which were written not earlier than the recent threshold
+	Recent int32 `protobuf:"varint,4,opt,name=recent,proto3" json:"recent,omitempty"`
+}
+
+func (m *ChurnStats) Reset()                    { *m = ChurnStats{} }
+func (m *ChurnStats) String() string            { return proto.CompactTextString(m) }
+func (*ChurnStats) ProtoMessage()               {}
+func (*ChurnStats) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{19} }
+
+func (m *ChurnStats) GetAdditions() int32 {
+	if m != nil {
+		return m.Additions
+	}
+	return 0
+}
+
+func (m *ChurnStats) GetDeletions() int32 {
+	if m != nil {
+		return m.Deletions
+	}
+	return 0
+}
+
+func (m *ChurnStats) GetRewrites() int32 {
+	if m != nil {
+		return m.Rewrites
+	}
+	return 0
+}
+
+func (m *ChurnStats) GetRecent() int32 {
+	if m != nil {
+		return m.Recent
+	}
+	return 0
+}
+
+type ChurnTimeline struct {
+	// days since the beginning, sorted
+	Days []int32 `protobuf:"varint,1,rep,packed,name=days" json:"days,omitempty"`
+	// the same length as `days`
+	Stats []*ChurnStats `protobuf:"bytes,2,rep,name=stats" json:"stats,omitempty"`
+}
+
+func (m *ChurnTimeline) Reset()                    { *m = ChurnTimeline{} }
+func (m *ChurnTimeline) String() string            { return proto.CompactTextString(m) }
+func (*ChurnTimeline) ProtoMessage()               {}
+func (*ChurnTimeline) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{20} }
+
+func (m *ChurnTimeline) GetDays() []int32 {
+	if m != nil {
+		return m.Days
+	}
+	return nil
+}
+
+func (m *ChurnTimeline) GetStats() []*ChurnStats {
+	if m != nil {
+		return m.Stats
+	}
+	return nil
+}
+
+type ChurnAnalysisResults struct {
+	// the maximum age of a removed line in days to count it as recent churn
+	RecentThreshold int32          `protobuf:"varint,1,opt,name=recent_threshold,json=recentThreshold,proto3" json:"recent_threshold,omitempty"`
+	Global          *ChurnTimeline `protobuf:"bytes,2,opt,name=global" json:"global,omitempty"`
+	// developers' names
+	People []string `protobuf:"bytes,3,rep,name=people" json:"people,omitempty"`
+	// the same order as `people`, the last timeline belongs to the unidentified developers
+	PeopleTimelines []*ChurnTimeline `protobuf:"bytes,4,rep,name=people_timelines,json=peopleTimelines" json:"people_timelines,omitempty"`
+	// totals per file path
+	Files map[string]*ChurnStats `protobuf:"bytes,5,rep,name=files" json:"files,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *ChurnAnalysisResults) Reset()                    { *m = ChurnAnalysisResults{} }
+func (m *ChurnAnalysisResults) String() string            { return proto.CompactTextString(m) }
+func (*ChurnAnalysisResults) ProtoMessage()               {}
+func (*ChurnAnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{21} }
+
+func (m *ChurnAnalysisResults) GetRecentThreshold() int32 {
+	if m != nil {
+		return m.RecentThreshold
+	}
+	return 0
+}
+
+func (m *ChurnAnalysisResults) GetGlobal() *ChurnTimeline {
+	if m != nil {
+		return m.Global
+	}
+	return nil
+}
+
+func (m *ChurnAnalysisResults) GetPeople() []string {
+	if m != nil {
+		return m.People
+	}
+	return nil
+}
+
+func (m *ChurnAnalysisResults) GetPeopleTimelines() []*ChurnTimeline {
+	if m != nil {
+		return m.PeopleTimelines
+	}
+	return nil
+}
+
+func (m *ChurnAnalysisResults) GetFiles() map[string]*ChurnStats {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
 type Sentiment struct {
 	Value    float32  `protobuf:"fixed32,1,opt,name=value,proto3" json:"value,omitempty"`
 	Comments []string `protobuf:"bytes,2,rep,name=comments" json:"comments,omitempty"`
@@ -528,7 +972,7 @@ type Sentiment struct {
 func (m *Sentiment) Reset()                    { *m = Sentiment{} }
 func (m *Sentiment) String() string            { return proto.CompactTextString(m) }
 func (*Sentiment) ProtoMessage()               {}
-func (*Sentiment) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{14} }
+func (*Sentiment) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{22} }
 
 func (m *Sentiment) GetValue() float32 {
 	if m != nil {
@@ -558,7 +1002,7 @@ type CommentSentimentResults struct {
 func (m *CommentSentimentResults) Reset()                    { *m = CommentSentimentResults{} }
 func (m *CommentSentimentResults) String() string            { return proto.CompactTextString(m) }
 func (*CommentSentimentResults) ProtoMessage()               {}
-func (*CommentSentimentResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{15} }
+func (*CommentSentimentResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{23} }
 
 func (m *CommentSentimentResults) GetSentimentByDay() map[int32]*Sentiment {
 	if m != nil {
@@ -567,6 +1011,90 @@ func (m *CommentSentimentResults) GetSentimentByDay() map[int32]*Sentiment {
 	return nil
 }
 
+type SubmoduleBump struct {
+	// hash of the commit in the analysed repository
+	Commit string `protobuf:"bytes,1,opt,name=commit,proto3" json:"commit,omitempty"`
+	Day    int32  `protobuf:"varint,2,opt,name=day,proto3" json:"day,omitempty"`
+	// hashes of the pinned commits in the submodule, empty if the submodule was added or removed
+	From string `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To   string `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (m *SubmoduleBump) Reset()                    { *m = SubmoduleBump{} }
+func (m *SubmoduleBump) String() string            { return proto.CompactTextString(m) }
+func (*SubmoduleBump) ProtoMessage()               {}
+func (*SubmoduleBump) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{24} }
+
+func (m *SubmoduleBump) GetCommit() string {
+	if m != nil {
+		return m.Commit
+	}
+	return ""
+}
+
+func (m *SubmoduleBump) GetDay() int32 {
+	if m != nil {
+		return m.Day
+	}
+	return 0
+}
+
+func (m *SubmoduleBump) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *SubmoduleBump) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+type SubmoduleResults struct {
+	Bumps []*SubmoduleBump `protobuf:"bytes,1,rep,name=bumps" json:"bumps,omitempty"`
+	// the analysis of the submodule history, set with --recurse-submodules
+	Analysis *AnalysisResults `protobuf:"bytes,2,opt,name=analysis" json:"analysis,omitempty"`
+}
+
+func (m *SubmoduleResults) Reset()                    { *m = SubmoduleResults{} }
+func (m *SubmoduleResults) String() string            { return proto.CompactTextString(m) }
+func (*SubmoduleResults) ProtoMessage()               {}
+func (*SubmoduleResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{25} }
+
+func (m *SubmoduleResults) GetBumps() []*SubmoduleBump {
+	if m != nil {
+		return m.Bumps
+	}
+	return nil
+}
+
+func (m *SubmoduleResults) GetAnalysis() *AnalysisResults {
+	if m != nil {
+		return m.Analysis
+	}
+	return nil
+}
+
+type SubmodulesAnalysisResults struct {
+	// the keys are the submodule paths
+	Submodules map[string]*SubmoduleResults `protobuf:"bytes,1,rep,name=submodules" json:"submodules,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *SubmodulesAnalysisResults) Reset()                    { *m = SubmodulesAnalysisResults{} }
+func (m *SubmodulesAnalysisResults) String() string            { return proto.CompactTextString(m) }
+func (*SubmodulesAnalysisResults) ProtoMessage()               {}
+func (*SubmodulesAnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{26} }
+
+func (m *SubmodulesAnalysisResults) GetSubmodules() map[string]*SubmoduleResults {
+	if m != nil {
+		return m.Submodules
+	}
+	return nil
+}
+
 type AnalysisResults struct {
 	Header *Metadata `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
 	// the mapped values are dynamic messages which require the second parsing pass.
@@ -576,7 +1104,7 @@ type AnalysisResults struct {
 func (m *AnalysisResults) Reset()                    { *m = AnalysisResults{} }
 func (m *AnalysisResults) String() string            { return proto.CompactTextString(m) }
 func (*AnalysisResults) ProtoMessage()               {}
-func (*AnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{16} }
+func (*AnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{27} }
 
 func (m *AnalysisResults) GetHeader() *Metadata {
 	if m != nil {
@@ -596,6 +1124,9 @@ func init() {
 	proto.RegisterType((*Metadata)(nil), "Metadata")
 	proto.RegisterType((*BurndownSparseMatrixRow)(nil), "BurndownSparseMatrixRow")
 	proto.RegisterType((*BurndownSparseMatrix)(nil), "BurndownSparseMatrix")
+	proto.RegisterType((*SurvivalCurve)(nil), "SurvivalCurve")
+	proto.RegisterType((*BurndownSurvival)(nil), "BurndownSurvival")
+	proto.RegisterType((*FileBlame)(nil), "FileBlame")
 	proto.RegisterType((*BurndownAnalysisResults)(nil), "BurndownAnalysisResults")
 	proto.RegisterType((*CompressedSparseRowMatrix)(nil), "CompressedSparseRowMatrix")
 	proto.RegisterType((*Couples)(nil), "Couples")
@@ -607,79 +1138,135 @@ func init() {
 	proto.RegisterType((*ShotnessAnalysisResults)(nil), "ShotnessAnalysisResults")
 	proto.RegisterType((*FileHistory)(nil), "FileHistory")
 	proto.RegisterType((*FileHistoryResultMessage)(nil), "FileHistoryResultMessage")
+	proto.RegisterType((*PathOwnership)(nil), "PathOwnership")
+	proto.RegisterType((*OwnershipAnalysisResults)(nil), "OwnershipAnalysisResults")
+	proto.RegisterType((*ChurnStats)(nil), "ChurnStats")
+	proto.RegisterType((*ChurnTimeline)(nil), "ChurnTimeline")
+	proto.RegisterType((*ChurnAnalysisResults)(nil), "ChurnAnalysisResults")
 	proto.RegisterType((*Sentiment)(nil), "Sentiment")
 	proto.RegisterType((*CommentSentimentResults)(nil), "CommentSentimentResults")
+	proto.RegisterType((*SubmoduleBump)(nil), "SubmoduleBump")
+	proto.RegisterType((*SubmoduleResults)(nil), "SubmoduleResults")
+	proto.RegisterType((*SubmodulesAnalysisResults)(nil), "SubmodulesAnalysisResults")
 	proto.RegisterType((*AnalysisResults)(nil), "AnalysisResults")
 }
 
 func init() { proto.RegisterFile("pb.proto", fileDescriptorPb) }
 
 var fileDescriptorPb = []byte{
-	// 1053 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0xdf, 0x6e, 0x1b, 0xc5,
-	0x17, 0xd6, 0x66, 0xfd, 0xf7, 0xac, 0x9d, 0xb4, 0xf3, 0xeb, 0xaf, 0xd9, 0x06, 0xb5, 0x98, 0x25,
-	0x80, 0xa1, 0x65, 0x8b, 0xdc, 0x1b, 0x08, 0x37, 0x24, 0x2e, 0x15, 0xbd, 0x08, 0x48, 0xe3, 0x14,
-	0x2e, 0xad, 0xf1, 0xee, 0x24, 0x5e, 0x58, 0xcf, 0xac, 0x66, 0x76, 0x93, 0xf8, 0x65, 0xb8, 0x43,
-	0x42, 0x48, 0x88, 0x0b, 0x5e, 0x80, 0xd7, 0xe0, 0x19, 0x78, 0x09, 0x34, 0xff, 0xec, 0xb5, 0xe5,
-	0x54, 0xdc, 0xcd, 0x39, 0xe7, 0xfb, 0xce, 0x9c, 0xf9, 0xce, 0x99, 0xd9, 0x85, 0x4e, 0x31, 0x8b,
-	0x0b, 0xc1, 0x4b, 0x1e, 0xfd, 0xed, 0x41, 0xe7, 0x9c, 0x96, 0x24, 0x25, 0x25, 0x41, 0x21, 0xb4,
-	0xaf, 0xa9, 0x90, 0x19, 0x67, 0xa1, 0x37, 0xf0, 0x86, 0x4d, 0xec, 0x4c, 0x84, 0xa0, 0x31, 0x27,
-	0x72, 0x1e, 0xee, 0x0d, 0xbc, 0x61, 0x17, 0xeb, 0x35, 0x7a, 0x02, 0x20, 0x68, 0xc1, 0x65, 0x56,
-	0x72, 0xb1, 0x0c, 0x7d, 0x1d, 0xa9, 0x79, 0xd0, 0x87, 0x70, 0x30, 0xa3, 0x57, 0x19, 0x9b, 0x56,
-	0x2c, 0xbb, 0x9d, 0x96, 0xd9, 0x82, 0x86, 0x8d, 0x81, 0x37, 0xf4, 0x71, 0x5f, 0xbb, 0xdf, 0xb0,
-	0xec, 0xf6, 0x22, 0x5b, 0x50, 0x14, 0x41, 0x9f, 0xb2, 0xb4, 0x86, 0x6a, 0x6a, 0x54, 0x40, 0x59,
-	0xba, 0xc2, 0x84, 0xd0, 0x4e, 0xf8, 0x62, 0x91, 0x95, 0x32, 0x6c, 0x99, 0xca, 0xac, 0x89, 0x1e,
-	0x41, 0x47, 0x54, 0xcc, 0x10, 0xdb, 0x9a, 0xd8, 0x16, 0x15, 0x53, 0xa4, 0xe8, 0x05, 0x1c, 0x9e,
-	0x55, 0x82, 0xa5, 0xfc, 0x86, 0x4d, 0x0a, 0x22, 0x24, 0x3d, 0x27, 0xa5, 0xc8, 0x6e, 0x31, 0xbf,
-	0x31, 0xf9, 0xf2, 0x6a, 0xc1, 0x64, 0xe8, 0x0d, 0xfc, 0x61, 0x1f, 0x3b, 0x33, 0xfa, 0xcd, 0x83,
-	0x07, 0xbb, 0x58, 0x4a, 0x02, 0x46, 0x16, 0x54, 0x2b, 0xd3, 0xc5, 0x7a, 0x8d, 0x8e, 0x61, 0x9f,
-	0x55, 0x8b, 0x19, 0x15, 0x53, 0x7e, 0x39, 0x15, 0xfc, 0x46, 0x6a, 0x81, 0x9a, 0xb8, 0x67, 0xbc,
-	0xdf, 0x5d, 0x62, 0x7e, 0x23, 0xd1, 0x27, 0x70, 0x7f, 0x8d, 0x72, 0xdb, 0xfa, 0x1a, 0x78, 0xe0,
-	0x80, 0x63, 0xe3, 0x46, 0xcf, 0xa0, 0xa1, 0xf3, 0x34, 0x06, 0xfe, 0x30, 0x18, 0x85, 0xf1, 0x1d,
-	0x07, 0xc0, 0x1a, 0x15, 0xfd, 0xb1, 0xb7, 0x3e, 0xe2, 0x29, 0x23, 0xf9, 0x52, 0x66, 0x12, 0x53,
-	0x59, 0xe5, 0xa5, 0x44, 0x03, 0x08, 0xae, 0x04, 0x61, 0x55, 0x4e, 0x44, 0x56, 0x2e, 0x6d, 0x43,
-	0xeb, 0x2e, 0x74, 0x04, 0x1d, 0x49, 0x16, 0x45, 0x9e, 0xb1, 0x2b, 0x5b, 0xf7, 0xca, 0x46, 0xcf,
-	0xa1, 0x5d, 0x08, 0xfe, 0x23, 0x4d, 0x4a, 0x5d, 0x69, 0x30, 0xfa, 0xff, 0xee, 0x52, 0x1c, 0x0a,
-	0x3d, 0x85, 0xe6, 0x65, 0x96, 0x53, 0x57, 0xf9, 0x1d, 0x70, 0x83, 0x41, 0x9f, 0x42, 0xab, 0xa0,
-	0xbc, 0xc8, 0x55, 0xaf, 0xdf, 0x82, 0xb6, 0x20, 0xf4, 0x1a, 0x90, 0x59, 0x4d, 0x33, 0x56, 0x52,
-	0x41, 0x92, 0x52, 0x8d, 0x68, 0x4b, 0xd7, 0x75, 0x14, 0x8f, 0xf9, 0xa2, 0x10, 0x54, 0x4a, 0x9a,
-	0x1a, 0x32, 0xe6, 0x37, 0x96, 0x7f, 0xdf, 0xb0, 0x5e, 0xaf, 0x49, 0xd1, 0x9f, 0x1e, 0x3c, 0xba,
-	0x93, 0xb0, 0xa3, 0x9f, 0xde, 0x7f, 0xed, 0xe7, 0xde, 0xee, 0x7e, 0x22, 0x68, 0xa8, 0xab, 0x15,
-	0xfa, 0x03, 0x7f, 0xe8, 0xe3, 0x86, 0xbb, 0x66, 0x19, 0x4b, 0xb3, 0xc4, 0x8a, 0xd5, 0xc4, 0xce,
-	0x44, 0x0f, 0xa1, 0x95, 0xb1, 0xb4, 0x28, 0x85, 0xd6, 0xc5, 0xc7, 0xd6, 0x8a, 0x26, 0xd0, 0x1e,
-	0xf3, 0xaa, 0x50, 0xd2, 0x3d, 0x80, 0x66, 0xc6, 0x52, 0x7a, 0xab, 0xe7, 0xb6, 0x8b, 0x8d, 0x81,
-	0x46, 0xd0, 0x5a, 0xe8, 0x23, 0xe8, 0x3a, 0xde, 0xae, 0x8a, 0x45, 0x46, 0xc7, 0xd0, 0xbb, 0xe0,
-	0x55, 0x32, 0xa7, 0xe9, 0xab, 0xcc, 0x66, 0x36, 0x1d, 0xf4, 0x74, 0x51, 0xc6, 0x88, 0x7e, 0xf5,
-	0xe0, 0xa1, 0xdd, 0x7b, 0x7b, 0xc2, 0x9e, 0x42, 0x4f, 0x61, 0xa6, 0x89, 0x09, 0xdb, 0x86, 0x74,
-	0x62, 0x0b, 0xc7, 0x81, 0x8a, 0xba, 0xba, 0x9f, 0xc3, 0xbe, 0xed, 0xa1, 0x83, 0xb7, 0xb7, 0xe0,
-	0x7d, 0x13, 0x77, 0x84, 0xcf, 0xa0, 0x67, 0x09, 0xa6, 0xaa, 0x8e, 0x9e, 0x94, 0x7e, 0x5c, 0xaf,
-	0x19, 0x07, 0x06, 0xa2, 0x8d, 0xe8, 0x17, 0x0f, 0xe0, 0xcd, 0xe9, 0xe4, 0x62, 0x3c, 0x27, 0xec,
-	0x8a, 0xa2, 0x77, 0xa0, 0xab, 0xcb, 0xab, 0xdd, 0xda, 0x8e, 0x72, 0x7c, 0xab, 0x6e, 0xee, 0x63,
-	0x00, 0x29, 0x92, 0xe9, 0x8c, 0x5e, 0x72, 0x41, 0xed, 0xb3, 0xd6, 0x95, 0x22, 0x39, 0xd3, 0x0e,
-	0xc5, 0x55, 0x61, 0x72, 0x59, 0x52, 0x61, 0x9f, 0xb6, 0x8e, 0x14, 0xc9, 0xa9, 0xb2, 0xd1, 0xbb,
-	0x10, 0x54, 0x44, 0x96, 0x8e, 0xdc, 0x30, 0x2f, 0x9f, 0x72, 0x59, 0xf6, 0x63, 0xd0, 0x96, 0xa5,
-	0x37, 0x4d, 0x72, 0xe5, 0xd1, 0xfc, 0xe8, 0x2b, 0x38, 0x5c, 0x97, 0x29, 0x27, 0xe4, 0x9a, 0x0a,
-	0x27, 0xe9, 0x07, 0xd0, 0x4e, 0x8c, 0x5b, 0x77, 0x21, 0x18, 0x05, 0xf1, 0x1a, 0x8a, 0x5d, 0x2c,
-	0xfa, 0xc7, 0x83, 0xfd, 0xc9, 0x9c, 0x97, 0x8c, 0x4a, 0x89, 0x69, 0xc2, 0x45, 0x8a, 0xde, 0x87,
-	0xbe, 0xbe, 0x1c, 0x8c, 0xe4, 0x53, 0xc1, 0x73, 0x77, 0xe2, 0x9e, 0x73, 0x62, 0x9e, 0x53, 0xd5,
-	0x62, 0x15, 0x53, 0xd3, 0xaa, 0x5b, 0xac, 0x8d, 0xd5, 0xcb, 0xe6, 0xd7, 0x5e, 0x36, 0x04, 0x0d,
-	0xa5, 0x95, 0x3d, 0x9c, 0x5e, 0xa3, 0x2f, 0xa0, 0x93, 0xf0, 0x4a, 0xe5, 0x93, 0xf6, 0xde, 0x3e,
-	0x8e, 0x37, 0xab, 0x50, 0xbd, 0xd4, 0xf1, 0xaf, 0x59, 0x29, 0x96, 0x78, 0x05, 0x3f, 0xfa, 0x12,
-	0xfa, 0x1b, 0x21, 0x74, 0x0f, 0xfc, 0x9f, 0xa8, 0x7b, 0x95, 0xd4, 0x52, 0xd5, 0x76, 0x4d, 0xf2,
-	0x8a, 0xda, 0x9b, 0x64, 0x8c, 0x93, 0xbd, 0xcf, 0xbd, 0xe8, 0x25, 0x1c, 0xba, 0x6d, 0xb6, 0x47,
-	0xf0, 0x63, 0x68, 0x0b, 0xbd, 0xb3, 0xd3, 0xeb, 0x60, 0xab, 0x22, 0xec, 0xe2, 0xd1, 0x47, 0x10,
-	0xa8, 0x31, 0xf9, 0x26, 0x93, 0xfa, 0xeb, 0x54, 0xfb, 0xa2, 0x98, 0x9b, 0xe4, 0xcc, 0xe8, 0x67,
-	0x0f, 0xc2, 0x1a, 0xd2, 0x6c, 0x75, 0x4e, 0xa5, 0x24, 0x57, 0x14, 0x9d, 0xd4, 0x2f, 0x49, 0x30,
-	0x3a, 0x8e, 0xef, 0x42, 0xea, 0x80, 0xd5, 0xc1, 0x50, 0x8e, 0x5e, 0x01, 0xac, 0x9d, 0x75, 0x05,
-	0xba, 0x46, 0x81, 0xa8, 0xae, 0x40, 0x30, 0xea, 0x6d, 0xe4, 0xae, 0xe9, 0xf1, 0x03, 0x74, 0x27,
-	0x94, 0xa9, 0x2f, 0x1e, 0x2b, 0xd7, 0xb2, 0xa9, 0x44, 0x7b, 0x16, 0xa6, 0x9e, 0x76, 0x75, 0x1c,
-	0xca, 0x4a, 0xd3, 0xeb, 0x2e, 0x5e, 0xd9, 0xf5, 0x93, 0xfb, 0x9b, 0x27, 0xff, 0xcb, 0x83, 0xc3,
-	0xb1, 0x81, 0xad, 0x36, 0x70, 0x4a, 0x7f, 0x0f, 0xf7, 0xa4, 0xf3, 0x4d, 0x67, 0xcb, 0x69, 0x4a,
-	0x96, 0x56, 0x83, 0x67, 0xf1, 0x1d, 0x9c, 0x78, 0xe5, 0x38, 0x5b, 0xbe, 0x24, 0x4b, 0xa3, 0xc5,
-	0xbe, 0xdc, 0x70, 0x1e, 0x9d, 0xc3, 0xff, 0x76, 0xc0, 0x76, 0xcc, 0xc7, 0x60, 0x53, 0x1d, 0x58,
-	0x67, 0xaf, 0x6b, 0xf3, 0xbb, 0x07, 0x07, 0xdb, 0x43, 0xf2, 0x1e, 0xb4, 0xe6, 0x94, 0xa4, 0x54,
-	0xe8, 0x74, 0xc1, 0xa8, 0x1b, 0xbb, 0x3f, 0x1e, 0x6c, 0x03, 0xe8, 0x44, 0xe9, 0xc5, 0xca, 0x95,
-	0x5e, 0xc1, 0xe8, 0x49, 0xbc, 0x95, 0x26, 0x1e, 0x5b, 0xc0, 0x6a, 0xb6, 0x8d, 0x69, 0x66, 0xbb,
-	0x16, 0xda, 0xd1, 0xd9, 0x8d, 0xd9, 0xee, 0xd5, 0xea, 0x9d, 0xb5, 0xf4, 0x6f, 0xd8, 0x8b, 0x7f,
-	0x03, 0x00, 0x00, 0xff, 0xff, 0x7e, 0x55, 0x2d, 0x51, 0x92, 0x09, 0x00, 0x00,
+	// 1813 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x58, 0xdd, 0x8e, 0x23, 0x47,
+	0xf5, 0x57, 0xdb, 0xe3, 0xb1, 0x7d, 0xfc, 0x31, 0x9e, 0xfa, 0xef, 0x7f, 0xa7, 0x77, 0x60, 0x83,
+	0xb7, 0x99, 0x24, 0x4e, 0x76, 0xd3, 0x41, 0x13, 0x09, 0x25, 0x8b, 0x84, 0xd8, 0x71, 0x32, 0x10,
+	0x94, 0x25, 0x51, 0x79, 0x03, 0xdc, 0xa0, 0x56, 0x75, 0x77, 0x79, 0xdc, 0x6c, 0xbb, 0xcb, 0xaa,
+	0xaa, 0x9e, 0x59, 0x23, 0x9e, 0x80, 0x87, 0xe0, 0x8e, 0x1b, 0x24, 0xae, 0x10, 0x17, 0x88, 0x1b,
+	0xee, 0x78, 0x02, 0x5e, 0x80, 0x5b, 0x1e, 0x80, 0x5b, 0x54, 0x5f, 0xed, 0xb6, 0xc7, 0xc3, 0xe4,
+	0xae, 0xce, 0x57, 0xd7, 0x39, 0xbf, 0xf3, 0x51, 0xc7, 0x86, 0xce, 0x2a, 0x0e, 0x57, 0x9c, 0x49,
+	0x16, 0xfc, 0xc7, 0x83, 0xce, 0x4b, 0x2a, 0x49, 0x4a, 0x24, 0x41, 0x3e, 0xb4, 0xaf, 0x29, 0x17,
+	0x19, 0x2b, 0x7c, 0x6f, 0xec, 0x4d, 0x5a, 0xd8, 0x91, 0x08, 0xc1, 0xc1, 0x82, 0x88, 0x85, 0xdf,
+	0x18, 0x7b, 0x93, 0x2e, 0xd6, 0x67, 0xf4, 0x16, 0x00, 0xa7, 0x2b, 0x26, 0x32, 0xc9, 0xf8, 0xda,
+	0x6f, 0x6a, 0x49, 0x8d, 0x83, 0xde, 0x81, 0xa3, 0x98, 0x5e, 0x65, 0x45, 0x54, 0x16, 0xd9, 0x9b,
+	0x48, 0x66, 0x4b, 0xea, 0x1f, 0x8c, 0xbd, 0x49, 0x13, 0x0f, 0x34, 0xfb, 0xeb, 0x22, 0x7b, 0xf3,
+	0x2a, 0x5b, 0x52, 0x14, 0xc0, 0x80, 0x16, 0x69, 0x4d, 0xab, 0xa5, 0xb5, 0x7a, 0xb4, 0x48, 0x2b,
+	0x1d, 0x1f, 0xda, 0x09, 0x5b, 0x2e, 0x33, 0x29, 0xfc, 0x43, 0xe3, 0x99, 0x25, 0xd1, 0x23, 0xe8,
+	0xf0, 0xb2, 0x30, 0x86, 0x6d, 0x6d, 0xd8, 0xe6, 0x65, 0xa1, 0x8d, 0xbe, 0x0d, 0x5d, 0x91, 0x5d,
+	0x15, 0x44, 0x96, 0x9c, 0xfa, 0x1d, 0xed, 0xdf, 0x86, 0x11, 0x7c, 0x04, 0x27, 0x17, 0x25, 0x2f,
+	0x52, 0x76, 0x53, 0xcc, 0x56, 0x84, 0x0b, 0xfa, 0x92, 0x48, 0x9e, 0xbd, 0xc1, 0xec, 0xc6, 0xdc,
+	0x96, 0x97, 0xcb, 0x42, 0xf8, 0xde, 0xb8, 0x39, 0x19, 0x60, 0x47, 0x06, 0x7f, 0xf4, 0xe0, 0xc1,
+	0x3e, 0x2b, 0x05, 0x50, 0x41, 0x96, 0x54, 0xe3, 0xd6, 0xc5, 0xfa, 0x8c, 0xce, 0x60, 0x58, 0x94,
+	0xcb, 0x98, 0xf2, 0x88, 0xcd, 0x23, 0xce, 0x6e, 0x84, 0x86, 0xaf, 0x85, 0xfb, 0x86, 0xfb, 0xe5,
+	0x1c, 0xb3, 0x1b, 0x81, 0xde, 0x87, 0xe3, 0x8d, 0x96, 0xbb, 0xb6, 0xa9, 0x15, 0x8f, 0x9c, 0xe2,
+	0xd4, 0xb0, 0xd1, 0x33, 0x38, 0xd0, 0xdf, 0x39, 0x18, 0x37, 0x27, 0xbd, 0x73, 0x3f, 0xbc, 0x23,
+	0x00, 0xac, 0xb5, 0x82, 0xdf, 0xc0, 0x60, 0x56, 0xf2, 0xeb, 0xec, 0x9a, 0xe4, 0xd3, 0x92, 0x5f,
+	0xd3, 0xbd, 0x4e, 0x22, 0x38, 0x48, 0xc9, 0x5a, 0xb9, 0xd6, 0x9c, 0xb4, 0xb0, 0x3e, 0xa3, 0x33,
+	0x18, 0xac, 0x38, 0x8b, 0x49, 0x9c, 0xe5, 0x99, 0xcc, 0xa8, 0x72, 0xa7, 0x39, 0x69, 0xe0, 0x6d,
+	0x26, 0xfa, 0x16, 0x74, 0x17, 0x24, 0x9f, 0x47, 0x79, 0x36, 0x37, 0x99, 0x6d, 0xe0, 0x8e, 0x62,
+	0x7c, 0x91, 0xcd, 0x69, 0xf0, 0x3b, 0x0f, 0x46, 0x95, 0x77, 0xd6, 0x09, 0x34, 0x81, 0xf6, 0x8a,
+	0xb3, 0x5f, 0xd3, 0x44, 0x6a, 0x17, 0x7a, 0xe7, 0xc3, 0x70, 0xcb, 0x41, 0xec, 0xc4, 0xe8, 0x0c,
+	0x5a, 0xf3, 0x2c, 0xa7, 0xc6, 0xad, 0xdb, 0x7a, 0x46, 0x88, 0xde, 0x81, 0xc3, 0x15, 0x65, 0xab,
+	0x9c, 0xfa, 0xcd, 0xbd, 0x6a, 0x56, 0x1a, 0x24, 0xd0, 0xbd, 0xcc, 0x72, 0x7a, 0x91, 0xdb, 0x80,
+	0x6f, 0x81, 0xf0, 0x00, 0x5a, 0x79, 0x56, 0x50, 0x87, 0x82, 0x21, 0x54, 0x19, 0x90, 0x52, 0x2e,
+	0x18, 0x37, 0x00, 0xb4, 0xb0, 0x23, 0x2b, 0xd0, 0x0e, 0x36, 0xa0, 0x05, 0xff, 0x68, 0x6d, 0x0a,
+	0xea, 0x45, 0x41, 0xf2, 0xb5, 0xc8, 0x04, 0xa6, 0xa2, 0xcc, 0xa5, 0x40, 0x63, 0xe8, 0x5d, 0x71,
+	0x52, 0x94, 0x39, 0xe1, 0x99, 0x5c, 0xdb, 0xe6, 0xaa, 0xb3, 0xd0, 0x29, 0x74, 0x04, 0x59, 0xae,
+	0xf2, 0xac, 0xb8, 0xb2, 0x55, 0x52, 0xd1, 0xe8, 0xc3, 0x0d, 0x6c, 0x4d, 0x0d, 0xdb, 0xff, 0xef,
+	0x4f, 0x7c, 0x85, 0xde, 0x53, 0x87, 0x9e, 0xa9, 0x93, 0x3b, 0xd4, 0x2d, 0x88, 0x1f, 0x54, 0x20,
+	0xb6, 0xfe, 0x97, 0xb6, 0x55, 0x42, 0x9f, 0x03, 0x32, 0xa7, 0x28, 0x2b, 0x24, 0xe5, 0x24, 0x91,
+	0x6a, 0x5c, 0x1c, 0x6a, 0xbf, 0x4e, 0xc3, 0x29, 0x5b, 0xae, 0x38, 0x15, 0x82, 0xa6, 0xc6, 0x18,
+	0xb3, 0x1b, 0x6b, 0x7f, 0x6c, 0xac, 0x3e, 0xdf, 0x18, 0xa1, 0x0f, 0xa0, 0x23, 0x6c, 0xbe, 0x74,
+	0xeb, 0xf6, 0xce, 0x8f, 0xc3, 0xdd, 0x9a, 0xc1, 0x95, 0x0a, 0x1a, 0x43, 0x2b, 0x56, 0x19, 0xf4,
+	0x3b, 0xda, 0x4f, 0x08, 0xab, 0x9c, 0x62, 0x23, 0x40, 0xbf, 0x84, 0xd3, 0xdb, 0xbe, 0x45, 0x8b,
+	0x4c, 0xe8, 0x09, 0xd5, 0x1d, 0x37, 0xef, 0xf1, 0xd1, 0xbf, 0xe5, 0xe3, 0x4f, 0x8c, 0xad, 0x2a,
+	0x10, 0x49, 0xc9, 0x52, 0xf8, 0x30, 0x6e, 0x4e, 0xba, 0xd8, 0x10, 0xe8, 0xc7, 0x70, 0xac, 0x0f,
+	0x5b, 0x50, 0xf4, 0xee, 0x85, 0x62, 0xa4, 0x8d, 0xea, 0x48, 0x7c, 0x01, 0xfd, 0x38, 0x67, 0x71,
+	0x94, 0xe4, 0x44, 0x08, 0x2a, 0xfc, 0xbe, 0x76, 0xf5, 0xbd, 0xf0, 0x8e, 0x7a, 0x0a, 0x2f, 0x72,
+	0x16, 0x4f, 0x8d, 0xee, 0x67, 0x85, 0xe4, 0x6b, 0xdc, 0x8b, 0x37, 0x9c, 0xd3, 0x1f, 0xc2, 0x68,
+	0x57, 0x01, 0x8d, 0xa0, 0xf9, 0x9a, 0xae, 0x6d, 0xd1, 0xab, 0xa3, 0x0a, 0xe9, 0x9a, 0xe4, 0x25,
+	0xb5, 0xe5, 0x66, 0x88, 0xe7, 0x8d, 0x8f, 0xbd, 0xe0, 0xcf, 0x1e, 0x3c, 0xba, 0xd3, 0xfb, 0x3d,
+	0x53, 0xcd, 0xfb, 0xa6, 0x53, 0xad, 0xb1, 0x7f, 0xaa, 0xe9, 0x6e, 0x92, 0x44, 0x37, 0x59, 0x13,
+	0x1f, 0xb8, 0xa7, 0x28, 0x2b, 0xd2, 0x2c, 0xa1, 0xae, 0xc9, 0x1c, 0x89, 0x1e, 0xc2, 0x61, 0x56,
+	0xa4, 0x2b, 0xc9, 0x75, 0xbd, 0x36, 0xb1, 0xa5, 0x82, 0x19, 0xb4, 0xa7, 0xac, 0x5c, 0xa9, 0x92,
+	0x7e, 0x00, 0xad, 0xac, 0x48, 0xe9, 0x1b, 0x3d, 0xbd, 0xbb, 0xd8, 0x10, 0xe8, 0x1c, 0x0e, 0x97,
+	0x3a, 0x04, 0xbf, 0x71, 0x6f, 0x8a, 0xac, 0x66, 0x70, 0x06, 0xfd, 0x57, 0xac, 0x4c, 0x16, 0x34,
+	0xbd, 0xcc, 0xec, 0x97, 0x4d, 0x67, 0x79, 0x66, 0x50, 0x68, 0x22, 0xf8, 0xa7, 0x07, 0x0f, 0xed,
+	0xdd, 0xbb, 0x9d, 0xff, 0x14, 0xfa, 0x4a, 0x27, 0x4a, 0x8c, 0xd8, 0x36, 0x4a, 0x27, 0xb4, 0xea,
+	0xb8, 0xa7, 0xa4, 0xce, 0xef, 0x0f, 0x61, 0x68, 0xeb, 0xd7, 0xa9, 0xb7, 0x77, 0xd4, 0x07, 0x46,
+	0xee, 0x0c, 0xbe, 0x07, 0x7d, 0x6b, 0x60, 0xbc, 0x32, 0x9d, 0x31, 0x08, 0xeb, 0x3e, 0xe3, 0x9e,
+	0x51, 0x31, 0x01, 0x3c, 0x85, 0xbe, 0xaa, 0xbe, 0xea, 0x82, 0xee, 0xae, 0x3f, 0x4a, 0x6a, 0x89,
+	0xe0, 0x0f, 0x1e, 0xc0, 0xd7, 0x2f, 0x66, 0xaf, 0xa6, 0x0b, 0x52, 0x5c, 0x51, 0x35, 0xf0, 0x75,
+	0x2c, 0xb5, 0xf1, 0xd9, 0x51, 0x8c, 0x9f, 0xa9, 0xde, 0x7b, 0x0c, 0x20, 0x78, 0x12, 0xc5, 0x74,
+	0xce, 0x38, 0xb5, 0x7b, 0x42, 0x57, 0xf0, 0xe4, 0x42, 0x33, 0x94, 0xad, 0x12, 0x93, 0xb9, 0xa4,
+	0xdc, 0xee, 0x0a, 0x1d, 0xc1, 0x93, 0x17, 0x8a, 0x46, 0xdf, 0x81, 0x5e, 0x49, 0x84, 0x74, 0xc6,
+	0x07, 0x5a, 0x0c, 0x8a, 0x65, 0xad, 0x1f, 0x83, 0xa6, 0xac, 0x79, 0xcb, 0x7c, 0x5c, 0x71, 0xb4,
+	0x7d, 0xf0, 0x23, 0x38, 0xd9, 0xb8, 0x29, 0x66, 0xe4, 0x9a, 0x72, 0x87, 0xff, 0xdb, 0xd0, 0x4e,
+	0x0c, 0x5b, 0xa7, 0xac, 0x77, 0xde, 0x0b, 0x37, 0xaa, 0xd8, 0xc9, 0x82, 0x7f, 0x7b, 0x30, 0x9c,
+	0x2d, 0x98, 0x2c, 0xa8, 0x10, 0x98, 0x26, 0x8c, 0xa7, 0xe8, 0xbb, 0x30, 0xd0, 0x6d, 0x5d, 0x90,
+	0x3c, 0xe2, 0x2c, 0x77, 0x11, 0xf7, 0x1d, 0x13, 0xb3, 0x5c, 0x3f, 0x1c, 0x4a, 0x56, 0x3d, 0x1c,
+	0x9a, 0xa8, 0x9e, 0x98, 0xe6, 0xf6, 0x3b, 0xab, 0xb0, 0xb2, 0xc1, 0xe9, 0x33, 0xfa, 0x04, 0x3a,
+	0x09, 0x2b, 0xd5, 0xf7, 0x84, 0x1d, 0xbe, 0x8f, 0xc3, 0x6d, 0x2f, 0xc2, 0xa9, 0x95, 0x9b, 0x36,
+	0xaf, 0xd4, 0x4f, 0x7f, 0x00, 0x83, 0x2d, 0x51, 0xbd, 0xc1, 0x5b, 0xf7, 0x35, 0xf8, 0xa7, 0x70,
+	0xe2, 0xae, 0xd9, 0xad, 0xd7, 0xf7, 0xa0, 0xcd, 0xf5, 0xcd, 0x0e, 0xaf, 0xa3, 0x1d, 0x8f, 0xb0,
+	0x93, 0x07, 0xef, 0x42, 0x4f, 0xd5, 0x94, 0x1b, 0x91, 0xb5, 0x15, 0xcd, 0xb4, 0x9d, 0x23, 0x83,
+	0xdf, 0x7b, 0xe0, 0xd7, 0x34, 0xcd, 0x55, 0x2f, 0xa9, 0x10, 0xe4, 0x8a, 0xa2, 0xe7, 0xf5, 0x8e,
+	0xea, 0x9d, 0x9f, 0x85, 0x77, 0x69, 0x6a, 0x81, 0xc5, 0xc1, 0x98, 0x9c, 0x5e, 0x02, 0x6c, 0x98,
+	0x7b, 0x46, 0x5c, 0x50, 0x47, 0xa0, 0x77, 0xde, 0xdf, 0xfa, 0x76, 0x0d, 0x8f, 0x7f, 0x79, 0x30,
+	0xf8, 0x8a, 0xc8, 0xc5, 0x97, 0x37, 0x05, 0xe5, 0x62, 0x91, 0xad, 0x54, 0xb6, 0x56, 0x44, 0x2e,
+	0xdc, 0x92, 0xa0, 0xce, 0xe8, 0x63, 0xe8, 0x32, 0xa7, 0xf0, 0x0d, 0x46, 0xc8, 0x46, 0x59, 0x95,
+	0x6f, 0x5c, 0x8a, 0x68, 0x4e, 0x12, 0xc9, 0xb8, 0xdd, 0xed, 0xba, 0x71, 0x29, 0x2e, 0x35, 0x43,
+	0xad, 0x5b, 0x09, 0x2b, 0x12, 0x5a, 0x48, 0x4e, 0xf4, 0x13, 0x62, 0x96, 0xa9, 0x6d, 0x26, 0x7a,
+	0x1b, 0x86, 0x29, 0x5d, 0x11, 0x2e, 0x69, 0x1a, 0x89, 0x05, 0xe1, 0x66, 0x4f, 0x6e, 0xe0, 0x81,
+	0xe3, 0xce, 0x14, 0x13, 0x9d, 0x40, 0x9b, 0xc8, 0x88, 0x67, 0xe2, 0xb5, 0x9e, 0x35, 0x1d, 0x7c,
+	0x48, 0x24, 0xce, 0xc4, 0xeb, 0xe0, 0x2f, 0x1e, 0xf8, 0x55, 0x80, 0xbb, 0x69, 0xaf, 0xaf, 0x1f,
+	0xde, 0xce, 0xfa, 0xf1, 0xb0, 0x5a, 0x10, 0x1a, 0x3a, 0xaf, 0x96, 0x52, 0x0d, 0x92, 0xeb, 0xa6,
+	0x4c, 0x64, 0x76, 0xad, 0xd6, 0x1a, 0xb3, 0x24, 0xf5, 0x15, 0xf3, 0x85, 0xe5, 0xa9, 0x0f, 0x3b,
+	0xff, 0xf4, 0x20, 0xef, 0xe0, 0x8a, 0x56, 0x4b, 0x9e, 0x02, 0xd6, 0xd5, 0xfe, 0x30, 0xdc, 0xca,
+	0x01, 0x36, 0xc2, 0xe0, 0xb7, 0x00, 0xd3, 0x45, 0xc9, 0x8b, 0x99, 0x24, 0x52, 0xa8, 0x9d, 0x9e,
+	0xa4, 0x69, 0xa6, 0x10, 0x71, 0x0f, 0xcf, 0x86, 0xa1, 0xa4, 0x29, 0xcd, 0xa9, 0x91, 0x9a, 0xb2,
+	0xdf, 0x30, 0x94, 0x2f, 0x9c, 0xde, 0xf0, 0x4c, 0x52, 0xb7, 0x60, 0x57, 0xb4, 0x0a, 0x92, 0x53,
+	0x85, 0xb6, 0x06, 0xbf, 0x85, 0x2d, 0x15, 0x5c, 0xc2, 0x40, 0xdf, 0xae, 0x7e, 0x50, 0xa8, 0xad,
+	0xb0, 0x5a, 0xfd, 0xbc, 0xda, 0xbe, 0xfc, 0x04, 0x5a, 0x42, 0x79, 0x67, 0xb7, 0xd5, 0x5e, 0xb8,
+	0x71, 0x18, 0x1b, 0x49, 0xf0, 0xb7, 0x06, 0x3c, 0xd0, 0xdc, 0xdb, 0x0d, 0x37, 0x32, 0x57, 0x45,
+	0x72, 0xc1, 0xa9, 0x58, 0xb0, 0x3c, 0xb5, 0x71, 0x1d, 0x19, 0xfe, 0x2b, 0xc7, 0x56, 0xeb, 0xee,
+	0x55, 0xce, 0x62, 0x92, 0xdb, 0xea, 0x1b, 0x86, 0x5b, 0xae, 0x61, 0x2b, 0xad, 0x25, 0xac, 0xb9,
+	0x95, 0xb0, 0x4f, 0x60, 0x64, 0x4e, 0x91, 0xb4, 0x26, 0x6e, 0x43, 0xdc, 0xfd, 0xd2, 0x91, 0xd1,
+	0x73, 0xb4, 0x40, 0xdf, 0x77, 0x5d, 0x6a, 0x52, 0x35, 0x0e, 0xf7, 0xc5, 0xb2, 0xa7, 0x43, 0x3f,
+	0xbb, 0xa7, 0x43, 0x9f, 0x6c, 0x77, 0xe8, 0x36, 0x72, 0x9b, 0x06, 0xfd, 0x05, 0x74, 0x67, 0xb4,
+	0x50, 0x5e, 0x17, 0x72, 0x33, 0xd7, 0x3c, 0x5d, 0xff, 0x86, 0x50, 0xc9, 0x55, 0xf3, 0x86, 0x16,
+	0x36, 0x0d, 0x5d, 0x5c, 0xd1, 0xf5, 0xd1, 0xd4, 0xdc, 0x1e, 0x4d, 0x7f, 0xf7, 0xe0, 0x64, 0x6a,
+	0xd4, 0xaa, 0x0b, 0x5c, 0x66, 0x7e, 0x0e, 0x23, 0xe1, 0x78, 0x51, 0xbc, 0x8e, 0x52, 0xb2, 0xb6,
+	0x43, 0xea, 0x59, 0x78, 0x87, 0x4d, 0x58, 0x31, 0x2e, 0xd6, 0x9f, 0x92, 0xb5, 0x81, 0x62, 0x28,
+	0xb6, 0x98, 0xa7, 0x2f, 0xe1, 0xff, 0xf6, 0xa8, 0xed, 0x19, 0xe0, 0xe3, 0x6d, 0x70, 0x60, 0xf3,
+	0xf5, 0x3a, 0x36, 0xbf, 0x52, 0xbf, 0xf2, 0xe2, 0x25, 0x4b, 0xcb, 0x9c, 0x5e, 0x94, 0xcb, 0x95,
+	0x4a, 0xbf, 0x09, 0xcf, 0x02, 0x6d, 0x29, 0x75, 0x81, 0x0a, 0xc1, 0xb4, 0x85, 0x3a, 0xea, 0x37,
+	0x89, 0xb3, 0xa5, 0x7b, 0xa7, 0xd4, 0x19, 0x0d, 0xa1, 0x21, 0x99, 0x7d, 0xa5, 0x1a, 0x92, 0x05,
+	0x73, 0x18, 0x55, 0x9f, 0x77, 0xc8, 0x9c, 0x41, 0x2b, 0x2e, 0x97, 0x2b, 0x37, 0xb3, 0x87, 0x61,
+	0xa5, 0xa1, 0x1c, 0xc0, 0x46, 0x88, 0x9e, 0x41, 0x87, 0xd8, 0x02, 0xb1, 0x11, 0x8c, 0xc2, 0x9d,
+	0x8a, 0xc1, 0x95, 0x46, 0xf0, 0x57, 0x0f, 0x1e, 0x55, 0x9f, 0xb9, 0xf5, 0x2c, 0xfd, 0x14, 0x40,
+	0x54, 0x42, 0x7b, 0xed, 0xfb, 0xe1, 0x9d, 0xfa, 0x35, 0x89, 0xc9, 0x41, 0xcd, 0xfa, 0xf4, 0x2b,
+	0x38, 0xda, 0x11, 0xef, 0x29, 0xcc, 0x77, 0xb7, 0xb1, 0x3f, 0x0e, 0x77, 0x41, 0xa8, 0xa7, 0xe0,
+	0x4f, 0x1e, 0x1c, 0xed, 0x7a, 0xfc, 0x04, 0x0e, 0x17, 0x94, 0xa4, 0x94, 0xdb, 0x9f, 0xba, 0xdd,
+	0xd0, 0xfd, 0xcd, 0x82, 0xad, 0x00, 0x3d, 0x57, 0x25, 0x5b, 0xc8, 0xaa, 0x64, 0x7b, 0xe7, 0x6f,
+	0xed, 0x02, 0x14, 0x4e, 0xad, 0x42, 0xf5, 0xfe, 0x1b, 0xd2, 0xbc, 0xff, 0x35, 0xd1, 0x7d, 0x0b,
+	0x7e, 0xbf, 0xe6, 0x6f, 0x7c, 0xa8, 0xff, 0xfb, 0xf9, 0xe8, 0xbf, 0x03, 0x00, 0x8d, 0x36, 0x0b,
+	0x2d, 0x07, 0x12, 0x00, 0x00,
 }
@@ -19,7 +19,7 @@ DESCRIPTOR = _descriptor.FileDescriptor(
   name='pb.proto',
   package='',
   syntax='proto3',
-  serialized_pb=_b('\n\x08pb.proto\"\x90\x01\n\x08Metadata\x12\x0f\n\x07version\x18\x01 \x01(\x05\x12\x0c\n\x04hash\x18\x02 \x01(\t\x12\x12\n\nrepository\x18\x03 \x01(\t\x12\x17\n\x0f\x62\x65gin_unix_time\x18\x04 \x01(\x03\x12\x15\n\rend_unix_time\x18\x05 \x01(\x03\x12\x0f\n\x07\x63ommits\x18\x06 \x01(\x05\x12\x10\n\x08run_time\x18\x07 \x01(\x03\"*\n\x17\x42urndownSparseMatrixRow\x12\x0f\n\x07\x63olumns\x18\x01 \x03(\r\"\x7f\n\x14\x42urndownSparseMatrix\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x16\n\x0enumber_of_rows\x18\x02 \x01(\x05\x12\x19\n\x11number_of_columns\x18\x03 \x01(\x05\x12&\n\x04rows\x18\x04 \x03(\x0b\x32\x18.BurndownSparseMatrixRow\"\xed\x01\n\x17\x42urndownAnalysisResults\x12\x13\n\x0bgranularity\x18\x01 \x01(\x05\x12\x10\n\x08sampling\x18\x02 \x01(\x05\x12&\n\x07project\x18\x03 \x01(\x0b\x32\x15.BurndownSparseMatrix\x12$\n\x05\x66iles\x18\x04 \x03(\x0b\x32\x15.BurndownSparseMatrix\x12%\n\x06people\x18\x05 \x03(\x0b\x32\x15.BurndownSparseMatrix\x12\x36\n\x12people_interaction\x18\x06 \x01(\x0b\x32\x1a.CompressedSparseRowMatrix\"}\n\x19\x43ompressedSparseRowMatrix\x12\x16\n\x0enumber_of_rows\x18\x01 \x01(\x05\x12\x19\n\x11number_of_columns\x18\x02 \x01(\x05\x12\x0c\n\x04\x64\x61ta\x18\x03 \x03(\x03\x12\x0f\n\x07indices\x18\x04 \x03(\x05\x12\x0e\n\x06indptr\x18\x05 \x03(\x03\"D\n\x07\x43ouples\x12\r\n\x05index\x18\x01 \x03(\t\x12*\n\x06matrix\x18\x02 \x01(\x0b\x32\x1a.CompressedSparseRowMatrix\"\x1d\n\x0cTouchedFiles\x12\r\n\x05\x66iles\x18\x01 \x03(\x05\"\x7f\n\x16\x43ouplesAnalysisResults\x12\x1e\n\x0c\x66ile_couples\x18\x06 \x01(\x0b\x32\x08.Couples\x12 \n\x0epeople_couples\x18\x07 \x01(\x0b\x32\x08.Couples\x12#\n\x0cpeople_files\x18\x08 \x03(\x0b\x32\r.TouchedFiles\"o\n\nUASTChange\x12\x11\n\tfile_name\x18\x01 \x01(\t\x12\x12\n\nsrc_before\x18\x02 \x01(\t\x12\x11\n\tsrc_after\x18\x03 \x01(\t\x12\x13\n\x0buast_before\x18\x04 \x01(\t\x12\x12\n\nuast_after\x18\x05 \x01(\t\"7\n\x17UASTChangesSaverResults\x12\x1c\n\x07\x63hanges\x18\x01 \x03(\x0b\x32\x0b.UASTChange\"\xb4\x01\n\x0eShotnessRecord\x12\x15\n\rinternal_role\x18\x01 \x01(\t\x12\r\n\x05roles\x18\x02 \x03(\x05\x12\x0c\n\x04name\x18\x03 \x01(\t\x12\x0c\n\x04\x66ile\x18\x04 \x01(\t\x12/\n\x08\x63ounters\x18\x05 \x03(\x0b\x32\x1d.ShotnessRecord.CountersEntry\x1a/\n\rCountersEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\r\n\x05value\x18\x02 \x01(\x05:\x02\x38\x01\";\n\x17ShotnessAnalysisResults\x12 \n\x07records\x18\x01 \x03(\x0b\x32\x0f.ShotnessRecord\"\x1e\n\x0b\x46ileHistory\x12\x0f\n\x07\x63ommits\x18\x01 \x03(\t\"\x8b\x01\n\x18\x46ileHistoryResultMessage\x12\x33\n\x05\x66iles\x18\x01 \x03(\x0b\x32$.FileHistoryResultMessage.FilesEntry\x1a:\n\nFilesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\x1b\n\x05value\x18\x02 \x01(\x0b\x32\x0c.FileHistory:\x02\x38\x01\"=\n\tSentiment\x12\r\n\x05value\x18\x01 \x01(\x02\x12\x10\n\x08\x63omments\x18\x02 \x03(\t\x12\x0f\n\x07\x63ommits\x18\x03 \x03(\t\"\xa4\x01\n\x17\x43ommentSentimentResults\x12\x46\n\x10sentiment_by_day\x18\x01 \x03(\x0b\x32,.CommentSentimentResults.SentimentByDayEntry\x1a\x41\n\x13SentimentByDayEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\x19\n\x05value\x18\x02 \x01(\x0b\x32\n.Sentiment:\x02\x38\x01\"\x8f\x01\n\x0f\x41nalysisResults\x12\x19\n\x06header\x18\x01 \x01(\x0b\x32\t.Metadata\x12\x30\n\x08\x63ontents\x18\x02 \x03(\x0b\x32\x1e.AnalysisResults.ContentsEntry\x1a/\n\rContentsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x0c:\x02\x38\x01\x62\x06proto3')
+  serialized_pb=_b('\n\x08pb.proto\"\xa3\x01\n\x08Metadata\x12\x0f\n\x07version\x18\x01 \x01(\x05\x12\x0c\n\x04hash\x18\x02 \x01(\t\x12\x12\n\nrepository\x18\x03 \x01(\t\x12\x17\n\x0f\x62\x65gin_unix_time\x18\x04 \x01(\x03\x12\x15\n\rend_unix_time\x18\x05 \x01(\x03\x12\x0f\n\x07\x63ommits\x18\x06 \x01(\x05\x12\x10\n\x08run_time\x18\x07 \x01(\x03\x12\x11\n\tsignature\x18\x08 \x01(\t\"*\n\x17\x42urndownSparseMatrixRow\x12\x0f\n\x07\x63olumns\x18\x01 \x03(\r\"\x7f\n\x14\x42urndownSparseMatrix\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x16\n\x0enumber_of_rows\x18\x02 \x01(\x05\x12\x19\n\x11number_of_columns\x18\x03 \x01(\x05\x12&\n\x04rows\x18\x04 \x03(\x0b\x32\x18.BurndownSparseMatrixRow\"U\n\rSurvivalCurve\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x0c\n\x04\x64\x61ys\x18\x02 \x03(\x05\x12\x15\n\rprobabilities\x18\x03 \x03(\x02\x12\x11\n\thalf_life\x18\x04 \x01(\x02\"r\n\x10\x42urndownSurvival\x12\x1f\n\x07project\x18\x01 \x01(\x0b\x32\x0e.SurvivalCurve\x12\x1d\n\x05\x66iles\x18\x02 \x03(\x0b\x32\x0e.SurvivalCurve\x12\x1e\n\x06people\x18\x03 \x03(\x0b\x32\x0e.SurvivalCurve\"G\n\tFileBlame\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\r\n\x05lines\x18\x02 \x03(\x05\x12\x0f\n\x07\x61uthors\x18\x03 \x03(\x05\x12\x0c\n\x04\x64\x61ys\x18\x04 \x03(\x05\"\xa8\x04\n\x17\x42urndownAnalysisResults\x12\x13\n\x0bgranularity\x18\x01 \x01(\x05\x12\x10\n\x08sampling\x18\x02 \x01(\x05\x12&\n\x07project\x18\x03 \x01(\x0b\x32\x15.BurndownSparseMatrix\x12$\n\x05\x66iles\x18\x04 \x03(\x0b\x32\x15.BurndownSparseMatrix\x12%\n\x06people\x18\x05 \x03(\x0b\x32\x15.BurndownSparseMatrix\x12\x36\n\x12people_interaction\x18\x06 \x01(\x0b\x32\x1a.CompressedSparseRowMatrix\x12#\n\x08survival\x18\x07 \x01(\x0b\x32\x11.BurndownSurvival\x12\x19\n\x05\x62lame\x18\x08 \x03(\x0b\x32\n.FileBlame\x12>\n\x1apeople_interaction_history\x18\t \x03(\x0b\x32\x1a.CompressedSparseRowMatrix\x12\r\n\x05teams\x18\n \x03(\t\x12\x35\n\x11teams_interaction\x18\x0b \x01(\x0b\x32\x1a.CompressedSparseRowMatrix\x12?\n\x0c\x62lob_classes\x18\x0c \x03(\x0b\x32).BurndownAnalysisResults.BlobClassesEntry\x1a\x32\n\x10\x42lobClassesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x05:\x02\x38\x01\"}\n\x19\x43ompressedSparseRowMatrix\x12\x16\n\x0enumber_of_rows\x18\x01 \x01(\x05\x12\x19\n\x11number_of_columns\x18\x02 \x01(\x05\x12\x0c\n\x04\x64\x61ta\x18\x03 \x03(\x03\x12\x0f\n\x07indices\x18\x04 \x03(\x05\x12\x0e\n\x06indptr\x18\x05 \x03(\x03\"D\n\x07\x43ouples\x12\r\n\x05index\x18\x01 \x03(\t\x12*\n\x06matrix\x18\x02 \x01(\x0b\x32\x1a.CompressedSparseRowMatrix\"\x1d\n\x0cTouchedFiles\x12\r\n\x05\x66iles\x18\x01 \x03(\x05\"\x9f\x01\n\x16\x43ouplesAnalysisResults\x12\x1e\n\x0c\x66ile_couples\x18\x06 \x01(\x0b\x32\x08.Couples\x12 \n\x0epeople_couples\x18\x07 \x01(\x0b\x32\x08.Couples\x12#\n\x0cpeople_files\x18\x08 \x03(\x0b\x32\r.TouchedFiles\x12\x1e\n\x0cteam_couples\x18\t \x01(\x0b\x32\x08.Couples\"o\n\nUASTChange\x12\x11\n\tfile_name\x18\x01 \x01(\t\x12\x12\n\nsrc_before\x18\x02 \x01(\t\x12\x11\n\tsrc_after\x18\x03 \x01(\t\x12\x13\n\x0buast_before\x18\x04 \x01(\t\x12\x12\n\nuast_after\x18\x05 \x01(\t\"7\n\x17UASTChangesSaverResults\x12\x1c\n\x07\x63hanges\x18\x01 \x03(\x0b\x32\x0b.UASTChange\"\xb4\x01\n\x0eShotnessRecord\x12\x15\n\rinternal_role\x18\x01 \x01(\t\x12\r\n\x05roles\x18\x02 \x03(\x05\x12\x0c\n\x04name\x18\x03 \x01(\t\x12\x0c\n\x04\x66ile\x18\x04 \x01(\t\x12/\n\x08\x63ounters\x18\x05 \x03(\x0b\x32\x1d.ShotnessRecord.CountersEntry\x1a/\n\rCountersEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\r\n\x05value\x18\x02 \x01(\x05:\x02\x38\x01\";\n\x17ShotnessAnalysisResults\x12 \n\x07records\x18\x01 \x03(\x0b\x32\x0f.ShotnessRecord\"\x1e\n\x0b\x46ileHistory\x12\x0f\n\x07\x63ommits\x18\x01 \x03(\t\"\x8b\x01\n\x18\x46ileHistoryResultMessage\x12\x33\n\x05\x66iles\x18\x01 \x03(\x0b\x32$.FileHistoryResultMessage.FilesEntry\x1a:\n\nFilesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\x1b\n\x05value\x18\x02 \x01(\x0b\x32\x0c.FileHistory:\x02\x38\x01\"\xa0\x01\n\rPathOwnership\x12\x0c\n\x04path\x18\x01 \x01(\t\x12-\n\townership\x18\x02 \x01(\x0b\x32\x1a.CompressedSparseRowMatrix\x12\x12\n\nbus_factor\x18\x03 \x01(\x05\x12\x15\n\rconcentration\x18\x04 \x01(\x02\x12\x16\n\x0e\x64\x65parted_share\x18\x05 \x01(\x02\x12\x0f\n\x07\x61t_risk\x18\x06 \x01(\x08\"\x84\x01\n\x18OwnershipAnalysisResults\x12\x10\n\x08sampling\x18\x01 \x01(\x05\x12\x0e\n\x06people\x18\x02 \x03(\t\x12\x15\n\rlast_activity\x18\x03 \x03(\x05\x12\x10\n\x08\x64\x65parted\x18\x04 \x03(\x08\x12\x1d\n\x05paths\x18\x05 \x03(\x0b\x32\x0e.PathOwnership\"T\n\nChurnStats\x12\x11\n\tadditions\x18\x01 \x01(\x05\x12\x11\n\tdeletions\x18\x02 \x01(\x05\x12\x10\n\x08rewrites\x18\x03 \x01(\x05\x12\x0e\n\x06recent\x18\x04 \x01(\x05\"9\n\rChurnTimeline\x12\x0c\n\x04\x64\x61ys\x18\x01 \x03(\x05\x12\x1a\n\x05stats\x18\x02 \x03(\x0b\x32\x0b.ChurnStats\"\xf6\x01\n\x14\x43hurnAnalysisResults\x12\x18\n\x10recent_threshold\x18\x01 \x01(\x05\x12\x1e\n\x06global\x18\x02 \x01(\x0b\x32\x0e.ChurnTimeline\x12\x0e\n\x06people\x18\x03 \x03(\t\x12(\n\x10people_timelines\x18\x04 \x03(\x0b\x32\x0e.ChurnTimeline\x12/\n\x05\x66iles\x18\x05 \x03(\x0b\x32 .ChurnAnalysisResults.FilesEntry\x1a\x39\n\nFilesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\x1a\n\x05value\x18\x02 \x01(\x0b\x32\x0b.ChurnStats:\x02\x38\x01\"=\n\tSentiment\x12\r\n\x05value\x18\x01 \x01(\x02\x12\x10\n\x08\x63omments\x18\x02 \x03(\t\x12\x0f\n\x07\x63ommits\x18\x03 \x03(\t\"\xa4\x01\n\x17\x43ommentSentimentResults\x12\x46\n\x10sentiment_by_day\x18\x01 \x03(\x0b\x32,.CommentSentimentResults.SentimentByDayEntry\x1a\x41\n\x13SentimentByDayEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\x19\n\x05value\x18\x02 \x01(\x0b\x32\n.Sentiment:\x02\x38\x01\"F\n\rSubmoduleBump\x12\x0e\n\x06\x63ommit\x18\x01 \x01(\t\x12\x0b\n\x03\x64\x61y\x18\x02 \x01(\x05\x12\x0c\n\x04\x66rom\x18\x03 \x01(\t\x12\n\n\x02to\x18\x04 \x01(\t\"U\n\x10SubmoduleResults\x12\x1d\n\x05\x62umps\x18\x01 \x03(\x0b\x32\x0e.SubmoduleBump\x12\"\n\x08\x61nalysis\x18\x02 \x01(\x0b\x32\x10.AnalysisResults\"\xa1\x01\n\x19SubmodulesAnalysisResults\x12>\n\nsubmodules\x18\x01 \x03(\x0b\x32*.SubmodulesAnalysisResults.SubmodulesEntry\x1a\x44\n\x0fSubmodulesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12 \n\x05value\x18\x02 \x01(\x0b\x32\x11.SubmoduleResults:\x02\x38\x01\"\x8f\x01\n\x0f\x41nalysisResults\x12\x19\n\x06header\x18\x01 \x01(\x0b\x32\t.Metadata\x12\x30\n\x08\x63ontents\x18\x02 \x03(\x0b\x32\x1e.AnalysisResults.ContentsEntry\x1a/\n\rContentsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x0c:\x02\x38\x01\x62\x06proto3')
 )
 
 
@@ -81,6 +81,13 @@ _METADATA = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
+    _descriptor.FieldDescriptor(
+      name='signature', full_name='Metadata.signature', index=7,
+      number=8, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
   ],
   extensions=[
   ],
@@ -94,7 +101,7 @@ _METADATA = _descriptor.Descriptor(
   oneofs=[
   ],
   serialized_start=13,
-  serialized_end=157,
+  serialized_end=176,
 )
 
 
@@ -124,8 +131,8 @@ _BURNDOWNSPARSEMATRIXROW = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=159,
-  serialized_end=201,
+  serialized_start=178,
+  serialized_end=220,
 )
 
 
@@ -176,11 +183,197 @@ _BURNDOWNSPARSEMATRIX = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=203,
-  serialized_end=330,
+  serialized_start=222,
+  serialized_end=349,
+)
+
+
+_SURVIVALCURVE = _descriptor.Descriptor(
+  name='SurvivalCurve',
+  full_name='SurvivalCurve',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='name', full_name='SurvivalCurve.name', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='days', full_name='SurvivalCurve.days', index=1,
+      number=2, type=5, cpp_type=1, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='probabilities', full_name='SurvivalCurve.probabilities', index=2,
+      number=3, type=2, cpp_type=6, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='half_life', full_name='SurvivalCurve.half_life', index=3,
+      number=4, type=2, cpp_type=6, label=1,
+      has_default_value=False, default_value=float(0),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=351,
+  serialized_end=436,
+)
+
+
+_BURNDOWNSURVIVAL = _descriptor.Descriptor(
+  name='BurndownSurvival',
+  full_name='BurndownSurvival',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='project', full_name='BurndownSurvival.project', index=0,
+      number=1, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='files', full_name='BurndownSurvival.files', index=1,
+      number=2, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='people', full_name='BurndownSurvival.people', index=2,
+      number=3, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=438,
+  serialized_end=552,
+)
+
+
+_FILEBLAME = _descriptor.Descriptor(
+  name='FileBlame',
+  full_name='FileBlame',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='name', full_name='FileBlame.name', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='lines', full_name='FileBlame.lines', index=1,
+      number=2, type=5, cpp_type=1, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='authors', full_name='FileBlame.authors', index=2,
+      number=3, type=5, cpp_type=1, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='days', full_name='FileBlame.days', index=3,
+      number=4, type=5, cpp_type=1, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=554,
+  serialized_end=625,
 )
 
 
+_BURNDOWNANALYSISRESULTS_BLOBCLASSESENTRY = _descriptor.Descriptor(
+  name='BlobClassesEntry',
+  full_name='BurndownAnalysisResults.BlobClassesEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='BurndownAnalysisResults.BlobClassesEntry.key', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='BurndownAnalysisResults.BlobClassesEntry.value', index=1,
+      number=2, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=_descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001')),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=1130,
+  serialized_end=1180,
+)
+
 _BURNDOWNANALYSISRESULTS = _descriptor.Descriptor(
   name='BurndownAnalysisResults',
   full_name='BurndownAnalysisResults',
@@ -230,10 +423,52 @@ _BURNDOWNANALYSISRESULTS = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
+    _descriptor.FieldDescriptor(
+      name='survival', full_name='BurndownAnalysisResults.survival', index=6,
+      number=7, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='blame', full_name='BurndownAnalysisResults.blame', index=7,
+      number=8, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='people_interaction_history', full_name='BurndownAnalysisResults.people_interaction_history', index=8,
+      number=9, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='teams', full_name='BurndownAnalysisResults.teams', index=9,
+      number=10, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='teams_interaction', full_name='BurndownAnalysisResults.teams_interaction', index=10,
+      number=11, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='blob_classes', full_name='BurndownAnalysisResults.blob_classes', index=11,
+      number=12, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
   ],
   extensions=[
   ],
-  nested_types=[],
+  nested_types=[_BURNDOWNANALYSISRESULTS_BLOBCLASSESENTRY, ],
   enum_types=[
   ],
   options=None,
@@ -242,8 +477,8 @@ _BURNDOWNANALYSISRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=333,
-  serialized_end=570,
+  serialized_start=628,
+  serialized_end=1180,
 )
 
 
@@ -301,8 +536,8 @@ _COMPRESSEDSPARSEROWMATRIX = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=572,
-  serialized_end=697,
+  serialized_start=1182,
+  serialized_end=1307,
 )
 
 
@@ -339,8 +574,8 @@ _COUPLES = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=699,
-  serialized_end=767,
+  serialized_start=1309,
+  serialized_end=1377,
 )
 
 
@@ -370,8 +605,8 @@ _TOUCHEDFILES = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=769,
-  serialized_end=798,
+  serialized_start=1379,
+  serialized_end=1408,
 )
 
 
@@ -403,6 +638,13 @@ _COUPLESANALYSISRESULTS = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
+    _descriptor.FieldDescriptor(
+      name='team_couples', full_name='CouplesAnalysisResults.team_couples', index=3,
+      number=9, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
   ],
   extensions=[
   ],
@@ -415,8 +657,8 @@ _COUPLESANALYSISRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=800,
-  serialized_end=927,
+  serialized_start=1411,
+  serialized_end=1570,
 )
 
 
@@ -474,8 +716,8 @@ _UASTCHANGE = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=929,
-  serialized_end=1040,
+  serialized_start=1572,
+  serialized_end=1683,
 )
 
 
@@ -505,8 +747,8 @@ _UASTCHANGESSAVERRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1042,
-  serialized_end=1097,
+  serialized_start=1685,
+  serialized_end=1740,
 )
 
 
@@ -543,8 +785,8 @@ _SHOTNESSRECORD_COUNTERSENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1233,
-  serialized_end=1280,
+  serialized_start=1876,
+  serialized_end=1923,
 )
 
 _SHOTNESSRECORD = _descriptor.Descriptor(
@@ -601,8 +843,8 @@ _SHOTNESSRECORD = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1100,
-  serialized_end=1280,
+  serialized_start=1743,
+  serialized_end=1923,
 )
 
 
@@ -632,8 +874,8 @@ _SHOTNESSANALYSISRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1282,
-  serialized_end=1341,
+  serialized_start=1925,
+  serialized_end=1984,
 )
 
 
@@ -663,8 +905,8 @@ _FILEHISTORY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1343,
-  serialized_end=1373,
+  serialized_start=1986,
+  serialized_end=2016,
 )
 
 
@@ -701,8 +943,8 @@ _FILEHISTORYRESULTMESSAGE_FILESENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1457,
-  serialized_end=1515,
+  serialized_start=2100,
+  serialized_end=2158,
 )
 
 _FILEHISTORYRESULTMESSAGE = _descriptor.Descriptor(
@@ -731,36 +973,57 @@ _FILEHISTORYRESULTMESSAGE = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1376,
-  serialized_end=1515,
+  serialized_start=2019,
+  serialized_end=2158,
 )
 
 
-_SENTIMENT = _descriptor.Descriptor(
-  name='Sentiment',
-  full_name='Sentiment',
+_PATHOWNERSHIP = _descriptor.Descriptor(
+  name='PathOwnership',
+  full_name='PathOwnership',
   filename=None,
   file=DESCRIPTOR,
   containing_type=None,
   fields=[
     _descriptor.FieldDescriptor(
-      name='value', full_name='Sentiment.value', index=0,
-      number=1, type=2, cpp_type=6, label=1,
+      name='path', full_name='PathOwnership.path', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='ownership', full_name='PathOwnership.ownership', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='bus_factor', full_name='PathOwnership.bus_factor', index=2,
+      number=3, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='concentration', full_name='PathOwnership.concentration', index=3,
+      number=4, type=2, cpp_type=6, label=1,
       has_default_value=False, default_value=float(0),
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
     _descriptor.FieldDescriptor(
-      name='comments', full_name='Sentiment.comments', index=1,
-      number=2, type=9, cpp_type=9, label=3,
-      has_default_value=False, default_value=[],
+      name='departed_share', full_name='PathOwnership.departed_share', index=4,
+      number=5, type=2, cpp_type=6, label=1,
+      has_default_value=False, default_value=float(0),
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
     _descriptor.FieldDescriptor(
-      name='commits', full_name='Sentiment.commits', index=2,
-      number=3, type=9, cpp_type=9, label=3,
-      has_default_value=False, default_value=[],
+      name='at_risk', full_name='PathOwnership.at_risk', index=5,
+      number=6, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
@@ -776,29 +1039,50 @@ _SENTIMENT = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1517,
-  serialized_end=1578,
+  serialized_start=2161,
+  serialized_end=2321,
 )
 
 
-_COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY = _descriptor.Descriptor(
-  name='SentimentByDayEntry',
-  full_name='CommentSentimentResults.SentimentByDayEntry',
+_OWNERSHIPANALYSISRESULTS = _descriptor.Descriptor(
+  name='OwnershipAnalysisResults',
+  full_name='OwnershipAnalysisResults',
   filename=None,
   file=DESCRIPTOR,
   containing_type=None,
   fields=[
     _descriptor.FieldDescriptor(
-      name='key', full_name='CommentSentimentResults.SentimentByDayEntry.key', index=0,
+      name='sampling', full_name='OwnershipAnalysisResults.sampling', index=0,
       number=1, type=5, cpp_type=1, label=1,
       has_default_value=False, default_value=0,
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
     _descriptor.FieldDescriptor(
-      name='value', full_name='CommentSentimentResults.SentimentByDayEntry.value', index=1,
-      number=2, type=11, cpp_type=10, label=1,
-      has_default_value=False, default_value=None,
+      name='people', full_name='OwnershipAnalysisResults.people', index=1,
+      number=2, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='last_activity', full_name='OwnershipAnalysisResults.last_activity', index=2,
+      number=3, type=5, cpp_type=1, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='departed', full_name='OwnershipAnalysisResults.departed', index=3,
+      number=4, type=8, cpp_type=7, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='paths', full_name='OwnershipAnalysisResults.paths', index=4,
+      number=5, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
@@ -808,27 +1092,296 @@ _COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY = _descriptor.Descriptor(
   nested_types=[],
   enum_types=[
   ],
-  options=_descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001')),
+  options=None,
   is_extendable=False,
   syntax='proto3',
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1680,
-  serialized_end=1745,
+  serialized_start=2324,
+  serialized_end=2456,
 )
 
-_COMMENTSENTIMENTRESULTS = _descriptor.Descriptor(
-  name='CommentSentimentResults',
-  full_name='CommentSentimentResults',
+
+_CHURNSTATS = _descriptor.Descriptor(
+  name='ChurnStats',
+  full_name='ChurnStats',
   filename=None,
   file=DESCRIPTOR,
   containing_type=None,
   fields=[
     _descriptor.FieldDescriptor(
-      name='sentiment_by_day', full_name='CommentSentimentResults.sentiment_by_day', index=0,
-      number=1, type=11, cpp_type=10, label=3,
-      has_default_value=False, default_value=[],
+      name='additions', full_name='ChurnStats.additions', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='deletions', full_name='ChurnStats.deletions', index=1,
+      number=2, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='rewrites', full_name='ChurnStats.rewrites', index=2,
+      number=3, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='recent', full_name='ChurnStats.recent', index=3,
+      number=4, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2458,
+  serialized_end=2542,
+)
+
+
+_CHURNTIMELINE = _descriptor.Descriptor(
+  name='ChurnTimeline',
+  full_name='ChurnTimeline',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='days', full_name='ChurnTimeline.days', index=0,
+      number=1, type=5, cpp_type=1, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='stats', full_name='ChurnTimeline.stats', index=1,
+      number=2, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2544,
+  serialized_end=2601,
+)
+
+
+_CHURNANALYSISRESULTS_FILESENTRY = _descriptor.Descriptor(
+  name='FilesEntry',
+  full_name='ChurnAnalysisResults.FilesEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='ChurnAnalysisResults.FilesEntry.key', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='ChurnAnalysisResults.FilesEntry.value', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=_descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001')),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2793,
+  serialized_end=2850,
+)
+
+_CHURNANALYSISRESULTS = _descriptor.Descriptor(
+  name='ChurnAnalysisResults',
+  full_name='ChurnAnalysisResults',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='recent_threshold', full_name='ChurnAnalysisResults.recent_threshold', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='global', full_name='ChurnAnalysisResults.global', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='people', full_name='ChurnAnalysisResults.people', index=2,
+      number=3, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='people_timelines', full_name='ChurnAnalysisResults.people_timelines', index=3,
+      number=4, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='files', full_name='ChurnAnalysisResults.files', index=4,
+      number=5, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[_CHURNANALYSISRESULTS_FILESENTRY, ],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2604,
+  serialized_end=2850,
+)
+
+
+_SENTIMENT = _descriptor.Descriptor(
+  name='Sentiment',
+  full_name='Sentiment',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='value', full_name='Sentiment.value', index=0,
+      number=1, type=2, cpp_type=6, label=1,
+      has_default_value=False, default_value=float(0),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='comments', full_name='Sentiment.comments', index=1,
+      number=2, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='commits', full_name='Sentiment.commits', index=2,
+      number=3, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2852,
+  serialized_end=2913,
+)
+
+
+_COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY = _descriptor.Descriptor(
+  name='SentimentByDayEntry',
+  full_name='CommentSentimentResults.SentimentByDayEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='CommentSentimentResults.SentimentByDayEntry.key', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='CommentSentimentResults.SentimentByDayEntry.value', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=_descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001')),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3015,
+  serialized_end=3080,
+)
+
+_COMMENTSENTIMENTRESULTS = _descriptor.Descriptor(
+  name='CommentSentimentResults',
+  full_name='CommentSentimentResults',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='sentiment_by_day', full_name='CommentSentimentResults.sentiment_by_day', index=0,
+      number=1, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
@@ -844,8 +1397,166 @@ _COMMENTSENTIMENTRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1581,
-  serialized_end=1745,
+  serialized_start=2916,
+  serialized_end=3080,
+)
+
+
+_SUBMODULEBUMP = _descriptor.Descriptor(
+  name='SubmoduleBump',
+  full_name='SubmoduleBump',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='commit', full_name='SubmoduleBump.commit', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='day', full_name='SubmoduleBump.day', index=1,
+      number=2, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='from', full_name='SubmoduleBump.from', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='to', full_name='SubmoduleBump.to', index=3,
+      number=4, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3082,
+  serialized_end=3152,
+)
+
+
+_SUBMODULERESULTS = _descriptor.Descriptor(
+  name='SubmoduleResults',
+  full_name='SubmoduleResults',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='bumps', full_name='SubmoduleResults.bumps', index=0,
+      number=1, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='analysis', full_name='SubmoduleResults.analysis', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3154,
+  serialized_end=3239,
+)
+
+
+_SUBMODULESANALYSISRESULTS_SUBMODULESENTRY = _descriptor.Descriptor(
+  name='SubmodulesEntry',
+  full_name='SubmodulesAnalysisResults.SubmodulesEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='SubmodulesAnalysisResults.SubmodulesEntry.key', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='SubmodulesAnalysisResults.SubmodulesEntry.value', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=_descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001')),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3335,
+  serialized_end=3403,
+)
+
+_SUBMODULESANALYSISRESULTS = _descriptor.Descriptor(
+  name='SubmodulesAnalysisResults',
+  full_name='SubmodulesAnalysisResults',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='submodules', full_name='SubmodulesAnalysisResults.submodules', index=0,
+      number=1, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[_SUBMODULESANALYSISRESULTS_SUBMODULESENTRY, ],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3242,
+  serialized_end=3403,
 )
 
 
@@ -882,8 +1593,8 @@ _ANALYSISRESULTS_CONTENTSENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1844,
-  serialized_end=1891,
+  serialized_start=3502,
+  serialized_end=3549,
 )
 
 _ANALYSISRESULTS = _descriptor.Descriptor(
@@ -919,19 +1630,29 @@ _ANALYSISRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1748,
-  serialized_end=1891,
+  serialized_start=3406,
+  serialized_end=3549,
 )
 
 _BURNDOWNSPARSEMATRIX.fields_by_name['rows'].message_type = _BURNDOWNSPARSEMATRIXROW
+_BURNDOWNSURVIVAL.fields_by_name['project'].message_type = _SURVIVALCURVE
+_BURNDOWNSURVIVAL.fields_by_name['files'].message_type = _SURVIVALCURVE
+_BURNDOWNSURVIVAL.fields_by_name['people'].message_type = _SURVIVALCURVE
+_BURNDOWNANALYSISRESULTS_BLOBCLASSESENTRY.containing_type = _BURNDOWNANALYSISRESULTS
 _BURNDOWNANALYSISRESULTS.fields_by_name['project'].message_type = _BURNDOWNSPARSEMATRIX
 _BURNDOWNANALYSISRESULTS.fields_by_name['files'].message_type = _BURNDOWNSPARSEMATRIX
 _BURNDOWNANALYSISRESULTS.fields_by_name['people'].message_type = _BURNDOWNSPARSEMATRIX
 _BURNDOWNANALYSISRESULTS.fields_by_name['people_interaction'].message_type = _COMPRESSEDSPARSEROWMATRIX
+_BURNDOWNANALYSISRESULTS.fields_by_name['survival'].message_type = _BURNDOWNSURVIVAL
+_BURNDOWNANALYSISRESULTS.fields_by_name['blame'].message_type = _FILEBLAME
+_BURNDOWNANALYSISRESULTS.fields_by_name['people_interaction_history'].message_type = _COMPRESSEDSPARSEROWMATRIX
+_BURNDOWNANALYSISRESULTS.fields_by_name['teams_interaction'].message_type = _COMPRESSEDSPARSEROWMATRIX
+_BURNDOWNANALYSISRESULTS.fields_by_name['blob_classes'].message_type = _BURNDOWNANALYSISRESULTS_BLOBCLASSESENTRY
 _COUPLES.fields_by_name['matrix'].message_type = _COMPRESSEDSPARSEROWMATRIX
 _COUPLESANALYSISRESULTS.fields_by_name['file_couples'].message_type = _COUPLES
 _COUPLESANALYSISRESULTS.fields_by_name['people_couples'].message_type = _COUPLES
 _COUPLESANALYSISRESULTS.fields_by_name['people_files'].message_type = _TOUCHEDFILES
+_COUPLESANALYSISRESULTS.fields_by_name['team_couples'].message_type = _COUPLES
 _UASTCHANGESSAVERRESULTS.fields_by_name['changes'].message_type = _UASTCHANGE
 _SHOTNESSRECORD_COUNTERSENTRY.containing_type = _SHOTNESSRECORD
 _SHOTNESSRECORD.fields_by_name['counters'].message_type = _SHOTNESSRECORD_COUNTERSENTRY
@@ -939,15 +1660,31 @@ _SHOTNESSANALYSISRESULTS.fields_by_name['records'].message_type = _SHOTNESSRECOR
 _FILEHISTORYRESULTMESSAGE_FILESENTRY.fields_by_name['value'].message_type = _FILEHISTORY
 _FILEHISTORYRESULTMESSAGE_FILESENTRY.containing_type = _FILEHISTORYRESULTMESSAGE
 _FILEHISTORYRESULTMESSAGE.fields_by_name['files'].message_type = _FILEHISTORYRESULTMESSAGE_FILESENTRY
+_PATHOWNERSHIP.fields_by_name['ownership'].message_type = _COMPRESSEDSPARSEROWMATRIX
+_OWNERSHIPANALYSISRESULTS.fields_by_name['paths'].message_type = _PATHOWNERSHIP
+_CHURNTIMELINE.fields_by_name['stats'].message_type = _CHURNSTATS
+_CHURNANALYSISRESULTS_FILESENTRY.fields_by_name['value'].message_type = _CHURNSTATS
+_CHURNANALYSISRESULTS_FILESENTRY.containing_type = _CHURNANALYSISRESULTS
+_CHURNANALYSISRESULTS.fields_by_name['global'].message_type = _CHURNTIMELINE
+_CHURNANALYSISRESULTS.fields_by_name['people_timelines'].message_type = _CHURNTIMELINE
+_CHURNANALYSISRESULTS.fields_by_name['files'].message_type = _CHURNANALYSISRESULTS_FILESENTRY
 _COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY.fields_by_name['value'].message_type = _SENTIMENT
 _COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY.containing_type = _COMMENTSENTIMENTRESULTS
 _COMMENTSENTIMENTRESULTS.fields_by_name['sentiment_by_day'].message_type = _COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY
+_SUBMODULERESULTS.fields_by_name['bumps'].message_type = _SUBMODULEBUMP
+_SUBMODULERESULTS.fields_by_name['analysis'].message_type = _ANALYSISRESULTS
+_SUBMODULESANALYSISRESULTS_SUBMODULESENTRY.fields_by_name['value'].message_type = _SUBMODULERESULTS
+_SUBMODULESANALYSISRESULTS_SUBMODULESENTRY.containing_type = _SUBMODULESANALYSISRESULTS
+_SUBMODULESANALYSISRESULTS.fields_by_name['submodules'].message_type = _SUBMODULESANALYSISRESULTS_SUBMODULESENTRY
 _ANALYSISRESULTS_CONTENTSENTRY.containing_type = _ANALYSISRESULTS
 _ANALYSISRESULTS.fields_by_name['header'].message_type = _METADATA
 _ANALYSISRESULTS.fields_by_name['contents'].message_type = _ANALYSISRESULTS_CONTENTSENTRY
 DESCRIPTOR.message_types_by_name['Metadata'] = _METADATA
 DESCRIPTOR.message_types_by_name['BurndownSparseMatrixRow'] = _BURNDOWNSPARSEMATRIXROW
 DESCRIPTOR.message_types_by_name['BurndownSparseMatrix'] = _BURNDOWNSPARSEMATRIX
+DESCRIPTOR.message_types_by_name['SurvivalCurve'] = _SURVIVALCURVE
+DESCRIPTOR.message_types_by_name['BurndownSurvival'] = _BURNDOWNSURVIVAL
+DESCRIPTOR.message_types_by_name['FileBlame'] = _FILEBLAME
 DESCRIPTOR.message_types_by_name['BurndownAnalysisResults'] = _BURNDOWNANALYSISRESULTS
 DESCRIPTOR.message_types_by_name['CompressedSparseRowMatrix'] = _COMPRESSEDSPARSEROWMATRIX
 DESCRIPTOR.message_types_by_name['Couples'] = _COUPLES
@@ -959,8 +1696,16 @@ DESCRIPTOR.message_types_by_name['ShotnessRecord'] = _SHOTNESSRECORD
 DESCRIPTOR.message_types_by_name['ShotnessAnalysisResults'] = _SHOTNESSANALYSISRESULTS
 DESCRIPTOR.message_types_by_name['FileHistory'] = _FILEHISTORY
 DESCRIPTOR.message_types_by_name['FileHistoryResultMessage'] = _FILEHISTORYRESULTMESSAGE
+DESCRIPTOR.message_types_by_name['PathOwnership'] = _PATHOWNERSHIP
+DESCRIPTOR.message_types_by_name['OwnershipAnalysisResults'] = _OWNERSHIPANALYSISRESULTS
+DESCRIPTOR.message_types_by_name['ChurnStats'] = _CHURNSTATS
+DESCRIPTOR.message_types_by_name['ChurnTimeline'] = _CHURNTIMELINE
+DESCRIPTOR.message_types_by_name['ChurnAnalysisResults'] = _CHURNANALYSISRESULTS
 DESCRIPTOR.message_types_by_name['Sentiment'] = _SENTIMENT
 DESCRIPTOR.message_types_by_name['CommentSentimentResults'] = _COMMENTSENTIMENTRESULTS
+DESCRIPTOR.message_types_by_name['SubmoduleBump'] = _SUBMODULEBUMP
+DESCRIPTOR.message_types_by_name['SubmoduleResults'] = _SUBMODULERESULTS
+DESCRIPTOR.message_types_by_name['SubmodulesAnalysisResults'] = _SUBMODULESANALYSISRESULTS
 DESCRIPTOR.message_types_by_name['AnalysisResults'] = _ANALYSISRESULTS
 _sym_db.RegisterFileDescriptor(DESCRIPTOR)
 
@@ -985,12 +1730,41 @@ BurndownSparseMatrix = _reflection.GeneratedProtocolMessageType('BurndownSparseM
   ))
 _sym_db.RegisterMessage(BurndownSparseMatrix)
 
+SurvivalCurve = _reflection.GeneratedProtocolMessageType('SurvivalCurve', (_message.Message,), dict(
+  DESCRIPTOR = _SURVIVALCURVE,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:SurvivalCurve)
+  ))
+_sym_db.RegisterMessage(SurvivalCurve)
+
+BurndownSurvival = _reflection.GeneratedProtocolMessageType('BurndownSurvival', (_message.Message,), dict(
+  DESCRIPTOR = _BURNDOWNSURVIVAL,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:BurndownSurvival)
+  ))
+_sym_db.RegisterMessage(BurndownSurvival)
+
+FileBlame = _reflection.GeneratedProtocolMessageType('FileBlame', (_message.Message,), dict(
+  DESCRIPTOR = _FILEBLAME,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:FileBlame)
+  ))
+_sym_db.RegisterMessage(FileBlame)
+
 BurndownAnalysisResults = _reflection.GeneratedProtocolMessageType('BurndownAnalysisResults', (_message.Message,), dict(
+
+  BlobClassesEntry = _reflection.GeneratedProtocolMessageType('BlobClassesEntry', (_message.Message,), dict(
+    DESCRIPTOR = _BURNDOWNANALYSISRESULTS_BLOBCLASSESENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:BurndownAnalysisResults.BlobClassesEntry)
+    ))
+  ,
   DESCRIPTOR = _BURNDOWNANALYSISRESULTS,
   __module__ = 'pb_pb2'
   # @@protoc_insertion_point(class_scope:BurndownAnalysisResults)
   ))
 _sym_db.RegisterMessage(BurndownAnalysisResults)
+_sym_db.RegisterMessage(BurndownAnalysisResults.BlobClassesEntry)
 
 CompressedSparseRowMatrix = _reflection.GeneratedProtocolMessageType('CompressedSparseRowMatrix', (_message.Message,), dict(
   DESCRIPTOR = _COMPRESSEDSPARSEROWMATRIX,
@@ -1078,6 +1852,49 @@ FileHistoryResultMessage = _reflection.GeneratedProtocolMessageType('FileHistory
 _sym_db.RegisterMessage(FileHistoryResultMessage)
 _sym_db.RegisterMessage(FileHistoryResultMessage.FilesEntry)
 
+PathOwnership = _reflection.GeneratedProtocolMessageType('PathOwnership', (_message.Message,), dict(
+  DESCRIPTOR = _PATHOWNERSHIP,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:PathOwnership)
+  ))
+_sym_db.RegisterMessage(PathOwnership)
+
+OwnershipAnalysisResults = _reflection.GeneratedProtocolMessageType('OwnershipAnalysisResults', (_message.Message,), dict(
+  DESCRIPTOR = _OWNERSHIPANALYSISRESULTS,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:OwnershipAnalysisResults)
+  ))
+_sym_db.RegisterMessage(OwnershipAnalysisResults)
+
+ChurnStats = _reflection.GeneratedProtocolMessageType('ChurnStats', (_message.Message,), dict(
+  DESCRIPTOR = _CHURNSTATS,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:ChurnStats)
+  ))
+_sym_db.RegisterMessage(ChurnStats)
+
+ChurnTimeline = _reflection.GeneratedProtocolMessageType('ChurnTimeline', (_message.Message,), dict(
+  DESCRIPTOR = _CHURNTIMELINE,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:ChurnTimeline)
+  ))
+_sym_db.RegisterMessage(ChurnTimeline)
+
+ChurnAnalysisResults = _reflection.GeneratedProtocolMessageType('ChurnAnalysisResults', (_message.Message,), dict(
+
+  FilesEntry = _reflection.GeneratedProtocolMessageType('FilesEntry', (_message.Message,), dict(
+    DESCRIPTOR = _CHURNANALYSISRESULTS_FILESENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:ChurnAnalysisResults.FilesEntry)
+    ))
+  ,
+  DESCRIPTOR = _CHURNANALYSISRESULTS,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:ChurnAnalysisResults)
+  ))
+_sym_db.RegisterMessage(ChurnAnalysisResults)
+_sym_db.RegisterMessage(ChurnAnalysisResults.FilesEntry)
+
 Sentiment = _reflection.GeneratedProtocolMessageType('Sentiment', (_message.Message,), dict(
   DESCRIPTOR = _SENTIMENT,
   __module__ = 'pb_pb2'
@@ -1100,6 +1917,35 @@ CommentSentimentResults = _reflection.GeneratedProtocolMessageType('CommentSenti
 _sym_db.RegisterMessage(CommentSentimentResults)
 _sym_db.RegisterMessage(CommentSentimentResults.SentimentByDayEntry)
 
+SubmoduleBump = _reflection.GeneratedProtocolMessageType('SubmoduleBump', (_message.Message,), dict(
+  DESCRIPTOR = _SUBMODULEBUMP,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:SubmoduleBump)
+  ))
+_sym_db.RegisterMessage(SubmoduleBump)
+
+SubmoduleResults = _reflection.GeneratedProtocolMessageType('SubmoduleResults', (_message.Message,), dict(
+  DESCRIPTOR = _SUBMODULERESULTS,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:SubmoduleResults)
+  ))
+_sym_db.RegisterMessage(SubmoduleResults)
+
+SubmodulesAnalysisResults = _reflection.GeneratedProtocolMessageType('SubmodulesAnalysisResults', (_message.Message,), dict(
+
+  SubmodulesEntry = _reflection.GeneratedProtocolMessageType('SubmodulesEntry', (_message.Message,), dict(
+    DESCRIPTOR = _SUBMODULESANALYSISRESULTS_SUBMODULESENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:SubmodulesAnalysisResults.SubmodulesEntry)
+    ))
+  ,
+  DESCRIPTOR = _SUBMODULESANALYSISRESULTS,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:SubmodulesAnalysisResults)
+  ))
+_sym_db.RegisterMessage(SubmodulesAnalysisResults)
+_sym_db.RegisterMessage(SubmodulesAnalysisResults.SubmodulesEntry)
+
 AnalysisResults = _reflection.GeneratedProtocolMessageType('AnalysisResults', (_message.Message,), dict(
 
   ContentsEntry = _reflection.GeneratedProtocolMessageType('ContentsEntry', (_message.Message,), dict(
@@ -1116,12 +1962,18 @@ _sym_db.RegisterMessage(AnalysisResults)
 _sym_db.RegisterMessage(AnalysisResults.ContentsEntry)
 
 
+_BURNDOWNANALYSISRESULTS_BLOBCLASSESENTRY.has_options = True
+_BURNDOWNANALYSISRESULTS_BLOBCLASSESENTRY._options = _descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001'))
 _SHOTNESSRECORD_COUNTERSENTRY.has_options = True
 _SHOTNESSRECORD_COUNTERSENTRY._options = _descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001'))
 _FILEHISTORYRESULTMESSAGE_FILESENTRY.has_options = True
 _FILEHISTORYRESULTMESSAGE_FILESENTRY._options = _descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001'))
+_CHURNANALYSISRESULTS_FILESENTRY.has_options = True
+_CHURNANALYSISRESULTS_FILESENTRY._options = _descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001'))
 _COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY.has_options = True
 _COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY._options = _descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001'))
+_SUBMODULESANALYSISRESULTS_SUBMODULESENTRY.has_options = True
+_SUBMODULESANALYSISRESULTS_SUBMODULESENTRY._options = _descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001'))
 _ANALYSISRESULTS_CONTENTSENTRY.has_options = True
 _ANALYSISRESULTS_CONTENTSENTRY._options = _descriptor._ParseOptions(descriptor_pb2.MessageOptions(), _b('8\001'))
 # @@protoc_insertion_point(module_scope)
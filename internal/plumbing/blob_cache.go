@@ -1,6 +1,7 @@
 package plumbing
 
 import (
+	"fmt"
 	"log"
 
 	"gopkg.in/src-d/go-git.v4"
@@ -14,25 +15,87 @@ import (
 
 // BlobCache loads the blobs which correspond to the changed files in a commit.
 // It is a PipelineItem.
-// It must provide the old and the new objects; "blobCache" rotates and allows to not load
-// the same blobs twice. Outdated objects are removed so "blobCache" never grows big.
+// It must provide the old and the new objects; the least recently used blobs are kept
+// in memory within MemoryBudget so that the same blobs are not loaded twice. The cache
+// is shared by all the branches and optionally filled in advance, see Prefetch.
+// Besides, it classifies the blobs into text, binary, too large and LFS pointers,
+// see BlobClasses. The LFS pointers are resolved to the real contents if the local LFS
+// object store has them.
 type BlobCache struct {
 	core.NoopMerger
 	// Specifies how to handle the situation when we encounter a git submodule - an object
 	// without the blob. If true, we look inside .gitmodules and if we don't find it,
 	// raise an error. If false, we do not look inside .gitmodules and always succeed.
 	FailOnMissingSubmodules bool
+	// MaxSize is the size limit of the blobs in bytes. The bigger blobs are BlobTooLarge.
+	// 0 means no limit.
+	MaxSize int
+	// Policy defines how the blobs which are not text are analysed: BlobPolicySkip
+	// or BlobPolicyCount.
+	Policy string
+	// LFSObjects is the path to the Git LFS object store. The default is "lfs/objects"
+	// inside the .git directory of the analysed repository.
+	LFSObjects string
+	// LFSBinary makes the Git LFS files BlobBinary, resolved or not.
+	LFSBinary bool
+	// MemoryBudget is the size limit of the cached blobs in megabytes.
+	MemoryBudget int
+	// Prefetch is the number of the upcoming commits whose blobs are loaded in the background.
+	// 0 disables prefetching.
+	Prefetch int
+	// GitBackend is the kind of ObjectBackend which reads the blobs, see
+	// core.ConfigPipelineGitBackend.
+	GitBackend string
 
 	repository *git.Repository
-	cache      map[plumbing.Hash]*object.Blob
+	backend    ObjectBackend
+	// lfsObjects is the actual path to the Git LFS object store, may be empty.
+	lfsObjects string
+	// commits are the analysed commits in the order of core.ConfigPipelineCommits.
+	commits []plumbing.Hash
+	// cache contains the classified blobs. It is shared by the forks.
+	cache *blobLRU
+	// prefetcher fills cache in advance, may be nil. It is shared by the forks.
+	prefetcher *blobPrefetcher
+	// stats are published as FactBlobCacheStats.
+	stats *BlobCacheStats
 }
 
 const (
 	// ConfigBlobCacheFailOnMissingSubmodules is the name of the configuration option for
 	// BlobCache.Configure() to check if the referenced submodules are registered in .gitignore.
 	ConfigBlobCacheFailOnMissingSubmodules = "BlobCache.FailOnMissingSubmodules"
+	// ConfigBlobCacheMaxSize is the name of the configuration option for
+	// BlobCache.Configure() to set the size limit of the analysed blobs.
+	ConfigBlobCacheMaxSize = "BlobCache.MaxSize"
+	// ConfigBlobCachePolicy is the name of the configuration option for
+	// BlobCache.Configure() to choose how the blobs which are not text are analysed.
+	ConfigBlobCachePolicy = "BlobCache.Policy"
+	// ConfigBlobCacheLFSObjects is the name of the configuration option for
+	// BlobCache.Configure() to set the path to the Git LFS object store.
+	ConfigBlobCacheLFSObjects = "BlobCache.LFSObjects"
+	// ConfigBlobCacheLFSBinary is the name of the configuration option for
+	// BlobCache.Configure() to treat the Git LFS files as binary.
+	ConfigBlobCacheLFSBinary = "BlobCache.LFSBinary"
+	// ConfigBlobCacheMemoryBudget is the name of the configuration option for
+	// BlobCache.Configure() to set the size limit of the cached blobs in megabytes.
+	ConfigBlobCacheMemoryBudget = "BlobCache.MemoryBudget"
+	// ConfigBlobCachePrefetch is the name of the configuration option for
+	// BlobCache.Configure() to set the number of the prefetched commits.
+	ConfigBlobCachePrefetch = "BlobCache.Prefetch"
+	// FactBlobCacheStats is the name of the fact which is inserted in BlobCache.Configure().
+	// It is the *BlobCacheStats with the hit and the miss counters.
+	FactBlobCacheStats = "BlobCache.Stats"
+	// FactBlobCacheLoader is the name of the fact which is inserted in BlobCache.Configure().
+	// It is the BlobLoader which reads the blobs through the shared cache.
+	FactBlobCacheLoader = "BlobCache.Loader"
+	// DefaultBlobCacheMemoryBudget is the default value of BlobCache.MemoryBudget.
+	DefaultBlobCacheMemoryBudget = 256
 	// DependencyBlobCache identifies the dependency provided by BlobCache.
 	DependencyBlobCache = "blob_cache"
+	// DependencyBlobClasses identifies the dependency provided by BlobCache
+	// which classifies the blobs, see BlobClasses.
+	DependencyBlobClasses = "blob_classes"
 )
 
 // Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
@@ -44,7 +107,7 @@ func (blobCache *BlobCache) Name() string {
 // Each produced entity will be inserted into `deps` of dependent Consume()-s according
 // to this list. Also used by core.Registry to build the global map of providers.
 func (blobCache *BlobCache) Provides() []string {
-	arr := [...]string{DependencyBlobCache}
+	arr := [...]string{DependencyBlobCache, DependencyBlobClasses}
 	return arr[:]
 }
 
@@ -65,7 +128,42 @@ func (blobCache *BlobCache) ListConfigurationOptions() []core.ConfigurationOptio
 			"Override this if you want to ensure that your repository is integral. ",
 		Flag:    "fail-on-missing-submodules",
 		Type:    core.BoolConfigurationOption,
-		Default: false}}
+		Default: false}, {
+		Name: ConfigBlobCacheMaxSize,
+		Description: "Maximum size of the analysed files in bytes. The bigger files are " +
+			"treated according to --blob-policy. 0 means no limit.",
+		Flag:    "max-blob-size",
+		Type:    core.IntConfigurationOption,
+		Default: 0}, {
+		Name: ConfigBlobCachePolicy,
+		Description: fmt.Sprintf("How to analyse the binary files, the files bigger than "+
+			"--max-blob-size and the Git LFS pointers. \"%s\" ignores them, \"%s\" counts "+
+			"the lines in the latter two but does not diff them.", BlobPolicySkip, BlobPolicyCount),
+		Flag:    "blob-policy",
+		Type:    core.StringConfigurationOption,
+		Default: BlobPolicySkip}, {
+		Name: ConfigBlobCacheLFSObjects,
+		Description: "Path to the Git LFS object store which is used to resolve the LFS pointers. " +
+			"The default is .git/lfs/objects in the analysed repository.",
+		Flag:    "lfs-objects",
+		Type:    core.StringConfigurationOption,
+		Default: ""}, {
+		Name:        ConfigBlobCacheLFSBinary,
+		Description: "Treat the Git LFS files as binary.",
+		Flag:        "lfs-binary",
+		Type:        core.BoolConfigurationOption,
+		Default:     false}, {
+		Name:        ConfigBlobCacheMemoryBudget,
+		Description: "Maximum size of the cached file contents in megabytes.",
+		Flag:        "blob-cache-size",
+		Type:        core.IntConfigurationOption,
+		Default:     DefaultBlobCacheMemoryBudget}, {
+		Name: ConfigBlobCachePrefetch,
+		Description: "Number of the upcoming commits whose files are loaded in the background. " +
+			"0 disables prefetching.",
+		Flag:    "blob-prefetch",
+		Type:    core.IntConfigurationOption,
+		Default: 0}}
 	return options[:]
 }
 
@@ -74,13 +172,87 @@ func (blobCache *BlobCache) Configure(facts map[string]interface{}) {
 	if val, exists := facts[ConfigBlobCacheFailOnMissingSubmodules].(bool); exists {
 		blobCache.FailOnMissingSubmodules = val
 	}
+	if val, exists := facts[ConfigBlobCacheMaxSize].(int); exists {
+		blobCache.MaxSize = val
+	}
+	if val, exists := facts[ConfigBlobCachePolicy].(string); exists {
+		switch val {
+		case BlobPolicySkip, BlobPolicyCount:
+			blobCache.Policy = val
+		default:
+			log.Printf("Unknown blob policy %s, using %s\n", val, BlobPolicySkip)
+			blobCache.Policy = BlobPolicySkip
+		}
+	}
+	if val, exists := facts[ConfigBlobCacheLFSObjects].(string); exists {
+		blobCache.LFSObjects = val
+	}
+	if val, exists := facts[ConfigBlobCacheLFSBinary].(bool); exists {
+		blobCache.LFSBinary = val
+	}
+	if val, exists := facts[ConfigBlobCacheMemoryBudget].(int); exists {
+		blobCache.MemoryBudget = val
+	}
+	if val, exists := facts[ConfigBlobCachePrefetch].(int); exists {
+		blobCache.Prefetch = val
+	}
+	if val, exists := facts[core.ConfigPipelineGitBackend].(string); exists {
+		blobCache.GitBackend = val
+	}
+	if val, exists := facts[core.ConfigPipelineCommits].([]*object.Commit); exists {
+		blobCache.commits = make([]plumbing.Hash, len(val))
+		for i, commit := range val {
+			blobCache.commits[i] = commit.Hash
+		}
+	}
+	if blobCache.stats == nil {
+		blobCache.stats = &BlobCacheStats{}
+	}
+	facts[FactBlobCacheStats] = blobCache.stats
+	facts[FactBlobCacheLoader] = BlobLoader(blobCache.loadBlob)
 }
 
 // Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
 // calls. The repository which is going to be analysed is supplied as an argument.
 func (blobCache *BlobCache) Initialize(repository *git.Repository) {
 	blobCache.repository = repository
-	blobCache.cache = map[plumbing.Hash]*object.Blob{}
+	if blobCache.backend != nil {
+		blobCache.backend.Close()
+	}
+	blobCache.backend = NewObjectBackend(blobCache.GitBackend, repository)
+	if blobCache.Policy == "" {
+		blobCache.Policy = BlobPolicySkip
+	}
+	blobCache.lfsObjects = blobCache.LFSObjects
+	if blobCache.lfsObjects == "" {
+		blobCache.lfsObjects = LFSObjectsPath(repository)
+	}
+	if blobCache.MemoryBudget <= 0 {
+		blobCache.MemoryBudget = DefaultBlobCacheMemoryBudget
+	}
+	if blobCache.stats == nil {
+		blobCache.stats = &BlobCacheStats{}
+	}
+	*blobCache.stats = BlobCacheStats{}
+	blobCache.cache = newBlobLRU(int64(blobCache.MemoryBudget)<<20, blobCache.stats)
+	if blobCache.prefetcher != nil {
+		blobCache.prefetcher.Stop()
+	}
+	blobCache.prefetcher = newBlobPrefetcher(
+		repository, blobCache.commits, blobCache.Prefetch, blobCache.classifyBlob, blobCache.cache)
+}
+
+// Dispose stops prefetching the blobs and closes the object backend. It is called when
+// Pipeline.Run() ends.
+func (blobCache *BlobCache) Dispose() {
+	if blobCache.prefetcher != nil {
+		blobCache.prefetcher.Stop()
+		blobCache.prefetcher = nil
+	}
+	if blobCache.backend != nil {
+		blobCache.backend.Close()
+		blobCache.backend = nil
+	}
 }
 
 // Consume runs this PipelineItem on the next commit data.
@@ -92,83 +264,149 @@ func (blobCache *BlobCache) Initialize(repository *git.Repository) {
 func (blobCache *BlobCache) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
 	commit := deps[core.DependencyCommit].(*object.Commit)
 	changes := deps[DependencyTreeChanges].(object.Changes)
+	if blobCache.prefetcher != nil {
+		blobCache.prefetcher.Schedule(commit.Hash)
+	}
 	cache := map[plumbing.Hash]*object.Blob{}
-	newCache := map[plumbing.Hash]*object.Blob{}
+	classes := BlobClasses{
+		Classes:  map[plumbing.Hash]BlobClass{},
+		LFSSizes: map[plumbing.Hash]int64{},
+		Policy:   blobCache.Policy,
+	}
+	// load puts the blob which corresponds to the entry into cache and classes
+	load := func(entry *object.ChangeEntry, allowMissing bool) error {
+		hash := entry.TreeEntry.Hash
+		if _, exists := cache[hash]; exists {
+			return nil
+		}
+		blob, class, exists := blobCache.cache.Get(hash)
+		if !exists {
+			var err error
+			blob, err = blobCache.getBlob(entry, commit.File)
+			if err != nil {
+				if !allowMissing || err.Error() != plumbing.ErrObjectNotFound.Error() {
+					return err
+				}
+				blob, err = internal.CreateDummyBlob(hash)
+				if err != nil {
+					return err
+				}
+			}
+			var memory int64
+			blob, class, memory, err = blobCache.classifyBlob(blob)
+			if err != nil {
+				return err
+			}
+			blobCache.cache.Put(blob, class, memory)
+		}
+		cache[hash] = blob
+		if class != BlobText {
+			classes.Classes[hash] = class
+		}
+		if size, exists := blobCache.lfsSize(blob, class); exists {
+			classes.LFSSizes[hash] = size
+		}
+		return nil
+	}
 	for _, change := range changes {
 		action, err := change.Action()
 		if err != nil {
 			log.Printf("no action in %s\n", change.To.TreeEntry.Hash)
 			return nil, err
 		}
-		var exists bool
-		var blob *object.Blob
 		switch action {
 		case merkletrie.Insert:
-			blob, err = blobCache.getBlob(&change.To, commit.File)
-			if err != nil {
-				log.Printf("file to %s %s\n",
-					change.To.Name, change.To.TreeEntry.Hash)
-			} else {
-				cache[change.To.TreeEntry.Hash] = blob
-				newCache[change.To.TreeEntry.Hash] = blob
+			if err = load(&change.To, false); err != nil {
+				log.Printf("file to %s %s\n", change.To.Name, change.To.TreeEntry.Hash)
 			}
 		case merkletrie.Delete:
-			cache[change.From.TreeEntry.Hash], exists =
-				blobCache.cache[change.From.TreeEntry.Hash]
-			if !exists {
-				cache[change.From.TreeEntry.Hash], err =
-					blobCache.getBlob(&change.From, commit.File)
-				if err != nil {
-					if err.Error() != plumbing.ErrObjectNotFound.Error() {
-						log.Printf("file from %s %s\n", change.From.Name,
-							change.From.TreeEntry.Hash)
-					} else {
-						cache[change.From.TreeEntry.Hash], err =
-							internal.CreateDummyBlob(change.From.TreeEntry.Hash)
-					}
-				}
+			if err = load(&change.From, true); err != nil {
+				log.Printf("file from %s %s\n", change.From.Name, change.From.TreeEntry.Hash)
 			}
 		case merkletrie.Modify:
-			blob, err = blobCache.getBlob(&change.To, commit.File)
-			if err != nil {
+			if err = load(&change.To, false); err != nil {
 				log.Printf("file to %s\n", change.To.Name)
-			} else {
-				cache[change.To.TreeEntry.Hash] = blob
-				newCache[change.To.TreeEntry.Hash] = blob
-			}
-			cache[change.From.TreeEntry.Hash], exists =
-				blobCache.cache[change.From.TreeEntry.Hash]
-			if !exists {
-				cache[change.From.TreeEntry.Hash], err =
-					blobCache.getBlob(&change.From, commit.File)
-				if err != nil {
-					log.Printf("file from %s\n", change.From.Name)
-				}
+			} else if err = load(&change.From, false); err != nil {
+				log.Printf("file from %s\n", change.From.Name)
 			}
 		}
 		if err != nil {
 			return nil, err
 		}
 	}
-	blobCache.cache = newCache
-	return map[string]interface{}{DependencyBlobCache: cache}, nil
+	return map[string]interface{}{
+		DependencyBlobCache: cache, DependencyBlobClasses: classes}, nil
 }
 
-// Fork clones this PipelineItem.
+// Fork clones this PipelineItem. The clones share the cache.
 func (blobCache *BlobCache) Fork(n int) []core.PipelineItem {
-	caches := make([]core.PipelineItem, n)
-	for i := 0; i < n; i++ {
-		cache := map[plumbing.Hash]*object.Blob{}
-		for k, v := range blobCache.cache {
-			cache[k] = v
-		}
-		caches[i] = &BlobCache{
-			FailOnMissingSubmodules: blobCache.FailOnMissingSubmodules,
-			repository: blobCache.repository,
-			cache: cache,
+	return core.ForkCopyPipelineItem(blobCache, n)
+}
+
+// classifyBlob determines the class of the blob. The Git LFS pointers are replaced with
+// the real contents, see ResolveLFSPointer(). It also returns the size of the encoded object
+// which the blob holds in memory: the real contents of the LFS files are read from disk
+// on demand, so they are charged with the size of the pointer.
+func (blobCache *BlobCache) classifyBlob(blob *object.Blob) (*object.Blob, BlobClass, int64, error) {
+	memory := blob.Size
+	class, err := ClassifyBlob(blob, blobCache.MaxSize)
+	if err != nil || class != BlobLFSPointer {
+		return blob, class, memory, err
+	}
+	pointer, err := ParseLFSPointer(blob)
+	if err != nil {
+		// it looks like a pointer but it is broken, so it has no real contents
+		if blobCache.LFSBinary {
+			class = BlobBinary
 		}
+		return blob, class, memory, nil
+	}
+	blob, resolved, err := ResolveLFSPointer(blob, pointer, blobCache.lfsObjects)
+	if err != nil {
+		return nil, class, memory, err
+	}
+	if blobCache.LFSBinary {
+		return blob, BlobBinary, memory, nil
+	}
+	if resolved {
+		class, err = ClassifyBlob(blob, blobCache.MaxSize)
+	}
+	return blob, class, memory, err
+}
+
+// lfsSize returns the size of the real contents if the classified blob is a Git LFS pointer
+// which was not resolved, see BlobClasses.LFSSizes.
+func (blobCache *BlobCache) lfsSize(blob *object.Blob, class BlobClass) (int64, bool) {
+	if blob.Size > lfsPointerMaxSize ||
+		(class != BlobLFSPointer && (class != BlobBinary || !blobCache.LFSBinary)) {
+		return 0, false
+	}
+	pointer, err := ParseLFSPointer(blob)
+	if err != nil {
+		return 0, false
+	}
+	return pointer.Size, true
+}
+
+// BlobLoader returns the classified blob with the specified hash, see FactBlobCacheLoader.
+type BlobLoader func(hash plumbing.Hash) (*object.Blob, BlobClass, error)
+
+// loadBlob returns the blob from the cache or reads it with the backend and caches.
+// It is published as FactBlobCacheLoader for the items which need the blobs that did not change.
+func (blobCache *BlobCache) loadBlob(hash plumbing.Hash) (*object.Blob, BlobClass, error) {
+	if blob, class, exists := blobCache.cache.Get(hash); exists {
+		return blob, class, nil
+	}
+	blob, err := blobCache.backend.Blob(hash)
+	if err != nil {
+		return nil, BlobText, err
+	}
+	blob, class, memory, err := blobCache.classifyBlob(blob)
+	if err != nil {
+		return nil, class, err
 	}
-	return caches
+	blobCache.cache.Put(blob, class, memory)
+	return blob, class, nil
 }
 
 // FileGetter defines a function which loads the Git file by
@@ -179,7 +417,7 @@ type FileGetter func(path string) (*object.File, error)
 // Returns the blob which corresponds to the specified ChangeEntry.
 func (blobCache *BlobCache) getBlob(entry *object.ChangeEntry, fileGetter FileGetter) (
 	*object.Blob, error) {
-	blob, err := blobCache.repository.BlobObject(entry.TreeEntry.Hash)
+	blob, err := blobCache.backend.Blob(entry.TreeEntry.Hash)
 	if err != nil {
 		if err.Error() != plumbing.ErrObjectNotFound.Error() {
 			log.Printf("getBlob(%s)\n", entry.TreeEntry.Hash.String())
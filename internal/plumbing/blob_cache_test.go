@@ -1,11 +1,19 @@
 package plumbing
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
 	"gopkg.in/src-d/hercules.v4/internal"
 	"gopkg.in/src-d/hercules.v4/internal/core"
 	"gopkg.in/src-d/hercules.v4/internal/test"
@@ -21,26 +29,89 @@ func TestBlobCacheConfigureInitialize(t *testing.T) {
 	cache := fixtureBlobCache()
 	assert.Equal(t, test.Repository, cache.repository)
 	assert.False(t, cache.FailOnMissingSubmodules)
+	assert.Equal(t, BlobPolicySkip, cache.Policy)
 	facts := map[string]interface{}{}
 	facts[ConfigBlobCacheFailOnMissingSubmodules] = true
+	facts[ConfigBlobCacheMaxSize] = 1000
+	facts[ConfigBlobCachePolicy] = BlobPolicyCount
+	facts[ConfigBlobCacheLFSObjects] = "/tmp/lfs"
+	facts[ConfigBlobCacheLFSBinary] = true
+	facts[ConfigBlobCacheMemoryBudget] = 10
+	facts[ConfigBlobCachePrefetch] = 8
+	facts[core.ConfigPipelineGitBackend] = core.GitBackendExec
+	commit := &object.Commit{Hash: plumbing.NewHash("1111111111111111111111111111111111111111")}
+	facts[core.ConfigPipelineCommits] = []*object.Commit{commit}
 	cache.Configure(facts)
 	assert.True(t, cache.FailOnMissingSubmodules)
+	assert.Equal(t, 1000, cache.MaxSize)
+	assert.Equal(t, BlobPolicyCount, cache.Policy)
+	assert.Equal(t, "/tmp/lfs", cache.LFSObjects)
+	assert.True(t, cache.LFSBinary)
+	assert.Equal(t, 10, cache.MemoryBudget)
+	assert.Equal(t, 8, cache.Prefetch)
+	assert.Equal(t, core.GitBackendExec, cache.GitBackend)
+	assert.Equal(t, []plumbing.Hash{commit.Hash}, cache.commits)
+	stats := facts[FactBlobCacheStats].(*BlobCacheStats)
+	assert.True(t, stats == cache.stats)
+	stats.Hits = 10
+	cache.Initialize(test.Repository)
+	assert.Equal(t, "/tmp/lfs", cache.lfsObjects)
+	assert.Equal(t, int64(10<<20), cache.cache.budget)
+	// the counters are reset but the fact stays valid
+	assert.Equal(t, int64(0), stats.Hits)
+	assert.True(t, stats == cache.stats)
+	// a single commit has nothing to prefetch
+	assert.Nil(t, cache.prefetcher)
+	cache.MemoryBudget = 0
+	cache.Initialize(test.Repository)
+	assert.Equal(t, DefaultBlobCacheMemoryBudget, cache.MemoryBudget)
 	facts = map[string]interface{}{}
 	cache.Configure(facts)
 	assert.True(t, cache.FailOnMissingSubmodules)
+	assert.Equal(t, BlobPolicyCount, cache.Policy)
+	cache.Configure(map[string]interface{}{ConfigBlobCachePolicy: "whatever"})
+	assert.Equal(t, BlobPolicySkip, cache.Policy)
+}
+
+func TestBlobCacheDispose(t *testing.T) {
+	root, repository, commits := fixtureGitHistory(t)
+	defer os.RemoveAll(root)
+	cache := &BlobCache{}
+	cache.Configure(map[string]interface{}{core.ConfigPipelineGitBackend: core.GitBackendExec})
+	cache.Initialize(repository)
+	backend := cache.backend.(*execObjectBackend)
+	changes, err := backend.ListTree(mustTree(commits[0]))
+	assert.Nil(t, err)
+	_, _, err = cache.loadBlob(changes[0].To.TreeEntry.Hash)
+	assert.Nil(t, err)
+	assert.NotNil(t, backend.batch)
+	cache.Dispose()
+	assert.Nil(t, backend.batch)
+	assert.Nil(t, cache.backend)
+	// Initialize() does not close the disposed backend again
+	cache.Initialize(repository)
+	assert.NotNil(t, cache.backend)
+	cache.Dispose()
 }
 
 func TestBlobCacheMetadata(t *testing.T) {
 	cache := fixtureBlobCache()
 	assert.Equal(t, cache.Name(), "BlobCache")
-	assert.Equal(t, len(cache.Provides()), 1)
+	assert.Equal(t, len(cache.Provides()), 2)
 	assert.Equal(t, cache.Provides()[0], DependencyBlobCache)
+	assert.Equal(t, cache.Provides()[1], DependencyBlobClasses)
 	assert.Equal(t, len(cache.Requires()), 1)
 	changes := &TreeDiff{}
 	assert.Equal(t, cache.Requires()[0], changes.Provides()[0])
 	opts := cache.ListConfigurationOptions()
-	assert.Len(t, opts, 1)
+	assert.Len(t, opts, 7)
 	assert.Equal(t, opts[0].Name, ConfigBlobCacheFailOnMissingSubmodules)
+	assert.Equal(t, opts[1].Name, ConfigBlobCacheMaxSize)
+	assert.Equal(t, opts[2].Name, ConfigBlobCachePolicy)
+	assert.Equal(t, opts[3].Name, ConfigBlobCacheLFSObjects)
+	assert.Equal(t, opts[4].Name, ConfigBlobCacheLFSBinary)
+	assert.Equal(t, opts[5].Name, ConfigBlobCacheMemoryBudget)
+	assert.Equal(t, opts[6].Name, ConfigBlobCachePrefetch)
 }
 
 func TestBlobCacheRegistration(t *testing.T) {
@@ -82,7 +153,7 @@ func TestBlobCacheConsumeModification(t *testing.T) {
 	deps[DependencyTreeChanges] = changes
 	result, err := fixtureBlobCache().Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(result), 1)
+	assert.Equal(t, len(result), 2)
 	cacheIface, exists := result[DependencyBlobCache]
 	assert.True(t, exists)
 	cache := cacheIface.(map[plumbing.Hash]*object.Blob)
@@ -128,7 +199,7 @@ func TestBlobCacheConsumeInsertionDeletion(t *testing.T) {
 	deps[DependencyTreeChanges] = changes
 	result, err := fixtureBlobCache().Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(result), 1)
+	assert.Equal(t, len(result), 2)
 	cacheIface, exists := result[DependencyBlobCache]
 	assert.True(t, exists)
 	cache := cacheIface.(map[plumbing.Hash]*object.Blob)
@@ -298,7 +369,7 @@ func TestBlobCacheDeleteInvalidBlob(t *testing.T) {
 	deps[DependencyTreeChanges] = changes
 	result, err := fixtureBlobCache().Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(result), 1)
+	assert.Equal(t, len(result), 2)
 	cacheIface, exists := result[DependencyBlobCache]
 	assert.True(t, exists)
 	cache := cacheIface.(map[plumbing.Hash]*object.Blob)
@@ -424,16 +495,107 @@ func TestBlobCacheFork(t *testing.T) {
 	deps[DependencyTreeChanges] = changes
 	cache1 := fixtureBlobCache()
 	cache1.FailOnMissingSubmodules = true
+	cache1.MaxSize = 1000
+	cache1.LFSBinary = true
+	cache1.lfsObjects = "/tmp/lfs"
 	cache1.Consume(deps)
 	clones := cache1.Fork(1)
 	assert.Len(t, clones, 1)
 	cache2 := clones[0].(*BlobCache)
 	assert.True(t, cache2.FailOnMissingSubmodules)
+	assert.Equal(t, 1000, cache2.MaxSize)
+	assert.Equal(t, BlobPolicySkip, cache2.Policy)
+	assert.True(t, cache2.LFSBinary)
+	assert.Equal(t, "/tmp/lfs", cache2.lfsObjects)
 	assert.Equal(t, cache1.repository, cache2.repository)
-	cache1.cache[plumbing.ZeroHash] = nil
-	assert.Len(t, cache1.cache, 2)
-	assert.Len(t, cache2.cache, 1)
-	assert.Equal(t, cache1.cache[hash].Size, cache2.cache[hash].Size)
+	// the clones share the cache
+	assert.True(t, cache1.cache == cache2.cache)
+	assert.Equal(t, 1, cache2.cache.Len())
+	blob, _, exists := cache2.cache.Get(hash)
+	assert.True(t, exists)
+	assert.Equal(t, int64(5576), blob.Size)
 	// just for the sake of it
 	cache1.Merge([]core.PipelineItem{cache2})
 }
+
+func TestBlobCacheClassifyLFS(t *testing.T) {
+	objects, err := ioutil.TempDir("", "hercules-lfs-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(objects)
+	contents := "real\ncontents\n"
+	oid := fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+	pointer := test.FakeBlob(lfsPointerPrefix + "oid sha256:" + oid + "\n" +
+		fmt.Sprintf("size %d\n", len(contents)))
+	cache := &BlobCache{LFSObjects: objects}
+	cache.Initialize(nil)
+	// the object is not downloaded
+	blob, class, memory, err := cache.classifyBlob(pointer)
+	assert.Nil(t, err)
+	assert.Equal(t, BlobLFSPointer, class)
+	assert.True(t, blob == pointer)
+	assert.Equal(t, pointer.Size, memory)
+	size, exists := cache.lfsSize(blob, class)
+	assert.True(t, exists)
+	assert.Equal(t, int64(len(contents)), size)
+	assert.Nil(t, os.MkdirAll(filepath.Join(objects, oid[:2], oid[2:4]), 0755))
+	assert.Nil(t, ioutil.WriteFile(
+		filepath.Join(objects, oid[:2], oid[2:4], oid), []byte(contents), 0644))
+	blob, class, memory, err = cache.classifyBlob(pointer)
+	assert.Nil(t, err)
+	assert.Equal(t, BlobText, class)
+	assert.Equal(t, pointer.Hash, blob.Hash)
+	assert.Equal(t, int64(len(contents)), blob.Size)
+	// read from disk
+	assert.Equal(t, pointer.Size, memory)
+	_, exists = cache.lfsSize(blob, class)
+	assert.False(t, exists)
+	text, err := BlobToString(blob)
+	assert.Nil(t, err)
+	assert.Equal(t, contents, text)
+	cache.MaxSize = 5
+	_, class, _, err = cache.classifyBlob(pointer)
+	assert.Nil(t, err)
+	assert.Equal(t, BlobTooLarge, class)
+	cache.MaxSize = 0
+	cache.LFSBinary = true
+	_, class, _, err = cache.classifyBlob(pointer)
+	assert.Nil(t, err)
+	assert.Equal(t, BlobBinary, class)
+	text = "package main\n"
+	blob, class, memory, err = cache.classifyBlob(test.FakeBlob(text))
+	assert.Nil(t, err)
+	assert.Equal(t, BlobText, class)
+	assert.Equal(t, int64(len(text)), blob.Size)
+	assert.Equal(t, blob.Size, memory)
+}
+
+func TestBlobCacheConsumeLFSSizes(t *testing.T) {
+	pointer := lfsPointerPrefix + "oid sha256:" + testLFSOID + "\nsize 200000000\n"
+	repository, err := git.Init(memory.NewStorage(), memfs.New())
+	assert.Nil(t, err)
+	commits := fixtureHistory(repository, []map[string]string{{"a.bin": pointer, "b.txt": "b\n"}})
+	cache := &BlobCache{MemoryBudget: 1}
+	cache.Configure(map[string]interface{}{core.ConfigPipelineCommits: commits})
+	cache.Initialize(repository)
+	treeDiff := TreeDiff{}
+	treeDiff.Initialize(repository)
+	deps := map[string]interface{}{core.DependencyCommit: commits[0]}
+	changes, err := treeDiff.Consume(deps)
+	assert.Nil(t, err)
+	deps[DependencyTreeChanges] = changes[DependencyTreeChanges]
+	result, err := cache.Consume(deps)
+	assert.Nil(t, err)
+	blobs := result[DependencyBlobCache].(map[plumbing.Hash]*object.Blob)
+	classes := result[DependencyBlobClasses].(BlobClasses)
+	assert.Len(t, classes.LFSSizes, 1)
+	for hash, size := range classes.LFSSizes {
+		assert.Equal(t, int64(200000000), size)
+		assert.Equal(t, BlobLFSPointer, classes.Class(hash))
+		// the blob is the pointer
+		assert.Equal(t, int64(len(pointer)), blobs[hash].Size)
+		assert.True(t, cache.cache.Contains(hash))
+	}
+	// the pointer does not evict the other blobs from the 1 MiB cache
+	assert.Equal(t, 2, cache.cache.Len())
+	assert.Equal(t, int64(0), cache.stats.Evicted)
+}
@@ -0,0 +1,172 @@
+package plumbing
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// BlobClass tells whether a blob can be analysed line by line.
+type BlobClass int
+
+const (
+	// BlobText is the class of the regular text files.
+	BlobText BlobClass = iota
+	// BlobBinary is the class of the blobs which contain zero bytes or invalid UTF-8.
+	// They are never analysed line by line.
+	BlobBinary
+	// BlobTooLarge is the class of the blobs which are bigger than BlobCache.MaxSize.
+	BlobTooLarge
+	// BlobLFSPointer is the class of the Git LFS pointers: the real contents are stored
+	// outside of the repository.
+	BlobLFSPointer
+)
+
+const (
+	// BlobPolicySkip is the value of ConfigBlobCachePolicy which excludes the binary,
+	// too large and LFS pointer blobs from the line analyses, the same way as the binary files.
+	BlobPolicySkip = "skip"
+	// BlobPolicyCount is the value of ConfigBlobCachePolicy which counts the lines
+	// in the too large and LFS pointer blobs but never diffs them: each modification
+	// replaces all the lines. The binary blobs are skipped.
+	BlobPolicyCount = "count"
+
+	// blobSniffSize is the number of the leading bytes which ClassifyBlob() reads,
+	// the same as Git reads to detect the binary files.
+	blobSniffSize = 8000
+	// lfsPointerPrefix is the first line of every Git LFS pointer.
+	lfsPointerPrefix = "version https://git-lfs.github.com/spec/v1\n"
+	// lfsPointerMaxSize is the size limit of a Git LFS pointer.
+	lfsPointerMaxSize = 1024
+)
+
+// String returns the name of the class which is used in the analysis results.
+func (class BlobClass) String() string {
+	switch class {
+	case BlobText:
+		return "text"
+	case BlobBinary:
+		return "binary"
+	case BlobTooLarge:
+		return "too_large"
+	case BlobLFSPointer:
+		return "lfs_pointer"
+	}
+	return ""
+}
+
+// ClassifyBlob reads the beginning of the blob and determines its class. `maxSize` is
+// the size limit in bytes, 0 means no limit.
+func ClassifyBlob(blob *object.Blob, maxSize int) (BlobClass, error) {
+	if maxSize > 0 && blob.Size > int64(maxSize) {
+		return BlobTooLarge, nil
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return BlobText, err
+	}
+	defer checkClose(reader)
+	buffer := make([]byte, blobSniffSize)
+	n, err := io.ReadFull(reader, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return BlobText, err
+	}
+	head := buffer[:n]
+	if blob.Size <= lfsPointerMaxSize && bytes.HasPrefix(head, []byte(lfsPointerPrefix)) {
+		return BlobLFSPointer, nil
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return BlobBinary, nil
+	}
+	valid := utf8.Valid(head)
+	if n == blobSniffSize {
+		// the last rune may be cut in the middle
+		for cut := 1; cut < utf8.UTFMax && !valid; cut++ {
+			valid = utf8.Valid(head[:n-cut])
+		}
+	}
+	if !valid {
+		return BlobBinary, nil
+	}
+	return BlobText, nil
+}
+
+// BlobClasses is the type of the dependency provided by BlobCache. It tells how to analyse
+// the blobs in DependencyBlobCache.
+type BlobClasses struct {
+	// Classes maps the hashes of the blobs which are not text to their classes.
+	Classes map[plumbing.Hash]BlobClass
+	// LFSSizes maps the hashes of the Git LFS pointers which were not resolved to the sizes
+	// of the real contents. The blobs themselves are the pointers.
+	LFSSizes map[plumbing.Hash]int64
+	// Policy is the copy of BlobCache.Policy.
+	Policy string
+}
+
+// Class returns the class of the blob with the specified hash.
+func (classes BlobClasses) Class(hash plumbing.Hash) BlobClass {
+	return classes.Classes[hash]
+}
+
+// Skipped indicates whether the blob with the specified hash must be treated as binary.
+func (classes BlobClasses) Skipped(hash plumbing.Hash) bool {
+	class := classes.Class(hash)
+	return class == BlobBinary || (class != BlobText && classes.Policy != BlobPolicyCount)
+}
+
+// CountLines returns the number of lines in the blob the same way as CountLines() does
+// except that the skipped blobs fail with the same error as the binary blobs.
+func (classes BlobClasses) CountLines(blob *object.Blob) (int, error) {
+	if blob != nil && classes.Skipped(blob.Hash) {
+		return -1, errors.New("binary")
+	}
+	return CountLines(blob)
+}
+
+// replaceBlobs returns the diff which replaces all the lines in `blobFrom` with all the lines
+// in `blobTo`. The skipped and the binary blobs have no lines. The blobs which are not text
+// are compared this way instead of diffing.
+func (classes BlobClasses) replaceBlobs(blobFrom, blobTo *object.Blob) (FileDiffData, error) {
+	countLines := func(blob *object.Blob) (int, error) {
+		lines, err := classes.CountLines(blob)
+		if err != nil && err.Error() == "binary" {
+			return 0, nil
+		}
+		return lines, err
+	}
+	oldLines, err := countLines(blobFrom)
+	if err != nil {
+		return FileDiffData{}, err
+	}
+	newLines, err := countLines(blobTo)
+	if err != nil {
+		return FileDiffData{}, err
+	}
+	// the runes stand for the lines, their values do not matter
+	lines := func(n int) string {
+		return strings.Repeat("\x01", n)
+	}
+	result := FileDiffData{OldLinesOfCode: oldLines, NewLinesOfCode: newLines}
+	if blobFrom.Hash == blobTo.Hash {
+		if oldLines > 0 {
+			result.Diffs = append(result.Diffs, diffmatchpatch.Diff{
+				Type: diffmatchpatch.DiffEqual, Text: lines(oldLines)})
+		}
+		return result, nil
+	}
+	if oldLines > 0 {
+		result.Diffs = append(result.Diffs, diffmatchpatch.Diff{
+			Type: diffmatchpatch.DiffDelete, Text: lines(oldLines)})
+	}
+	if newLines > 0 {
+		result.Diffs = append(result.Diffs, diffmatchpatch.Diff{
+			Type: diffmatchpatch.DiffInsert, Text: lines(newLines)})
+	}
+	return result, nil
+}
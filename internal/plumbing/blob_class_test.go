@@ -0,0 +1,96 @@
+package plumbing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v4/internal"
+	"gopkg.in/src-d/hercules.v4/internal/test"
+)
+
+func TestClassifyBlob(t *testing.T) {
+	classify := func(contents string, maxSize int) BlobClass {
+		class, err := ClassifyBlob(test.FakeBlob(contents), maxSize)
+		assert.Nil(t, err)
+		return class
+	}
+	assert.Equal(t, BlobText, classify("", 0))
+	assert.Equal(t, BlobText, classify("package main\n", 0))
+	assert.Equal(t, BlobText, classify("package main\n", 13))
+	assert.Equal(t, BlobTooLarge, classify("package main\n", 12))
+	assert.Equal(t, BlobBinary, classify("PNG\x00\x01", 0))
+	assert.Equal(t, BlobBinary, classify("\xff\xfe", 0))
+	// the zero byte is beyond the sniffed prefix
+	assert.Equal(t, BlobText, classify(strings.Repeat("a", blobSniffSize)+"\x00", 0))
+	// the last rune is cut by the sniffed prefix
+	assert.Equal(t, BlobText, classify(strings.Repeat("a", blobSniffSize-1)+"я", 0))
+	pointer := "version https://git-lfs.github.com/spec/v1\n" +
+		"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n" +
+		"size 12345\n"
+	assert.Equal(t, BlobLFSPointer, classify(pointer, 0))
+	assert.Equal(t, BlobText, classify(pointer+strings.Repeat("\n", lfsPointerMaxSize), 0))
+	blob, _ := internal.CreateDummyBlob(plumbing.ZeroHash, true)
+	_, err := ClassifyBlob(blob, 0)
+	assert.NotNil(t, err)
+}
+
+func TestBlobClassString(t *testing.T) {
+	assert.Equal(t, "text", BlobText.String())
+	assert.Equal(t, "binary", BlobBinary.String())
+	assert.Equal(t, "too_large", BlobTooLarge.String())
+	assert.Equal(t, "lfs_pointer", BlobLFSPointer.String())
+}
+
+func TestBlobClassesSkipped(t *testing.T) {
+	text, binary := test.FakeBlob("a\nb\n"), test.FakeBlob("\x00")
+	large, pointer := test.FakeBlob("c\nd\ne\n"), test.FakeBlob("f\n")
+	classes := BlobClasses{Classes: map[plumbing.Hash]BlobClass{
+		binary.Hash: BlobBinary, large.Hash: BlobTooLarge, pointer.Hash: BlobLFSPointer,
+	}, Policy: BlobPolicySkip}
+	assert.False(t, classes.Skipped(text.Hash))
+	for _, blob := range []*object.Blob{binary, large, pointer} {
+		assert.True(t, classes.Skipped(blob.Hash))
+		_, err := classes.CountLines(blob)
+		assert.Equal(t, "binary", err.Error())
+	}
+	lines, err := classes.CountLines(text)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, lines)
+	classes.Policy = BlobPolicyCount
+	assert.True(t, classes.Skipped(binary.Hash))
+	assert.False(t, classes.Skipped(large.Hash))
+	assert.False(t, classes.Skipped(pointer.Hash))
+	lines, err = classes.CountLines(large)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, lines)
+	// the zero value treats everything as text
+	assert.False(t, BlobClasses{}.Skipped(binary.Hash))
+}
+
+func TestBlobClassesReplaceBlobs(t *testing.T) {
+	text, large := test.FakeBlob("a\nb\n"), test.FakeBlob("c\nd\ne\n")
+	classes := BlobClasses{
+		Classes: map[plumbing.Hash]BlobClass{large.Hash: BlobTooLarge}, Policy: BlobPolicySkip}
+	diff, err := classes.replaceBlobs(text, large)
+	assert.Nil(t, err)
+	assert.Equal(t, FileDiffData{OldLinesOfCode: 2, NewLinesOfCode: 0, Diffs: []diffmatchpatch.Diff{
+		{Type: diffmatchpatch.DiffDelete, Text: "\x01\x01"},
+	}}, diff)
+	classes.Policy = BlobPolicyCount
+	diff, err = classes.replaceBlobs(text, large)
+	assert.Nil(t, err)
+	assert.Equal(t, FileDiffData{OldLinesOfCode: 2, NewLinesOfCode: 3, Diffs: []diffmatchpatch.Diff{
+		{Type: diffmatchpatch.DiffDelete, Text: "\x01\x01"},
+		{Type: diffmatchpatch.DiffInsert, Text: "\x01\x01\x01"},
+	}}, diff)
+	// a rename keeps the lines
+	diff, err = classes.replaceBlobs(large, large)
+	assert.Nil(t, err)
+	assert.Equal(t, FileDiffData{OldLinesOfCode: 3, NewLinesOfCode: 3, Diffs: []diffmatchpatch.Diff{
+		{Type: diffmatchpatch.DiffEqual, Text: "\x01\x01\x01"},
+	}}, diff)
+}
@@ -0,0 +1,297 @@
+package plumbing
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+)
+
+// BlobCacheStats are the counters of BlobCache. The fields are updated atomically.
+type BlobCacheStats struct {
+	// Hits is the number of the blobs which were found in the cache.
+	Hits int64
+	// Misses is the number of the blobs which were loaded from the repository.
+	Misses int64
+	// Prefetched is the number of the blobs which were loaded in the background.
+	Prefetched int64
+	// Evicted is the number of the blobs which were removed to fit the memory budget.
+	Evicted int64
+}
+
+// String formats the counters for logging.
+func (stats *BlobCacheStats) String() string {
+	hits, misses := atomic.LoadInt64(&stats.Hits), atomic.LoadInt64(&stats.Misses)
+	ratio := 0.0
+	if hits+misses > 0 {
+		ratio = float64(hits) / float64(hits+misses)
+	}
+	return fmt.Sprintf("%d hits, %d misses (%.1f%% hit rate), %d prefetched, %d evicted",
+		hits, misses, ratio*100, atomic.LoadInt64(&stats.Prefetched),
+		atomic.LoadInt64(&stats.Evicted))
+}
+
+// blobLRU is the least recently used cache of the classified blobs which is bounded by
+// the memory which the blobs hold. It is shared by the forks of BlobCache and the prefetcher.
+type blobLRU struct {
+	lock   sync.Mutex
+	budget int64
+	size   int64
+	// order has the most recently used entries in the front.
+	order   *list.List
+	entries map[plumbing.Hash]*list.Element
+	stats   *BlobCacheStats
+}
+
+type blobLRUEntry struct {
+	blob  *object.Blob
+	class BlobClass
+	// memory is the size of the encoded object which the blob holds, see BlobCache.classifyBlob().
+	memory int64
+}
+
+func newBlobLRU(budget int64, stats *BlobCacheStats) *blobLRU {
+	return &blobLRU{
+		budget:  budget,
+		order:   list.New(),
+		entries: map[plumbing.Hash]*list.Element{},
+		stats:   stats,
+	}
+}
+
+// Get returns the cached blob and its class and updates the hit or the miss counter.
+func (lru *blobLRU) Get(hash plumbing.Hash) (*object.Blob, BlobClass, bool) {
+	lru.lock.Lock()
+	defer lru.lock.Unlock()
+	element, exists := lru.entries[hash]
+	if !exists {
+		atomic.AddInt64(&lru.stats.Misses, 1)
+		return nil, BlobText, false
+	}
+	atomic.AddInt64(&lru.stats.Hits, 1)
+	lru.order.MoveToFront(element)
+	entry := element.Value.(*blobLRUEntry)
+	return entry.blob, entry.class, true
+}
+
+// Contains checks whether the blob is cached without touching it or the counters.
+func (lru *blobLRU) Contains(hash plumbing.Hash) bool {
+	lru.lock.Lock()
+	defer lru.lock.Unlock()
+	_, exists := lru.entries[hash]
+	return exists
+}
+
+// Put inserts the blob and evicts the least recently used blobs which do not fit the budget.
+// `memory` is the size of the encoded object which the blob holds. The blobs which need
+// more memory than the whole budget are not cached.
+func (lru *blobLRU) Put(blob *object.Blob, class BlobClass, memory int64) {
+	lru.lock.Lock()
+	defer lru.lock.Unlock()
+	if _, exists := lru.entries[blob.Hash]; exists || memory > lru.budget {
+		return
+	}
+	lru.entries[blob.Hash] = lru.order.PushFront(
+		&blobLRUEntry{blob: blob, class: class, memory: memory})
+	lru.size += memory
+	for lru.size > lru.budget {
+		last := lru.order.Back()
+		entry := lru.order.Remove(last).(*blobLRUEntry)
+		delete(lru.entries, entry.blob.Hash)
+		lru.size -= entry.memory
+		atomic.AddInt64(&lru.stats.Evicted, 1)
+	}
+}
+
+// Len returns the number of the cached blobs.
+func (lru *blobLRU) Len() int {
+	lru.lock.Lock()
+	defer lru.lock.Unlock()
+	return len(lru.entries)
+}
+
+// blobPrefetcher loads the blobs changed in the upcoming commits into blobLRU on a background
+// goroutine, in batches of `window` commits.
+type blobPrefetcher struct {
+	// storage is a separate instance which does not share the go-git caches with the pipeline.
+	storage   storer.EncodedObjectStorer
+	commits   []plumbing.Hash
+	positions map[plumbing.Hash]int
+	window    int
+	// classify determines the class of the loaded blob, see BlobCache.classifyBlob().
+	classify func(*object.Blob) (*object.Blob, BlobClass, int64, error)
+	cache    *blobLRU
+
+	lock sync.Mutex
+	// next is the position of the first commit which has not been scheduled yet.
+	next    int
+	running bool
+	done    sync.WaitGroup
+	// stop is closed by Stop() to interrupt the current batch.
+	stop    chan struct{}
+	stopped bool
+}
+
+// newBlobPrefetcher returns nil if the repository cannot be read concurrently.
+func newBlobPrefetcher(repository *git.Repository, commits []plumbing.Hash, window int,
+	classify func(*object.Blob) (*object.Blob, BlobClass, int64, error),
+	cache *blobLRU) *blobPrefetcher {
+	if repository == nil || window <= 0 || len(commits) < 2 {
+		return nil
+	}
+	var objects storer.EncodedObjectStorer
+	switch storage := repository.Storer.(type) {
+	case *filesystem.Storage:
+		// the go-git file system storage is not safe for concurrent use
+		independent, err := filesystem.NewStorage(storage.Filesystem())
+		if err != nil {
+			return nil
+		}
+		objects = independent
+	case *memory.Storage:
+		// the pipeline never writes to the storage, so it is safe to read concurrently
+		objects = storage
+	default:
+		return nil
+	}
+	positions := map[plumbing.Hash]int{}
+	for i, hash := range commits {
+		positions[hash] = i
+	}
+	return &blobPrefetcher{
+		storage:   objects,
+		commits:   commits,
+		positions: positions,
+		window:    window,
+		classify:  classify,
+		cache:     cache,
+		next:      1,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Schedule starts loading the next batch when the analysed commit approaches the last
+// scheduled one.
+func (prefetcher *blobPrefetcher) Schedule(commit plumbing.Hash) {
+	position, exists := prefetcher.positions[commit]
+	if !exists {
+		return
+	}
+	prefetcher.lock.Lock()
+	defer prefetcher.lock.Unlock()
+	if prefetcher.stopped || prefetcher.running || prefetcher.next >= len(prefetcher.commits) ||
+		prefetcher.next-position-1 > prefetcher.window/2 {
+		return
+	}
+	begin := prefetcher.next
+	if begin <= position {
+		begin = position + 1
+	}
+	end := position + prefetcher.window + 1
+	if end > len(prefetcher.commits) {
+		end = len(prefetcher.commits)
+	}
+	if begin >= end {
+		return
+	}
+	prefetcher.next = end
+	prefetcher.running = true
+	prefetcher.done.Add(1)
+	go func() {
+		defer prefetcher.done.Done()
+		prefetcher.load(begin, end)
+		prefetcher.lock.Lock()
+		prefetcher.running = false
+		prefetcher.lock.Unlock()
+	}()
+}
+
+// Wait blocks until the current batch is loaded.
+func (prefetcher *blobPrefetcher) Wait() {
+	prefetcher.done.Wait()
+}
+
+// Stop interrupts the current batch, waits for the goroutine to exit and disables Schedule().
+func (prefetcher *blobPrefetcher) Stop() {
+	prefetcher.lock.Lock()
+	if !prefetcher.stopped {
+		prefetcher.stopped = true
+		close(prefetcher.stop)
+	}
+	prefetcher.lock.Unlock()
+	prefetcher.done.Wait()
+}
+
+// interrupted returns true if Stop() has been called.
+func (prefetcher *blobPrefetcher) interrupted() bool {
+	select {
+	case <-prefetcher.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// load reads the blobs which are changed in the commits from `begin` to `end` relative to
+// the previous commits in the sequence. The errors are ignored since BlobCache loads
+// the missing blobs anyway.
+func (prefetcher *blobPrefetcher) load(begin, end int) {
+	var previous *object.Tree
+	for i := begin; i < end && !prefetcher.interrupted(); i++ {
+		if previous == nil {
+			previous = prefetcher.tree(prefetcher.commits[i-1])
+		}
+		tree := prefetcher.tree(prefetcher.commits[i])
+		if previous == nil || tree == nil {
+			previous = tree
+			continue
+		}
+		changes, err := object.DiffTree(previous, tree)
+		previous = tree
+		if err != nil {
+			continue
+		}
+		for _, change := range changes {
+			if prefetcher.interrupted() {
+				return
+			}
+			action, err := change.Action()
+			if err != nil || action == merkletrie.Delete ||
+				change.To.TreeEntry.Mode == filemode.Submodule ||
+				prefetcher.cache.Contains(change.To.TreeEntry.Hash) {
+				continue
+			}
+			blob, err := object.GetBlob(prefetcher.storage, change.To.TreeEntry.Hash)
+			if err != nil {
+				continue
+			}
+			blob, class, memory, err := prefetcher.classify(blob)
+			if err != nil {
+				continue
+			}
+			prefetcher.cache.Put(blob, class, memory)
+			atomic.AddInt64(&prefetcher.cache.stats.Prefetched, 1)
+		}
+	}
+}
+
+func (prefetcher *blobPrefetcher) tree(hash plumbing.Hash) *object.Tree {
+	commit, err := object.GetCommit(prefetcher.storage, hash)
+	if err != nil {
+		return nil
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil
+	}
+	return tree
+}
@@ -0,0 +1,229 @@
+package plumbing
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+	"gopkg.in/src-d/hercules.v4/internal/test"
+)
+
+// fixtureHistory commits each snapshot of the files to the repository which must have
+// a working tree.
+func fixtureHistory(repository *git.Repository, history []map[string]string) []*object.Commit {
+	worktree, err := repository.Worktree()
+	if err != nil {
+		panic(err)
+	}
+	var commits []*object.Commit
+	for i, files := range history {
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			err = util.WriteFile(worktree.Filesystem, name, []byte(files[name]), 0644)
+			if err != nil {
+				panic(err)
+			}
+			if _, err = worktree.Add(name); err != nil {
+				panic(err)
+			}
+		}
+		hash, err := worktree.Commit("test", &git.CommitOptions{Author: &object.Signature{
+			Name: "test", Email: "test@test", When: time.Unix(int64(i)*3600, 0)}})
+		if err != nil {
+			panic(err)
+		}
+		commit, err := repository.CommitObject(hash)
+		if err != nil {
+			panic(err)
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+func fixtureMemoryHistory() (*git.Repository, []*object.Commit) {
+	repository, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		panic(err)
+	}
+	return repository, fixtureHistory(repository, []map[string]string{
+		{"a.txt": "1\n", "b.txt": "x\n"},
+		{"a.txt": "1\n2\n"},
+		{"a.txt": "1\n2\n3\n"},
+	})
+}
+
+func TestBlobLRU(t *testing.T) {
+	stats := &BlobCacheStats{}
+	lru := newBlobLRU(10, stats)
+	blob1, blob2, blob3 := test.FakeBlob("12345"), test.FakeBlob("abcd"), test.FakeBlob("xyz")
+	lru.Put(blob1, BlobText, blob1.Size)
+	lru.Put(blob2, BlobBinary, blob2.Size)
+	assert.Equal(t, 2, lru.Len())
+	assert.Equal(t, int64(9), lru.size)
+	blob, class, exists := lru.Get(blob1.Hash)
+	assert.True(t, exists)
+	assert.True(t, blob == blob1)
+	assert.Equal(t, BlobText, class)
+	// blob2 is the least recently used
+	lru.Put(blob3, BlobText, blob3.Size)
+	assert.Equal(t, 2, lru.Len())
+	assert.Equal(t, int64(8), lru.size)
+	assert.False(t, lru.Contains(blob2.Hash))
+	assert.True(t, lru.Contains(blob3.Hash))
+	_, _, exists = lru.Get(blob2.Hash)
+	assert.False(t, exists)
+	// too big for the budget
+	big := test.FakeBlob("0123456789a")
+	lru.Put(big, BlobText, big.Size)
+	assert.False(t, lru.Contains(big.Hash))
+	assert.Equal(t, 2, lru.Len())
+	// the duplicates are ignored
+	lru.Put(blob3, BlobBinary, blob3.Size)
+	_, class, _ = lru.Get(blob3.Hash)
+	assert.Equal(t, BlobText, class)
+	assert.Equal(t, BlobCacheStats{Hits: 2, Misses: 1, Evicted: 1}, *stats)
+	assert.Equal(t, "2 hits, 1 misses (66.7% hit rate), 0 prefetched, 1 evicted", stats.String())
+	assert.Equal(t, "0 hits, 0 misses (0.0% hit rate), 0 prefetched, 0 evicted",
+		(&BlobCacheStats{}).String())
+	// the budget is charged with the memory, not with the size
+	lru.Put(big, BlobText, 1)
+	assert.True(t, lru.Contains(big.Hash))
+	assert.Equal(t, int64(9), lru.size)
+	assert.True(t, lru.Contains(blob1.Hash))
+	assert.True(t, lru.Contains(blob3.Hash))
+}
+
+func consumeHistory(t *testing.T, cache *BlobCache, repository *git.Repository,
+	commits []*object.Commit, wait bool) []map[plumbing.Hash]*object.Blob {
+	treeDiff := TreeDiff{}
+	treeDiff.Initialize(repository)
+	var result []map[plumbing.Hash]*object.Blob
+	for _, commit := range commits {
+		deps := map[string]interface{}{core.DependencyCommit: commit}
+		changes, err := treeDiff.Consume(deps)
+		assert.Nil(t, err)
+		deps[DependencyTreeChanges] = changes[DependencyTreeChanges]
+		blobs, err := cache.Consume(deps)
+		assert.Nil(t, err)
+		result = append(result, blobs[DependencyBlobCache].(map[plumbing.Hash]*object.Blob))
+		if wait && cache.prefetcher != nil {
+			cache.prefetcher.Wait()
+		}
+	}
+	return result
+}
+
+func TestBlobCacheLRUConsume(t *testing.T) {
+	repository, commits := fixtureMemoryHistory()
+	cache := &BlobCache{}
+	facts := map[string]interface{}{core.ConfigPipelineCommits: commits}
+	cache.Configure(facts)
+	cache.Initialize(repository)
+	assert.Nil(t, cache.prefetcher)
+	blobs := consumeHistory(t, cache, repository, commits, false)
+	assert.Len(t, blobs[0], 2)
+	assert.Len(t, blobs[1], 2)
+	assert.Len(t, blobs[2], 2)
+	// the old contents of a.txt are always cached
+	assert.Equal(t, BlobCacheStats{Hits: 2, Misses: 4}, *cache.stats)
+	assert.Equal(t, 4, cache.cache.Len())
+	// the loader reads through the cache
+	load := facts[FactBlobCacheLoader].(BlobLoader)
+	for hash := range blobs[2] {
+		blob, class, err := load(hash)
+		assert.Nil(t, err)
+		assert.Equal(t, hash, blob.Hash)
+		assert.Equal(t, BlobText, class)
+	}
+	assert.Equal(t, BlobCacheStats{Hits: 4, Misses: 4}, *cache.stats)
+	_, _, err := load(plumbing.ZeroHash)
+	assert.NotNil(t, err)
+	clone := cache.Fork(1)[0].(*BlobCache)
+	assert.True(t, clone.cache == cache.cache)
+	assert.True(t, clone.stats == cache.stats)
+}
+
+func TestBlobCachePrefetch(t *testing.T) {
+	repository, commits := fixtureMemoryHistory()
+	cache := &BlobCache{Prefetch: 2}
+	cache.Configure(map[string]interface{}{core.ConfigPipelineCommits: commits})
+	cache.Initialize(repository)
+	assert.NotNil(t, cache.prefetcher)
+	blobs := consumeHistory(t, cache, repository, commits, true)
+	assert.Len(t, blobs[2], 2)
+	// only the first commit is loaded in the pipeline
+	assert.Equal(t, BlobCacheStats{Hits: 4, Misses: 2, Prefetched: 2}, *cache.stats)
+	// the unknown commits are ignored
+	cache.prefetcher.Schedule(plumbing.ZeroHash)
+	assert.False(t, cache.prefetcher.running)
+}
+
+func TestBlobPrefetcherStorage(t *testing.T) {
+	classify := func(blob *object.Blob) (*object.Blob, BlobClass, int64, error) {
+		return blob, BlobText, blob.Size, nil
+	}
+	hashes := []plumbing.Hash{plumbing.ZeroHash, plumbing.ZeroHash}
+	lru := newBlobLRU(1<<20, &BlobCacheStats{})
+	assert.Nil(t, newBlobPrefetcher(nil, hashes, 2, classify, lru))
+	repository, commits := fixtureMemoryHistory()
+	assert.Nil(t, newBlobPrefetcher(repository, hashes, 0, classify, lru))
+	assert.Nil(t, newBlobPrefetcher(repository, hashes[:1], 2, classify, lru))
+	prefetcher := newBlobPrefetcher(repository, hashes, 2, classify, lru)
+	assert.NotNil(t, prefetcher)
+	assert.True(t, prefetcher.storage == repository.Storer)
+	root, err := ioutil.TempDir("", "hercules-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+	repository, err = git.PlainInit(root, false)
+	assert.Nil(t, err)
+	commits = fixtureHistory(repository, []map[string]string{{"a.txt": "a\n"}, {"a.txt": "b\n"}})
+	hashes = []plumbing.Hash{commits[0].Hash, commits[1].Hash}
+	prefetcher = newBlobPrefetcher(repository, hashes, 1, classify, lru)
+	assert.NotNil(t, prefetcher)
+	// the file system storage is opened again
+	assert.False(t, prefetcher.storage == repository.Storer)
+	prefetcher.Schedule(commits[0].Hash)
+	prefetcher.Wait()
+	assert.Equal(t, 1, lru.Len())
+	assert.Equal(t, int64(1), lru.stats.Prefetched)
+}
+
+func TestBlobPrefetcherStop(t *testing.T) {
+	repository, commits := fixtureMemoryHistory()
+	cache := &BlobCache{Prefetch: 2}
+	cache.Configure(map[string]interface{}{core.ConfigPipelineCommits: commits})
+	cache.Initialize(repository)
+	prefetcher := cache.prefetcher
+	assert.NotNil(t, prefetcher)
+	cache.Dispose()
+	assert.Nil(t, cache.prefetcher)
+	assert.True(t, prefetcher.interrupted())
+	// nothing is loaded after Stop()
+	prefetcher.Schedule(commits[0].Hash)
+	assert.False(t, prefetcher.running)
+	prefetcher.Stop()
+	prefetcher = newBlobPrefetcher(
+		repository, prefetcher.commits, 2, cache.classifyBlob, cache.cache)
+	close(prefetcher.stop)
+	prefetcher.load(1, len(prefetcher.commits))
+	assert.Equal(t, 0, cache.cache.Len())
+	// Initialize() starts prefetching again
+	cache.Initialize(repository)
+	assert.NotNil(t, cache.prefetcher)
+	cache.Dispose()
+}
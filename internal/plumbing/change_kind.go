@@ -0,0 +1,109 @@
+package plumbing
+
+import (
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+)
+
+// ChangeKind tells what kind of tree entries an object.Change touches and what changed in them.
+type ChangeKind int
+
+const (
+	// ChangeKindContent is the kind of the regular file insertions, deletions and modifications.
+	ChangeKindContent ChangeKind = iota
+	// ChangeKindMode is the kind of the modifications which change only the file mode,
+	// e.g. flip the executable bit. The contents and the name stay the same.
+	ChangeKindMode
+	// ChangeKindSymlink is the kind of the changes which involve only symbolic links.
+	// The blob of a symlink is the target path, not the file contents.
+	ChangeKindSymlink
+	// ChangeKindType is the kind of the modifications which turn a regular file into
+	// a symbolic link or vice versa.
+	ChangeKindType
+	// ChangeKindSubmodule is the kind of the changes which involve the submodules.
+	ChangeKindSubmodule
+)
+
+// String returns the name of the kind.
+func (kind ChangeKind) String() string {
+	switch kind {
+	case ChangeKindContent:
+		return "content"
+	case ChangeKindMode:
+		return "mode"
+	case ChangeKindSymlink:
+		return "symlink"
+	case ChangeKindType:
+		return "type"
+	case ChangeKindSubmodule:
+		return "submodule"
+	}
+	return ""
+}
+
+// ClassifyChange determines the kind of the change from the modes and the hashes
+// of the tree entries.
+func ClassifyChange(change *object.Change) ChangeKind {
+	from, to := change.From.TreeEntry.Mode, change.To.TreeEntry.Mode
+	if from == filemode.Submodule || to == filemode.Submodule {
+		return ChangeKindSubmodule
+	}
+	fromLink, toLink := from == filemode.Symlink, to == filemode.Symlink
+	if change.From.Name == "" {
+		fromLink = toLink
+	}
+	if change.To.Name == "" {
+		toLink = fromLink
+	}
+	if fromLink != toLink {
+		return ChangeKindType
+	}
+	if fromLink {
+		return ChangeKindSymlink
+	}
+	if change.From.Name != "" && change.From.Name == change.To.Name && from != to &&
+		change.From.TreeEntry.Hash == change.To.TreeEntry.Hash {
+		return ChangeKindMode
+	}
+	return ChangeKindContent
+}
+
+// ChangeKinds is the type of the dependency provided by TreeDiff. It maps the names
+// of the changed files to the kinds of the changes which are not ChangeKindContent.
+type ChangeKinds map[string]ChangeKind
+
+// Kind returns the kind of the change. The changes which are not in the map, e.g.
+// the renames detected by RenameAnalysis, are classified on the fly.
+func (kinds ChangeKinds) Kind(change *object.Change) ChangeKind {
+	if change.From.Name == "" || change.To.Name == "" || change.From.Name == change.To.Name {
+		if kind, exists := kinds[changeName(change)]; exists {
+			return kind
+		}
+		if kinds != nil {
+			return ChangeKindContent
+		}
+	}
+	return ClassifyChange(change)
+}
+
+// LineAction returns the action of the change from the point of view of the line analyses.
+// They ignore the pure mode changes and the symlinks, so the returned action is 0 for those.
+// Turning a file into a symlink is a deletion and vice versa is an insertion. The submodules
+// keep their actions: BlobCache loads them as empty blobs.
+func (kinds ChangeKinds) LineAction(change *object.Change) (merkletrie.Action, error) {
+	action, err := change.Action()
+	if err != nil {
+		return action, err
+	}
+	switch kinds.Kind(change) {
+	case ChangeKindMode, ChangeKindSymlink:
+		return 0, nil
+	case ChangeKindType:
+		if change.To.TreeEntry.Mode == filemode.Symlink {
+			return merkletrie.Delete, nil
+		}
+		return merkletrie.Insert, nil
+	}
+	return action, nil
+}
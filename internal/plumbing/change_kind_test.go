@@ -0,0 +1,142 @@
+package plumbing
+
+import (
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+)
+
+func changeEntry(name string, mode filemode.FileMode, hash string) object.ChangeEntry {
+	if name == "" {
+		return object.ChangeEntry{}
+	}
+	return object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+		Name: name, Mode: mode, Hash: plumbing.NewHash(hash)}}
+}
+
+func TestChangeKindString(t *testing.T) {
+	assert.Equal(t, "content", ChangeKindContent.String())
+	assert.Equal(t, "mode", ChangeKindMode.String())
+	assert.Equal(t, "symlink", ChangeKindSymlink.String())
+	assert.Equal(t, "type", ChangeKindType.String())
+	assert.Equal(t, "submodule", ChangeKindSubmodule.String())
+	assert.Equal(t, "", ChangeKind(100).String())
+}
+
+func TestClassifyChange(t *testing.T) {
+	const hash1, hash2 = "1111111111111111111111111111111111111111",
+		"2222222222222222222222222222222222222222"
+	regular, exec, link := filemode.Regular, filemode.Executable, filemode.Symlink
+	for i, pair := range []struct {
+		from, to object.ChangeEntry
+		kind     ChangeKind
+		action   merkletrie.Action
+	}{
+		{changeEntry("", 0, ""), changeEntry("a", regular, hash1), ChangeKindContent, merkletrie.Insert},
+		{changeEntry("a", regular, hash1), changeEntry("", 0, ""), ChangeKindContent, merkletrie.Delete},
+		{changeEntry("a", regular, hash1), changeEntry("a", regular, hash2), ChangeKindContent, merkletrie.Modify},
+		{changeEntry("a", regular, hash1), changeEntry("a", exec, hash2), ChangeKindContent, merkletrie.Modify},
+		{changeEntry("a", regular, hash1), changeEntry("a", exec, hash1), ChangeKindMode, 0},
+		{changeEntry("a", exec, hash1), changeEntry("a", regular, hash1), ChangeKindMode, 0},
+		// a rename is not a pure mode change
+		{changeEntry("a", regular, hash1), changeEntry("b", exec, hash1), ChangeKindContent, merkletrie.Modify},
+		{changeEntry("", 0, ""), changeEntry("l", link, hash1), ChangeKindSymlink, 0},
+		{changeEntry("l", link, hash1), changeEntry("", 0, ""), ChangeKindSymlink, 0},
+		{changeEntry("l", link, hash1), changeEntry("l", link, hash2), ChangeKindSymlink, 0},
+		{changeEntry("l", link, hash1), changeEntry("l", regular, hash2), ChangeKindType, merkletrie.Insert},
+		{changeEntry("l", regular, hash1), changeEntry("l", link, hash2), ChangeKindType, merkletrie.Delete},
+		{changeEntry("s", filemode.Submodule, hash1), changeEntry("s", filemode.Submodule, hash2),
+			ChangeKindSubmodule, merkletrie.Modify},
+		{changeEntry("", 0, ""), changeEntry("s", filemode.Submodule, hash1),
+			ChangeKindSubmodule, merkletrie.Insert},
+	} {
+		change := &object.Change{From: pair.from, To: pair.to}
+		assert.Equal(t, pair.kind, ClassifyChange(change), "%d", i)
+		for _, kinds := range []ChangeKinds{nil, {changeName(change): pair.kind}} {
+			assert.Equal(t, pair.kind, kinds.Kind(change), "%d", i)
+			action, err := kinds.LineAction(change)
+			assert.Nil(t, err)
+			assert.Equal(t, pair.action, action, "%d", i)
+		}
+	}
+	_, err := ChangeKinds{}.LineAction(&object.Change{})
+	assert.NotNil(t, err)
+}
+
+func TestChangeKindsKind(t *testing.T) {
+	const hash = "1111111111111111111111111111111111111111"
+	change := &object.Change{
+		From: changeEntry("a", filemode.Regular, hash), To: changeEntry("a", filemode.Executable, hash)}
+	// the map is trusted for the changes under the same name
+	assert.Equal(t, ChangeKindContent, ChangeKinds{}.Kind(change))
+	assert.Equal(t, ChangeKindMode, ChangeKinds{"a": ChangeKindMode}.Kind(change))
+	// the renames are classified
+	change.To = changeEntry("b", filemode.Symlink, hash)
+	assert.Equal(t, ChangeKindType, ChangeKinds{}.Kind(change))
+}
+
+func TestTreeDiffChangeKinds(t *testing.T) {
+	root, repository, commits := fixtureGitHistory(t)
+	defer os.RemoveAll(root)
+	for _, backend := range []string{core.GitBackendGoGit, core.GitBackendExec} {
+		td := &TreeDiff{GitBackend: backend}
+		td.Initialize(repository)
+		var kinds []ChangeKinds
+		for _, commit := range commits {
+			res, err := td.Consume(map[string]interface{}{core.DependencyCommit: commit})
+			assert.Nil(t, err)
+			changes := res[DependencyTreeChanges].(object.Changes)
+			commitKinds := res[DependencyTreeChangeKinds].(ChangeKinds)
+			for _, change := range changes {
+				assert.Equal(t, ClassifyChange(change), commitKinds.Kind(change))
+			}
+			kinds = append(kinds, commitKinds)
+		}
+		assert.Equal(t, ChangeKinds{"link": ChangeKindSymlink}, kinds[0], backend)
+		assert.Equal(t, ChangeKinds{
+			"exec.sh": ChangeKindMode, "link": ChangeKindType, "libs/sub": ChangeKindSubmodule},
+			kinds[1], backend)
+		assert.Equal(t, ChangeKinds{"libs/sub": ChangeKindSubmodule}, kinds[2], backend)
+	}
+}
+
+func TestFileDiffChangeKinds(t *testing.T) {
+	root, repository, commits := fixtureGitHistory(t)
+	defer os.RemoveAll(root)
+	td := &TreeDiff{}
+	td.Initialize(repository)
+	cache := &BlobCache{}
+	cache.Configure(map[string]interface{}{})
+	cache.Initialize(repository)
+	fd := &FileDiff{}
+	fd.Initialize(repository)
+	var names [][]string
+	for _, commit := range commits {
+		deps := map[string]interface{}{core.DependencyCommit: commit}
+		for _, item := range []core.PipelineItem{td, cache, fd} {
+			res, err := item.Consume(deps)
+			assert.Nil(t, err)
+			for key, val := range res {
+				deps[key] = val
+			}
+		}
+		var commitNames []string
+		for name := range deps[DependencyFileDiff].(map[string]FileDiffData) {
+			commitNames = append(commitNames, name)
+		}
+		sort.Strings(commitNames)
+		names = append(names, commitNames)
+	}
+	assert.Len(t, names[0], 0)
+	// exec.sh changed the mode and link turned into a regular file
+	assert.Equal(t, []string{"a/b.txt", "libs/sub"}, names[1])
+	// the submodule and the directory a were deleted
+	assert.Len(t, names[2], 0)
+}
@@ -16,6 +16,8 @@ type DaysSinceStart struct {
 	day0        time.Time
 	previousDay int
 	commits     map[int][]plumbing.Hash
+	// signature is core.SignatureAuthor or core.SignatureCommitter
+	signature string
 }
 
 const (
@@ -58,6 +60,9 @@ func (days *DaysSinceStart) Configure(facts map[string]interface{}) {
 		days.commits = map[int][]plumbing.Hash{}
 	}
 	facts[FactCommitsByDay] = days.commits
+	if val, exists := facts[core.ConfigPipelineSignature].(string); exists {
+		days.signature = val
+	}
 }
 
 // Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
@@ -84,13 +89,14 @@ func (days *DaysSinceStart) Initialize(repository *git.Repository) {
 func (days *DaysSinceStart) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
 	commit := deps[core.DependencyCommit].(*object.Commit)
 	index := deps[core.DependencyIndex].(int)
+	when := core.CommitSignature(commit, days.signature).When
 	if index == 0 {
 		// first iteration - initialize the file objects from the tree
-		days.day0 = commit.Author.When
+		days.day0 = when
 		// our precision is 1 day
 		days.day0 = days.day0.Truncate(24 * time.Hour)
 	}
-	day := int(commit.Author.When.Sub(days.day0).Hours() / 24)
+	day := int(when.Sub(days.day0).Hours() / 24)
 	if day < days.previousDay {
 		// rebase works miracles, but we need the monotonous time
 		day = days.previousDay
@@ -2,9 +2,11 @@ package plumbing
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"gopkg.in/src-d/hercules.v4/internal/core"
 	"gopkg.in/src-d/hercules.v4/internal/test"
 )
@@ -123,3 +125,30 @@ func TestDaysSinceStartFork(t *testing.T) {
 	// just for the sake of it
 	dss1.Merge([]core.PipelineItem{dss2})
 }
+
+func TestDaysSinceStartSignature(t *testing.T) {
+	dss := DaysSinceStart{}
+	dss.Configure(map[string]interface{}{core.ConfigPipelineSignature: core.SignatureCommitter})
+	dss.Initialize(test.Repository)
+	day0 := time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+	commit := &object.Commit{
+		Hash:      plumbing.NewHash("cce947b98a050c6d356bc6ba95030254914027b1"),
+		Author:    object.Signature{When: day0.AddDate(0, 0, -10)},
+		Committer: object.Signature{When: day0},
+	}
+	deps := map[string]interface{}{core.DependencyCommit: commit, core.DependencyIndex: 0}
+	res, err := dss.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res[DependencyDay].(int))
+	commit = &object.Commit{
+		Hash:         plumbing.NewHash("fc9ceecb6dabcb2aab60e8619d972e8d8208a7df"),
+		ParentHashes: []plumbing.Hash{commit.Hash},
+		Author:       object.Signature{When: day0.AddDate(0, 0, -20)},
+		Committer:    object.Signature{When: day0.AddDate(0, 0, 3)},
+	}
+	deps[core.DependencyCommit] = commit
+	deps[core.DependencyIndex] = 1
+	res, err = dss.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, res[DependencyDay].(int))
+}
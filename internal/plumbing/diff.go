@@ -5,6 +5,9 @@ import (
 	"bytes"
 	"errors"
 	"io"
+	"log"
+	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
@@ -16,10 +19,22 @@ import (
 )
 
 // FileDiff calculates the difference of files which were modified.
-// It is a PipelineItem.
+// It is a PipelineItem. The blobs which are not text are not diffed, see BlobClasses.
+// The pure mode changes and the symlinks are skipped, see ChangeKinds.LineAction().
 type FileDiff struct {
 	core.NoopMerger
 	CleanupDisabled bool
+	// Algorithm is the name of the diff algorithm, one of the keys of DiffAlgorithms.
+	// The default is DiffAlgorithmMyers.
+	Algorithm string
+	// IgnoreSpaceChange treats the lines which differ only in the amount of whitespace
+	// as equal, like `git diff --ignore-space-change`.
+	IgnoreSpaceChange bool
+	// IgnoreCRAtEOL treats the lines which differ only in the CRLF and LF line endings
+	// as equal, like `git diff --ignore-cr-at-eol`.
+	IgnoreCRAtEOL bool
+	// IgnoreIndentation treats the lines which differ only in the leading whitespace as equal.
+	IgnoreIndentation bool
 }
 
 const (
@@ -28,6 +43,22 @@ const (
 	// the human interpretability of diffs.
 	ConfigFileDiffDisableCleanup = "FileDiff.NoCleanup"
 
+	// ConfigFileDiffAlgorithm is the name of the configuration option (FileDiff.Configure())
+	// which sets FileDiff.Algorithm.
+	ConfigFileDiffAlgorithm = "FileDiff.Algorithm"
+
+	// ConfigFileDiffIgnoreSpaceChange is the name of the configuration option
+	// (FileDiff.Configure()) which sets FileDiff.IgnoreSpaceChange.
+	ConfigFileDiffIgnoreSpaceChange = "FileDiff.IgnoreSpaceChange"
+
+	// ConfigFileDiffIgnoreCRAtEOL is the name of the configuration option
+	// (FileDiff.Configure()) which sets FileDiff.IgnoreCRAtEOL.
+	ConfigFileDiffIgnoreCRAtEOL = "FileDiff.IgnoreCRAtEOL"
+
+	// ConfigFileDiffIgnoreIndentation is the name of the configuration option
+	// (FileDiff.Configure()) which sets FileDiff.IgnoreIndentation.
+	ConfigFileDiffIgnoreIndentation = "FileDiff.IgnoreIndentation"
+
 	// DependencyFileDiff is the name of the dependency provided by FileDiff.
 	DependencyFileDiff = "file_diff"
 )
@@ -56,7 +87,8 @@ func (diff *FileDiff) Provides() []string {
 // Each requested entity will be inserted into `deps` of Consume(). In turn, those
 // entities are Provides() upstream.
 func (diff *FileDiff) Requires() []string {
-	arr := [...]string{DependencyTreeChanges, DependencyBlobCache}
+	arr := [...]string{
+		DependencyTreeChanges, DependencyBlobCache, DependencyBlobClasses, DependencyTreeChangeKinds}
 	return arr[:]
 }
 
@@ -67,6 +99,27 @@ func (diff *FileDiff) ListConfigurationOptions() []core.ConfigurationOption {
 		Description: "Do not apply additional heuristics to improve diffs.",
 		Flag:        "no-diff-cleanup",
 		Type:        core.BoolConfigurationOption,
+		Default:     false}, {
+		Name: ConfigFileDiffAlgorithm,
+		Description: "The line diff algorithm: \"" + DiffAlgorithmMyers + "\", \"" +
+			DiffAlgorithmPatience + "\" or \"" + DiffAlgorithmHistogram + "\".",
+		Flag:    "diff-algorithm",
+		Type:    core.StringConfigurationOption,
+		Default: DiffAlgorithmMyers}, {
+		Name:        ConfigFileDiffIgnoreSpaceChange,
+		Description: "Consider the lines which differ only in the amount of whitespace unchanged.",
+		Flag:        "ignore-space-change",
+		Type:        core.BoolConfigurationOption,
+		Default:     false}, {
+		Name:        ConfigFileDiffIgnoreCRAtEOL,
+		Description: "Consider the lines which differ only in the CRLF and LF line endings unchanged.",
+		Flag:        "ignore-cr-at-eol",
+		Type:        core.BoolConfigurationOption,
+		Default:     false}, {
+		Name:        ConfigFileDiffIgnoreIndentation,
+		Description: "Consider the lines which differ only in the indentation unchanged.",
+		Flag:        "ignore-indentation",
+		Type:        core.BoolConfigurationOption,
 		Default:     false},
 	}
 	return options[:]
@@ -77,6 +130,23 @@ func (diff *FileDiff) Configure(facts map[string]interface{}) {
 	if val, exists := facts[ConfigFileDiffDisableCleanup].(bool); exists {
 		diff.CleanupDisabled = val
 	}
+	if val, exists := facts[ConfigFileDiffAlgorithm].(string); exists {
+		diff.Algorithm = val
+	}
+	if val, exists := facts[ConfigFileDiffIgnoreSpaceChange].(bool); exists {
+		diff.IgnoreSpaceChange = val
+	}
+	if val, exists := facts[ConfigFileDiffIgnoreCRAtEOL].(bool); exists {
+		diff.IgnoreCRAtEOL = val
+	}
+	if val, exists := facts[ConfigFileDiffIgnoreIndentation].(bool); exists {
+		diff.IgnoreIndentation = val
+	}
+	if _, exists := DiffAlgorithms[diff.Algorithm]; !exists && diff.Algorithm != "" {
+		log.Printf("Unknown diff algorithm: %s, falling back to %s\n",
+			diff.Algorithm, DiffAlgorithmMyers)
+		diff.Algorithm = DiffAlgorithmMyers
+	}
 }
 
 // Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
@@ -92,36 +162,28 @@ func (diff *FileDiff) Consume(deps map[string]interface{}) (map[string]interface
 	result := map[string]FileDiffData{}
 	cache := deps[DependencyBlobCache].(map[plumbing.Hash]*object.Blob)
 	treeDiff := deps[DependencyTreeChanges].(object.Changes)
+	classes, _ := deps[DependencyBlobClasses].(BlobClasses)
+	kinds, _ := deps[DependencyTreeChangeKinds].(ChangeKinds)
 	for _, change := range treeDiff {
-		action, err := change.Action()
+		// the symlink targets are not text and the mode changes have nothing to diff
+		action, err := kinds.LineAction(change)
 		if err != nil {
 			return nil, err
 		}
 		switch action {
 		case merkletrie.Modify:
-			blobFrom := cache[change.From.TreeEntry.Hash]
-			blobTo := cache[change.To.TreeEntry.Hash]
-			// we are not validating UTF-8 here because for example
-			// git/git 4f7770c87ce3c302e1639a7737a6d2531fe4b160 fetch-pack.c is invalid UTF-8
-			strFrom, err := BlobToString(blobFrom)
-			if err != nil {
-				return nil, err
+			blobFrom, blobTo := cache[change.From.TreeEntry.Hash], cache[change.To.TreeEntry.Hash]
+			var data FileDiffData
+			if classes.Class(change.From.TreeEntry.Hash) != BlobText ||
+				classes.Class(change.To.TreeEntry.Hash) != BlobText {
+				data, err = classes.replaceBlobs(blobFrom, blobTo)
+			} else {
+				data, err = diff.diffBlobs(blobFrom, blobTo)
 			}
-			strTo, err := BlobToString(blobTo)
 			if err != nil {
 				return nil, err
 			}
-			dmp := diffmatchpatch.New()
-			src, dst, _ := dmp.DiffLinesToRunes(strFrom, strTo)
-			diffs := dmp.DiffMainRunes(src, dst, false)
-			if !diff.CleanupDisabled {
-				diffs = dmp.DiffCleanupMerge(dmp.DiffCleanupSemanticLossless(diffs))
-			}
-			result[change.To.Name] = FileDiffData{
-				OldLinesOfCode: len(src),
-				NewLinesOfCode: len(dst),
-				Diffs:          diffs,
-			}
+			result[change.To.Name] = data
 		default:
 			continue
 		}
@@ -134,6 +196,106 @@ func (diff *FileDiff) Fork(n int) []core.PipelineItem {
 	return core.ForkSamePipelineItem(diff, n)
 }
 
+// diffAlgorithm returns the DiffAlgorithm with the specified name, DiffAlgorithmMyers
+// if there is no such algorithm.
+func diffAlgorithm(name string) DiffAlgorithm {
+	if algorithm, exists := DiffAlgorithms[name]; exists {
+		return algorithm
+	}
+	return DiffAlgorithms[DiffAlgorithmMyers]
+}
+
+// diffBlobs calculates the line difference between two blobs.
+func (diff *FileDiff) diffBlobs(blobFrom *object.Blob, blobTo *object.Blob) (FileDiffData, error) {
+	// we are not validating UTF-8 here because for example
+	// git/git 4f7770c87ce3c302e1639a7737a6d2531fe4b160 fetch-pack.c is invalid UTF-8
+	strFrom, err := BlobToString(blobFrom)
+	if err != nil {
+		return FileDiffData{}, err
+	}
+	strTo, err := BlobToString(blobTo)
+	if err != nil {
+		return FileDiffData{}, err
+	}
+	dmp := diffmatchpatch.New()
+	var src, dst []rune
+	if diff.IgnoreSpaceChange || diff.IgnoreCRAtEOL || diff.IgnoreIndentation {
+		src, dst = linesToRunes(strFrom, strTo, diff.normalizeLine)
+	} else {
+		src, dst, _ = dmp.DiffLinesToRunes(strFrom, strTo)
+	}
+	diffs := diffAlgorithm(diff.Algorithm).Diff(src, dst)
+	if !diff.CleanupDisabled {
+		diffs = dmp.DiffCleanupMerge(dmp.DiffCleanupSemanticLossless(diffs))
+	}
+	return FileDiffData{
+		OldLinesOfCode: len(src),
+		NewLinesOfCode: len(dst),
+		Diffs:          diffs,
+	}, nil
+}
+
+// normalizeLine erases the differences which FileDiff is configured to ignore.
+func (diff *FileDiff) normalizeLine(line string) string {
+	line = strings.TrimSuffix(line, "\n")
+	if diff.IgnoreCRAtEOL {
+		line = strings.TrimSuffix(line, "\r")
+	}
+	if diff.IgnoreIndentation {
+		line = strings.TrimLeftFunc(line, unicode.IsSpace)
+	}
+	if diff.IgnoreSpaceChange {
+		// the trailing whitespace is ignored and the other whitespace sequences are equivalent
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		var builder bytes.Buffer
+		space := false
+		for _, char := range line {
+			if unicode.IsSpace(char) {
+				if !space {
+					builder.WriteRune(' ')
+				}
+				space = true
+				continue
+			}
+			space = false
+			builder.WriteRune(char)
+		}
+		line = builder.String()
+	}
+	return line
+}
+
+// linesToRunes is the same as diffmatchpatch.DiffLinesToRunes() except that the lines
+// are equal if their normalized forms are equal.
+func linesToRunes(text1, text2 string, normalize func(string) string) ([]rune, []rune) {
+	lines := map[string]rune{}
+	next := rune(1)
+	convert := func(text string) []rune {
+		var result []rune
+		for len(text) > 0 {
+			end := strings.IndexByte(text, '\n') + 1
+			if end == 0 {
+				end = len(text)
+			}
+			key := normalize(text[:end])
+			char, exists := lines[key]
+			if !exists {
+				char = next
+				lines[key] = char
+				next++
+				if next == 0xD800 {
+					// skip the surrogates which are not valid runes
+					next = 0xE000
+				}
+			}
+			result = append(result, char)
+			text = text[end:]
+		}
+		return result
+	}
+	return convert(text1), convert(text2)
+}
+
 // CountLines returns the number of lines in a *object.Blob.
 func CountLines(file *object.Blob) (int, error) {
 	if file == nil {
@@ -0,0 +1,265 @@
+package plumbing
+
+import (
+	"sort"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// DiffAlgorithm calculates the line difference which FileDiff reports. Each rune in `src` and `dst`
+// stands for a line, see diffmatchpatch.DiffLinesToRunes(), and so does each rune in
+// the texts of the returned diffs.
+type DiffAlgorithm interface {
+	Diff(src, dst []rune) []diffmatchpatch.Diff
+}
+
+const (
+	// DiffAlgorithmMyers is the name of the default diff algorithm, like
+	// `git diff --diff-algorithm=myers`. It is implemented by diffmatchpatch.
+	DiffAlgorithmMyers = "myers"
+	// DiffAlgorithmPatience is the name of the patience diff algorithm, like
+	// `git diff --diff-algorithm=patience`. It anchors the diff at the unique lines and better
+	// preserves the moved blocks.
+	DiffAlgorithmPatience = "patience"
+	// DiffAlgorithmHistogram is the name of the histogram diff algorithm, like
+	// `git diff --diff-algorithm=histogram`. It extends patience to the lines which occur
+	// several times and prefers the rarest ones.
+	DiffAlgorithmHistogram = "histogram"
+
+	// histogramMaxChainLength is the maximum number of occurrences of a line which
+	// the histogram algorithm considers as an anchor. It is the same as in Git.
+	histogramMaxChainLength = 64
+)
+
+// DiffAlgorithms maps the names of the diff algorithms to their implementations.
+var DiffAlgorithms = map[string]DiffAlgorithm{
+	DiffAlgorithmMyers:     myersDiff{},
+	DiffAlgorithmPatience:  patienceDiff{},
+	DiffAlgorithmHistogram: histogramDiff{},
+}
+
+type myersDiff struct{}
+
+// Diff implements DiffAlgorithm.
+func (myersDiff) Diff(src, dst []rune) []diffmatchpatch.Diff {
+	return diffmatchpatch.New().DiffMainRunes(src, dst, false)
+}
+
+type patienceDiff struct{}
+
+// Diff implements DiffAlgorithm.
+func (patienceDiff) Diff(src, dst []rune) []diffmatchpatch.Diff {
+	builder := &diffBuilder{}
+	builder.recurse(src, dst, patienceAnchors)
+	return builder.compact()
+}
+
+type histogramDiff struct{}
+
+// Diff implements DiffAlgorithm.
+func (histogramDiff) Diff(src, dst []rune) []diffmatchpatch.Diff {
+	builder := &diffBuilder{}
+	builder.recurse(src, dst, histogramAnchors)
+	return builder.compact()
+}
+
+// diffAnchor is the pair of equal ranges src[srcPos:srcPos+length] and dst[dstPos:dstPos+length].
+type diffAnchor struct {
+	srcPos int
+	dstPos int
+	length int
+}
+
+// diffBuilder accumulates the diffs and merges the adjacent ones of the same type.
+type diffBuilder struct {
+	diffs []diffmatchpatch.Diff
+}
+
+func (builder *diffBuilder) add(op diffmatchpatch.Operation, lines []rune) {
+	if len(lines) == 0 {
+		return
+	}
+	if n := len(builder.diffs); n > 0 && builder.diffs[n-1].Type == op {
+		builder.diffs[n-1].Text += string(lines)
+		return
+	}
+	builder.diffs = append(builder.diffs, diffmatchpatch.Diff{Type: op, Text: string(lines)})
+}
+
+// compact slides each standalone insertion or deletion down while its first line equals
+// the next unchanged line, the same as Git does before printing the hunks. The deletions
+// always precede the insertions in the result, as diffmatchpatch guarantees.
+// For example, "}A{1" followed by "}" becomes "A{1}".
+func (builder *diffBuilder) compact() []diffmatchpatch.Diff {
+	diffs := builder.diffs
+	builder.diffs = nil
+	for i := 0; i < len(diffs); i++ {
+		diff := diffs[i]
+		standalone := diff.Type != diffmatchpatch.DiffEqual &&
+			(i == 0 || diffs[i-1].Type == diffmatchpatch.DiffEqual) &&
+			i+1 < len(diffs) && diffs[i+1].Type == diffmatchpatch.DiffEqual
+		if !standalone {
+			builder.add(diff.Type, []rune(diff.Text))
+			continue
+		}
+		hunk, next := []rune(diff.Text), []rune(diffs[i+1].Text)
+		// an insertion may not become adjacent to the following deletion
+		last := 1
+		if i+2 >= len(diffs) || diffs[i+2].Type == diff.Type {
+			last = 0
+		}
+		for len(next) > last && hunk[0] == next[0] {
+			builder.add(diffmatchpatch.DiffEqual, hunk[:1])
+			hunk = append(hunk[1:], hunk[0])
+			next = next[1:]
+		}
+		builder.add(diff.Type, hunk)
+		diffs[i+1].Text = string(next)
+	}
+	return builder.diffs
+}
+
+// recurse strips the common prefix and suffix, splits the rest by the anchors and descends
+// into the gaps between them. The regions without anchors fall back to Myers.
+func (builder *diffBuilder) recurse(
+	src, dst []rune, findAnchors func(src, dst []rune) []diffAnchor) {
+	prefix := 0
+	for prefix < len(src) && prefix < len(dst) && src[prefix] == dst[prefix] {
+		prefix++
+	}
+	builder.add(diffmatchpatch.DiffEqual, src[:prefix])
+	src, dst = src[prefix:], dst[prefix:]
+	suffix := 0
+	for suffix < len(src) && suffix < len(dst) &&
+		src[len(src)-suffix-1] == dst[len(dst)-suffix-1] {
+		suffix++
+	}
+	common := src[len(src)-suffix:]
+	src, dst = src[:len(src)-suffix], dst[:len(dst)-suffix]
+	if len(src) == 0 || len(dst) == 0 {
+		builder.add(diffmatchpatch.DiffDelete, src)
+		builder.add(diffmatchpatch.DiffInsert, dst)
+	} else if anchors := findAnchors(src, dst); len(anchors) == 0 {
+		for _, diff := range (myersDiff{}).Diff(src, dst) {
+			builder.add(diff.Type, []rune(diff.Text))
+		}
+	} else {
+		srcPos, dstPos := 0, 0
+		for _, anchor := range anchors {
+			builder.recurse(src[srcPos:anchor.srcPos], dst[dstPos:anchor.dstPos], findAnchors)
+			builder.add(diffmatchpatch.DiffEqual, src[anchor.srcPos:anchor.srcPos+anchor.length])
+			srcPos, dstPos = anchor.srcPos+anchor.length, anchor.dstPos+anchor.length
+		}
+		builder.recurse(src[srcPos:], dst[dstPos:], findAnchors)
+	}
+	builder.add(diffmatchpatch.DiffEqual, common)
+}
+
+// patienceAnchors returns the longest increasing sequence of the lines which are unique
+// in both `src` and `dst`.
+func patienceAnchors(src, dst []rune) []diffAnchor {
+	type occurrence struct {
+		srcCount, dstCount int
+		srcPos, dstPos     int
+	}
+	occurrences := map[rune]*occurrence{}
+	for i, line := range src {
+		occ := occurrences[line]
+		if occ == nil {
+			occ = &occurrence{}
+			occurrences[line] = occ
+		}
+		occ.srcCount++
+		occ.srcPos = i
+	}
+	for j, line := range dst {
+		if occ := occurrences[line]; occ != nil {
+			occ.dstCount++
+			occ.dstPos = j
+		}
+	}
+	var unique []diffAnchor
+	for _, occ := range occurrences {
+		if occ.srcCount == 1 && occ.dstCount == 1 {
+			unique = append(unique, diffAnchor{srcPos: occ.srcPos, dstPos: occ.dstPos, length: 1})
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool { return unique[i].srcPos < unique[j].srcPos })
+	// patience sorting: tails[k] is the index of the smallest dstPos which ends
+	// an increasing sequence of length k+1
+	var tails []int
+	previous := make([]int, len(unique))
+	for i, anchor := range unique {
+		k := sort.Search(len(tails), func(k int) bool {
+			return unique[tails[k]].dstPos > anchor.dstPos
+		})
+		if k > 0 {
+			previous[i] = tails[k-1]
+		} else {
+			previous[i] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	if len(tails) == 0 {
+		return nil
+	}
+	result := make([]diffAnchor, len(tails))
+	for i, k := tails[len(tails)-1], len(tails)-1; k >= 0; i, k = previous[i], k-1 {
+		result[k] = unique[i]
+	}
+	return result
+}
+
+// histogramAnchors returns the longest common region which contains the rarest lines
+// of `src`, the same way as Git does. The lines which occur more than histogramMaxChainLength
+// times are never anchors.
+func histogramAnchors(src, dst []rune) []diffAnchor {
+	positions := map[rune][]int{}
+	for i, line := range src {
+		positions[line] = append(positions[line], i)
+	}
+	best := diffAnchor{}
+	bestCount := histogramMaxChainLength + 1
+	for j := 0; j < len(dst); {
+		next := j + 1
+		occurrences := positions[dst[j]]
+		if len(occurrences) > bestCount {
+			j = next
+			continue
+		}
+		for _, i := range occurrences {
+			srcStart, dstStart := i, j
+			for srcStart > 0 && dstStart > 0 && src[srcStart-1] == dst[dstStart-1] {
+				srcStart--
+				dstStart--
+			}
+			srcEnd, dstEnd := i+1, j+1
+			for srcEnd < len(src) && dstEnd < len(dst) && src[srcEnd] == dst[dstEnd] {
+				srcEnd++
+				dstEnd++
+			}
+			count := len(occurrences)
+			for _, line := range src[srcStart:srcEnd] {
+				if lineCount := len(positions[line]); lineCount < count {
+					count = lineCount
+				}
+			}
+			if count < bestCount || (count == bestCount && srcEnd-srcStart > best.length) {
+				best = diffAnchor{srcPos: srcStart, dstPos: dstStart, length: srcEnd - srcStart}
+				bestCount = count
+			}
+			if dstEnd > next {
+				next = dstEnd
+			}
+		}
+		j = next
+	}
+	if best.length == 0 {
+		return nil
+	}
+	return []diffAnchor{best}
+}
@@ -0,0 +1,78 @@
+package plumbing
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffAlgorithmsRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+	generate := func() []rune {
+		lines := make([]rune, rnd.Intn(40))
+		for i := range lines {
+			lines[i] = 'a' + rune(rnd.Intn(6))
+		}
+		return lines
+	}
+	for name, algorithm := range DiffAlgorithms {
+		for i := 0; i < 200; i++ {
+			src, dst := generate(), generate()
+			var before, after []rune
+			diffs := algorithm.Diff(src, dst)
+			for j, diff := range diffs {
+				if j > 0 {
+					assert.NotEqual(t, diffs[j-1].Type, diff.Type, name)
+					assert.False(t, diffs[j-1].Type == diffmatchpatch.DiffInsert &&
+						diff.Type == diffmatchpatch.DiffDelete, name)
+				}
+				switch diff.Type {
+				case diffmatchpatch.DiffEqual:
+					before = append(before, []rune(diff.Text)...)
+					after = append(after, []rune(diff.Text)...)
+				case diffmatchpatch.DiffDelete:
+					before = append(before, []rune(diff.Text)...)
+				case diffmatchpatch.DiffInsert:
+					after = append(after, []rune(diff.Text)...)
+				}
+			}
+			assert.Equal(t, string(src), string(before), name)
+			assert.Equal(t, string(dst), string(after), name)
+		}
+	}
+}
+
+func TestDiffAlgorithmsMovedBlock(t *testing.T) {
+	// "{" and "}" repeat, the function names and bodies are unique
+	src := []rune("A{1}B{2}")
+	dst := []rune("B{2}A{1}")
+	for _, name := range []string{DiffAlgorithmPatience, DiffAlgorithmHistogram} {
+		assert.Equal(t, []diffmatchpatch.Diff{
+			{Type: diffmatchpatch.DiffDelete, Text: "A{1}"},
+			{Type: diffmatchpatch.DiffEqual, Text: "B{2}"},
+			{Type: diffmatchpatch.DiffInsert, Text: "A{1}"},
+		}, DiffAlgorithms[name].Diff(src, dst), name)
+	}
+}
+
+func TestPatienceAnchors(t *testing.T) {
+	assert.Equal(t, []diffAnchor{{0, 2, 1}, {2, 4, 1}},
+		patienceAnchors([]rune("AxBxC"), []rune("CxAxB")))
+	assert.Nil(t, patienceAnchors([]rune("xx"), []rune("xx")))
+}
+
+func TestHistogramAnchors(t *testing.T) {
+	assert.Equal(t, []diffAnchor{{3, 0, 1}}, histogramAnchors([]rune("AxxB"), []rune("BxxA")))
+	// the rarest line wins over the longer region
+	assert.Equal(t, []diffAnchor{{4, 3, 1}},
+		histogramAnchors([]rune("xyxyC"), []rune("xyxC")))
+	assert.Nil(t, histogramAnchors([]rune("ab"), []rune("cd")))
+}
+
+func TestDiffAlgorithmFallback(t *testing.T) {
+	assert.Equal(t, DiffAlgorithms[DiffAlgorithmMyers], diffAlgorithm(""))
+	assert.Equal(t, DiffAlgorithms[DiffAlgorithmMyers], diffAlgorithm("whatever"))
+	assert.Equal(t, DiffAlgorithms[DiffAlgorithmPatience], diffAlgorithm(DiffAlgorithmPatience))
+}
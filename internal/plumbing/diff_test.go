@@ -20,15 +20,32 @@ func TestFileDiffMeta(t *testing.T) {
 	assert.Equal(t, fd.Name(), "FileDiff")
 	assert.Equal(t, len(fd.Provides()), 1)
 	assert.Equal(t, fd.Provides()[0], items.DependencyFileDiff)
-	assert.Equal(t, len(fd.Requires()), 2)
+	assert.Equal(t, len(fd.Requires()), 4)
 	assert.Equal(t, fd.Requires()[0], items.DependencyTreeChanges)
 	assert.Equal(t, fd.Requires()[1], items.DependencyBlobCache)
-	assert.Len(t, fd.ListConfigurationOptions(), 1)
+	assert.Equal(t, fd.Requires()[2], items.DependencyBlobClasses)
+	assert.Equal(t, fd.Requires()[3], items.DependencyTreeChangeKinds)
+	assert.Len(t, fd.ListConfigurationOptions(), 5)
 	assert.Equal(t, fd.ListConfigurationOptions()[0].Name, items.ConfigFileDiffDisableCleanup)
+	assert.Equal(t, fd.ListConfigurationOptions()[1].Name, items.ConfigFileDiffAlgorithm)
+	assert.Equal(t, fd.ListConfigurationOptions()[2].Name, items.ConfigFileDiffIgnoreSpaceChange)
+	assert.Equal(t, fd.ListConfigurationOptions()[3].Name, items.ConfigFileDiffIgnoreCRAtEOL)
+	assert.Equal(t, fd.ListConfigurationOptions()[4].Name, items.ConfigFileDiffIgnoreIndentation)
 	facts := map[string]interface{}{}
 	facts[items.ConfigFileDiffDisableCleanup] = true
+	facts[items.ConfigFileDiffAlgorithm] = items.DiffAlgorithmHistogram
+	facts[items.ConfigFileDiffIgnoreSpaceChange] = true
+	facts[items.ConfigFileDiffIgnoreCRAtEOL] = true
+	facts[items.ConfigFileDiffIgnoreIndentation] = true
 	fd.Configure(facts)
 	assert.True(t, fd.CleanupDisabled)
+	assert.Equal(t, items.DiffAlgorithmHistogram, fd.Algorithm)
+	assert.True(t, fd.IgnoreSpaceChange)
+	assert.True(t, fd.IgnoreCRAtEOL)
+	assert.True(t, fd.IgnoreIndentation)
+	facts[items.ConfigFileDiffAlgorithm] = "whatever"
+	fd.Configure(facts)
+	assert.Equal(t, items.DiffAlgorithmMyers, fd.Algorithm)
 }
 
 func TestFileDiffRegistration(t *testing.T) {
@@ -281,4 +298,82 @@ func TestFileDiffFork(t *testing.T) {
 	fd2 := clones[0].(*items.FileDiff)
 	assert.True(t, fd1 == fd2)
 	fd1.Merge([]core.PipelineItem{fd2})
-}
\ No newline at end of file
+}
+
+func TestFileDiffIgnoreWhitespace(t *testing.T) {
+	blobFrom := test.FakeBlob("func main() {\n\tfoo(a, b)\n\tbar()\n}\n")
+	blobTo := test.FakeBlob("func main() {\r\n    foo(a,  b)  \r\n        bar()\r\n}")
+	cache := map[plumbing.Hash]*object.Blob{blobFrom.Hash: blobFrom, blobTo.Hash: blobTo}
+	deps := map[string]interface{}{
+		items.DependencyBlobCache: cache,
+		items.DependencyTreeChanges: object.Changes{&object.Change{
+			From: object.ChangeEntry{Name: "main.go", TreeEntry: object.TreeEntry{Hash: blobFrom.Hash}},
+			To:   object.ChangeEntry{Name: "main.go", TreeEntry: object.TreeEntry{Hash: blobTo.Hash}},
+		}},
+	}
+	changedLines := func(fd *items.FileDiff) int {
+		res, err := fd.Consume(deps)
+		assert.Nil(t, err)
+		diff := res[items.DependencyFileDiff].(map[string]items.FileDiffData)["main.go"]
+		assert.Equal(t, 4, diff.OldLinesOfCode)
+		assert.Equal(t, 4, diff.NewLinesOfCode)
+		changed := 0
+		for _, edit := range diff.Diffs {
+			if edit.Type == diffmatchpatch.DiffInsert {
+				changed += utf8.RuneCountInString(edit.Text)
+			}
+		}
+		return changed
+	}
+	fd := &items.FileDiff{}
+	assert.Equal(t, 4, changedLines(fd))
+	fd.IgnoreCRAtEOL = true
+	// the missing newline at the end does not matter, the indentation does
+	assert.Equal(t, 2, changedLines(fd))
+	fd.IgnoreCRAtEOL = false
+	fd.IgnoreSpaceChange = true
+	assert.Equal(t, 0, changedLines(fd))
+	fd.IgnoreSpaceChange = false
+	fd.IgnoreIndentation = true
+	fd.IgnoreCRAtEOL = true
+	// "foo(a,  b)  " still differs
+	assert.Equal(t, 1, changedLines(fd))
+}
+
+func TestFileDiffBlobClasses(t *testing.T) {
+	blobFrom := test.FakeBlob("a\nb\nc\n")
+	blobTo := test.FakeBlob("a\nb\nc\nd\n")
+	cache := map[plumbing.Hash]*object.Blob{blobFrom.Hash: blobFrom, blobTo.Hash: blobTo}
+	deps := map[string]interface{}{
+		items.DependencyBlobCache: cache,
+		items.DependencyBlobClasses: items.BlobClasses{
+			Classes: map[plumbing.Hash]items.BlobClass{blobTo.Hash: items.BlobTooLarge},
+			Policy:  items.BlobPolicySkip,
+		},
+		items.DependencyTreeChanges: object.Changes{&object.Change{
+			From: object.ChangeEntry{Name: "data.csv", TreeEntry: object.TreeEntry{Hash: blobFrom.Hash}},
+			To:   object.ChangeEntry{Name: "data.csv", TreeEntry: object.TreeEntry{Hash: blobTo.Hash}},
+		}},
+	}
+	fd := &items.FileDiff{}
+	res, err := fd.Consume(deps)
+	assert.Nil(t, err)
+	diff := res[items.DependencyFileDiff].(map[string]items.FileDiffData)["data.csv"]
+	// the file stops being tracked
+	assert.Equal(t, 3, diff.OldLinesOfCode)
+	assert.Equal(t, 0, diff.NewLinesOfCode)
+	assert.Len(t, diff.Diffs, 1)
+	assert.Equal(t, diffmatchpatch.DiffDelete, diff.Diffs[0].Type)
+	classes := deps[items.DependencyBlobClasses].(items.BlobClasses)
+	classes.Policy = items.BlobPolicyCount
+	deps[items.DependencyBlobClasses] = classes
+	res, err = fd.Consume(deps)
+	assert.Nil(t, err)
+	diff = res[items.DependencyFileDiff].(map[string]items.FileDiffData)["data.csv"]
+	// all the lines are replaced instead of appending one
+	assert.Equal(t, 3, diff.OldLinesOfCode)
+	assert.Equal(t, 4, diff.NewLinesOfCode)
+	assert.Len(t, diff.Diffs, 2)
+	assert.Equal(t, diffmatchpatch.DiffInsert, diff.Diffs[1].Type)
+	assert.Equal(t, 4, utf8.RuneCountInString(diff.Diffs[1].Text))
+}
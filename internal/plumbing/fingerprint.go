@@ -0,0 +1,144 @@
+package plumbing
+
+import (
+	"math"
+	"sort"
+
+	"gopkg.in/src-d/hercules.v4/internal"
+)
+
+const (
+	// minhashSize is the number of hash functions in a MinHash signature.
+	minhashSize = 64
+	// minhashBands is the number of LSH bands, each band consists of
+	// minhashSize / minhashBands signature values.
+	minhashBands = 16
+	// minhashRows is the number of signature values in each LSH band.
+	minhashRows = minhashSize / minhashBands
+)
+
+// minhashSeeds randomize the hash functions of MinHash. They are constant to keep
+// the results reproducible.
+var minhashSeeds = func() [minhashSize]uint64 {
+	var seeds [minhashSize]uint64
+	for i := range seeds {
+		seeds[i] = mix64(uint64(i+1) * 0x9e3779b97f4a7c15)
+	}
+	return seeds
+}()
+
+// mix64 is the finalizer of SplitMix64, a cheap and well distributed hash of an integer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// lineFingerprint is the MinHash signature of the multiset of lines in a text. The repeated
+// lines are distinct elements: the line and the number of its previous occurrences.
+// The Jaccard similarity of two multisets is estimated as the share of equal signature values.
+type lineFingerprint struct {
+	signature [minhashSize]uint64
+	// lines is the number of lines.
+	lines int
+}
+
+// newLineFingerprint calculates the MinHash signature of the lines in `text`.
+func newLineFingerprint(text string) *lineFingerprint {
+	fp := &lineFingerprint{}
+	for i := range fp.signature {
+		fp.signature[i] = math.MaxUint64
+	}
+	occurrences := map[uint64]uint64{}
+	add := func(hash uint64) {
+		element := mix64(hash + occurrences[hash]*0x9e3779b97f4a7c15)
+		occurrences[hash]++
+		for i, seed := range minhashSeeds {
+			if value := mix64(element ^ seed); value < fp.signature[i] {
+				fp.signature[i] = value
+			}
+		}
+		fp.lines++
+	}
+	// FNV-1a
+	hash := uint64(14695981039346656037)
+	for i := 0; i < len(text); i++ {
+		hash ^= uint64(text[i])
+		hash *= 1099511628211
+		if text[i] == '\n' || i == len(text)-1 {
+			add(hash)
+			hash = 14695981039346656037
+		}
+	}
+	return fp
+}
+
+// jaccard estimates the Jaccard similarity of the line multisets.
+func (fp *lineFingerprint) jaccard(other *lineFingerprint) float64 {
+	equal := 0
+	for i, value := range fp.signature {
+		if value == other.signature[i] {
+			equal++
+		}
+	}
+	return float64(equal) / minhashSize
+}
+
+// similarity estimates the percentage of the common lines relative to the smaller text,
+// the same units as RenameAnalysis.SimilarityThreshold.
+func (fp *lineFingerprint) similarity(other *lineFingerprint) int {
+	jaccard := fp.jaccard(other)
+	// |A ∩ B| = J * (|A| + |B|) / (1 + J)
+	common := jaccard * float64(fp.lines+other.lines) / (1 + jaccard)
+	return int(common * 100 / float64(internal.Max(1, internal.Min(fp.lines, other.lines))))
+}
+
+// bands returns the LSH keys of the signature, one per band.
+func (fp *lineFingerprint) bands() [minhashBands]uint64 {
+	var keys [minhashBands]uint64
+	for band := range keys {
+		key := uint64(band)
+		for _, value := range fp.signature[band*minhashRows : (band+1)*minhashRows] {
+			key = mix64(key ^ value)
+		}
+		keys[band] = key
+	}
+	return keys
+}
+
+// fingerprintIndex is the locality-sensitive hashing index of lineFingerprint-s. Two
+// fingerprints collide if at least one of their bands is equal, which is likely for
+// similar texts and unlikely for different.
+type fingerprintIndex struct {
+	buckets map[uint64][]int
+}
+
+func newFingerprintIndex() *fingerprintIndex {
+	return &fingerprintIndex{buckets: map[uint64][]int{}}
+}
+
+// Add inserts the fingerprint with the specified identifier.
+func (index *fingerprintIndex) Add(id int, fp *lineFingerprint) {
+	for _, key := range fp.bands() {
+		index.buckets[key] = append(index.buckets[key], id)
+	}
+}
+
+// Query returns the sorted identifiers of the fingerprints which collide with `fp`.
+func (index *fingerprintIndex) Query(fp *lineFingerprint) []int {
+	seen := map[int]bool{}
+	result := []int{}
+	for _, key := range fp.bands() {
+		for _, id := range index.buckets[key] {
+			if !seen[id] {
+				seen[id] = true
+				result = append(result, id)
+			}
+		}
+	}
+	sort.Ints(result)
+	return result
+}
@@ -0,0 +1,60 @@
+package plumbing
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureLines(prefix string, n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s %d", prefix, i)
+	}
+	return lines
+}
+
+func TestLineFingerprint(t *testing.T) {
+	lines := fixtureLines("line", 100)
+	text := strings.Join(lines, "\n") + "\n"
+	fp1 := newLineFingerprint(text)
+	assert.Equal(t, 100, fp1.lines)
+	assert.Equal(t, fp1, newLineFingerprint(text))
+	assert.Equal(t, 1.0, fp1.jaccard(fp1))
+	assert.Equal(t, 100, fp1.similarity(fp1))
+	for i := 0; i < 10; i++ {
+		lines[i*10] = "changed"
+	}
+	fp2 := newLineFingerprint(strings.Join(lines, "\n") + "\n")
+	assert.Equal(t, 100, fp2.lines)
+	assert.InDelta(t, 90, fp1.similarity(fp2), 10)
+	fp3 := newLineFingerprint(strings.Join(fixtureLines("other", 100), "\n"))
+	assert.InDelta(t, 0, fp1.similarity(fp3), 5)
+	empty := newLineFingerprint("")
+	assert.Equal(t, 0, empty.lines)
+	assert.Equal(t, 0, fp1.similarity(empty))
+	// duplicate lines matter, but not the order
+	assert.NotEqual(t, newLineFingerprint("a\nb\n").signature, newLineFingerprint("a\nb\na\nb\n").signature)
+	assert.Equal(t, newLineFingerprint("a\nb\na\nb\n").signature, newLineFingerprint("b\nb\na\na\n").signature)
+	assert.Equal(t, 4, newLineFingerprint("a\nb\na\nb\n").lines)
+	repeated := append(fixtureLines("line", 10), strings.Split(strings.Repeat("}\n", 90), "\n")...)
+	fp4 := newLineFingerprint(strings.Join(repeated, "\n"))
+	for i := 0; i < 5; i++ {
+		repeated[i] = "changed"
+	}
+	assert.InDelta(t, 95, fp4.similarity(newLineFingerprint(strings.Join(repeated, "\n"))), 10)
+}
+
+func TestFingerprintIndex(t *testing.T) {
+	index := newFingerprintIndex()
+	lines := fixtureLines("line", 100)
+	index.Add(0, newLineFingerprint(strings.Join(lines, "\n")))
+	index.Add(1, newLineFingerprint(strings.Join(fixtureLines("other", 100), "\n")))
+	index.Add(2, newLineFingerprint(strings.Join(lines, "\n")))
+	lines[50] = "changed"
+	assert.Equal(t, []int{0, 2}, index.Query(newLineFingerprint(strings.Join(lines, "\n"))))
+	assert.Equal(t, []int{}, index.Query(
+		newLineFingerprint(strings.Join(fixtureLines("third", 100), "\n"))))
+}
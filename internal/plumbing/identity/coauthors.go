@@ -0,0 +1,78 @@
+package identity
+
+import (
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const (
+	// CoAuthorsIgnore is the co-authors policy which credits only the commit author.
+	CoAuthorsIgnore = "ignore"
+	// CoAuthorsSplit is the co-authors policy which divides the credit for the commit
+	// between the author and the co-authors.
+	CoAuthorsSplit = "split"
+	// CoAuthorsDuplicate is the co-authors policy which gives the full credit for the commit
+	// to the author and to each of the co-authors.
+	CoAuthorsDuplicate = "duplicate"
+
+	// coAuthoredByTrailer is the commit message trailer which names a co-author, e.g.
+	// "Co-authored-by: Vadim Markovtsev <vadim@sourced.tech>". The case is ignored.
+	coAuthoredByTrailer = "co-authored-by:"
+)
+
+// ParseCoAuthors extracts the signatures from the "Co-authored-by:" trailers of the commit
+// message. The trailers must have both the name and the email in angle brackets.
+func ParseCoAuthors(message string) []object.Signature {
+	var result []object.Signature
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) <= len(coAuthoredByTrailer) ||
+			!strings.EqualFold(line[:len(coAuthoredByTrailer)], coAuthoredByTrailer) {
+			continue
+		}
+		value := strings.TrimSpace(line[len(coAuthoredByTrailer):])
+		open := strings.LastIndex(value, "<")
+		end := strings.LastIndex(value, ">")
+		if open < 0 || end < open {
+			continue
+		}
+		signature := object.Signature{
+			Name:  strings.TrimSpace(value[:open]),
+			Email: strings.TrimSpace(value[open+1 : end]),
+		}
+		if signature.Name != "" && signature.Email != "" {
+			result = append(result, signature)
+		}
+	}
+	return result
+}
+
+// CreditedAuthors returns the developers who receive the credit for the commit in `deps`
+// and their teams: only DependencyAuthor and DependencyTeam with CoAuthorsIgnore,
+// otherwise DependencyAuthors and DependencyTeams.
+func CreditedAuthors(deps map[string]interface{}, policy string) (authors []int, teams []int) {
+	if policy == CoAuthorsSplit || policy == CoAuthorsDuplicate {
+		if authors, exists := deps[DependencyAuthors].([]int); exists {
+			return authors, deps[DependencyTeams].([]int)
+		}
+	}
+	team, exists := deps[DependencyTeam].(int)
+	if !exists {
+		team = TeamMissing
+	}
+	return []int{deps[DependencyAuthor].(int)}, []int{team}
+}
+
+// SplitCredit divides `total` between `parts` developers as evenly as possible.
+// The first developers receive the remainder, so the commit author gets the most.
+func SplitCredit(total int, parts int) []int {
+	result := make([]int, parts)
+	for i := range result {
+		result[i] = total / parts
+		if i < total%parts {
+			result[i]++
+		}
+	}
+	return result
+}
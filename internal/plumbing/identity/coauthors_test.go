@@ -0,0 +1,94 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+)
+
+const testCoAuthoredMessage = `Fix the tokenizer
+
+Co-authored-by: Egor Bulychev <egor@sourced.tech>
+co-authored-by:Máximo Cuadros <mcuadros@gmail.com>
+Co-authored-by: nobody
+Co-authored-by: <anonymous@example.com>
+Signed-off-by: Vadim Markovtsev <vadim@sourced.tech>`
+
+func TestParseCoAuthors(t *testing.T) {
+	signatures := ParseCoAuthors(testCoAuthoredMessage)
+	assert.Equal(t, []object.Signature{
+		{Name: "Egor Bulychev", Email: "egor@sourced.tech"},
+		{Name: "Máximo Cuadros", Email: "mcuadros@gmail.com"},
+	}, signatures)
+	assert.Len(t, ParseCoAuthors("Co-authored-by:"), 0)
+	assert.Len(t, ParseCoAuthors(""), 0)
+}
+
+func TestSplitCredit(t *testing.T) {
+	assert.Equal(t, []int{10}, SplitCredit(10, 1))
+	assert.Equal(t, []int{4, 3, 3}, SplitCredit(10, 3))
+	assert.Equal(t, []int{1, 1, 0}, SplitCredit(2, 3))
+	assert.Equal(t, []int{0, 0}, SplitCredit(0, 2))
+	assert.Len(t, SplitCredit(5, 0), 0)
+}
+
+func TestCreditedAuthors(t *testing.T) {
+	deps := map[string]interface{}{
+		DependencyAuthor: 1, DependencyTeam: 2,
+		DependencyAuthors: []int{1, 0}, DependencyTeams: []int{2, TeamMissing},
+	}
+	authors, teams := CreditedAuthors(deps, CoAuthorsIgnore)
+	assert.Equal(t, []int{1}, authors)
+	assert.Equal(t, []int{2}, teams)
+	authors, teams = CreditedAuthors(deps, "")
+	assert.Equal(t, []int{1}, authors)
+	for _, policy := range []string{CoAuthorsSplit, CoAuthorsDuplicate} {
+		authors, teams = CreditedAuthors(deps, policy)
+		assert.Equal(t, []int{1, 0}, authors)
+		assert.Equal(t, []int{2, TeamMissing}, teams)
+	}
+	authors, teams = CreditedAuthors(map[string]interface{}{DependencyAuthor: 3}, CoAuthorsSplit)
+	assert.Equal(t, []int{3}, authors)
+	assert.Equal(t, []int{TeamMissing}, teams)
+}
+
+func TestIdentityDetectorConsumeCoAuthors(t *testing.T) {
+	id := fixtureIdentityDetector()
+	id.PeopleDict["egor@sourced.tech"] = 1
+	id.ReversedPeopleDict = append(id.ReversedPeopleDict, "Egor")
+	commit := &object.Commit{
+		Author:  object.Signature{Name: "Vadim", Email: "vadim@sourced.tech"},
+		Message: testCoAuthoredMessage + "\nCo-authored-by: Vadim <gmarkhor@gmail.com>",
+	}
+	deps := map[string]interface{}{core.DependencyCommit: commit}
+	res, err := id.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0}, res[DependencyAuthors])
+	assert.Equal(t, []int{TeamMissing}, res[DependencyTeams])
+	id.CoAuthorsPolicy = CoAuthorsSplit
+	res, err = id.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res[DependencyAuthor])
+	assert.Equal(t, []int{0, 1, AuthorMissing}, res[DependencyAuthors])
+	assert.Equal(t, []int{TeamMissing, TeamMissing, TeamMissing}, res[DependencyTeams])
+}
+
+func TestIdentityDetectorConfigureCoAuthors(t *testing.T) {
+	id := fixtureIdentityDetector()
+	facts := map[string]interface{}{
+		FactIdentityDetectorPeopleDict:         id.PeopleDict,
+		FactIdentityDetectorReversedPeopleDict: id.ReversedPeopleDict,
+	}
+	id.Configure(facts)
+	assert.Equal(t, CoAuthorsIgnore, id.CoAuthorsPolicy)
+	assert.Equal(t, CoAuthorsIgnore, facts[FactIdentityDetectorCoAuthorsPolicy])
+	facts[ConfigIdentityDetectorCoAuthors] = CoAuthorsDuplicate
+	id.Configure(facts)
+	assert.Equal(t, CoAuthorsDuplicate, id.CoAuthorsPolicy)
+	assert.Equal(t, CoAuthorsDuplicate, facts[FactIdentityDetectorCoAuthorsPolicy])
+	facts[ConfigIdentityDetectorCoAuthors] = "whatever"
+	id.Configure(facts)
+	assert.Equal(t, CoAuthorsIgnore, id.CoAuthorsPolicy)
+}
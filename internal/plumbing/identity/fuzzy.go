@@ -0,0 +1,376 @@
+package identity
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/src-d/hercules.v4/yaml"
+)
+
+// FuzzyMatch explains why two identities were merged by the heuristics in GeneratePeopleDict().
+type FuzzyMatch struct {
+	// First and Second are the merged identities in the format of ReversedPeopleDict.
+	First, Second string
+	// Confidence is the score of the heuristic, from 0 to 1.
+	Confidence float64
+	// Heuristic is the name of the rule which matched.
+	Heuristic string
+}
+
+const (
+	// githubNoreplyDomain is the email domain which GitHub uses to hide the real emails.
+	// The local part is either "login" or "id+login".
+	githubNoreplyDomain = "@users.noreply.github.com"
+	// minFuzzyLength is the minimum length of the compared name or email local part
+	// for edit distance and exact local part matching.
+	minFuzzyLength = 4
+)
+
+// Confidence of each heuristic in mergeFuzzy().
+const (
+	confidenceSameName       = 0.95
+	confidenceNoreply        = 0.9
+	confidenceEmailLocalPart = 0.85
+	confidenceLoginName      = 0.85
+	confidenceEmailName      = 0.8
+	confidenceInitials       = 0.75
+	// edit distance scales the similarity of the names
+	confidenceEditDistance = 0.9
+)
+
+// genericLocalParts are the email local parts which are shared by unrelated people.
+var genericLocalParts = map[string]bool{
+	"admin": true, "bot": true, "build": true, "ci": true, "contact": true, "dev": true,
+	"git": true, "github": true, "info": true, "mail": true, "noreply": true, "root": true,
+	"support": true, "test": true, "user": true,
+}
+
+// mergeFuzzy joins the identities which were not merged by the exact names or emails
+// but look the same according to the heuristics with confidence not less than FuzzyThreshold.
+// The merges are transitive. `names` and `emails` are indexed by the identity and are updated
+// in-place together with `dict`. The new number of identities is returned and the explanations
+// are written to FuzzyMatches.
+func (detector *Detector) mergeFuzzy(
+	dict map[string]int, names, emails map[int][]string, size int) int {
+	parents := make([]int, size)
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+	describe := func(i int) string {
+		ns := append([]string{}, names[i]...)
+		es := append([]string{}, emails[i]...)
+		sort.Strings(ns)
+		sort.Strings(es)
+		return strings.Join(ns, "|") + "|" + strings.Join(es, "|")
+	}
+	detector.FuzzyMatches = nil
+	for i := 0; i < size; i++ {
+		for j := i + 1; j < size; j++ {
+			ri, rj := find(i), find(j)
+			if ri == rj {
+				continue
+			}
+			confidence, heuristic := matchIdentities(names[i], emails[i], names[j], emails[j])
+			if confidence < detector.FuzzyThreshold {
+				continue
+			}
+			detector.FuzzyMatches = append(detector.FuzzyMatches, FuzzyMatch{
+				First: describe(i), Second: describe(j),
+				Confidence: confidence, Heuristic: heuristic,
+			})
+			// the root is always the smallest index
+			if ri < rj {
+				parents[rj] = ri
+			} else {
+				parents[ri] = rj
+			}
+		}
+	}
+	newIDs := make([]int, size)
+	newSize := 0
+	for i := range newIDs {
+		if root := find(i); root == i {
+			newIDs[i] = newSize
+			newSize++
+		} else {
+			newIDs[i] = newIDs[root]
+		}
+	}
+	newNames := map[int][]string{}
+	newEmails := map[int][]string{}
+	for i := 0; i < size; i++ {
+		newNames[newIDs[i]] = append(newNames[newIDs[i]], names[i]...)
+		newEmails[newIDs[i]] = append(newEmails[newIDs[i]], emails[i]...)
+		delete(names, i)
+		delete(emails, i)
+	}
+	for i := 0; i < newSize; i++ {
+		names[i] = newNames[i]
+		emails[i] = newEmails[i]
+	}
+	for key, id := range dict {
+		dict[key] = newIDs[id]
+	}
+	return newSize
+}
+
+// matchIdentities returns the best confidence that the two identities belong to the same
+// person and the name of the corresponding heuristic. Similar names alone are not enough,
+// e.g. "John Smith" and "Joan Smith" are different people: the initials and the edit distance
+// are taken into account only if the emails match too, and then they raise the confidence.
+func matchIdentities(names1, emails1, names2, emails2 []string) (float64, string) {
+	best, heuristic := 0.0, ""
+	update := func(confidence float64, name string) {
+		if confidence > best {
+			best = confidence
+			heuristic = name
+		}
+	}
+	for _, e1 := range emails1 {
+		for _, e2 := range emails2 {
+			update(matchEmails(e1, e2))
+		}
+		for _, n2 := range names2 {
+			update(matchNameEmail(n2, e1))
+		}
+	}
+	for _, n1 := range names1 {
+		for _, e2 := range emails2 {
+			update(matchNameEmail(n1, e2))
+		}
+	}
+	emailBest, emailHeuristic := best, heuristic
+	for _, n1 := range names1 {
+		for _, n2 := range names2 {
+			confidence, name := matchNames(n1, n2)
+			if name == "normalized name" {
+				update(confidence, name)
+			} else if emailBest > 0 && confidence > 0 {
+				// the names and the emails are independent evidence
+				update(1-(1-confidence)*(1-emailBest), name+" and "+emailHeuristic)
+			}
+		}
+	}
+	return best, heuristic
+}
+
+// matchNames compares two developer names.
+func matchNames(name1, name2 string) (float64, string) {
+	tokens1 := nameTokens(name1)
+	tokens2 := nameTokens(name2)
+	if len(tokens1) == 0 || len(tokens2) == 0 {
+		return 0, ""
+	}
+	sorted1 := sortedCopy(tokens1)
+	sorted2 := sortedCopy(tokens2)
+	norm1 := strings.Join(sorted1, " ")
+	norm2 := strings.Join(sorted2, " ")
+	if norm1 == norm2 {
+		return confidenceSameName, "normalized name"
+	}
+	if matchInitials(tokens1, tokens2) {
+		return confidenceInitials, "initials"
+	}
+	runes1 := []rune(norm1)
+	runes2 := []rune(norm2)
+	maxLen := len(runes1)
+	if len(runes2) > maxLen {
+		maxLen = len(runes2)
+	}
+	if len(runes1) < minFuzzyLength+2 || len(runes2) < minFuzzyLength+2 {
+		return 0, ""
+	}
+	similarity := 1 - float64(levenshtein(runes1, runes2))/float64(maxLen)
+	return confidenceEditDistance * similarity, "edit distance"
+}
+
+// matchInitials checks whether the names are the same except that some of the parts
+// are shortened to the initials, e.g. "J. Smith" and "John Smith".
+func matchInitials(tokens1, tokens2 []string) bool {
+	if len(tokens1) != len(tokens2) || len(tokens1) < 2 {
+		return false
+	}
+	full, initials := 0, 0
+	for i, t1 := range tokens1 {
+		t2 := tokens2[i]
+		r1, r2 := []rune(t1), []rune(t2)
+		switch {
+		case t1 == t2 && len(r1) > 1:
+			full++
+		case len(r1) == 1 && r2[0] == r1[0], len(r2) == 1 && r1[0] == r2[0]:
+			initials++
+		default:
+			return false
+		}
+	}
+	return full > 0 && initials > 0
+}
+
+// matchEmails compares two emails by their local parts and GitHub logins.
+func matchEmails(email1, email2 string) (float64, string) {
+	login1, login2 := githubLogin(email1), githubLogin(email2)
+	local1, local2 := emailLocalPart(email1), emailLocalPart(email2)
+	if login1 != "" && (login1 == login2 || login1 == local2) ||
+		login2 != "" && login2 == local1 {
+		return confidenceNoreply, "GitHub noreply email"
+	}
+	if local1 != "" && local1 == local2 &&
+		len([]rune(local1)) >= minFuzzyLength && !genericLocalParts[local1] {
+		return confidenceEmailLocalPart, "email local part"
+	}
+	return 0, ""
+}
+
+// matchNameEmail checks whether the email local part is derived from the name, e.g.
+// "John Smith" and "john.smith@gmail.com" or "jsmith@corp".
+func matchNameEmail(name, email string) (float64, string) {
+	local := githubLogin(email)
+	if local == "" {
+		local = emailLocalPart(email)
+	}
+	if local == "" || genericLocalParts[local] {
+		return 0, ""
+	}
+	tokens := nameTokens(name)
+	switch len(tokens) {
+	case 0:
+		return 0, ""
+	case 1:
+		// the name is the login
+		if tokens[0] == local && len([]rune(local)) >= minFuzzyLength {
+			return confidenceLoginName, "login name"
+		}
+		return 0, ""
+	}
+	if strings.Join(tokens, "") == local {
+		return confidenceEmailName, "email derived from name"
+	}
+	first := []rune(tokens[0])
+	if string(first[:1])+tokens[len(tokens)-1] == local {
+		return confidenceEmailName, "email derived from name"
+	}
+	return 0, ""
+}
+
+// nameTokens splits the lower case name by everything except letters and digits.
+func nameTokens(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// emailLocalPart returns the lower case part before "@" without the "+" suffix and
+// the separators ".", "-" and "_".
+func emailLocalPart(email string) string {
+	email = strings.ToLower(email)
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	local := email[:at]
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	return localPartReplacer.Replace(local)
+}
+
+// localPartReplacer removes the separators from the email local parts and the logins.
+var localPartReplacer = strings.NewReplacer(".", "", "-", "", "_", "")
+
+// githubLogin extracts the lower case login without the separators from a GitHub noreply
+// email or returns "".
+func githubLogin(email string) string {
+	email = strings.ToLower(email)
+	if !strings.HasSuffix(email, githubNoreplyDomain) {
+		return ""
+	}
+	local := email[:len(email)-len(githubNoreplyDomain)]
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[plus+1:]
+	}
+	return localPartReplacer.Replace(local)
+}
+
+func sortedCopy(strs []string) []string {
+	result := append([]string{}, strs...)
+	sort.Strings(result)
+	return result
+}
+
+// levenshtein calculates the edit distance between two rune sequences.
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// WriteIdentities prints ReversedPeopleDict and FuzzyMatches as the structured people dictionary
+// which can be reviewed, edited and loaded with LoadIdentities(). The longest name of each
+// developer becomes the canonical one.
+func (detector *Detector) WriteIdentities(writer io.Writer) {
+	fmt.Fprintf(writer, "# %d developers\n", len(detector.ReversedPeopleDict))
+	if len(detector.FuzzyMatches) > 0 {
+		fmt.Fprintln(writer, "# merged by the heuristics:")
+		for _, match := range detector.FuzzyMatches {
+			fmt.Fprintf(writer, "#   %s + %s: %s, %.2f\n",
+				match.First, match.Second, match.Heuristic, match.Confidence)
+		}
+	}
+	fmt.Fprintln(writer, "people:")
+	for _, person := range detector.ReversedPeopleDict {
+		var aliases []string
+		for _, alias := range strings.Split(person, "|") {
+			if alias != "" {
+				aliases = append(aliases, alias)
+			}
+		}
+		if len(aliases) == 0 {
+			continue
+		}
+		name := ""
+		for _, alias := range aliases {
+			if !strings.Contains(alias, "@") && len([]rune(alias)) > len([]rune(name)) {
+				name = alias
+			}
+		}
+		if name == "" {
+			name = aliases[0]
+		}
+		quoted := make([]string, len(aliases))
+		for i, alias := range aliases {
+			quoted[i] = yaml.SafeString(alias)
+		}
+		fmt.Fprintf(writer, "  - name: %s\n", yaml.SafeString(name))
+		fmt.Fprintf(writer, "    aliases: [%s]\n", strings.Join(quoted, ", "))
+	}
+}
@@ -0,0 +1,191 @@
+package identity
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyMatchNames(t *testing.T) {
+	confidence, heuristic := matchNames("Vadim Markovtsev", "markovtsev, vadim")
+	assert.Equal(t, confidenceSameName, confidence)
+	assert.Equal(t, "normalized name", heuristic)
+	confidence, heuristic = matchNames("V. Markovtsev", "Vadim Markovtsev")
+	assert.Equal(t, confidenceInitials, confidence)
+	assert.Equal(t, "initials", heuristic)
+	confidence, heuristic = matchNames("Vadim Markovtsev", "Vadim Markovcev")
+	assert.Equal(t, "edit distance", heuristic)
+	assert.InDelta(t, 0.9*(1-2.0/16), confidence, 1e-6)
+	confidence, _ = matchNames("Vadim", "Vadik")
+	assert.Equal(t, 0.0, confidence)
+	confidence, _ = matchNames("Vadim Markovtsev", "Egor Bulychev")
+	assert.True(t, confidence < 0.5)
+	confidence, _ = matchNames("", "Egor")
+	assert.Equal(t, 0.0, confidence)
+}
+
+func TestFuzzyMatchEmails(t *testing.T) {
+	confidence, heuristic := matchEmails(
+		"2793551+vmarkovtsev@users.noreply.github.com", "vmarkovtsev@gmail.com")
+	assert.Equal(t, confidenceNoreply, confidence)
+	assert.Equal(t, "GitHub noreply email", heuristic)
+	confidence, _ = matchEmails(
+		"v-markovtsev@users.noreply.github.com", "vmarkovtsev@users.noreply.github.com")
+	assert.Equal(t, confidenceNoreply, confidence)
+	confidence, heuristic = matchEmails("vadim.markovtsev@gmail.com", "vadim_markovtsev+git@corp.com")
+	assert.Equal(t, confidenceEmailLocalPart, confidence)
+	assert.Equal(t, "email local part", heuristic)
+	confidence, _ = matchEmails("admin@one.com", "admin@two.com")
+	assert.Equal(t, 0.0, confidence)
+	confidence, _ = matchEmails("vm@one.com", "vm@two.com")
+	assert.Equal(t, 0.0, confidence)
+	confidence, _ = matchEmails("vadim@one.com", "egor@one.com")
+	assert.Equal(t, 0.0, confidence)
+}
+
+func TestFuzzyMatchNameEmail(t *testing.T) {
+	confidence, heuristic := matchNameEmail("Vadim Markovtsev", "vadim.markovtsev@gmail.com")
+	assert.Equal(t, confidenceEmailName, confidence)
+	assert.Equal(t, "email derived from name", heuristic)
+	confidence, _ = matchNameEmail("Vadim Markovtsev", "vmarkovtsev@corp.com")
+	assert.Equal(t, confidenceEmailName, confidence)
+	confidence, heuristic = matchNameEmail("vmarkovtsev", "1+vmarkovtsev@users.noreply.github.com")
+	assert.Equal(t, confidenceLoginName, confidence)
+	assert.Equal(t, "login name", heuristic)
+	confidence, _ = matchNameEmail("Vadim Markovtsev", "egor@corp.com")
+	assert.Equal(t, 0.0, confidence)
+	confidence, _ = matchNameEmail("root", "root@localhost")
+	assert.Equal(t, 0.0, confidence)
+}
+
+func TestFuzzyMatchIdentities(t *testing.T) {
+	// similar names of different people
+	confidence, _ := matchNames("John Smith", "Joan Smith")
+	assert.True(t, confidence > 0.8)
+	confidence, heuristic := matchIdentities(
+		[]string{"john smith"}, []string{"john@one.com"},
+		[]string{"joan smith"}, []string{"joan@two.com"})
+	assert.Equal(t, 0.0, confidence)
+	assert.Equal(t, "", heuristic)
+	confidence, heuristic = matchIdentities(
+		[]string{"smith, john"}, []string{"john@one.com"},
+		[]string{"john smith"}, []string{"joan@two.com"})
+	assert.Equal(t, confidenceSameName, confidence)
+	assert.Equal(t, "normalized name", heuristic)
+	// the email corroborates the similar name
+	confidence, heuristic = matchIdentities(
+		[]string{"john smith"}, []string{"jsmith@one.com"},
+		[]string{"jon smith"}, []string{"jsmith@two.com"})
+	assert.Equal(t, "edit distance and email local part", heuristic)
+	assert.True(t, confidence > confidenceEmailLocalPart)
+	assert.True(t, confidence < 1)
+}
+
+func TestFuzzyHelpers(t *testing.T) {
+	assert.Equal(t, 0, levenshtein([]rune("abc"), []rune("abc")))
+	assert.Equal(t, 3, levenshtein([]rune("kitten"), []rune("sitting")))
+	assert.Equal(t, 4, levenshtein([]rune(""), []rune("four")))
+	assert.Equal(t, "vmarkovtsev", githubLogin("123+VMarkovtsev@users.noreply.github.com"))
+	assert.Equal(t, "vmarkovtsev", githubLogin("v-markovtsev@users.noreply.github.com"))
+	assert.Equal(t, "", githubLogin("vmarkovtsev@gmail.com"))
+	assert.Equal(t, "vadimmarkovtsev", emailLocalPart("Vadim.Markovtsev+spam@gmail.com"))
+	assert.Equal(t, "", emailLocalPart("nobody"))
+	assert.Equal(t, []string{"máximo", "cuadros"}, nameTokens("Máximo  Cuadros-"))
+}
+
+func TestFuzzyMerge(t *testing.T) {
+	dict := map[string]int{
+		"vadim markovtsev": 0, "vadim@sourced.tech": 0,
+		"egor": 1, "egor@sourced.tech": 1,
+		"vmarkovtsev": 2, "2793551+vmarkovtsev@users.noreply.github.com": 2,
+		"v. markovtsev": 3, "vmarkovtsev@gmail.com": 3,
+		"egor bulychev": 4, "bulychev@corp.com": 4,
+	}
+	names := map[int][]string{
+		0: {"vadim markovtsev"}, 1: {"egor"}, 2: {"vmarkovtsev"}, 3: {"v. markovtsev"},
+		4: {"egor bulychev"},
+	}
+	emails := map[int][]string{
+		0: {"vadim@sourced.tech"}, 1: {"egor@sourced.tech"},
+		2: {"2793551+vmarkovtsev@users.noreply.github.com"}, 3: {"vmarkovtsev@gmail.com"},
+		4: {"bulychev@corp.com"},
+	}
+	detector := Detector{FuzzyThreshold: 0.75}
+	size := detector.mergeFuzzy(dict, names, emails, 5)
+	assert.Equal(t, 3, size)
+	assert.Equal(t, 0, dict["vadim markovtsev"])
+	assert.Equal(t, 0, dict["v. markovtsev"])
+	assert.Equal(t, 0, dict["vmarkovtsev"])
+	assert.Equal(t, 1, dict["egor"])
+	assert.Equal(t, 2, dict["egor bulychev"])
+	assert.Equal(t, []string{"vadim markovtsev", "vmarkovtsev", "v. markovtsev"}, names[0])
+	assert.Len(t, emails[0], 3)
+	assert.Len(t, names, 3)
+	assert.Len(t, detector.FuzzyMatches, 2)
+	for _, match := range detector.FuzzyMatches {
+		assert.True(t, match.Confidence >= 0.75)
+		assert.NotEmpty(t, match.Heuristic)
+	}
+	detector = Detector{FuzzyThreshold: 0.8}
+	names = map[int][]string{0: {"john smith"}, 1: {"joan smith"}, 2: {"joan smyth"}}
+	emails = map[int][]string{0: {"john@one.com"}, 1: {"joan@two.com"}, 2: {"js@three.com"}}
+	dict = map[string]int{"john smith": 0, "joan smith": 1, "joan smyth": 2}
+	assert.Equal(t, 3, detector.mergeFuzzy(dict, names, emails, 3))
+	assert.Len(t, detector.FuzzyMatches, 0)
+	detector = Detector{FuzzyThreshold: 0.99}
+	names = map[int][]string{0: {"a"}, 1: {"a"}}
+	emails = map[int][]string{0: {}, 1: {}}
+	dict = map[string]int{"x": 0, "y": 1}
+	assert.Equal(t, 2, detector.mergeFuzzy(dict, names, emails, 2))
+	assert.Len(t, detector.FuzzyMatches, 0)
+}
+
+func TestFuzzyWriteIdentities(t *testing.T) {
+	detector := Detector{
+		ReversedPeopleDict: []string{
+			"vadim markovtsev|vmarkovtsev|vadim@sourced.tech|vmarkovtsev@gmail.com",
+			"egor|egor@sourced.tech",
+			"|nobody@localhost",
+		},
+		FuzzyMatches: []FuzzyMatch{{
+			First: "vadim markovtsev|vadim@sourced.tech", Second: "vmarkovtsev|vmarkovtsev@gmail.com",
+			Confidence: 0.85, Heuristic: "login name",
+		}},
+	}
+	buffer := &bytes.Buffer{}
+	detector.WriteIdentities(buffer)
+	assert.Equal(t, `# 3 developers
+# merged by the heuristics:
+#   vadim markovtsev|vadim@sourced.tech + vmarkovtsev|vmarkovtsev@gmail.com: login name, 0.85
+people:
+  - name: "vadim markovtsev"
+    aliases: ["vadim markovtsev", "vmarkovtsev", "vadim@sourced.tech", "vmarkovtsev@gmail.com"]
+  - name: "egor"
+    aliases: ["egor", "egor@sourced.tech"]
+  - name: "nobody@localhost"
+    aliases: ["nobody@localhost"]
+`, buffer.String())
+	filePath, cleanup := writeIdentities(t, "people.yaml", buffer.String())
+	defer cleanup()
+	loaded := Detector{}
+	assert.Nil(t, loaded.LoadPeopleDict(filePath))
+	assert.Equal(t, []string{"vadim markovtsev", "egor", "nobody@localhost", AuthorMissingName},
+		loaded.ReversedPeopleDict)
+	assert.Equal(t, 0, loaded.PeopleDict["vmarkovtsev@gmail.com"])
+}
+
+func TestFuzzyConfigureThreshold(t *testing.T) {
+	id := fixtureIdentityDetector()
+	facts := map[string]interface{}{
+		FactIdentityDetectorPeopleDict:         id.PeopleDict,
+		FactIdentityDetectorReversedPeopleDict: id.ReversedPeopleDict,
+		ConfigIdentityDetectorFuzzyThreshold:   float32(0.8),
+	}
+	id.Configure(facts)
+	assert.Equal(t, 0.8, id.FuzzyThreshold)
+	facts[ConfigIdentityDetectorFuzzyThreshold] = 0.75
+	id.Configure(facts)
+	assert.Equal(t, 0.75, id.FuzzyThreshold)
+	assert.Equal(t, float32(0), id.ListConfigurationOptions()[2].Default)
+}
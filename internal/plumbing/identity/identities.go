@@ -0,0 +1,176 @@
+package identity
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// BotsAggregate is the bots policy which merges all the bots into a single pseudo-author.
+	BotsAggregate = "aggregate"
+	// BotsDrop is the bots policy which makes the bots unmatched (AuthorMissing).
+	BotsDrop = "drop"
+	// BotsName is the name of the pseudo-author which represents all the bots
+	// with the BotsAggregate policy.
+	BotsName = "<bots>"
+)
+
+// IdentityMatchers describes the signatures which belong to the same identity.
+type IdentityMatchers struct {
+	// Aliases are the exact names and emails, case-insensitive.
+	Aliases []string `yaml:"aliases"`
+	// Regexps are the regular expressions which are matched against the names and the emails,
+	// case-insensitive.
+	Regexps []string `yaml:"regexps"`
+}
+
+// IdentityRecord is a single developer in the structured people dictionary.
+type IdentityRecord struct {
+	// Name is the canonical name of the developer which appears in the results.
+	// The first alias is used if it is empty.
+	Name             string `yaml:"name"`
+	IdentityMatchers `yaml:",inline"`
+	// Bot marks the automated committers such as dependabot or CI users.
+	Bot bool `yaml:"bot"`
+}
+
+// Identities is the structured people dictionary.
+type Identities struct {
+	People []IdentityRecord `yaml:"people"`
+	// Bots is either BotsAggregate (the default) or BotsDrop.
+	Bots string `yaml:"bots"`
+	// Exclude lists the signatures which are never attributed to anybody.
+	Exclude IdentityMatchers `yaml:"exclude"`
+}
+
+// RegexpIdentity maps the signatures which match Regexp to the developer id.
+type RegexpIdentity struct {
+	Regexp *regexp.Regexp
+	Author int
+}
+
+// ParseIdentities parses the structured people dictionary in YAML or JSON:
+//
+//	people:
+//	  - name: Vadim Markovtsev
+//	    aliases: [vadim@sourced.tech, gmarkhor@gmail.com, vmarkovtsev]
+//	  - name: Máximo Cuadros
+//	    regexps: ['^mcuadros(@.*)?$']
+//	  - name: dependabot
+//	    aliases: [dependabot[bot]]
+//	    bot: true
+//	bots: aggregate
+//	exclude:
+//	  aliases: [root@localhost]
+func ParseIdentities(contents []byte) (*Identities, error) {
+	identities := &Identities{}
+	err := yaml.Unmarshal(contents, identities)
+	if err != nil {
+		return nil, err
+	}
+	switch identities.Bots {
+	case "":
+		identities.Bots = BotsAggregate
+	case BotsAggregate, BotsDrop:
+	default:
+		return nil, fmt.Errorf("unknown bots policy: %s", identities.Bots)
+	}
+	for i, record := range identities.People {
+		if record.Name == "" {
+			if len(record.Aliases) == 0 {
+				return nil, fmt.Errorf("person #%d has neither a name nor aliases", i+1)
+			}
+			identities.People[i].Name = record.Aliases[0]
+		}
+	}
+	return identities, nil
+}
+
+// LoadIdentities reads the structured people dictionary from a YAML or JSON file,
+// see ParseIdentities() for the format. The bots are either merged into BotsName or dropped,
+// the excluded signatures are mapped to AuthorMissing.
+func (detector *Detector) LoadIdentities(path string) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	identities, err := ParseIdentities(contents)
+	if err != nil {
+		return err
+	}
+	dict := map[string]int{}
+	reverseDict := []string{}
+	var regexps []RegexpIdentity
+	add := func(matchers IdentityMatchers, id int) error {
+		for _, alias := range matchers.Aliases {
+			alias = strings.ToLower(alias)
+			if _, exists := dict[alias]; exists {
+				return fmt.Errorf("identity %s is declared twice", alias)
+			}
+			dict[alias] = id
+		}
+		for _, expr := range matchers.Regexps {
+			re, err := regexp.Compile("(?i)" + expr)
+			if err != nil {
+				return err
+			}
+			regexps = append(regexps, RegexpIdentity{Regexp: re, Author: id})
+		}
+		return nil
+	}
+	err = add(identities.Exclude, AuthorMissing)
+	if err != nil {
+		return err
+	}
+	addRecord := func(record IdentityRecord, id int) error {
+		if err := add(record.IdentityMatchers, id); err != nil {
+			return err
+		}
+		// the canonical name is matched, too
+		if _, exists := dict[strings.ToLower(record.Name)]; !exists {
+			dict[strings.ToLower(record.Name)] = id
+		}
+		return nil
+	}
+	var bots []IdentityRecord
+	for _, record := range identities.People {
+		if record.Bot {
+			bots = append(bots, record)
+			continue
+		}
+		if err = addRecord(record, len(reverseDict)); err != nil {
+			return err
+		}
+		reverseDict = append(reverseDict, record.Name)
+	}
+	botsID := AuthorMissing
+	if identities.Bots == BotsAggregate && len(bots) > 0 {
+		botsID = len(reverseDict)
+		reverseDict = append(reverseDict, BotsName)
+	}
+	for _, record := range bots {
+		if err = addRecord(record, botsID); err != nil {
+			return err
+		}
+	}
+	reverseDict = append(reverseDict, AuthorMissingName)
+	detector.PeopleDict = dict
+	detector.ReversedPeopleDict = reverseDict
+	detector.Regexps = regexps
+	return nil
+}
+
+// isIdentitiesFile checks whether the people dictionary should be parsed with LoadIdentities().
+func isIdentitiesFile(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range [...]string{".yaml", ".yml", ".json"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
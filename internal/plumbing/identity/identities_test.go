@@ -0,0 +1,140 @@
+package identity
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+)
+
+const testIdentities = `people:
+  - name: Vadim Markovtsev
+    aliases: [vadim@sourced.tech, gmarkhor@gmail.com]
+  - name: Máximo Cuadros
+    regexps: ['^mcuadros(@.*)?$']
+  - aliases: [Egor, egor@sourced.tech]
+  - name: dependabot
+    aliases: ['dependabot[bot]']
+    bot: true
+  - name: Travis
+    aliases: [travis@travis-ci.org]
+    bot: true
+exclude:
+  aliases: [root@localhost]
+  regexps: ['@example\.com$']
+`
+
+func writeIdentities(t *testing.T, name, contents string) (string, func()) {
+	tmpdir, err := ioutil.TempDir("", "hercules-")
+	assert.Nil(t, err)
+	filePath := path.Join(tmpdir, name)
+	assert.Nil(t, ioutil.WriteFile(filePath, []byte(contents), 0666))
+	return filePath, func() { os.RemoveAll(tmpdir) }
+}
+
+func consumeSignature(id *Detector, name, email string) int {
+	commit := &object.Commit{Author: object.Signature{Name: name, Email: email}}
+	result, _ := id.Consume(map[string]interface{}{core.DependencyCommit: commit})
+	return result[DependencyAuthor].(int)
+}
+
+func TestParseIdentities(t *testing.T) {
+	identities, err := ParseIdentities([]byte(testIdentities))
+	assert.Nil(t, err)
+	assert.Len(t, identities.People, 5)
+	assert.Equal(t, "Vadim Markovtsev", identities.People[0].Name)
+	assert.Equal(t, []string{"vadim@sourced.tech", "gmarkhor@gmail.com"},
+		identities.People[0].Aliases)
+	assert.Equal(t, []string{"^mcuadros(@.*)?$"}, identities.People[1].Regexps)
+	assert.Equal(t, "Egor", identities.People[2].Name)
+	assert.True(t, identities.People[3].Bot)
+	assert.False(t, identities.People[2].Bot)
+	assert.Equal(t, BotsAggregate, identities.Bots)
+	assert.Equal(t, []string{"root@localhost"}, identities.Exclude.Aliases)
+	assert.Equal(t, []string{`@example\.com$`}, identities.Exclude.Regexps)
+	identities, err = ParseIdentities([]byte(
+		`{"people": [{"name": "Vadim", "aliases": ["vadim@sourced.tech"]}], "bots": "drop"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "Vadim", identities.People[0].Name)
+	assert.Equal(t, BotsDrop, identities.Bots)
+	_, err = ParseIdentities([]byte("bots: whatever"))
+	assert.NotNil(t, err)
+	_, err = ParseIdentities([]byte("people:\n  - bot: true"))
+	assert.NotNil(t, err)
+	_, err = ParseIdentities([]byte("people: ["))
+	assert.NotNil(t, err)
+}
+
+func TestIdentityDetectorLoadIdentities(t *testing.T) {
+	filePath, cleanup := writeIdentities(t, "people.yaml", testIdentities)
+	defer cleanup()
+	id := Detector{}
+	assert.Nil(t, id.LoadPeopleDict(filePath))
+	assert.Equal(t, []string{
+		"Vadim Markovtsev", "Máximo Cuadros", "Egor", BotsName, AuthorMissingName,
+	}, id.ReversedPeopleDict)
+	assert.Equal(t, 0, consumeSignature(&id, "Vadim", "VADIM@sourced.tech"))
+	assert.Equal(t, 0, consumeSignature(&id, "vadim markovtsev", "vadim@localhost"))
+	assert.Equal(t, 1, consumeSignature(&id, "Máximo", "mcuadros@gmail.com"))
+	assert.Equal(t, 1, consumeSignature(&id, "MCuadros", "max@localhost"))
+	assert.Equal(t, 2, consumeSignature(&id, "Egor", "bulychev@sourced.tech"))
+	assert.Equal(t, 3, consumeSignature(&id, "dependabot[bot]", "support@dependabot.com"))
+	assert.Equal(t, 3, consumeSignature(&id, "Travis CI", "travis@travis-ci.org"))
+	assert.Equal(t, AuthorMissing, consumeSignature(&id, "Vadim", "root@localhost"))
+	assert.Equal(t, AuthorMissing, consumeSignature(&id, "Somebody", "me@example.com"))
+	assert.Equal(t, AuthorMissing, consumeSignature(&id, "Somebody", "me@sourced.tech"))
+}
+
+func TestIdentityDetectorLoadIdentitiesDropBots(t *testing.T) {
+	filePath, cleanup := writeIdentities(t, "people.json", `{
+  "people": [
+    {"name": "Vadim", "aliases": ["vadim@sourced.tech"]},
+    {"name": "dependabot", "aliases": ["dependabot[bot]"], "bot": true}
+  ],
+  "bots": "drop"
+}`)
+	defer cleanup()
+	id := Detector{}
+	assert.Nil(t, id.LoadPeopleDict(filePath))
+	assert.Equal(t, []string{"Vadim", AuthorMissingName}, id.ReversedPeopleDict)
+	assert.Equal(t, 0, consumeSignature(&id, "Vadim", "vadim@sourced.tech"))
+	assert.Equal(t, AuthorMissing, consumeSignature(&id, "dependabot[bot]", "x@dependabot.com"))
+}
+
+func TestIdentityDetectorLoadIdentitiesErrors(t *testing.T) {
+	id := Detector{}
+	assert.NotNil(t, id.LoadIdentities("/does/not/exist.yaml"))
+	for _, contents := range []string{
+		"people:\n  - aliases: [a, b]\n  - aliases: [B]",
+		"people:\n  - aliases: [a]\nexclude:\n  aliases: [a]",
+		"people:\n  - name: a\n    regexps: ['(']",
+		"bots: whatever",
+	} {
+		filePath, cleanup := writeIdentities(t, "people.yml", contents)
+		assert.NotNil(t, id.LoadPeopleDict(filePath), contents)
+		cleanup()
+	}
+}
+
+func TestIdentityDetectorConfigureIdentities(t *testing.T) {
+	filePath, cleanup := writeIdentities(t, "people.yaml", testIdentities)
+	defer cleanup()
+	id := Detector{}
+	facts := map[string]interface{}{ConfigIdentityDetectorPeopleDictPath: filePath}
+	id.Configure(facts)
+	assert.Equal(t, 4, facts[FactIdentityDetectorPeopleCount])
+	assert.Equal(t, id.ReversedPeopleDict, facts[FactIdentityDetectorReversedPeopleDict])
+	assert.Len(t, id.Regexps, 2)
+}
+
+func TestIsIdentitiesFile(t *testing.T) {
+	assert.True(t, isIdentitiesFile("people.yaml"))
+	assert.True(t, isIdentitiesFile("people.YML"))
+	assert.True(t, isIdentitiesFile("/tmp/people.json"))
+	assert.False(t, isIdentitiesFile("people.txt"))
+	assert.False(t, isIdentitiesFile("people"))
+}
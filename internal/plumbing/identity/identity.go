@@ -2,6 +2,9 @@ package identity
 
 import (
 	"bufio"
+	"io/ioutil"
+	"log"
+	"math"
 	"os"
 	"sort"
 	"strings"
@@ -20,6 +23,24 @@ type Detector struct {
 	PeopleDict map[string]int
 	// ReversedPeopleDict maps developer id -> description
 	ReversedPeopleDict []string
+	// Regexps are checked in order if neither the email nor the name is found in PeopleDict
+	Regexps []RegexpIdentity
+	// FuzzyThreshold enables the heuristics in GeneratePeopleDict() if it is greater than 0
+	FuzzyThreshold float64
+	// FuzzyMatches explains the identities merged by the heuristics
+	FuzzyMatches []FuzzyMatch
+	// Teams maps developer id -> team memberships
+	Teams map[int][]TeamMembership
+	// ReversedTeamsDict maps team id -> team name
+	ReversedTeamsDict []string
+	// CoAuthorsPolicy is CoAuthorsIgnore, CoAuthorsSplit or CoAuthorsDuplicate. The co-authors
+	// are parsed from the commit messages unless it is CoAuthorsIgnore or empty.
+	CoAuthorsPolicy string
+	// Signature is core.SignatureAuthor (the default) or core.SignatureCommitter - whose
+	// identity is resolved.
+	Signature string
+	// Mailmap canonicalizes the signatures before they are looked up in PeopleDict
+	Mailmap *Mailmap
 }
 
 const (
@@ -44,9 +65,45 @@ const (
 	// Detector.Configure(). It is equal to the overall number of unique authors
 	// (the length of ReversedPeopleDict).
 	FactIdentityDetectorPeopleCount = "IdentityDetector.PeopleCount"
+	// ConfigIdentityDetectorFuzzyThreshold is the name of the configuration option
+	// (Detector.Configure()) which sets Detector.FuzzyThreshold.
+	ConfigIdentityDetectorFuzzyThreshold = "IdentityDetector.FuzzyThreshold"
+	// ConfigIdentityDetectorTeamsPath is the name of the configuration option
+	// (Detector.Configure()) which allows to load the team memberships from a file.
+	ConfigIdentityDetectorTeamsPath = "IdentityDetector.TeamsPath"
+	// FactIdentityDetectorReversedTeamsDict is the name of the fact which is inserted in
+	// Detector.Configure() if the teams were loaded. It corresponds to
+	// Detector.ReversedTeamsDict - the mapping from the team indices to the names.
+	FactIdentityDetectorReversedTeamsDict = "IdentityDetector.ReversedTeamsDict"
+	// FactIdentityDetectorTeamsCount is the name of the fact which is inserted in
+	// Detector.Configure() if the teams were loaded. It is equal to the number of teams.
+	FactIdentityDetectorTeamsCount = "IdentityDetector.TeamsCount"
+	// TeamMissing is the internal team index which denotes the developers without a team.
+	TeamMissing = AuthorMissing
+	// ConfigIdentityDetectorCoAuthors is the name of the configuration option
+	// (Detector.Configure()) which sets Detector.CoAuthorsPolicy.
+	ConfigIdentityDetectorCoAuthors = "IdentityDetector.CoAuthors"
+	// ConfigIdentityDetectorMailmapPath is the name of the configuration option
+	// (Detector.Configure()) which allows to load an additional mailmap from a file.
+	// It overrides the repository's .mailmap.
+	ConfigIdentityDetectorMailmapPath = "IdentityDetector.MailmapPath"
+	// FactIdentityDetectorCoAuthorsPolicy is the name of the fact which is inserted in
+	// Detector.Configure(). It is the validated Detector.CoAuthorsPolicy which the people-aware
+	// analyses pass to CreditedAuthors().
+	FactIdentityDetectorCoAuthorsPolicy = "IdentityDetector.CoAuthorsPolicy"
 
 	// DependencyAuthor is the name of the dependency provided by Detector.
 	DependencyAuthor = "author"
+	// DependencyTeam is the name of the dependency provided by Detector.
+	// It is the team of the author at the moment of the commit.
+	DependencyTeam = "team"
+	// DependencyAuthors is the name of the dependency provided by Detector.
+	// It is the list of the distinct developer ids of the author and the co-authors,
+	// the author goes first.
+	DependencyAuthors = "authors"
+	// DependencyTeams is the name of the dependency provided by Detector.
+	// It is the list of the teams which corresponds to DependencyAuthors.
+	DependencyTeams = "teams"
 )
 
 // Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
@@ -58,7 +115,7 @@ func (detector *Detector) Name() string {
 // Each produced entity will be inserted into `deps` of dependent Consume()-s according
 // to this list. Also used by core.Registry to build the global map of providers.
 func (detector *Detector) Provides() []string {
-	arr := [...]string{DependencyAuthor}
+	arr := [...]string{DependencyAuthor, DependencyTeam, DependencyAuthors, DependencyTeams}
 	return arr[:]
 }
 
@@ -76,13 +133,65 @@ func (detector *Detector) ListConfigurationOptions() []core.ConfigurationOption
 		Description: "Path to the developers' email associations.",
 		Flag:        "people-dict",
 		Type:        core.StringConfigurationOption,
-		Default:     ""},
+		Default:     ""}, {
+		Name:        ConfigIdentityDetectorTeamsPath,
+		Description: "Path to the developers' team memberships.",
+		Flag:        "teams",
+		Type:        core.StringConfigurationOption,
+		Default:     ""}, {
+		Name: ConfigIdentityDetectorFuzzyThreshold,
+		Description: "Merge the developers who look the same by the fuzzy heuristics with " +
+			"the confidence not less than this value, from 0 to 1. 0 disables the heuristics.",
+		Flag:    "fuzzy-identities",
+		Type:    core.FloatConfigurationOption,
+		Default: float32(0)}, {
+		Name: ConfigIdentityDetectorCoAuthors,
+		Description: "How to credit the co-authors from the \"Co-authored-by:\" commit message trailers: " +
+			"\"" + CoAuthorsIgnore + "\", \"" + CoAuthorsSplit + "\" or \"" + CoAuthorsDuplicate + "\".",
+		Flag:    "co-authors",
+		Type:    core.StringConfigurationOption,
+		Default: CoAuthorsIgnore}, {
+		Name: ConfigIdentityDetectorMailmapPath,
+		Description: "Path to the mailmap file which is applied on top of the repository's .mailmap " +
+			"(the same as mailmap.file in Git).",
+		Flag:    "mailmap",
+		Type:    core.StringConfigurationOption,
+		Default: ""},
 	}
 	return options[:]
 }
 
 // Configure sets the properties previously published by ListConfigurationOptions().
 func (detector *Detector) Configure(facts map[string]interface{}) {
+	switch val := facts[ConfigIdentityDetectorFuzzyThreshold].(type) {
+	case float32:
+		// the command line flag is float32 and e.g. 0.8 turns into 0.800000011920929
+		detector.FuzzyThreshold = math.Floor(float64(val)*1e6+0.5) / 1e6
+	case float64:
+		detector.FuzzyThreshold = val
+	}
+	if val, exists := facts[core.ConfigPipelineSignature].(string); exists {
+		detector.Signature = val
+	}
+	if val, exists := facts[ConfigIdentityDetectorCoAuthors].(string); exists {
+		detector.CoAuthorsPolicy = val
+	}
+	switch detector.CoAuthorsPolicy {
+	case "":
+		detector.CoAuthorsPolicy = CoAuthorsIgnore
+	case CoAuthorsIgnore, CoAuthorsSplit, CoAuthorsDuplicate:
+	default:
+		log.Printf("Unknown co-authors policy: %s, the co-authors are ignored\n",
+			detector.CoAuthorsPolicy)
+		detector.CoAuthorsPolicy = CoAuthorsIgnore
+	}
+	facts[FactIdentityDetectorCoAuthorsPolicy] = detector.CoAuthorsPolicy
+	mailmapPath, _ := facts[ConfigIdentityDetectorMailmapPath].(string)
+	commits, _ := facts[core.ConfigPipelineCommits].([]*object.Commit)
+	repository, _ := facts[core.FactPipelineRepository].(*git.Repository)
+	if err := detector.LoadMailmap(repository, commits, mailmapPath); err != nil {
+		log.Printf("Failed to load the mailmap from %s: %v\n", mailmapPath, err)
+	}
 	if val, exists := facts[FactIdentityDetectorPeopleDict].(map[string]int); exists {
 		detector.PeopleDict = val
 	}
@@ -92,13 +201,24 @@ func (detector *Detector) Configure(facts map[string]interface{}) {
 	if detector.PeopleDict == nil || detector.ReversedPeopleDict == nil {
 		peopleDictPath, _ := facts[ConfigIdentityDetectorPeopleDictPath].(string)
 		if peopleDictPath != "" {
-			detector.LoadPeopleDict(peopleDictPath)
+			if err := detector.LoadPeopleDict(peopleDictPath); err != nil {
+				log.Printf("Failed to load the people dictionary from %s: %v\n", peopleDictPath, err)
+			}
 			facts[FactIdentityDetectorPeopleCount] = len(detector.ReversedPeopleDict) - 1
 		} else {
 			if _, exists := facts[core.ConfigPipelineCommits]; !exists {
 				panic("IdentityDetector needs a list of commits to initialize.")
 			}
-			detector.GeneratePeopleDict(facts[core.ConfigPipelineCommits].([]*object.Commit))
+			commits := facts[core.ConfigPipelineCommits].([]*object.Commit)
+			// Pipeline.Commits() does not decode the commits
+			if load, exists := facts[core.FactPipelineCommitLoader].(core.CommitLoader); exists {
+				for _, commit := range commits {
+					if err := load(commit); err != nil {
+						log.Printf("Failed to load commit %s: %v\n", commit.Hash.String(), err)
+					}
+				}
+			}
+			detector.GeneratePeopleDict(commits)
 			facts[FactIdentityDetectorPeopleCount] = len(detector.ReversedPeopleDict)
 		}
 	} else {
@@ -106,6 +226,15 @@ func (detector *Detector) Configure(facts map[string]interface{}) {
 	}
 	facts[FactIdentityDetectorPeopleDict] = detector.PeopleDict
 	facts[FactIdentityDetectorReversedPeopleDict] = detector.ReversedPeopleDict
+	if teamsPath, _ := facts[ConfigIdentityDetectorTeamsPath].(string); teamsPath != "" {
+		if err := detector.LoadTeams(teamsPath); err != nil {
+			log.Printf("Failed to load the teams from %s: %v\n", teamsPath, err)
+		}
+	}
+	if detector.ReversedTeamsDict != nil {
+		facts[FactIdentityDetectorReversedTeamsDict] = detector.ReversedTeamsDict
+		facts[FactIdentityDetectorTeamsCount] = len(detector.ReversedTeamsDict)
+	}
 }
 
 // Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
@@ -120,15 +249,65 @@ func (detector *Detector) Initialize(repository *git.Repository) {
 // in Provides(). If there was an error, nil is returned.
 func (detector *Detector) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
 	commit := deps[core.DependencyCommit].(*object.Commit)
-	signature := commit.Author
+	signature := core.CommitSignature(commit, detector.Signature)
+	authorID := detector.resolveSignature(signature)
+	teamID := detector.ResolveTeam(authorID, signature.When)
+	authors := []int{authorID}
+	teams := []int{teamID}
+	if detector.parseCoAuthors() {
+		for _, coAuthor := range ParseCoAuthors(commit.Message) {
+			coAuthorID := detector.resolveSignature(coAuthor)
+			duplicate := false
+			for _, id := range authors {
+				if id == coAuthorID {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				authors = append(authors, coAuthorID)
+				teams = append(teams, detector.ResolveTeam(coAuthorID, signature.When))
+			}
+		}
+	}
+	return map[string]interface{}{
+		DependencyAuthor: authorID, DependencyTeam: teamID,
+		DependencyAuthors: authors, DependencyTeams: teams}, nil
+}
+
+// parseCoAuthors indicates whether the "Co-authored-by:" trailers should be taken into account.
+func (detector *Detector) parseCoAuthors() bool {
+	return detector.CoAuthorsPolicy != "" && detector.CoAuthorsPolicy != CoAuthorsIgnore
+}
+
+// resolveSignature canonicalizes the signature with Mailmap and finds the developer id of
+// the result. The original signature is tried if the canonical one is unknown.
+func (detector *Detector) resolveSignature(signature object.Signature) int {
+	canonical := detector.Mailmap.Resolve(signature)
+	if canonical.Name != signature.Name || canonical.Email != signature.Email {
+		if authorID := detector.lookupSignature(canonical); authorID != AuthorMissing {
+			return authorID
+		}
+	}
+	return detector.lookupSignature(signature)
+}
+
+// lookupSignature finds the developer id of the signature in PeopleDict and then in Regexps.
+func (detector *Detector) lookupSignature(signature object.Signature) int {
 	authorID, exists := detector.PeopleDict[strings.ToLower(signature.Email)]
-	if !exists {
-		authorID, exists = detector.PeopleDict[strings.ToLower(signature.Name)]
-		if !exists {
-			authorID = AuthorMissing
+	if exists {
+		return authorID
+	}
+	authorID, exists = detector.PeopleDict[strings.ToLower(signature.Name)]
+	if exists {
+		return authorID
+	}
+	for _, matcher := range detector.Regexps {
+		if matcher.Regexp.MatchString(signature.Email) || matcher.Regexp.MatchString(signature.Name) {
+			return matcher.Author
 		}
 	}
-	return map[string]interface{}{DependencyAuthor: authorID}, nil
+	return AuthorMissing
 }
 
 // Fork clones this PipelineItem.
@@ -136,10 +315,34 @@ func (detector *Detector) Fork(n int) []core.PipelineItem {
 	return core.ForkSamePipelineItem(detector, n)
 }
 
+// LoadMailmap initializes Mailmap from the .mailmap file in the last commit and then from
+// the file at `path` if it is not empty. Similar to Git, the entries from `path` take
+// precedence over the repository's .mailmap. See core.LastCommitFile() about `repository`.
+func (detector *Detector) LoadMailmap(
+	repository *git.Repository, commits []*object.Commit, path string) error {
+	detector.Mailmap = NewMailmap()
+	if contents, exists := core.LastCommitFile(repository, commits, ".mailmap"); exists {
+		detector.Mailmap.Parse(contents)
+	}
+	if path == "" {
+		return nil
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	detector.Mailmap.Parse(string(contents))
+	return nil
+}
+
 // LoadPeopleDict loads author signatures from a text file.
 // The format is one signature per line, and the signature consists of several
 // keys separated by "|". The first key is the main one and used to reference all the rest.
+// YAML and JSON files are loaded with LoadIdentities() instead.
 func (detector *Detector) LoadPeopleDict(path string) error {
+	if isIdentitiesFile(path) {
+		return detector.LoadIdentities(path)
+	}
 	file, err := os.Open(path)
 	if err != nil {
 		return err
@@ -160,94 +363,60 @@ func (detector *Detector) LoadPeopleDict(path string) error {
 	reverseDict = append(reverseDict, AuthorMissingName)
 	detector.PeopleDict = dict
 	detector.ReversedPeopleDict = reverseDict
+	detector.Regexps = nil
 	return nil
 }
 
 // GeneratePeopleDict loads author signatures from the specified list of Git commits.
+// The committers are loaded instead if Signature is core.SignatureCommitter.
+// The signatures are canonicalized with Mailmap, which is loaded from the last commit
+// if it is nil.
 func (detector *Detector) GeneratePeopleDict(commits []*object.Commit) {
 	dict := map[string]int{}
 	emails := map[int][]string{}
 	names := map[int][]string{}
 	size := 0
 
-	mailmapFile, err := commits[len(commits)-1].File(".mailmap")
-	if err == nil {
-		mailMapContents, err := mailmapFile.Contents()
-		if err == nil {
-			mailmap := ParseMailmap(mailMapContents)
-			for key, val := range mailmap {
-				key = strings.ToLower(key)
-				toEmail := strings.ToLower(val.Email)
-				toName := strings.ToLower(val.Name)
-				id, exists := dict[toEmail]
-				if !exists {
-					id, exists = dict[toName]
-				}
-				if exists {
-					dict[key] = id
-				} else {
-					id = size
-					size++
-					if toEmail != "" {
-						dict[toEmail] = id
-						emails[id] = append(emails[id], toEmail)
-					}
-					if toName != "" {
-						dict[toName] = id
-						names[id] = append(names[id], toName)
-					}
-					dict[key] = id
-				}
-				if strings.Contains(key, "@") {
-					exists := false
-					for _, val := range emails[id] {
-						if key == val {
-							exists = true
-							break
-						}
-					}
-					if !exists {
-						emails[id] = append(emails[id], key)
-					}
-				} else {
-					exists := false
-					for _, val := range names[id] {
-						if key == val {
-							exists = true
-							break
-						}
-					}
-					if !exists {
-						names[id] = append(names[id], key)
-					}
-				}
-			}
-		}
+	if detector.Mailmap == nil {
+		detector.LoadMailmap(nil, commits, "")
 	}
 
-	for _, commit := range commits {
-		email := strings.ToLower(commit.Author.Email)
-		name := strings.ToLower(commit.Author.Name)
-		id, exists := dict[email]
-		if exists {
-			_, exists := dict[name]
-			if !exists {
-				dict[name] = id
-				names[id] = append(names[id], name)
-			}
-			continue
-		}
-		id, exists = dict[name]
-		if exists {
+	addAlias := func(id int, signature object.Signature) {
+		email := strings.ToLower(signature.Email)
+		name := strings.ToLower(signature.Name)
+		if _, exists := dict[email]; !exists {
 			dict[email] = id
 			emails[id] = append(emails[id], email)
-			continue
 		}
-		dict[email] = size
-		dict[name] = size
-		emails[size] = append(emails[size], email)
-		names[size] = append(names[size], name)
-		size++
+		if _, exists := dict[name]; !exists {
+			dict[name] = id
+			names[id] = append(names[id], name)
+		}
+	}
+	addSignature := func(signature object.Signature) {
+		canonical := detector.Mailmap.Resolve(signature)
+		id, exists := dict[strings.ToLower(canonical.Email)]
+		if !exists {
+			id, exists = dict[strings.ToLower(canonical.Name)]
+		}
+		if !exists {
+			id = size
+			size++
+		}
+		addAlias(id, canonical)
+		// the original identity remains an alias of the canonical one
+		addAlias(id, signature)
+	}
+	for _, commit := range commits {
+		addSignature(core.CommitSignature(commit, detector.Signature))
+		if detector.parseCoAuthors() {
+			for _, coAuthor := range ParseCoAuthors(commit.Message) {
+				addSignature(coAuthor)
+			}
+		}
+	}
+	if detector.FuzzyThreshold > 0 {
+		size = detector.mergeFuzzy(dict, names, emails, size)
 	}
 	reverseDict := make([]string, size)
 	for _, val := range dict {
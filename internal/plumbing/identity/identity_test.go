@@ -8,12 +8,17 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
 	"gopkg.in/src-d/hercules.v4/internal/core"
 	"gopkg.in/src-d/hercules.v4/internal/test"
 )
@@ -36,11 +41,18 @@ func TestIdentityDetectorMeta(t *testing.T) {
 	id := fixtureIdentityDetector()
 	assert.Equal(t, id.Name(), "IdentityDetector")
 	assert.Equal(t, len(id.Requires()), 0)
-	assert.Equal(t, len(id.Provides()), 1)
+	assert.Equal(t, len(id.Provides()), 4)
 	assert.Equal(t, id.Provides()[0], DependencyAuthor)
+	assert.Equal(t, id.Provides()[1], DependencyTeam)
+	assert.Equal(t, id.Provides()[2], DependencyAuthors)
+	assert.Equal(t, id.Provides()[3], DependencyTeams)
 	opts := id.ListConfigurationOptions()
-	assert.Len(t, opts, 1)
+	assert.Len(t, opts, 5)
 	assert.Equal(t, opts[0].Name, ConfigIdentityDetectorPeopleDictPath)
+	assert.Equal(t, opts[1].Name, ConfigIdentityDetectorTeamsPath)
+	assert.Equal(t, opts[2].Name, ConfigIdentityDetectorFuzzyThreshold)
+	assert.Equal(t, opts[3].Name, ConfigIdentityDetectorCoAuthors)
+	assert.Equal(t, opts[4].Name, ConfigIdentityDetectorMailmapPath)
 }
 
 func TestIdentityDetectorConfigure(t *testing.T) {
@@ -401,3 +413,90 @@ func TestIdentityDetectorFork(t *testing.T) {
 	assert.True(t, id1 == id2)
 	id1.Merge([]core.PipelineItem{id2})
 }
+
+func TestIdentityDetectorSignature(t *testing.T) {
+	id := fixtureIdentityDetector()
+	facts := map[string]interface{}{
+		FactIdentityDetectorPeopleDict:         id.PeopleDict,
+		FactIdentityDetectorReversedPeopleDict: id.ReversedPeopleDict,
+		core.ConfigPipelineSignature:           core.SignatureCommitter,
+	}
+	id.Configure(facts)
+	assert.Equal(t, core.SignatureCommitter, id.Signature)
+	commit := &object.Commit{
+		Author:    object.Signature{Name: "Somebody", Email: "somebody@example.com"},
+		Committer: object.Signature{Name: "Vadim", Email: "vadim@sourced.tech"},
+	}
+	res, err := id.Consume(map[string]interface{}{core.DependencyCommit: commit})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res[DependencyAuthor])
+	id.Signature = core.SignatureAuthor
+	res, err = id.Consume(map[string]interface{}{core.DependencyCommit: commit})
+	assert.Nil(t, err)
+	assert.Equal(t, AuthorMissing, res[DependencyAuthor])
+}
+
+func TestIdentityDetectorMailmapPeopleDict(t *testing.T) {
+	tmpf, err := ioutil.TempFile("", "hercules-test-mailmap-")
+	assert.Nil(t, err)
+	defer os.Remove(tmpf.Name())
+	_, err = tmpf.WriteString("Vadim <vadim@sourced.tech> Strange Guy <strange@example.com>\n")
+	assert.Nil(t, err)
+	tmpf.Close()
+	id := Detector{}
+	id.Configure(map[string]interface{}{
+		ConfigIdentityDetectorPeopleDictPath: path.Join("..", "..", "test_data", "identities"),
+		ConfigIdentityDetectorMailmapPath:    tmpf.Name(),
+	})
+	assert.Equal(t, 1, id.Mailmap.Len())
+	commit := &object.Commit{
+		Author: object.Signature{Name: "strange guy", Email: "STRANGE@example.com"}}
+	res, err := id.Consume(map[string]interface{}{core.DependencyCommit: commit})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, res[DependencyAuthor])
+	commit.Author.Name = "Someone Else"
+	res, err = id.Consume(map[string]interface{}{core.DependencyCommit: commit})
+	assert.Nil(t, err)
+	assert.Equal(t, AuthorMissing, res[DependencyAuthor])
+	// the original signature is looked up if the canonical one is unknown
+	commit.Author = object.Signature{Name: "Linus Torvalds", Email: "commit@example.com"}
+	id.Mailmap.Parse("Unknown <commit@example.com>")
+	res, err = id.Consume(map[string]interface{}{core.DependencyCommit: commit})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res[DependencyAuthor])
+}
+
+func TestIdentityDetectorLoadMailmapInvalidPath(t *testing.T) {
+	id := Detector{}
+	assert.NotNil(t, id.LoadMailmap(nil, nil, "/xxxyyyzzz"))
+	assert.NotNil(t, id.Mailmap)
+	assert.Nil(t, id.LoadMailmap(nil, nil, ""))
+}
+
+func TestIdentityDetectorMailmapBareCommits(t *testing.T) {
+	repository, err := git.Init(memory.NewStorage(), memfs.New())
+	assert.Nil(t, err)
+	worktree, err := repository.Worktree()
+	assert.Nil(t, err)
+	assert.Nil(t, util.WriteFile(worktree.Filesystem, ".mailmap",
+		[]byte("Vadim <vadim@sourced.tech> Strange Guy <strange@example.com>\n"), 0644))
+	_, err = worktree.Add(".mailmap")
+	assert.Nil(t, err)
+	hash, err := worktree.Commit("mailmap", &git.CommitOptions{Author: &object.Signature{
+		Name: "Strange Guy", Email: "strange@example.com", When: time.Unix(0, 0)}})
+	assert.Nil(t, err)
+	// no object storage
+	commits := []*object.Commit{{Hash: hash}}
+	id := Detector{}
+	id.Configure(map[string]interface{}{
+		core.ConfigPipelineCommits:             commits,
+		core.FactPipelineRepository:            repository,
+		FactIdentityDetectorPeopleDict:         map[string]int{"vadim@sourced.tech": 0},
+		FactIdentityDetectorReversedPeopleDict: []string{"vadim"},
+	})
+	assert.Equal(t, 1, id.Mailmap.Len())
+	// the unknown commit is skipped
+	commits[0].Hash = plumbing.NewHash("1111111111111111111111111111111111111111")
+	assert.Nil(t, id.LoadMailmap(repository, commits, ""))
+	assert.Equal(t, 0, id.Mailmap.Len())
+}
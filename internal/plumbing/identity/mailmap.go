@@ -9,7 +9,7 @@ import (
 // ParseMailmap parses the contents of .mailmap and returns the mapping
 // between signature parts. It does *not* follow the full signature
 // matching convention, that is, developers are identified by email
-// and by name independently.
+// and by name independently. Mailmap implements the full convention.
 func ParseMailmap(contents string) map[string]object.Signature {
 	mm := map[string]object.Signature{}
 	lines := strings.Split(contents, "\n")
@@ -49,3 +49,109 @@ func ParseMailmap(contents string) map[string]object.Signature {
 	}
 	return mm
 }
+
+// Mailmap rewrites the commit signatures following the rules of gitmailmap(5):
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+//
+// The emails and the names are matched case-insensitively. The entries which match both
+// the name and the email take precedence over the entries which match only the email.
+// Later entries override the earlier ones, so that the files which are loaded last win.
+type Mailmap struct {
+	entries map[string]*mailmapEntry
+}
+
+// mailmapEntry holds the replacements for a single commit email.
+type mailmapEntry struct {
+	// replacement is applied if none of the names match; empty parts are not replaced
+	replacement object.Signature
+	// names maps the lower case commit names to the specific replacements
+	names map[string]object.Signature
+}
+
+// NewMailmap creates an empty Mailmap.
+func NewMailmap() *Mailmap {
+	return &Mailmap{entries: map[string]*mailmapEntry{}}
+}
+
+// Parse adds the entries from the contents of a .mailmap file. Invalid lines are ignored.
+func (mailmap *Mailmap) Parse(contents string) {
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var names, emails []string
+		for len(emails) < 2 {
+			open := strings.Index(line, "<")
+			end := strings.Index(line, ">")
+			if open < 0 || end < open {
+				break
+			}
+			names = append(names, strings.TrimSpace(line[:open]))
+			emails = append(emails, strings.TrimSpace(line[open+1:end]))
+			line = line[end+1:]
+		}
+		switch len(emails) {
+		case 1:
+			if names[0] != "" {
+				mailmap.add(emails[0], "", object.Signature{Name: names[0]})
+			}
+		case 2:
+			mailmap.add(emails[1], names[1], object.Signature{Name: names[0], Email: emails[0]})
+		}
+	}
+}
+
+func (mailmap *Mailmap) add(email string, name string, replacement object.Signature) {
+	email = strings.ToLower(email)
+	entry := mailmap.entries[email]
+	if entry == nil {
+		entry = &mailmapEntry{names: map[string]object.Signature{}}
+		mailmap.entries[email] = entry
+	}
+	if name != "" {
+		entry.names[strings.ToLower(name)] = replacement
+		return
+	}
+	if replacement.Name != "" {
+		entry.replacement.Name = replacement.Name
+	}
+	if replacement.Email != "" {
+		entry.replacement.Email = replacement.Email
+	}
+}
+
+// Len returns the number of the distinct commit emails with replacements.
+func (mailmap *Mailmap) Len() int {
+	if mailmap == nil {
+		return 0
+	}
+	return len(mailmap.entries)
+}
+
+// Resolve returns the canonical version of the signature. The timestamp is preserved.
+// A nil Mailmap returns the signature as is.
+func (mailmap *Mailmap) Resolve(signature object.Signature) object.Signature {
+	if mailmap == nil {
+		return signature
+	}
+	entry := mailmap.entries[strings.ToLower(signature.Email)]
+	if entry == nil {
+		return signature
+	}
+	replacement, exists := entry.names[strings.ToLower(signature.Name)]
+	if !exists {
+		replacement = entry.replacement
+	}
+	if replacement.Name != "" {
+		signature.Name = replacement.Name
+	}
+	if replacement.Email != "" {
+		signature.Email = replacement.Email
+	}
+	return signature
+}
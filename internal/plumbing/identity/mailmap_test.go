@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
 )
 
 func TestParseMailmap(t *testing.T) {
@@ -301,3 +302,36 @@ func TestParseMailmapBadFormat(t *testing.T) {
 	assert.Equal(t, mm["<dengemann"].Name, "Denis Engemann")
 	assert.Equal(t, mm["<dengemann"].Email, "denis-alexander.engemann@inria.fr")
 }
+
+func TestMailmapResolve(t *testing.T) {
+	mm := NewMailmap()
+	mm.Parse(`# comment
+Proper Name <commit@example.com>
+<proper@example.com> <Other@Example.com>
+Both Name <both@example.com> <both-commit@example.com>
+Specific <specific@example.com> Bad Name <commit@example.com> # trailing comment
+garbage line
+`)
+	assert.Equal(t, 3, mm.Len())
+	sig := mm.Resolve(object.Signature{Name: "whatever", Email: "COMMIT@example.com"})
+	assert.Equal(t, object.Signature{Name: "Proper Name", Email: "COMMIT@example.com"}, sig)
+	sig = mm.Resolve(object.Signature{Name: "bad name", Email: "commit@example.com"})
+	assert.Equal(t, object.Signature{Name: "Specific", Email: "specific@example.com"}, sig)
+	sig = mm.Resolve(object.Signature{Name: "Other", Email: "other@example.com"})
+	assert.Equal(t, object.Signature{Name: "Other", Email: "proper@example.com"}, sig)
+	sig = mm.Resolve(object.Signature{Name: "X", Email: "both-commit@example.com"})
+	assert.Equal(t, object.Signature{Name: "Both Name", Email: "both@example.com"}, sig)
+	sig = mm.Resolve(object.Signature{Name: "Unknown", Email: "unknown@example.com"})
+	assert.Equal(t, object.Signature{Name: "Unknown", Email: "unknown@example.com"}, sig)
+	var nilmm *Mailmap
+	assert.Equal(t, 0, nilmm.Len())
+	assert.Equal(t, sig, nilmm.Resolve(sig))
+}
+
+func TestMailmapOverride(t *testing.T) {
+	mm := NewMailmap()
+	mm.Parse("Old Name <old@example.com> <commit@example.com>")
+	mm.Parse("New Name <commit@example.com>")
+	sig := mm.Resolve(object.Signature{Name: "x", Email: "commit@example.com"})
+	assert.Equal(t, object.Signature{Name: "New Name", Email: "old@example.com"}, sig)
+}
@@ -0,0 +1,115 @@
+package identity
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"time"
+)
+
+// TeamMembership is the period of time during which a developer belonged to a team.
+type TeamMembership struct {
+	// Team is the index in Detector.ReversedTeamsDict.
+	Team int
+	// Since is the first moment of the membership. Zero means that there is no lower bound.
+	Since time.Time
+	// Until is the moment when the membership ended. Zero means that there is no upper bound.
+	Until time.Time
+}
+
+// TeamsDateFormat is the layout of the dates in the teams file.
+const TeamsDateFormat = "2006-01-02"
+
+// Contains checks whether the specified moment belongs to the membership period.
+func (membership TeamMembership) Contains(when time.Time) bool {
+	if !membership.Since.IsZero() && when.Before(membership.Since) {
+		return false
+	}
+	if !membership.Until.IsZero() && !when.Before(membership.Until) {
+		return false
+	}
+	return true
+}
+
+// ParseTeams parses the contents of the teams file. Each line is a single membership record
+// which consists of the developer's identity, the team name and optionally the first day
+// of the membership and the day after the last one, separated by "|":
+//
+//	vadim@sourced.tech|Machine Learning
+//	Máximo Cuadros|Infrastructure|2016-01-01|2017-06-01
+//	maximo@sourced.tech|Engine|2017-06-01
+//
+// The dates are formatted as YYYY-MM-DD, either of them may be empty. Empty lines and
+// the lines which start with "#" are ignored. The returned records are ordered as in the file.
+// The identities are returned as is, the teams are the indexes in the returned list of names.
+func ParseTeams(contents string) (identities []string, memberships []TeamMembership,
+	teams []string, err error) {
+	teamIndex := map[string]int{}
+	for i, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, "|")
+		if len(parts) < 2 || len(parts) > 4 || parts[0] == "" || parts[1] == "" {
+			return nil, nil, nil, fmt.Errorf("teams line %d is invalid: %s", i+1, line)
+		}
+		membership := TeamMembership{}
+		for j, bound := range []*time.Time{&membership.Since, &membership.Until} {
+			if len(parts) <= j+2 || parts[j+2] == "" {
+				continue
+			}
+			*bound, err = time.Parse(TeamsDateFormat, parts[j+2])
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("teams line %d: %v", i+1, err)
+			}
+		}
+		team, exists := teamIndex[parts[1]]
+		if !exists {
+			team = len(teams)
+			teamIndex[parts[1]] = team
+			teams = append(teams, parts[1])
+		}
+		membership.Team = team
+		identities = append(identities, parts[0])
+		memberships = append(memberships, membership)
+	}
+	return identities, memberships, teams, nil
+}
+
+// LoadTeams reads the team memberships from a text file, see ParseTeams() for the format.
+// The identities are resolved with PeopleDict, so it must be loaded beforehand.
+func (detector *Detector) LoadTeams(path string) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	identities, memberships, teams, err := ParseTeams(string(contents))
+	if err != nil {
+		return err
+	}
+	detector.Teams = map[int][]TeamMembership{}
+	for i, membership := range memberships {
+		id, exists := detector.PeopleDict[strings.ToLower(identities[i])]
+		if !exists {
+			log.Printf("Warning: unknown developer in the teams file: %s\n", identities[i])
+			continue
+		}
+		detector.Teams[id] = append(detector.Teams[id], membership)
+	}
+	detector.ReversedTeamsDict = teams
+	return nil
+}
+
+// ResolveTeam returns the team of the developer at the specified moment or TeamMissing.
+// If several memberships match, the last one in the teams file wins.
+func (detector *Detector) ResolveTeam(author int, when time.Time) int {
+	memberships := detector.Teams[author]
+	for i := len(memberships) - 1; i >= 0; i-- {
+		if memberships[i].Contains(when) {
+			return memberships[i].Team
+		}
+	}
+	return TeamMissing
+}
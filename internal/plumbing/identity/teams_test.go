@@ -0,0 +1,133 @@
+package identity
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+)
+
+const fixtureTeams = `# comment
+vadim@sourced.tech|Machine Learning
+
+Máximo Cuadros|Infrastructure|2016-01-01|2017-06-01
+maximo@sourced.tech|Engine|2017-06-01
+unknown@sourced.tech|Engine
+`
+
+func date(str string) time.Time {
+	result, _ := time.Parse(TeamsDateFormat, str)
+	return result
+}
+
+func fixtureTeamsDetector(t *testing.T) *Detector {
+	id := Detector{
+		PeopleDict: map[string]int{
+			"vadim@sourced.tech": 0, "máximo cuadros": 1, "maximo@sourced.tech": 1},
+		ReversedPeopleDict: []string{"Vadim", "Máximo"},
+	}
+	tmpf, err := ioutil.TempFile("", "hercules-test-")
+	assert.Nil(t, err)
+	defer os.Remove(tmpf.Name())
+	_, err = tmpf.WriteString(fixtureTeams)
+	assert.Nil(t, err)
+	assert.Nil(t, tmpf.Close())
+	assert.Nil(t, id.LoadTeams(tmpf.Name()))
+	return &id
+}
+
+func TestParseTeams(t *testing.T) {
+	identities, memberships, teams, err := ParseTeams(fixtureTeams)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"vadim@sourced.tech", "Máximo Cuadros", "maximo@sourced.tech", "unknown@sourced.tech"},
+		identities)
+	assert.Equal(t, []string{"Machine Learning", "Infrastructure", "Engine"}, teams)
+	assert.Equal(t, []TeamMembership{
+		{Team: 0},
+		{Team: 1, Since: date("2016-01-01"), Until: date("2017-06-01")},
+		{Team: 2, Since: date("2017-06-01")},
+		{Team: 2},
+	}, memberships)
+	_, _, _, err = ParseTeams("vadim@sourced.tech")
+	assert.NotNil(t, err)
+	_, _, _, err = ParseTeams("vadim@sourced.tech|ML|yesterday")
+	assert.NotNil(t, err)
+	_, _, _, err = ParseTeams("vadim@sourced.tech|ML|||")
+	assert.NotNil(t, err)
+	_, _, teams, err = ParseTeams("")
+	assert.Nil(t, err)
+	assert.Len(t, teams, 0)
+}
+
+func TestTeamMembershipContains(t *testing.T) {
+	membership := TeamMembership{Since: date("2016-01-01"), Until: date("2017-06-01")}
+	assert.False(t, membership.Contains(date("2015-12-31")))
+	assert.True(t, membership.Contains(date("2016-01-01")))
+	assert.True(t, membership.Contains(date("2017-05-31")))
+	assert.False(t, membership.Contains(date("2017-06-01")))
+	assert.True(t, TeamMembership{}.Contains(date("2017-06-01")))
+}
+
+func TestIdentityDetectorLoadTeams(t *testing.T) {
+	id := fixtureTeamsDetector(t)
+	assert.Equal(t, []string{"Machine Learning", "Infrastructure", "Engine"}, id.ReversedTeamsDict)
+	assert.Len(t, id.Teams, 2)
+	assert.Len(t, id.Teams[0], 1)
+	assert.Len(t, id.Teams[1], 2)
+	assert.NotNil(t, id.LoadTeams("/xxxyyyzzzInvalidPath!hehe"))
+}
+
+func TestIdentityDetectorResolveTeam(t *testing.T) {
+	id := fixtureTeamsDetector(t)
+	assert.Equal(t, 0, id.ResolveTeam(0, date("2010-01-01")))
+	assert.Equal(t, TeamMissing, id.ResolveTeam(1, date("2010-01-01")))
+	assert.Equal(t, 1, id.ResolveTeam(1, date("2017-01-01")))
+	assert.Equal(t, 2, id.ResolveTeam(1, date("2018-01-01")))
+	assert.Equal(t, TeamMissing, id.ResolveTeam(AuthorMissing, date("2018-01-01")))
+}
+
+func TestIdentityDetectorConsumeTeam(t *testing.T) {
+	id := fixtureTeamsDetector(t)
+	deps := map[string]interface{}{
+		core.DependencyCommit: &object.Commit{Author: object.Signature{
+			Name: "Máximo Cuadros", Email: "mcuadros@gmail.com", When: date("2017-01-01")}},
+	}
+	res, err := id.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, res[DependencyAuthor])
+	assert.Equal(t, 1, res[DependencyTeam])
+	deps[core.DependencyCommit] = &object.Commit{Author: object.Signature{
+		Name: "Vadim", Email: "vadim@sourced.tech"}}
+	res, err = id.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res[DependencyTeam])
+}
+
+func TestIdentityDetectorConfigureTeams(t *testing.T) {
+	tmpf, err := ioutil.TempFile("", "hercules-test-")
+	assert.Nil(t, err)
+	defer os.Remove(tmpf.Name())
+	_, err = tmpf.WriteString(fixtureTeams)
+	assert.Nil(t, err)
+	assert.Nil(t, tmpf.Close())
+	id := Detector{}
+	facts := map[string]interface{}{
+		FactIdentityDetectorPeopleDict:         map[string]int{"vadim@sourced.tech": 0},
+		FactIdentityDetectorReversedPeopleDict: []string{"Vadim"},
+		ConfigIdentityDetectorTeamsPath:        tmpf.Name(),
+	}
+	id.Configure(facts)
+	assert.Equal(t, 3, facts[FactIdentityDetectorTeamsCount])
+	assert.Equal(t, id.ReversedTeamsDict, facts[FactIdentityDetectorReversedTeamsDict])
+	assert.Len(t, id.Teams, 1)
+	id = Detector{}
+	delete(facts, ConfigIdentityDetectorTeamsPath)
+	delete(facts, FactIdentityDetectorTeamsCount)
+	id.Configure(facts)
+	assert.NotContains(t, facts, FactIdentityDetectorTeamsCount)
+}
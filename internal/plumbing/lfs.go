@@ -0,0 +1,144 @@
+package plumbing
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+)
+
+// LFSPointer is the parsed Git LFS pointer file, see
+// https://github.com/git-lfs/git-lfs/blob/master/docs/spec.md
+type LFSPointer struct {
+	// OID is the hex SHA-256 of the real contents.
+	OID string
+	// Size is the size of the real contents in bytes.
+	Size int64
+}
+
+// ParseLFSPointer reads the Git LFS pointer from the blob. It fails if the blob is not
+// a valid pointer.
+func ParseLFSPointer(blob *object.Blob) (LFSPointer, error) {
+	pointer := LFSPointer{Size: -1}
+	if blob.Size > lfsPointerMaxSize {
+		return pointer, errors.New("too large for an LFS pointer")
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return pointer, err
+	}
+	defer checkClose(reader)
+	scanner := bufio.NewScanner(reader)
+	for line := 0; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 0 {
+			if text+"\n" != lfsPointerPrefix {
+				return pointer, errors.New("not an LFS pointer")
+			}
+			continue
+		}
+		space := strings.IndexByte(text, ' ')
+		if space < 0 {
+			return pointer, fmt.Errorf("invalid LFS pointer line: %s", text)
+		}
+		key, value := text[:space], text[space+1:]
+		switch key {
+		case "oid":
+			if !strings.HasPrefix(value, "sha256:") {
+				return pointer, fmt.Errorf("unsupported LFS oid: %s", value)
+			}
+			value = value[len("sha256:"):]
+			if _, err := hex.DecodeString(value); err != nil || len(value) != 64 {
+				return pointer, fmt.Errorf("invalid LFS oid: %s", value)
+			}
+			pointer.OID = value
+		case "size":
+			pointer.Size, err = strconv.ParseInt(value, 10, 64)
+			if err != nil || pointer.Size < 0 {
+				return pointer, fmt.Errorf("invalid LFS size: %s", value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return pointer, err
+	}
+	if pointer.OID == "" || pointer.Size < 0 {
+		return pointer, errors.New("incomplete LFS pointer")
+	}
+	return pointer, nil
+}
+
+// LFSObjectsPath returns the path to the local Git LFS object store of the repository,
+// "lfs/objects" inside the .git directory. It returns an empty string if the repository
+// is not stored on disk, e.g. was cloned into memory.
+func LFSObjectsPath(repository *git.Repository) string {
+	if repository == nil {
+		return ""
+	}
+	storage, ok := repository.Storer.(*filesystem.Storage)
+	if !ok {
+		return ""
+	}
+	return filepath.Join(storage.Filesystem().Root(), "lfs", "objects")
+}
+
+// ResolveLFSPointer returns the blob with the real contents referenced by the Git LFS pointer.
+// `objects` is the path to the LFS object store. The returned blob keeps the hash of the pointer
+// and is read from the store on demand. If the object is not in the store, the pointer blob
+// is returned as is and `resolved` is false.
+func ResolveLFSPointer(blob *object.Blob, pointer LFSPointer, objects string) (
+	result *object.Blob, resolved bool, err error) {
+	if objects != "" {
+		path := filepath.Join(objects, pointer.OID[:2], pointer.OID[2:4], pointer.OID)
+		// the partially downloaded objects have a different size
+		if stat, err := os.Stat(path); err == nil && stat.Mode().IsRegular() &&
+			stat.Size() == pointer.Size {
+			result, err = object.DecodeBlob(&lfsObject{hash: blob.Hash, path: path, size: pointer.Size})
+			return result, err == nil, err
+		}
+	}
+	return blob, false, nil
+}
+
+// lfsObject is the plumbing.EncodedObject which reads a file in the Git LFS object store.
+type lfsObject struct {
+	hash plumbing.Hash
+	path string
+	size int64
+}
+
+func (obj *lfsObject) Hash() plumbing.Hash {
+	return obj.hash
+}
+
+func (obj *lfsObject) Type() plumbing.ObjectType {
+	return plumbing.BlobObject
+}
+
+func (obj *lfsObject) SetType(plumbing.ObjectType) {
+}
+
+func (obj *lfsObject) Size() int64 {
+	return obj.size
+}
+
+func (obj *lfsObject) SetSize(int64) {
+}
+
+func (obj *lfsObject) Reader() (io.ReadCloser, error) {
+	return os.Open(obj.path)
+}
+
+func (obj *lfsObject) Writer() (io.WriteCloser, error) {
+	return nil, errors.New("Git LFS objects are read-only")
+}
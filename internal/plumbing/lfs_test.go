@@ -0,0 +1,89 @@
+package plumbing
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+	"gopkg.in/src-d/hercules.v4/internal/test"
+)
+
+const testLFSOID = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+
+func TestParseLFSPointer(t *testing.T) {
+	pointer, err := ParseLFSPointer(test.FakeBlob(lfsPointerPrefix +
+		"oid sha256:" + testLFSOID + "\nsize 12345\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, LFSPointer{OID: testLFSOID, Size: 12345}, pointer)
+	// the unknown keys are ignored
+	pointer, err = ParseLFSPointer(test.FakeBlob(lfsPointerPrefix +
+		"ext-0-foo sha256:" + testLFSOID + "\noid sha256:" + testLFSOID + "\nsize 0\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, LFSPointer{OID: testLFSOID, Size: 0}, pointer)
+	for _, contents := range []string{
+		"",
+		"package main\n",
+		lfsPointerPrefix,
+		lfsPointerPrefix + "oid sha256:" + testLFSOID + "\n",
+		lfsPointerPrefix + "size 10\n",
+		lfsPointerPrefix + "oid md5:" + testLFSOID + "\nsize 10\n",
+		lfsPointerPrefix + "oid sha256:xyz\nsize 10\n",
+		lfsPointerPrefix + "oid sha256:" + testLFSOID + "\nsize -1\n",
+		lfsPointerPrefix + "oid\n",
+		lfsPointerPrefix + "oid sha256:" + testLFSOID + "\nsize 10\n" +
+			strings.Repeat("\n", lfsPointerMaxSize),
+	} {
+		_, err = ParseLFSPointer(test.FakeBlob(contents))
+		assert.NotNil(t, err, contents)
+	}
+}
+
+func TestLFSObjectsPath(t *testing.T) {
+	assert.Equal(t, "", LFSObjectsPath(nil))
+	repository, err := git.Init(memory.NewStorage(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "", LFSObjectsPath(repository))
+	root, err := ioutil.TempDir("", "hercules-lfs-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+	repository, err = git.PlainInit(root, false)
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(root, ".git", "lfs", "objects"), LFSObjectsPath(repository))
+}
+
+func TestResolveLFSPointer(t *testing.T) {
+	objects, err := ioutil.TempDir("", "hercules-lfs-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(objects)
+	blob := test.FakeBlob(lfsPointerPrefix + "oid sha256:" + testLFSOID + "\nsize 4\n")
+	pointer := LFSPointer{OID: testLFSOID, Size: 4}
+	for _, root := range []string{"", objects} {
+		resolved, ok, err := ResolveLFSPointer(blob, pointer, root)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		// the pointer is returned as is
+		assert.True(t, blob == resolved)
+		assert.NotEqual(t, int64(4), resolved.Size)
+	}
+	dir := filepath.Join(objects, testLFSOID[:2], testLFSOID[2:4])
+	assert.Nil(t, os.MkdirAll(dir, 0755))
+	// the partial download
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, testLFSOID), []byte("ab"), 0644))
+	_, ok, err := ResolveLFSPointer(blob, pointer, objects)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, testLFSOID), []byte("abcd"), 0644))
+	resolved, ok, err := ResolveLFSPointer(blob, pointer, objects)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, blob.Hash, resolved.Hash)
+	assert.Equal(t, int64(4), resolved.Size)
+	contents, err := BlobToString(resolved)
+	assert.Nil(t, err)
+	assert.Equal(t, "abcd", contents)
+}
@@ -0,0 +1,384 @@
+package plumbing
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+)
+
+// ObjectBackend reads the trees and the blobs of the analysed repository. TreeDiff and BlobCache
+// use it, see core.ConfigPipelineGitBackend. All the implementations return the same results.
+type ObjectBackend interface {
+	// ListTree returns the insertions of all the files in the tree except the submodules.
+	ListTree(tree *object.Tree) (object.Changes, error)
+	// DiffTree returns the changes between the two trees, the same as object.DiffTree().
+	DiffTree(from, to *object.Tree) (object.Changes, error)
+	// Blob loads the blob with the specified hash. It returns plumbing.ErrObjectNotFound
+	// if the blob does not exist.
+	Blob(hash plumbing.Hash) (*object.Blob, error)
+	// Close releases the resources, e.g. stops the external processes.
+	Close() error
+}
+
+// NewObjectBackend creates the ObjectBackend of the specified kind, core.GitBackendGoGit or
+// core.GitBackendExec. The exec backend requires the repository on disk and the git executable
+// in PATH, otherwise it falls back to go-git.
+func NewObjectBackend(kind string, repository *git.Repository) ObjectBackend {
+	if kind == core.GitBackendExec {
+		backend, err := newExecObjectBackend(repository)
+		if err == nil {
+			return backend
+		}
+		log.Printf("Falling back to the %s backend: %v\n", core.GitBackendGoGit, err)
+	}
+	return &goGitObjectBackend{repository: repository}
+}
+
+// goGitObjectBackend implements ObjectBackend with go-git.
+type goGitObjectBackend struct {
+	repository *git.Repository
+}
+
+func (backend *goGitObjectBackend) ListTree(tree *object.Tree) (object.Changes, error) {
+	changes := object.Changes{}
+	fileIter := tree.Files()
+	defer fileIter.Close()
+	for {
+		file, err := fileIter.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		changes = append(changes, &object.Change{
+			To: object.ChangeEntry{Name: file.Name, Tree: tree, TreeEntry: object.TreeEntry{
+				Name: file.Name, Mode: file.Mode, Hash: file.Hash}}})
+	}
+	return changes, nil
+}
+
+func (backend *goGitObjectBackend) DiffTree(from, to *object.Tree) (object.Changes, error) {
+	return object.DiffTree(from, to)
+}
+
+func (backend *goGitObjectBackend) Blob(hash plumbing.Hash) (*object.Blob, error) {
+	return backend.repository.BlobObject(hash)
+}
+
+func (backend *goGitObjectBackend) Close() error {
+	return nil
+}
+
+// execObjectBackend implements ObjectBackend with the git executable. The trees are compared
+// with `git diff-tree` and the objects are read from a long running `git cat-file --batch`.
+type execObjectBackend struct {
+	gitDir string
+	// storage is used by the decoded trees to load the subtrees.
+	storage storer.EncodedObjectStorer
+
+	// lock protects the cat-file process which is shared by the forks.
+	lock   sync.Mutex
+	batch  *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+func newExecObjectBackend(repository *git.Repository) (*execObjectBackend, error) {
+	if repository == nil {
+		return nil, fmt.Errorf("no repository")
+	}
+	storage, ok := repository.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, fmt.Errorf("the repository is not on disk")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, err
+	}
+	return &execObjectBackend{gitDir: storage.Filesystem().Root(), storage: repository.Storer}, nil
+}
+
+// git runs the git command in the repository and returns its stdout.
+func (backend *execObjectBackend) git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"--git-dir", backend.gitDir}, args...)...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+func (backend *execObjectBackend) ListTree(tree *object.Tree) (object.Changes, error) {
+	output, err := backend.git("ls-tree", "-r", "-z", tree.Hash.String())
+	if err != nil {
+		return nil, err
+	}
+	changes := object.Changes{}
+	for _, record := range strings.Split(string(output), "\x00") {
+		if record == "" {
+			continue
+		}
+		// <mode> SP <type> SP <hash> TAB <path>
+		tab := strings.IndexByte(record, '\t')
+		if tab < 0 {
+			return nil, fmt.Errorf("git ls-tree: invalid record %q", record)
+		}
+		fields := strings.Fields(record[:tab])
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("git ls-tree: invalid record %q", record)
+		}
+		if fields[1] != "blob" {
+			continue
+		}
+		mode, err := filemode.New(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		name := record[tab+1:]
+		changes = append(changes, &object.Change{
+			To: object.ChangeEntry{Name: name, Tree: tree, TreeEntry: object.TreeEntry{
+				Name: name, Mode: mode, Hash: plumbing.NewHash(fields[2])}}})
+	}
+	return changes, nil
+}
+
+func (backend *execObjectBackend) DiffTree(from, to *object.Tree) (object.Changes, error) {
+	output, err := backend.git("diff-tree", "-r", "-t", "-z", "--no-renames", "--no-commit-id",
+		from.Hash.String(), to.Hash.String())
+	if err != nil {
+		return nil, err
+	}
+	// the hashes of the changed directories, -t lists them
+	fromDirs := map[string]plumbing.Hash{"": from.Hash}
+	toDirs := map[string]plumbing.Hash{"": to.Hash}
+	trees := map[plumbing.Hash]*object.Tree{from.Hash: from, to.Hash: to}
+	type record struct {
+		name             string
+		fromMode, toMode filemode.FileMode
+		fromHash, toHash plumbing.Hash
+	}
+	var records []record
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		// :<mode> SP <mode> SP <hash> SP <hash> SP <status>, then the path
+		header := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(header) != 5 {
+			return nil, fmt.Errorf("git diff-tree: invalid record %q", fields[i])
+		}
+		rec := record{name: fields[i+1],
+			fromHash: plumbing.NewHash(header[2]), toHash: plumbing.NewHash(header[3])}
+		if rec.fromMode, err = filemode.New(header[0]); err != nil {
+			return nil, err
+		}
+		if rec.toMode, err = filemode.New(header[1]); err != nil {
+			return nil, err
+		}
+		if rec.fromMode == filemode.Dir || rec.toMode == filemode.Dir {
+			if rec.fromMode == filemode.Dir {
+				fromDirs[rec.name] = rec.fromHash
+			}
+			if rec.toMode == filemode.Dir {
+				toDirs[rec.name] = rec.toHash
+			}
+			continue
+		}
+		records = append(records, rec)
+	}
+	parent := func(name string, dirs map[string]plumbing.Hash) (*object.Tree, error) {
+		dir := path.Dir(name)
+		if dir == "." {
+			dir = ""
+		}
+		hash, exists := dirs[dir]
+		if !exists {
+			return nil, fmt.Errorf("git diff-tree: no parent tree of %s", name)
+		}
+		if tree := trees[hash]; tree != nil {
+			return tree, nil
+		}
+		tree, err := backend.tree(hash)
+		if err != nil {
+			return nil, err
+		}
+		trees[hash] = tree
+		return tree, nil
+	}
+	changes := make(object.Changes, 0, len(records))
+	for _, rec := range records {
+		change := &object.Change{}
+		if rec.fromMode != filemode.Empty {
+			tree, err := parent(rec.name, fromDirs)
+			if err != nil {
+				return nil, err
+			}
+			change.From = object.ChangeEntry{Name: rec.name, Tree: tree, TreeEntry: object.TreeEntry{
+				Name: path.Base(rec.name), Mode: rec.fromMode, Hash: rec.fromHash}}
+		}
+		if rec.toMode != filemode.Empty {
+			tree, err := parent(rec.name, toDirs)
+			if err != nil {
+				return nil, err
+			}
+			change.To = object.ChangeEntry{Name: rec.name, Tree: tree, TreeEntry: object.TreeEntry{
+				Name: path.Base(rec.name), Mode: rec.toMode, Hash: rec.toHash}}
+		}
+		changes = append(changes, change)
+	}
+	// git sorts the directories as if they end with a slash while go-git compares the names
+	sort.SliceStable(changes, func(i, j int) bool {
+		return lessChangePaths(changeName(changes[i]), changeName(changes[j]))
+	})
+	return changes, nil
+}
+
+func (backend *execObjectBackend) Blob(hash plumbing.Hash) (*object.Blob, error) {
+	obj, err := backend.readObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	if obj.Type() != plumbing.BlobObject {
+		return nil, plumbing.ErrObjectNotFound
+	}
+	return object.DecodeBlob(obj)
+}
+
+func (backend *execObjectBackend) tree(hash plumbing.Hash) (*object.Tree, error) {
+	obj, err := backend.readObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	return object.DecodeTree(backend.storage, obj)
+}
+
+// readObject requests the object from `git cat-file --batch` which is started on demand.
+func (backend *execObjectBackend) readObject(hash plumbing.Hash) (plumbing.EncodedObject, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	if backend.batch == nil {
+		if err := backend.startBatch(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := fmt.Fprintln(backend.stdin, hash.String()); err != nil {
+		backend.stopBatch()
+		return nil, err
+	}
+	// <hash> SP <type> SP <size> LF <contents> LF or <hash> SP missing LF
+	header, err := backend.stdout.ReadString('\n')
+	if err != nil {
+		backend.stopBatch()
+		return nil, err
+	}
+	fields := strings.Fields(header)
+	if len(fields) == 2 && fields[1] == "missing" {
+		return nil, plumbing.ErrObjectNotFound
+	}
+	if len(fields) != 3 {
+		backend.stopBatch()
+		return nil, fmt.Errorf("git cat-file: invalid header %q", header)
+	}
+	objType, err := plumbing.ParseObjectType(fields[1])
+	if err != nil {
+		backend.stopBatch()
+		return nil, err
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		backend.stopBatch()
+		return nil, err
+	}
+	contents := make([]byte, size+1)
+	if _, err = io.ReadFull(backend.stdout, contents); err != nil {
+		backend.stopBatch()
+		return nil, err
+	}
+	obj := &plumbing.MemoryObject{}
+	obj.SetType(objType)
+	obj.Write(contents[:size])
+	return obj, nil
+}
+
+func (backend *execObjectBackend) startBatch() error {
+	cmd := exec.Command("git", "--git-dir", backend.gitDir, "cat-file", "--batch")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	backend.batch = cmd
+	backend.stdin = stdin
+	backend.stdout = bufio.NewReaderSize(stdout, 1<<16)
+	return nil
+}
+
+// stopBatch terminates `git cat-file --batch`; the next readObject() starts it again.
+func (backend *execObjectBackend) stopBatch() error {
+	if backend.batch == nil {
+		return nil
+	}
+	backend.stdin.Close()
+	err := backend.batch.Wait()
+	backend.batch, backend.stdin, backend.stdout = nil, nil, nil
+	return err
+}
+
+func (backend *execObjectBackend) Close() error {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	return backend.stopBatch()
+}
+
+func changeName(change *object.Change) string {
+	if change.To.Name != "" {
+		return change.To.Name
+	}
+	return change.From.Name
+}
+
+// lessChangePaths compares the paths component by component, which is the order of the changes
+// in object.DiffTree().
+func lessChangePaths(left, right string) bool {
+	for {
+		leftName, leftRest := splitFirstDir(left)
+		rightName, rightRest := splitFirstDir(right)
+		if leftName != rightName {
+			return leftName < rightName
+		}
+		if leftRest == "" || rightRest == "" {
+			return leftRest == "" && rightRest != ""
+		}
+		left, right = leftRest, rightRest
+	}
+}
+
+func splitFirstDir(name string) (string, string) {
+	slash := strings.IndexByte(name, '/')
+	if slash < 0 {
+		return name, ""
+	}
+	return name[:slash], name[slash+1:]
+}
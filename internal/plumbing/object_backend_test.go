@@ -0,0 +1,202 @@
+package plumbing
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+)
+
+const testSubmoduleHash = "0123456789abcdef0123456789abcdef01234567"
+
+// fixtureGitHistory creates the repository on disk with the git executable. The history
+// contains the mode changes, the type changes, the submodules, the replaced directories and
+// the names which git and go-git sort differently.
+func fixtureGitHistory(t *testing.T) (string, *git.Repository, []*object.Commit) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root, err := ioutil.TempDir("", "hercules-backend-")
+	assert.Nil(t, err)
+	run := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{
+			"-c", "user.name=test", "-c", "user.email=test@test"}, args...)...)
+		cmd.Dir = root
+		output, err := cmd.CombinedOutput()
+		assert.Nil(t, err, string(output))
+		return strings.TrimSpace(string(output))
+	}
+	write := func(name, contents string) {
+		name = filepath.Join(root, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(name), 0755))
+		assert.Nil(t, ioutil.WriteFile(name, []byte(contents), 0644))
+	}
+	run("init", "-q")
+	write("a.txt", "a\n")
+	write("a/b.txt", "b\n")
+	write("a-b/c.txt", "c\n")
+	write("dir/sub/x.txt", "x\n")
+	write("exec.sh", "#!/bin/sh\n")
+	assert.Nil(t, os.Chmod(filepath.Join(root, "exec.sh"), 0755))
+	assert.Nil(t, os.Symlink("a.txt", filepath.Join(root, "link")))
+	write("f", "file\n")
+	run("add", "-A")
+	run("update-index", "--add", "--cacheinfo", "160000,"+testSubmoduleHash+",libs/sub")
+	run("commit", "-q", "-m", "first")
+	write("a/b.txt", "b\nb\n")
+	assert.Nil(t, os.Chmod(filepath.Join(root, "exec.sh"), 0644))
+	assert.Nil(t, os.RemoveAll(filepath.Join(root, "dir")))
+	assert.Nil(t, os.Remove(filepath.Join(root, "link")))
+	write("link", "a.txt\n")
+	assert.Nil(t, os.Remove(filepath.Join(root, "f")))
+	write("f/g", "dir\n")
+	write("new/deep/file.txt", "new\n")
+	run("add", "-A")
+	run("update-index", "--add", "--cacheinfo", "160000,"+strings.Repeat("1", 40)+",libs/sub")
+	run("commit", "-q", "-m", "second")
+	write("a.txt", "a\na\n")
+	run("rm", "-q", "--cached", "libs/sub")
+	run("rm", "-q", "-r", "a")
+	run("commit", "-q", "-m", "third")
+	repository, err := git.PlainOpen(root)
+	assert.Nil(t, err)
+	var commits []*object.Commit
+	for _, rev := range strings.Fields(run("rev-list", "--reverse", "HEAD")) {
+		commit, err := repository.CommitObject(plumbing.NewHash(rev))
+		assert.Nil(t, err)
+		commits = append(commits, commit)
+	}
+	return root, repository, commits
+}
+
+// assertEqualChanges compares the changes except the pointers to the parent trees.
+func assertEqualChanges(t *testing.T, expected, actual object.Changes) {
+	treeHash := func(tree *object.Tree) plumbing.Hash {
+		if tree == nil {
+			return plumbing.ZeroHash
+		}
+		return tree.Hash
+	}
+	assert.Len(t, actual, len(expected))
+	for i := 0; i < len(expected) && i < len(actual); i++ {
+		for _, pair := range [][2]object.ChangeEntry{
+			{expected[i].From, actual[i].From}, {expected[i].To, actual[i].To}} {
+			assert.Equal(t, pair[0].Name, pair[1].Name)
+			assert.Equal(t, pair[0].TreeEntry, pair[1].TreeEntry)
+			assert.Equal(t, treeHash(pair[0].Tree), treeHash(pair[1].Tree), pair[0].Name)
+		}
+	}
+}
+
+func TestObjectBackendExec(t *testing.T) {
+	root, repository, commits := fixtureGitHistory(t)
+	defer os.RemoveAll(root)
+	goGit := NewObjectBackend(core.GitBackendGoGit, repository)
+	backend := NewObjectBackend(core.GitBackendExec, repository)
+	defer backend.Close()
+	assert.IsType(t, &goGitObjectBackend{}, goGit)
+	assert.IsType(t, &execObjectBackend{}, backend)
+	var previous *object.Tree
+	for _, commit := range commits {
+		tree, err := commit.Tree()
+		assert.Nil(t, err)
+		expected, err := goGit.ListTree(tree)
+		assert.Nil(t, err)
+		actual, err := backend.ListTree(tree)
+		assert.Nil(t, err)
+		assertEqualChanges(t, expected, actual)
+		if previous != nil {
+			expected, err = goGit.DiffTree(previous, tree)
+			assert.Nil(t, err)
+			actual, err = backend.DiffTree(previous, tree)
+			assert.Nil(t, err)
+			assertEqualChanges(t, expected, actual)
+			for _, change := range actual {
+				// the parent trees must be usable
+				for _, entry := range []object.ChangeEntry{change.From, change.To} {
+					if entry.Tree != nil {
+						_, err = entry.Tree.FindEntry(entry.TreeEntry.Name)
+						assert.Nil(t, err, entry.Name)
+					}
+				}
+			}
+		}
+		previous = tree
+	}
+	// a.txt, a/b.txt, a-b/c.txt, dir/sub/x.txt, exec.sh, f, link; libs/sub is skipped
+	first, _ := backend.ListTree(mustTree(commits[0]))
+	assert.Len(t, first, 7)
+	second, _ := backend.DiffTree(mustTree(commits[0]), mustTree(commits[1]))
+	var names []string
+	for _, change := range second {
+		names = append(names, changeName(change))
+	}
+	assert.Equal(t, []string{"a/b.txt", "dir/sub/x.txt", "exec.sh", "f", "f/g", "libs/sub",
+		"link", "new/deep/file.txt"}, names)
+}
+
+func mustTree(commit *object.Commit) *object.Tree {
+	tree, err := commit.Tree()
+	if err != nil {
+		panic(err)
+	}
+	return tree
+}
+
+func TestObjectBackendExecBlob(t *testing.T) {
+	root, repository, commits := fixtureGitHistory(t)
+	defer os.RemoveAll(root)
+	backend := NewObjectBackend(core.GitBackendExec, repository)
+	defer backend.Close()
+	changes, err := backend.ListTree(mustTree(commits[0]))
+	assert.Nil(t, err)
+	for _, change := range changes {
+		expected, err := repository.BlobObject(change.To.TreeEntry.Hash)
+		assert.Nil(t, err)
+		blob, err := backend.Blob(change.To.TreeEntry.Hash)
+		assert.Nil(t, err)
+		assert.Equal(t, expected.Hash, blob.Hash)
+		assert.Equal(t, expected.Size, blob.Size)
+		expectedContents, _ := BlobToString(expected)
+		contents, err := BlobToString(blob)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedContents, contents)
+	}
+	_, err = backend.Blob(plumbing.NewHash(testSubmoduleHash))
+	assert.Equal(t, plumbing.ErrObjectNotFound, err)
+	// not a blob
+	_, err = backend.Blob(commits[0].Hash)
+	assert.Equal(t, plumbing.ErrObjectNotFound, err)
+	assert.Nil(t, backend.Close())
+	// restarts cat-file
+	blob, err := backend.Blob(changes[0].To.TreeEntry.Hash)
+	assert.Nil(t, err)
+	assert.Equal(t, changes[0].To.TreeEntry.Hash, blob.Hash)
+}
+
+func TestObjectBackendFallback(t *testing.T) {
+	assert.IsType(t, &goGitObjectBackend{}, NewObjectBackend(core.GitBackendExec, nil))
+	repository, err := git.Init(memory.NewStorage(), nil)
+	assert.Nil(t, err)
+	assert.IsType(t, &goGitObjectBackend{}, NewObjectBackend(core.GitBackendExec, repository))
+	assert.IsType(t, &goGitObjectBackend{}, NewObjectBackend("", repository))
+}
+
+func TestLessChangePaths(t *testing.T) {
+	assert.True(t, lessChangePaths("a/b.txt", "a.txt"))
+	assert.False(t, lessChangePaths("a.txt", "a/b.txt"))
+	assert.True(t, lessChangePaths("a/b.txt", "a-b/c.txt"))
+	assert.True(t, lessChangePaths("f", "f/g"))
+	assert.False(t, lessChangePaths("f/g", "f"))
+	assert.False(t, lessChangePaths("f", "f"))
+	assert.True(t, lessChangePaths("x/a/b", "x/b"))
+}
@@ -1,8 +1,11 @@
 package plumbing
 
 import (
+	"io"
 	"log"
+	"path"
 	"sort"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
@@ -16,6 +19,7 @@ import (
 
 // RenameAnalysis improves TreeDiff's results by searching for changed blobs under different
 // paths which are likely to be the result of a rename with subsequent edits.
+// The blobs which are not text are renamed only if their hashes are equal, see BlobClasses.
 // RenameAnalysis is a PipelineItem.
 type RenameAnalysis struct {
 	core.NoopMerger
@@ -23,8 +27,61 @@ type RenameAnalysis struct {
 	// It has the same units as cgit's -X rename-threshold or -M. Better to
 	// set it to the default value of 90 (90%).
 	SimilarityThreshold int
+	// FindCopies enables matching the added files against the unchanged files in the tree,
+	// similar to cgit's -C --find-copies-harder. It is expensive on big trees.
+	FindCopies bool
+	// CrossCommitWindow is the number of commits during which the deleted files are retained
+	// to be matched with the files added later. 0 disables the cross-commit renames.
+	CrossCommitWindow int
 
 	repository *git.Repository
+	// maxBlobSize is the copy of BlobCache.MaxSize to classify the copy sources
+	// if loadBlob is nil
+	maxBlobSize int
+	// loadBlob reads the copy sources through BlobCache, see FactBlobCacheLoader.
+	loadBlob BlobLoader
+	// sources are the sizes and the classes of the blobs which may be copied if FindCopies
+	// is enabled. They are collected from the changes and then narrowed down to the current tree
+	// so that the blobs outside of the size window are never read.
+	sources map[plumbing.Hash]copySource
+	// diff calculates the differences of the copies the same way as FileDiff
+	diff FileDiff
+	// commits is the number of consumed commits
+	commits int
+	// retained are the files which were deleted within CrossCommitWindow commits
+	retained []retainedDeletion
+}
+
+// copySource is the size and the class of a blob which may be copied.
+type copySource struct {
+	size  int64
+	class BlobClass
+}
+
+// retainedDeletion is a file deletion which RenameAnalysis remembers for
+// RenameAnalysis.CrossCommitWindow commits.
+type retainedDeletion struct {
+	change *object.Change
+	blob   *object.Blob
+	// commit is the index of the commit which deleted the file
+	commit int
+}
+
+// FileCopy is the change kind which RenameAnalysis emits in DependencyTreeCopies if an added
+// file is a copy of another file which exists before and after the commit, or if an added file
+// is the renamed version of a file deleted in one of the previous commits. The insertion
+// of the copy remains in DependencyTreeChanges so that the analyses which do not care
+// about copies treat it as a new file.
+type FileCopy struct {
+	// From is the copied unchanged file or the deleted file.
+	From object.ChangeEntry
+	// To is the added file.
+	To object.ChangeEntry
+	// Diff is the difference between From and To; it is empty if the contents are identical.
+	Diff FileDiffData
+	// Deleted indicates that From was deleted within RenameAnalysis.CrossCommitWindow
+	// commits before, that is, this is a rename which spans several commits.
+	Deleted bool
 }
 
 const (
@@ -35,6 +92,19 @@ const (
 	// ConfigRenameAnalysisSimilarityThreshold is the name of the configuration option
 	// (RenameAnalysis.Configure()) which sets the similarity threshold.
 	ConfigRenameAnalysisSimilarityThreshold = "RenameAnalysis.SimilarityThreshold"
+
+	// ConfigRenameAnalysisFindCopies is the name of the configuration option
+	// (RenameAnalysis.Configure()) which enables the copy detection.
+	ConfigRenameAnalysisFindCopies = "RenameAnalysis.FindCopies"
+
+	// ConfigRenameAnalysisCrossCommitWindow is the name of the configuration option
+	// (RenameAnalysis.Configure()) which sets RenameAnalysis.CrossCommitWindow.
+	ConfigRenameAnalysisCrossCommitWindow = "RenameAnalysis.CrossCommitWindow"
+
+	// DependencyTreeCopies is the name of the dependency provided by RenameAnalysis.
+	// It is the list of FileCopy-s, always empty unless RenameAnalysis.FindCopies is true
+	// or RenameAnalysis.CrossCommitWindow is positive.
+	DependencyTreeCopies = "copies"
 )
 
 // Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
@@ -46,7 +116,7 @@ func (ra *RenameAnalysis) Name() string {
 // Each produced entity will be inserted into `deps` of dependent Consume()-s according
 // to this list. Also used by core.Registry to build the global map of providers.
 func (ra *RenameAnalysis) Provides() []string {
-	arr := [...]string{DependencyTreeChanges}
+	arr := [...]string{DependencyTreeChanges, DependencyTreeCopies}
 	return arr[:]
 }
 
@@ -54,7 +124,7 @@ func (ra *RenameAnalysis) Provides() []string {
 // Each requested entity will be inserted into `deps` of Consume(). In turn, those
 // entities are Provides() upstream.
 func (ra *RenameAnalysis) Requires() []string {
-	arr := [...]string{DependencyBlobCache, DependencyTreeChanges}
+	arr := [...]string{DependencyBlobCache, DependencyBlobClasses, DependencyTreeChanges}
 	return arr[:]
 }
 
@@ -65,7 +135,19 @@ func (ra *RenameAnalysis) ListConfigurationOptions() []core.ConfigurationOption
 		Description: "The threshold on the similarity index used to detect renames.",
 		Flag:        "M",
 		Type:        core.IntConfigurationOption,
-		Default:     RenameAnalysisDefaultThreshold},
+		Default:     RenameAnalysisDefaultThreshold}, {
+		Name: ConfigRenameAnalysisFindCopies,
+		Description: "Detect the added files which are copies of the unchanged files " +
+			"so that they inherit the history.",
+		Flag:    "C",
+		Type:    core.BoolConfigurationOption,
+		Default: false}, {
+		Name: ConfigRenameAnalysisCrossCommitWindow,
+		Description: "The number of commits during which the deleted files can be matched " +
+			"with the added files as renames. 0 disables.",
+		Flag:    "renames-window",
+		Type:    core.IntConfigurationOption,
+		Default: 0},
 	}
 	return options[:]
 }
@@ -75,6 +157,19 @@ func (ra *RenameAnalysis) Configure(facts map[string]interface{}) {
 	if val, exists := facts[ConfigRenameAnalysisSimilarityThreshold].(int); exists {
 		ra.SimilarityThreshold = val
 	}
+	if val, exists := facts[ConfigRenameAnalysisFindCopies].(bool); exists {
+		ra.FindCopies = val
+	}
+	if val, exists := facts[ConfigRenameAnalysisCrossCommitWindow].(int); exists {
+		ra.CrossCommitWindow = val
+	}
+	if val, exists := facts[ConfigBlobCacheMaxSize].(int); exists {
+		ra.maxBlobSize = val
+	}
+	if val, exists := facts[FactBlobCacheLoader].(BlobLoader); exists {
+		ra.loadBlob = val
+	}
+	ra.diff.Configure(facts)
 }
 
 // Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
@@ -85,7 +180,15 @@ func (ra *RenameAnalysis) Initialize(repository *git.Repository) {
 			RenameAnalysisDefaultThreshold)
 		ra.SimilarityThreshold = RenameAnalysisDefaultThreshold
 	}
+	if ra.CrossCommitWindow < 0 {
+		log.Printf("Warning: adjusted the cross-commit renames window to 0\n")
+		ra.CrossCommitWindow = 0
+	}
 	ra.repository = repository
+	ra.diff.CleanupDisabled = true
+	ra.commits = 0
+	ra.retained = nil
+	ra.sources = map[plumbing.Hash]copySource{}
 }
 
 // Consume runs this PipelineItem on the next commit data.
@@ -96,6 +199,13 @@ func (ra *RenameAnalysis) Initialize(repository *git.Repository) {
 func (ra *RenameAnalysis) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
 	changes := deps[DependencyTreeChanges].(object.Changes)
 	cache := deps[DependencyBlobCache].(map[plumbing.Hash]*object.Blob)
+	classes, _ := deps[DependencyBlobClasses].(BlobClasses)
+	ra.commits++
+	if ra.FindCopies {
+		for hash, blob := range cache {
+			ra.sources[hash] = copySource{size: blob.Size, class: classes.Classes[hash]}
+		}
+	}
 
 	reducedChanges := make(object.Changes, 0, changes.Len())
 
@@ -146,10 +256,30 @@ func (ra *RenameAnalysis) Consume(deps map[string]interface{}) (map[string]inter
 	for ; d < deleted.Len(); d++ {
 		stillDeleted = append(stillDeleted, deleted[d].change)
 	}
+	// the blobs which are not text are never compared
+	var nonText object.Changes
+	stillAdded, nonText = partitionText(stillAdded, classes, nonText)
+	stillDeleted, nonText = partitionText(stillDeleted, classes, nonText)
+
+	// Stage 2 - prefer the pairs which are consistent with the directory moves
+	// A directory is moved if most of the deleted files in it are added under the same
+	// new directory, see detectDirectoryMoves(). Similar files of close sizes are not crossed
+	// then, because the same relative path goes first.
+	if moves := detectDirectoryMoves(deleted, added); len(moves) > 0 {
+		var movedChanges object.Changes
+		var err error
+		movedChanges, stillAdded, stillDeleted, err = ra.matchDirectoryMoves(
+			moves, stillAdded, stillDeleted, cache)
+		if err != nil {
+			return nil, err
+		}
+		reducedChanges = append(reducedChanges, movedChanges...)
+	}
 
-	// Stage 2 - apply the similarity threshold
-	// n^2 but actually linear
-	// We sort the blobs by size and do the single linear scan.
+	// Stage 3 - apply the similarity threshold
+	// We sort the blobs by size and slide the window of the deleted blobs with close sizes.
+	// Small windows are checked exhaustively, big windows are narrowed down with
+	// the MinHash fingerprints of the lines, see renameCandidates.
 	addedBlobs := make(sortableBlobs, 0, stillAdded.Len())
 	deletedBlobs := make(sortableBlobs, 0, stillDeleted.Len())
 	for _, change := range stillAdded {
@@ -164,49 +294,460 @@ func (ra *RenameAnalysis) Consume(deps map[string]interface{}) (map[string]inter
 	}
 	sort.Sort(addedBlobs)
 	sort.Sort(deletedBlobs)
+	matches, err := ra.matchBlobs(addedBlobs, deletedBlobs, cache, cache)
+	if err != nil {
+		return nil, err
+	}
+	addedMatched := make([]bool, addedBlobs.Len())
+	deletedMatched := make([]bool, deletedBlobs.Len())
+	for a, d := range matches {
+		if d >= 0 {
+			addedMatched[a] = true
+			deletedMatched[d] = true
+			reducedChanges = append(
+				reducedChanges,
+				&object.Change{From: deletedBlobs[d].change.From, To: addedBlobs[a].change.To})
+		}
+	}
+	addedBlobs = addedBlobs.filter(addedMatched)
+	deletedBlobs = deletedBlobs.filter(deletedMatched)
+
+	// Stage 4 - we give up, everything left are independent additions and deletions
+	for _, blob := range addedBlobs {
+		reducedChanges = append(reducedChanges, blob.change)
+	}
+	for _, blob := range deletedBlobs {
+		reducedChanges = append(reducedChanges, blob.change)
+	}
+	reducedChanges = append(reducedChanges, nonText...)
+
+	// Stage 5 - the remaining additions can be the files deleted in the previous commits
+	copies := []FileCopy{}
+	if ra.CrossCommitWindow > 0 {
+		copies, addedBlobs, err = ra.matchRetained(addedBlobs, cache)
+		if err != nil {
+			return nil, err
+		}
+		for _, blob := range deletedBlobs {
+			ra.retained = append(ra.retained, retainedDeletion{
+				change: blob.change, blob: cache[blob.change.From.TreeEntry.Hash],
+				commit: ra.commits})
+		}
+	}
+
+	// Stage 6 - the remaining additions can be copies of the unchanged files
+	if ra.FindCopies && addedBlobs.Len() > 0 {
+		commit := deps[core.DependencyCommit].(*object.Commit)
+		fileCopies, err := ra.findCopies(commit, changes, addedBlobs, cache)
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, fileCopies...)
+	}
+	return map[string]interface{}{
+		DependencyTreeChanges: reducedChanges, DependencyTreeCopies: copies}, nil
+}
+
+// findCopies matches the added files against the files in the commit's tree which were not
+// changed. Identical blobs are matched first, then the similarity threshold is applied.
+func (ra *RenameAnalysis) findCopies(
+	commit *object.Commit, changes object.Changes, addedBlobs sortableBlobs,
+	cache map[plumbing.Hash]*object.Blob) ([]FileCopy, error) {
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changed := map[string]bool{}
+	for _, change := range changes {
+		changed[change.From.Name] = true
+		changed[change.To.Name] = true
+	}
+	sources := []object.ChangeEntry{}
+	sourcesByHash := map[plumbing.Hash]int{}
+	// the sizes of the blobs which are no longer in the tree are forgotten
+	treeSources := map[plumbing.Hash]copySource{}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !entry.Mode.IsFile() {
+			continue
+		}
+		if source, exists := ra.sources[entry.Hash]; exists {
+			treeSources[entry.Hash] = source
+		}
+		if changed[name] {
+			continue
+		}
+		if _, exists := sourcesByHash[entry.Hash]; !exists {
+			sourcesByHash[entry.Hash] = len(sources)
+		}
+		sources = append(sources, object.ChangeEntry{Name: name, Tree: tree, TreeEntry: entry})
+	}
+	ra.sources = treeSources
+	copies := []FileCopy{}
+	var sourceBlobs sortableBlobs
+	sourceCache := map[plumbing.Hash]*object.Blob{}
+	for _, added := range addedBlobs {
+		if added.size == 0 {
+			// every empty file is a copy of any other empty file
+			continue
+		}
+		to := added.change.To
+		if index, exists := sourcesByHash[to.TreeEntry.Hash]; exists {
+			copies = append(copies, FileCopy{From: sources[index], To: to})
+			continue
+		}
+		if sourceBlobs == nil {
+			sourceBlobs = make(sortableBlobs, 0, len(sources))
+			for i := range sources {
+				hash := sources[i].TreeEntry.Hash
+				source, exists := ra.sources[hash]
+				if !exists {
+					blob, class, err := ra.readBlob(hash)
+					if err != nil {
+						return nil, err
+					}
+					source = copySource{size: blob.Size, class: class}
+					ra.sources[hash] = source
+				}
+				if source.class != BlobText {
+					continue
+				}
+				sourceBlobs = append(sourceBlobs, sortableBlob{
+					change: &object.Change{From: sources[i]}, size: source.size})
+			}
+			sort.Stable(sourceBlobs)
+		}
+		myBlob := cache[to.TreeEntry.Hash]
+		s := sort.Search(sourceBlobs.Len(), func(i int) bool {
+			return sourceBlobs[i].size >= added.size || ra.sizesAreClose(added.size, sourceBlobs[i].size)
+		})
+		for ; s < sourceBlobs.Len() && ra.sizesAreClose(added.size, sourceBlobs[s].size); s++ {
+			from := sourceBlobs[s].change.From
+			fromBlob, exists := sourceCache[from.TreeEntry.Hash]
+			if !exists {
+				var err error
+				fromBlob, _, err = ra.readBlob(from.TreeEntry.Hash)
+				if err != nil {
+					return nil, err
+				}
+				sourceCache[from.TreeEntry.Hash] = fromBlob
+			}
+			diff, err := ra.diff.diffBlobs(fromBlob, myBlob)
+			if err != nil {
+				return nil, err
+			}
+			if diffSimilarity(diff) >= ra.SimilarityThreshold {
+				copies = append(copies, FileCopy{From: from, To: to, Diff: diff})
+				break
+			}
+		}
+	}
+	return copies, nil
+}
+
+// readBlob returns the classified copy source through BlobCache or directly from
+// the repository if there is no BlobCache.
+func (ra *RenameAnalysis) readBlob(hash plumbing.Hash) (*object.Blob, BlobClass, error) {
+	if ra.loadBlob != nil {
+		return ra.loadBlob(hash)
+	}
+	blob, err := ra.repository.BlobObject(hash)
+	if err != nil {
+		return nil, BlobText, err
+	}
+	class, err := ClassifyBlob(blob, ra.maxBlobSize)
+	return blob, class, err
+}
+
+// Fork clones this PipelineItem. The retained deletions are copied because they
+// are different in each branch.
+func (ra *RenameAnalysis) Fork(n int) []core.PipelineItem {
+	if ra.CrossCommitWindow == 0 {
+		return core.ForkSamePipelineItem(ra, n)
+	}
+	result := make([]core.PipelineItem, n)
+	for i := range result {
+		clone := *ra
+		clone.retained = append([]retainedDeletion{}, ra.retained...)
+		result[i] = &clone
+	}
+	return result
+}
+
+// matchBlobs pairs the added blobs with the similar deleted blobs. Both slices must be sorted
+// by size. We slide the window of the deleted blobs with close sizes. Small windows are checked
+// exhaustively, big windows are narrowed down with the MinHash fingerprints of the lines,
+// see renameCandidates. The result maps the index of each added blob to the index of
+// the matched deleted blob or -1.
+func (ra *RenameAnalysis) matchBlobs(
+	addedBlobs, deletedBlobs sortableBlobs,
+	addedCache, deletedCache map[plumbing.Hash]*object.Blob) ([]int, error) {
+
+	matches := make([]int, addedBlobs.Len())
+	candidates := &renameCandidates{ra: ra, deleted: deletedBlobs, cache: deletedCache}
+	deletedMatched := make([]bool, deletedBlobs.Len())
 	dStart := 0
-	for a = 0; a < addedBlobs.Len(); a++ {
-		myBlob := cache[addedBlobs[a].change.To.TreeEntry.Hash]
+	for a := range addedBlobs {
+		matches[a] = -1
+		myBlob := addedCache[addedBlobs[a].change.To.TreeEntry.Hash]
 		mySize := addedBlobs[a].size
-		for d = dStart; d < deletedBlobs.Len() && !ra.sizesAreClose(mySize, deletedBlobs[d].size); d++ {
+		for dStart < deletedBlobs.Len() && !ra.sizesAreClose(mySize, deletedBlobs[dStart].size) {
+			dStart++
+		}
+		dEnd := dStart
+		for dEnd < deletedBlobs.Len() && ra.sizesAreClose(mySize, deletedBlobs[dEnd].size) {
+			dEnd++
 		}
-		dStart = d
-		foundMatch := false
-		for d = dStart; d < deletedBlobs.Len() && ra.sizesAreClose(mySize, deletedBlobs[d].size); d++ {
-			blobsAreClose, err := ra.blobsAreClose(
-				myBlob, cache[deletedBlobs[d].change.From.TreeEntry.Hash])
+		for _, exhaustive := range [...]bool{false, true} {
+			window, err := candidates.Find(myBlob, dStart, dEnd, deletedMatched, exhaustive)
 			if err != nil {
 				return nil, err
 			}
-			if blobsAreClose {
-				foundMatch = true
-				reducedChanges = append(
-					reducedChanges,
-					&object.Change{From: deletedBlobs[d].change.From,
-						To: addedBlobs[a].change.To})
+			for _, d := range window {
+				blobsAreClose, err := ra.blobsAreClose(
+					myBlob, deletedCache[deletedBlobs[d].change.From.TreeEntry.Hash])
+				if err != nil {
+					return nil, err
+				}
+				if blobsAreClose {
+					matches[a] = d
+					deletedMatched[d] = true
+					break
+				}
+			}
+			if matches[a] >= 0 {
 				break
 			}
 		}
-		if foundMatch {
-			addedBlobs = append(addedBlobs[:a], addedBlobs[a+1:]...)
-			a--
-			deletedBlobs = append(deletedBlobs[:d], deletedBlobs[d+1:]...)
+	}
+	return matches, nil
+}
+
+// matchRetained matches the added blobs with the files which were deleted within
+// CrossCommitWindow commits. The matched deletions are forgotten, and so are the expired ones.
+// It returns the cross-commit renames and the unmatched added blobs.
+func (ra *RenameAnalysis) matchRetained(
+	addedBlobs sortableBlobs, cache map[plumbing.Hash]*object.Blob) (
+	[]FileCopy, sortableBlobs, error) {
+
+	retained := ra.retained[:0]
+	for _, deletion := range ra.retained {
+		if ra.commits-deletion.commit <= ra.CrossCommitWindow {
+			retained = append(retained, deletion)
+		}
+	}
+	ra.retained = retained
+	renames := []FileCopy{}
+	if len(retained) == 0 || addedBlobs.Len() == 0 {
+		return renames, addedBlobs, nil
+	}
+	// identical blobs go first, the most recent deletion wins
+	byHash := map[plumbing.Hash]int{}
+	for i, deletion := range retained {
+		byHash[deletion.change.From.TreeEntry.Hash] = i
+	}
+	retainedMatched := make([]bool, len(retained))
+	addedMatched := make([]bool, addedBlobs.Len())
+	for a, added := range addedBlobs {
+		if i, exists := byHash[added.change.To.TreeEntry.Hash]; exists && !retainedMatched[i] {
+			retainedMatched[i] = true
+			addedMatched[a] = true
+			renames = append(renames, FileCopy{
+				From: retained[i].change.From, To: added.change.To, Deleted: true})
+		}
+	}
+	deletedBlobs := make(sortableBlobs, 0, len(retained))
+	deletedCache := map[plumbing.Hash]*object.Blob{}
+	indices := map[*object.Change]int{}
+	for i, deletion := range retained {
+		if !retainedMatched[i] {
+			deletedBlobs = append(deletedBlobs, sortableBlob{
+				change: deletion.change, size: deletion.blob.Size})
+			deletedCache[deletion.blob.Hash] = deletion.blob
+			indices[deletion.change] = i
+		}
+	}
+	sort.Stable(deletedBlobs)
+	addedBlobs = addedBlobs.filter(addedMatched)
+	matches, err := ra.matchBlobs(addedBlobs, deletedBlobs, cache, deletedCache)
+	if err != nil {
+		return nil, nil, err
+	}
+	addedMatched = make([]bool, addedBlobs.Len())
+	for a, d := range matches {
+		if d < 0 {
+			continue
+		}
+		addedMatched[a] = true
+		deletion := deletedBlobs[d].change
+		retainedMatched[indices[deletion]] = true
+		diff, err := ra.diff.diffBlobs(deletedCache[deletion.From.TreeEntry.Hash],
+			cache[addedBlobs[a].change.To.TreeEntry.Hash])
+		if err != nil {
+			return nil, nil, err
+		}
+		renames = append(renames, FileCopy{
+			From: deletion.From, To: addedBlobs[a].change.To, Diff: diff, Deleted: true})
+	}
+	ra.retained = make([]retainedDeletion, 0, len(retained))
+	for i, deletion := range retained {
+		if !retainedMatched[i] {
+			ra.retained = append(ra.retained, deletion)
+		}
+	}
+	return renames, addedBlobs.filter(addedMatched), nil
+}
+
+// detectDirectoryMoves finds the directories which were moved in the commit. Each deleted file
+// votes for the pairs of the old and the new path prefixes which remain after stripping
+// the longest common suffix with the added files of the same base name. The most voted
+// new prefix wins if it has at least two votes and it covers more than a half of the deleted
+// files under the old prefix. The result maps the old prefixes to the new prefixes,
+// both are empty or end with a slash.
+func detectDirectoryMoves(deleted, added sortableChanges) map[string]string {
+	byBase := map[string][]string{}
+	for _, change := range added {
+		name := change.change.To.Name
+		byBase[path.Base(name)] = append(byBase[path.Base(name)], name)
+	}
+	votes := map[[2]string]int{}
+	// the number of deleted files under each prefix, recursively
+	totals := map[string]int{}
+	for _, change := range deleted {
+		name := change.change.From.Name
+		for _, prefix := range pathPrefixes(name) {
+			totals[prefix]++
+		}
+		for _, other := range byBase[path.Base(name)] {
+			oldPrefix, newPrefix := stripCommonSuffix(name, other)
+			if oldPrefix != newPrefix {
+				votes[[2]string{oldPrefix, newPrefix}]++
+			}
+		}
+	}
+	moves := map[string]string{}
+	best := map[string]int{}
+	for move, count := range votes {
+		if count < 2 || count*2 <= totals[move[0]] {
+			continue
+		}
+		if count > best[move[0]] || (count == best[move[0]] && move[1] < moves[move[0]]) {
+			best[move[0]] = count
+			moves[move[0]] = move[1]
 		}
 	}
+	return moves
+}
 
-	// Stage 3 - we give up, everything left are independent additions and deletions
-	for _, blob := range addedBlobs {
-		reducedChanges = append(reducedChanges, blob.change)
+// pathPrefixes returns the directory prefixes of the path from the longest to the shortest,
+// including the empty root prefix. "a/b/c" yields "a/b/", "a/", "".
+func pathPrefixes(name string) []string {
+	var prefixes []string
+	for i := strings.LastIndexByte(name, '/'); i >= 0; i = strings.LastIndexByte(name[:i], '/') {
+		prefixes = append(prefixes, name[:i+1])
 	}
-	for _, blob := range deletedBlobs {
-		reducedChanges = append(reducedChanges, blob.change)
+	return append(prefixes, "")
+}
+
+// stripCommonSuffix removes the longest common sequence of trailing path components
+// and returns the remaining prefixes.
+func stripCommonSuffix(name1, name2 string) (string, string) {
+	parts1 := strings.Split(name1, "/")
+	parts2 := strings.Split(name2, "/")
+	i, j := len(parts1), len(parts2)
+	for i > 0 && j > 0 && parts1[i-1] == parts2[j-1] {
+		i--
+		j--
 	}
-	return map[string]interface{}{DependencyTreeChanges: reducedChanges}, nil
+	prefix := func(parts []string) string {
+		if len(parts) == 0 {
+			return ""
+		}
+		return strings.Join(parts, "/") + "/"
+	}
+	return prefix(parts1[:i]), prefix(parts2[:j])
 }
 
-// Fork clones this PipelineItem.
-func (ra *RenameAnalysis) Fork(n int) []core.PipelineItem {
-	return core.ForkSamePipelineItem(ra, n)
+// matchDirectoryMoves pairs each deleted file under a moved directory with the added file
+// at the same relative path in the new directory, provided that they are similar.
+// Nested moves are tried before the moves of their parents. It returns the found renames
+// and the remaining added and deleted changes.
+func (ra *RenameAnalysis) matchDirectoryMoves(
+	moves map[string]string, added, deleted object.Changes,
+	cache map[plumbing.Hash]*object.Blob) (object.Changes, object.Changes, object.Changes, error) {
+
+	addedIndex := map[string]int{}
+	for i, change := range added {
+		addedIndex[change.To.Name] = i
+	}
+	addedMatched := make([]bool, len(added))
+	renames := object.Changes{}
+	stillDeleted := make(object.Changes, 0, len(deleted))
+	for _, change := range deleted {
+		matched := false
+		for _, prefix := range pathPrefixes(change.From.Name) {
+			newPrefix, exists := moves[prefix]
+			if !exists {
+				continue
+			}
+			i, exists := addedIndex[newPrefix+change.From.Name[len(prefix):]]
+			if !exists || addedMatched[i] {
+				continue
+			}
+			blobFrom := cache[change.From.TreeEntry.Hash]
+			blobTo := cache[added[i].To.TreeEntry.Hash]
+			if !ra.sizesAreClose(blobFrom.Size, blobTo.Size) {
+				continue
+			}
+			similar, err := ra.blobsAreClose(blobFrom, blobTo)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if similar {
+				matched = true
+				addedMatched[i] = true
+				renames = append(renames, &object.Change{From: change.From, To: added[i].To})
+				break
+			}
+		}
+		if !matched {
+			stillDeleted = append(stillDeleted, change)
+		}
+	}
+	stillAdded := make(object.Changes, 0, len(added))
+	for i, change := range added {
+		if !addedMatched[i] {
+			stillAdded = append(stillAdded, change)
+		}
+	}
+	return renames, stillAdded, stillDeleted, nil
+}
+
+// partitionText returns the changes whose blobs are text and appends the rest to `nonText`.
+func partitionText(changes object.Changes, classes BlobClasses, nonText object.Changes) (
+	object.Changes, object.Changes) {
+	text := make(object.Changes, 0, len(changes))
+	for _, change := range changes {
+		entry := change.To
+		if entry.Name == "" {
+			entry = change.From
+		}
+		if classes.Class(entry.TreeEntry.Hash) == BlobText {
+			text = append(text, change)
+		} else {
+			nonText = append(nonText, change)
+		}
+	}
+	return text, nonText
 }
 
 func (ra *RenameAnalysis) sizesAreClose(size1 int64, size2 int64) bool {
@@ -227,14 +768,20 @@ func (ra *RenameAnalysis) blobsAreClose(
 	dmp := diffmatchpatch.New()
 	src, dst, _ := dmp.DiffLinesToRunes(strFrom, strTo)
 	diffs := dmp.DiffMainRunes(src, dst, false)
+	similarity := diffSimilarity(FileDiffData{
+		OldLinesOfCode: len(src), NewLinesOfCode: len(dst), Diffs: diffs})
+	return similarity >= ra.SimilarityThreshold, nil
+}
+
+// diffSimilarity returns the percentage of the common lines relative to the smaller file.
+func diffSimilarity(diff FileDiffData) int {
 	common := 0
-	for _, edit := range diffs {
+	for _, edit := range diff.Diffs {
 		if edit.Type == diffmatchpatch.DiffEqual {
 			common += utf8.RuneCountInString(edit.Text)
 		}
 	}
-	similarity := common * 100 / internal.Max(1, internal.Min(len(src), len(dst)))
-	return similarity >= ra.SimilarityThreshold, nil
+	return common * 100 / internal.Max(1, internal.Min(diff.OldLinesOfCode, diff.NewLinesOfCode))
 }
 
 type sortableChange struct {
@@ -288,6 +835,103 @@ func (slice sortableBlobs) Swap(i, j int) {
 	slice[i], slice[j] = slice[j], slice[i]
 }
 
+// filter returns the blobs which are not marked in `exclude`.
+func (slice sortableBlobs) filter(exclude []bool) sortableBlobs {
+	result := make(sortableBlobs, 0, len(slice))
+	for i, blob := range slice {
+		if !exclude[i] {
+			result = append(result, blob)
+		}
+	}
+	return result
+}
+
+const (
+	// renameExhaustiveWindow is the maximum number of the deleted blobs with close sizes
+	// which are compared with an added blob one by one in the original order.
+	renameExhaustiveWindow = 16
+	// renameMinhashMargin is the maximum difference between SimilarityThreshold and
+	// the estimated similarity of a candidate which is not in the same LSH bucket.
+	// The candidates which are estimated to be even less similar are never compared.
+	renameMinhashMargin = 20
+)
+
+// renameCandidates chooses which deleted blobs should be compared with an added blob.
+// The fingerprints and the index are calculated lazily on the first big window.
+type renameCandidates struct {
+	ra           *RenameAnalysis
+	deleted      sortableBlobs
+	cache        map[plumbing.Hash]*object.Blob
+	fingerprints []*lineFingerprint
+	index        *fingerprintIndex
+	// last is the fingerprint of the most recent added blob, Find() is called twice for it
+	last     *lineFingerprint
+	lastHash plumbing.Hash
+}
+
+// Find returns the indices of the unmatched deleted blobs in [start, end) in the order
+// of comparison. If the window is small, all the blobs are returned at once when
+// `exhaustive` is false. Otherwise, the blobs which share an LSH bucket with `blob` are
+// returned when `exhaustive` is false, and the rest of the blobs with the estimated similarity
+// not worse than SimilarityThreshold - renameMinhashMargin are returned when `exhaustive`
+// is true. Both lists are sorted by the estimated similarity in descending order.
+func (rc *renameCandidates) Find(
+	blob *object.Blob, start, end int, matched []bool, exhaustive bool) ([]int, error) {
+	window := make([]int, 0, end-start)
+	for d := start; d < end; d++ {
+		if !matched[d] {
+			window = append(window, d)
+		}
+	}
+	if len(window) <= renameExhaustiveWindow {
+		if exhaustive {
+			return nil, nil
+		}
+		return window, nil
+	}
+	if rc.index == nil {
+		rc.fingerprints = make([]*lineFingerprint, rc.deleted.Len())
+		rc.index = newFingerprintIndex()
+		for d, deleted := range rc.deleted {
+			text, err := BlobToString(rc.cache[deleted.change.From.TreeEntry.Hash])
+			if err != nil {
+				return nil, err
+			}
+			rc.fingerprints[d] = newLineFingerprint(text)
+			rc.index.Add(d, rc.fingerprints[d])
+		}
+	}
+	if rc.last == nil || rc.lastHash != blob.Hash {
+		text, err := BlobToString(blob)
+		if err != nil {
+			return nil, err
+		}
+		rc.last = newLineFingerprint(text)
+		rc.lastHash = blob.Hash
+	}
+	fp := rc.last
+	collisions := map[int]bool{}
+	for _, d := range rc.index.Query(fp) {
+		collisions[d] = true
+	}
+	estimates := map[int]int{}
+	result := window[:0]
+	for _, d := range window {
+		if collisions[d] == exhaustive {
+			continue
+		}
+		estimates[d] = fp.similarity(rc.fingerprints[d])
+		if exhaustive && estimates[d] < rc.ra.SimilarityThreshold-renameMinhashMargin {
+			continue
+		}
+		result = append(result, d)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return estimates[result[i]] > estimates[result[j]]
+	})
+	return result, nil
+}
+
 func init() {
 	core.Registry.Register(&RenameAnalysis{})
 }
@@ -1,11 +1,18 @@
 package plumbing
 
 import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
 	"gopkg.in/src-d/hercules.v4/internal/core"
 	"gopkg.in/src-d/hercules.v4/internal/test"
 )
@@ -19,22 +26,34 @@ func fixtureRenameAnalysis() *RenameAnalysis {
 func TestRenameAnalysisMeta(t *testing.T) {
 	ra := fixtureRenameAnalysis()
 	assert.Equal(t, ra.Name(), "RenameAnalysis")
-	assert.Equal(t, len(ra.Provides()), 1)
+	assert.Equal(t, len(ra.Provides()), 2)
 	assert.Equal(t, ra.Provides()[0], DependencyTreeChanges)
-	assert.Equal(t, len(ra.Requires()), 2)
+	assert.Equal(t, ra.Provides()[1], DependencyTreeCopies)
+	assert.Equal(t, len(ra.Requires()), 3)
 	assert.Equal(t, ra.Requires()[0], DependencyBlobCache)
-	assert.Equal(t, ra.Requires()[1], DependencyTreeChanges)
+	assert.Equal(t, ra.Requires()[1], DependencyBlobClasses)
+	assert.Equal(t, ra.Requires()[2], DependencyTreeChanges)
 	opts := ra.ListConfigurationOptions()
-	assert.Len(t, opts, 1)
+	assert.Len(t, opts, 3)
 	assert.Equal(t, opts[0].Name, ConfigRenameAnalysisSimilarityThreshold)
+	assert.Equal(t, opts[1].Name, ConfigRenameAnalysisFindCopies)
+	assert.Equal(t, opts[2].Name, ConfigRenameAnalysisCrossCommitWindow)
 	ra.SimilarityThreshold = 0
 	facts := map[string]interface{}{}
 	facts[ConfigRenameAnalysisSimilarityThreshold] = 70
+	facts[ConfigRenameAnalysisFindCopies] = true
+	facts[ConfigRenameAnalysisCrossCommitWindow] = 5
 	ra.Configure(facts)
 	assert.Equal(t, ra.SimilarityThreshold, 70)
+	assert.True(t, ra.FindCopies)
+	assert.Equal(t, ra.CrossCommitWindow, 5)
 	delete(facts, ConfigRenameAnalysisSimilarityThreshold)
+	delete(facts, ConfigRenameAnalysisFindCopies)
+	delete(facts, ConfigRenameAnalysisCrossCommitWindow)
 	ra.Configure(facts)
 	assert.Equal(t, ra.SimilarityThreshold, 70)
+	assert.True(t, ra.FindCopies)
+	assert.Equal(t, ra.CrossCommitWindow, 5)
 }
 
 func TestRenameAnalysisRegistration(t *testing.T) {
@@ -172,4 +191,448 @@ func TestRenameAnalysisFork(t *testing.T) {
 	ra2 := clones[0].(*RenameAnalysis)
 	assert.True(t, ra1 == ra2)
 	ra1.Merge([]core.PipelineItem{ra2})
+	ra1.CrossCommitWindow = 2
+	ra1.retained = []retainedDeletion{{commit: 1}}
+	clones = ra1.Fork(2)
+	assert.Len(t, clones, 2)
+	ra2 = clones[0].(*RenameAnalysis)
+	assert.False(t, ra1 == ra2)
+	assert.Equal(t, ra1.retained, ra2.retained)
+	ra2.retained[0].commit = 2
+	assert.Equal(t, 1, ra1.retained[0].commit)
+}
+
+// fixtureCommitWithFiles creates an in-memory repository with a single commit which contains
+// the specified files in the root directory.
+func fixtureCommitWithFiles(files map[string]string) (*git.Repository, *object.Commit) {
+	storage := memory.NewStorage()
+	repository, err := git.Init(storage, nil)
+	if err != nil {
+		panic(err)
+	}
+	store := func(encoder interface {
+		Encode(plumbing.EncodedObject) error
+	}) plumbing.Hash {
+		obj := storage.NewEncodedObject()
+		if err := encoder.Encode(obj); err != nil {
+			panic(err)
+		}
+		hash, err := storage.SetEncodedObject(obj)
+		if err != nil {
+			panic(err)
+		}
+		return hash
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tree := &object.Tree{}
+	for _, name := range names {
+		blob := storage.NewEncodedObject()
+		blob.SetType(plumbing.BlobObject)
+		writer, _ := blob.Writer()
+		writer.Write([]byte(files[name]))
+		writer.Close()
+		hash, err := storage.SetEncodedObject(blob)
+		if err != nil {
+			panic(err)
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{
+			Name: name, Mode: filemode.Regular, Hash: hash})
+	}
+	commit := &object.Commit{TreeHash: store(tree), Message: "test"}
+	commit, err = repository.CommitObject(store(commit))
+	if err != nil {
+		panic(err)
+	}
+	return repository, commit
+}
+
+func TestRenameAnalysisCopies(t *testing.T) {
+	lines := make([]string, 10)
+	for i := range lines {
+		lines[i] = "line " + string('a'+rune(i))
+	}
+	source := strings.Join(lines, "\n") + "\n"
+	lines[4] = "LINE E"
+	repository, commit := fixtureCommitWithFiles(map[string]string{
+		"source.go":  source,
+		"exact.go":   source,
+		"similar.go": strings.Join(lines, "\n") + "\n",
+		"new.go":     "something completely different\n",
+		"empty.go":   "",
+		"empty2.go":  "",
+	})
+	tree, _ := commit.Tree()
+	changes := object.Changes{}
+	cache := map[plumbing.Hash]*object.Blob{}
+	for _, name := range []string{"exact.go", "similar.go", "new.go", "empty2.go"} {
+		entry, err := tree.FindEntry(name)
+		assert.Nil(t, err)
+		changes = append(changes, &object.Change{To: object.ChangeEntry{
+			Name: name, Tree: tree, TreeEntry: *entry}})
+		cache[entry.Hash], _ = repository.BlobObject(entry.Hash)
+	}
+	ra := RenameAnalysis{SimilarityThreshold: 80}
+	ra.Initialize(repository)
+	deps := map[string]interface{}{
+		core.DependencyCommit: commit, DependencyTreeChanges: changes, DependencyBlobCache: cache}
+	res, err := ra.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyTreeChanges].(object.Changes), 4)
+	assert.Len(t, res[DependencyTreeCopies].([]FileCopy), 0)
+	ra.FindCopies = true
+	res, err = ra.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyTreeChanges].(object.Changes), 4)
+	copies := res[DependencyTreeCopies].([]FileCopy)
+	assert.Len(t, copies, 2)
+	sort.Slice(copies, func(i, j int) bool { return copies[i].To.Name < copies[j].To.Name })
+	assert.Equal(t, "source.go", copies[0].From.Name)
+	assert.Equal(t, "exact.go", copies[0].To.Name)
+	assert.Nil(t, copies[0].Diff.Diffs)
+	assert.Equal(t, "source.go", copies[1].From.Name)
+	assert.Equal(t, "similar.go", copies[1].To.Name)
+	assert.Equal(t, 10, copies[1].Diff.OldLinesOfCode)
+	assert.Equal(t, 10, copies[1].Diff.NewLinesOfCode)
+	assert.Len(t, copies[1].Diff.Diffs, 4)
+	ra.SimilarityThreshold = 95
+	res, err = ra.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyTreeCopies].([]FileCopy), 1)
+}
+
+func TestRenameAnalysisCopiesLoader(t *testing.T) {
+	lines := make([]string, 10)
+	for i := range lines {
+		lines[i] = "line " + string('a'+rune(i))
+	}
+	source := strings.Join(lines, "\n") + "\n"
+	lines[4] = "LINE E"
+	repository, commit := fixtureCommitWithFiles(map[string]string{
+		"source.go":  source,
+		"similar.go": strings.Join(lines, "\n") + "\n",
+		"big.go":     strings.Repeat(source, 100),
+	})
+	tree, _ := commit.Tree()
+	changes := object.Changes{}
+	cache := map[plumbing.Hash]*object.Blob{}
+	for _, name := range []string{"source.go", "similar.go", "big.go"} {
+		entry, err := tree.FindEntry(name)
+		assert.Nil(t, err)
+		changes = append(changes, &object.Change{To: object.ChangeEntry{
+			Name: name, Tree: tree, TreeEntry: *entry}})
+		cache[entry.Hash], _ = repository.BlobObject(entry.Hash)
+	}
+	var loaded []plumbing.Hash
+	ra := RenameAnalysis{}
+	ra.Configure(map[string]interface{}{
+		ConfigRenameAnalysisSimilarityThreshold: 80,
+		ConfigRenameAnalysisFindCopies:          true,
+		FactBlobCacheLoader: BlobLoader(func(hash plumbing.Hash) (*object.Blob, BlobClass, error) {
+			loaded = append(loaded, hash)
+			blob, err := repository.BlobObject(hash)
+			return blob, BlobText, err
+		}),
+	})
+	ra.Initialize(repository)
+	// the sizes are remembered
+	res, err := ra.Consume(map[string]interface{}{
+		core.DependencyCommit: commit, DependencyTreeChanges: changes, DependencyBlobCache: cache})
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyTreeCopies].([]FileCopy), 0)
+	assert.Len(t, loaded, 0)
+	assert.Len(t, ra.sources, 3)
+	// big.go does not fit the size window and is never read
+	res, err = ra.Consume(map[string]interface{}{
+		core.DependencyCommit: commit, DependencyTreeChanges: changes[1:2],
+		DependencyBlobCache: map[plumbing.Hash]*object.Blob{
+			changes[1].To.TreeEntry.Hash: cache[changes[1].To.TreeEntry.Hash]}})
+	assert.Nil(t, err)
+	copies := res[DependencyTreeCopies].([]FileCopy)
+	assert.Len(t, copies, 1)
+	assert.Equal(t, "source.go", copies[0].From.Name)
+	assert.Equal(t, []plumbing.Hash{changes[0].To.TreeEntry.Hash}, loaded)
+}
+
+func TestRenameAnalysisConsumeFingerprints(t *testing.T) {
+	ra := fixtureRenameAnalysis()
+	changes := object.Changes{}
+	cache := map[plumbing.Hash]*object.Blob{}
+	// many files of the same size which differ in the line prefixes
+	for i := 0; i < 40; i++ {
+		prefix := fmt.Sprintf("file%02d", i)
+		lines := fixtureLines(prefix, 50)
+		blobFrom := test.FakeBlob(strings.Join(lines, "\n") + "\n")
+		lines[10] = prefix + " changed"
+		blobTo := test.FakeBlob(strings.Join(lines, "\n") + "\n")
+		cache[blobFrom.Hash] = blobFrom
+		cache[blobTo.Hash] = blobTo
+		changes = append(changes, &object.Change{From: object.ChangeEntry{
+			Name: "old/" + prefix, TreeEntry: object.TreeEntry{Name: prefix, Hash: blobFrom.Hash}}})
+		changes = append(changes, &object.Change{To: object.ChangeEntry{
+			Name: "new/" + prefix, TreeEntry: object.TreeEntry{Name: prefix, Hash: blobTo.Hash}}})
+	}
+	// the new file without a match
+	blob := test.FakeBlob(strings.Join(fixtureLines("file99", 50), "\n") + "\n")
+	cache[blob.Hash] = blob
+	changes = append(changes, &object.Change{To: object.ChangeEntry{
+		Name: "new/file99", TreeEntry: object.TreeEntry{Name: "file99", Hash: blob.Hash}}})
+	res, err := ra.Consume(map[string]interface{}{
+		DependencyBlobCache: cache, DependencyTreeChanges: changes})
+	assert.Nil(t, err)
+	reduced := res[DependencyTreeChanges].(object.Changes)
+	assert.Len(t, reduced, 41)
+	for _, change := range reduced {
+		if change.To.Name == "new/file99" {
+			assert.Equal(t, "", change.From.Name)
+			continue
+		}
+		assert.Equal(t, "old/"+change.To.TreeEntry.Name, change.From.Name)
+	}
+}
+
+func TestRenameAnalysisConsumeFingerprintsDuplicateLines(t *testing.T) {
+	ra := fixtureRenameAnalysis()
+	changes := object.Changes{}
+	cache := map[plumbing.Hash]*object.Blob{}
+	add := func(from bool, name string, lines []string) {
+		blob := test.FakeBlob(strings.Join(lines, "\n") + "\n")
+		cache[blob.Hash] = blob
+		entry := object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{Name: name, Hash: blob.Hash}}
+		if from {
+			changes = append(changes, &object.Change{From: entry})
+		} else {
+			changes = append(changes, &object.Change{To: entry})
+		}
+	}
+	// the window is big
+	for i := 0; i < 20; i++ {
+		add(true, fmt.Sprintf("old/decoy%02d", i), fixtureLines(fmt.Sprintf("decoy%02d", i), 40))
+	}
+	// most of the lines are the same, but half of the distinct lines changed
+	lines := fixtureLines("line of the renamed file", 10)
+	for i := 0; i < 90; i++ {
+		lines = append(lines, "}")
+	}
+	add(true, "old/renamed", lines)
+	for i := 0; i < 5; i++ {
+		lines[i] = fmt.Sprintf("LINE OF THE RENAMED FILE %d", i)
+	}
+	add(false, "new/renamed", lines)
+	res, err := ra.Consume(map[string]interface{}{
+		DependencyBlobCache: cache, DependencyTreeChanges: changes})
+	assert.Nil(t, err)
+	reduced := res[DependencyTreeChanges].(object.Changes)
+	assert.Len(t, reduced, 21)
+	renamed := false
+	for _, change := range reduced {
+		if change.To.Name == "new/renamed" {
+			assert.Equal(t, "old/renamed", change.From.Name)
+			renamed = true
+		}
+	}
+	assert.True(t, renamed)
+}
+
+func TestRenameCandidatesFind(t *testing.T) {
+	ra := fixtureRenameAnalysis()
+	cache := map[plumbing.Hash]*object.Blob{}
+	deleted := sortableBlobs{}
+	for i := 0; i < renameExhaustiveWindow*2; i++ {
+		blob := test.FakeBlob(strings.Join(fixtureLines(fmt.Sprintf("file%02d", i), 50), "\n"))
+		cache[blob.Hash] = blob
+		deleted = append(deleted, sortableBlob{
+			change: &object.Change{From: object.ChangeEntry{
+				TreeEntry: object.TreeEntry{Hash: blob.Hash}}},
+			size: blob.Size})
+	}
+	rc := &renameCandidates{ra: ra, deleted: deleted, cache: cache}
+	matched := make([]bool, deleted.Len())
+	lines := fixtureLines("file07", 50)
+	lines[0] = "changed"
+	blob := test.FakeBlob(strings.Join(lines, "\n"))
+	window, err := rc.Find(blob, 0, 4, matched, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, window)
+	assert.Nil(t, rc.index)
+	window, err = rc.Find(blob, 0, 4, matched, true)
+	assert.Nil(t, err)
+	assert.Len(t, window, 0)
+	window, err = rc.Find(blob, 0, deleted.Len(), matched, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{7}, window)
+	assert.NotNil(t, rc.index)
+	window, err = rc.Find(blob, 0, deleted.Len(), matched, true)
+	assert.Nil(t, err)
+	assert.Len(t, window, 0)
+	ra.SimilarityThreshold = 0
+	window, err = rc.Find(blob, 0, deleted.Len(), matched, true)
+	assert.Nil(t, err)
+	assert.Len(t, window, deleted.Len()-1)
+	matched[7] = true
+	window, err = rc.Find(blob, 0, deleted.Len(), matched, false)
+	assert.Nil(t, err)
+	assert.Len(t, window, 0)
+}
+
+func TestRenameAnalysisCrossCommit(t *testing.T) {
+	ra := fixtureRenameAnalysis()
+	ra.CrossCommitWindow = 2
+	lines := fixtureLines("line", 20)
+	deleted := test.FakeBlob(strings.Join(lines, "\n") + "\n")
+	gone := test.FakeBlob(strings.Join(fixtureLines("gone", 20), "\n") + "\n")
+	lines[5] = "changed"
+	similar := test.FakeBlob(strings.Join(lines, "\n") + "\n")
+	other := test.FakeBlob(strings.Join(fixtureLines("other", 20), "\n") + "\n")
+	consume := func(changes object.Changes, blobs ...*object.Blob) []FileCopy {
+		cache := map[plumbing.Hash]*object.Blob{}
+		for _, blob := range blobs {
+			cache[blob.Hash] = blob
+		}
+		res, err := ra.Consume(map[string]interface{}{
+			DependencyBlobCache: cache, DependencyTreeChanges: changes})
+		assert.Nil(t, err)
+		assert.Len(t, res[DependencyTreeChanges].(object.Changes), len(changes))
+		return res[DependencyTreeCopies].([]FileCopy)
+	}
+	deletion := func(name string, blob *object.Blob) *object.Change {
+		return &object.Change{From: object.ChangeEntry{
+			Name: name, TreeEntry: object.TreeEntry{Name: name, Hash: blob.Hash}}}
+	}
+	insertion := func(name string, blob *object.Blob) *object.Change {
+		return &object.Change{To: object.ChangeEntry{
+			Name: name, TreeEntry: object.TreeEntry{Name: name, Hash: blob.Hash}}}
+	}
+	copies := consume(object.Changes{
+		deletion("old.go", deleted), deletion("gone.go", gone)}, deleted, gone)
+	assert.Len(t, copies, 0)
+	assert.Len(t, ra.retained, 2)
+	copies = consume(object.Changes{insertion("other.go", other)}, other)
+	assert.Len(t, copies, 0)
+	copies = consume(object.Changes{insertion("new.go", similar)}, similar)
+	assert.Len(t, copies, 1)
+	assert.True(t, copies[0].Deleted)
+	assert.Equal(t, "old.go", copies[0].From.Name)
+	assert.Equal(t, "new.go", copies[0].To.Name)
+	assert.Equal(t, 20, copies[0].Diff.OldLinesOfCode)
+	assert.Len(t, copies[0].Diff.Diffs, 4)
+	assert.Len(t, ra.retained, 1)
+	// gone.go has expired
+	copies = consume(object.Changes{insertion("gone.go", gone)}, gone)
+	assert.Len(t, copies, 0)
+	assert.Len(t, ra.retained, 0)
+	// identical contents
+	copies = consume(object.Changes{deletion("new.go", similar)}, similar)
+	assert.Len(t, copies, 0)
+	copies = consume(object.Changes{insertion("again.go", similar)}, similar)
+	assert.Len(t, copies, 1)
+	assert.Equal(t, "new.go", copies[0].From.Name)
+	assert.Equal(t, "again.go", copies[0].To.Name)
+	assert.Nil(t, copies[0].Diff.Diffs)
+	assert.Len(t, ra.retained, 0)
+	ra.CrossCommitWindow = 0
+	copies = consume(object.Changes{deletion("again.go", similar)}, similar)
+	copies = consume(object.Changes{insertion("new.go", similar)}, similar)
+	assert.Len(t, copies, 0)
+}
+
+func TestDetectDirectoryMoves(t *testing.T) {
+	changes := func(names ...string) sortableChanges {
+		result := sortableChanges{}
+		for _, name := range names {
+			entry := object.ChangeEntry{Name: name}
+			result = append(result, sortableChange{
+				change: &object.Change{From: entry, To: entry}})
+		}
+		return result
+	}
+	moves := detectDirectoryMoves(
+		changes("src/a.go", "src/b.go", "src/sub/c.go", "docs/readme.md", "x.go"),
+		changes("lib/a.go", "lib/b.go", "lib/sub/c.go", "other/readme.md", "pkg/x.go"))
+	assert.Equal(t, map[string]string{"src/": "lib/"}, moves)
+	// not the majority of the deleted files
+	moves = detectDirectoryMoves(
+		changes("src/a.go", "src/b.go", "src/c.go", "src/d.go", "src/e.go"),
+		changes("lib/a.go", "lib/b.go"))
+	assert.Len(t, moves, 0)
+	moves = detectDirectoryMoves(
+		changes("a.go", "b.go", "dir/c.go"),
+		changes("root/a.go", "root/b.go", "root/dir/c.go"))
+	assert.Equal(t, map[string]string{"": "root/"}, moves)
+}
+
+func TestPathPrefixes(t *testing.T) {
+	assert.Equal(t, []string{"a/b/", "a/", ""}, pathPrefixes("a/b/c"))
+	assert.Equal(t, []string{""}, pathPrefixes("c"))
+	oldPrefix, newPrefix := stripCommonSuffix("src/sub/c.go", "lib/sub/c.go")
+	assert.Equal(t, "src/", oldPrefix)
+	assert.Equal(t, "lib/", newPrefix)
+	oldPrefix, newPrefix = stripCommonSuffix("c.go", "lib/c.go")
+	assert.Equal(t, "", oldPrefix)
+	assert.Equal(t, "lib/", newPrefix)
+}
+
+func TestRenameAnalysisDirectoryMove(t *testing.T) {
+	ra := fixtureRenameAnalysis()
+	base := strings.Join(fixtureLines("line", 20), "\n") + "\n"
+	contents := map[string]string{
+		"old/a.go": base + "a extra\n",
+		"old/b.go": base + "b\n",
+		// new/a.go is similar to the smaller old/b.go which goes first in the size order
+		"new/a.go": strings.Replace(base, "line 5\n", "", 1) + "a extra\n",
+		"new/b.go": strings.Replace(base, "line 7\n", "line seven\n", 1) + "b\n",
+	}
+	changes := object.Changes{}
+	cache := map[plumbing.Hash]*object.Blob{}
+	for name, text := range contents {
+		blob := test.FakeBlob(text)
+		cache[blob.Hash] = blob
+		entry := object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{Hash: blob.Hash}}
+		if strings.HasPrefix(name, "old/") {
+			changes = append(changes, &object.Change{From: entry})
+		} else {
+			changes = append(changes, &object.Change{To: entry})
+		}
+	}
+	res, err := ra.Consume(map[string]interface{}{
+		DependencyBlobCache: cache, DependencyTreeChanges: changes})
+	assert.Nil(t, err)
+	reduced := res[DependencyTreeChanges].(object.Changes)
+	assert.Len(t, reduced, 2)
+	for _, change := range reduced {
+		assert.Equal(t, "old/"+path.Base(change.To.Name), change.From.Name)
+	}
+}
+
+func TestRenameAnalysisBlobClasses(t *testing.T) {
+	ra := fixtureRenameAnalysis()
+	lines := fixtureLines("line", 20)
+	deleted := test.FakeBlob(strings.Join(lines, "\n") + "\n")
+	lines[5] = "changed"
+	added := test.FakeBlob(strings.Join(lines, "\n") + "\n")
+	changes := object.Changes{
+		&object.Change{From: object.ChangeEntry{
+			Name: "old.csv", TreeEntry: object.TreeEntry{Name: "old.csv", Hash: deleted.Hash}}},
+		&object.Change{To: object.ChangeEntry{
+			Name: "new.csv", TreeEntry: object.TreeEntry{Name: "new.csv", Hash: added.Hash}}},
+	}
+	deps := map[string]interface{}{
+		DependencyBlobCache:   map[plumbing.Hash]*object.Blob{deleted.Hash: deleted, added.Hash: added},
+		DependencyTreeChanges: changes,
+	}
+	res, err := ra.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyTreeChanges].(object.Changes), 1)
+	// the similar blobs which are not text are never compared
+	deps[DependencyBlobClasses] = BlobClasses{
+		Classes: map[plumbing.Hash]BlobClass{added.Hash: BlobTooLarge}, Policy: BlobPolicyCount}
+	res, err = ra.Consume(deps)
+	assert.Nil(t, err)
+	reduced := res[DependencyTreeChanges].(object.Changes)
+	assert.Len(t, reduced, 2)
+	assert.Equal(t, "old.csv", reduced[0].From.Name)
+	assert.Equal(t, "new.csv", reduced[1].To.Name)
 }
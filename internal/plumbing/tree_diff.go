@@ -1,7 +1,6 @@
 package plumbing
 
 import (
-	"io"
 	"strings"
 
 	"gopkg.in/src-d/go-git.v4"
@@ -15,13 +14,21 @@ import (
 // TreeDiff is a PipelineItem.
 type TreeDiff struct {
 	core.NoopMerger
-	SkipDirs     []string
+	SkipDirs []string
+	// GitBackend is the kind of ObjectBackend which compares the trees, see
+	// core.ConfigPipelineGitBackend.
+	GitBackend string
+
 	previousTree *object.Tree
+	backend      ObjectBackend
 }
 
 const (
 	// DependencyTreeChanges is the name of the dependency provided by TreeDiff.
 	DependencyTreeChanges = "changes"
+	// DependencyTreeChangeKinds is the name of the dependency provided by TreeDiff which
+	// classifies DependencyTreeChanges, see ChangeKinds.
+	DependencyTreeChangeKinds = "change_kinds"
 	// ConfigTreeDiffEnableBlacklist is the name of the configuration option
 	// (TreeDiff.Configure()) which allows to skip blacklisted directories.
 	ConfigTreeDiffEnableBlacklist = "TreeDiff.EnableBlacklist"
@@ -41,7 +48,7 @@ func (treediff *TreeDiff) Name() string {
 // Each produced entity will be inserted into `deps` of dependent Consume()-s according
 // to this list. Also used by core.Registry to build the global map of providers.
 func (treediff *TreeDiff) Provides() []string {
-	arr := [...]string{DependencyTreeChanges}
+	arr := [...]string{DependencyTreeChanges, DependencyTreeChangeKinds}
 	return arr[:]
 }
 
@@ -74,12 +81,28 @@ func (treediff *TreeDiff) Configure(facts map[string]interface{}) {
 	if val, exist := facts[ConfigTreeDiffEnableBlacklist]; exist && val.(bool) {
 		treediff.SkipDirs = facts[ConfigTreeDiffBlacklistedDirs].([]string)
 	}
+	if val, exists := facts[core.ConfigPipelineGitBackend].(string); exists {
+		treediff.GitBackend = val
+	}
 }
 
 // Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
 // calls. The repository which is going to be analysed is supplied as an argument.
 func (treediff *TreeDiff) Initialize(repository *git.Repository) {
 	treediff.previousTree = nil
+	if treediff.backend != nil {
+		treediff.backend.Close()
+	}
+	treediff.backend = NewObjectBackend(treediff.GitBackend, repository)
+}
+
+// Dispose closes the object backend, e.g. stops `git cat-file --batch`. It is called when
+// Pipeline.Run() ends.
+func (treediff *TreeDiff) Dispose() {
+	if treediff.backend != nil {
+		treediff.backend.Close()
+		treediff.backend = nil
+	}
 }
 
 // Consume runs this PipelineItem on the next commit data.
@@ -95,32 +118,12 @@ func (treediff *TreeDiff) Consume(deps map[string]interface{}) (map[string]inter
 	}
 	var diff object.Changes
 	if treediff.previousTree != nil {
-		diff, err = object.DiffTree(treediff.previousTree, tree)
-		if err != nil {
-			return nil, err
-		}
+		diff, err = treediff.backend.DiffTree(treediff.previousTree, tree)
 	} else {
-		diff = []*object.Change{}
-		err = func() error {
-			fileIter := tree.Files()
-			defer fileIter.Close()
-			for {
-				file, err := fileIter.Next()
-				if err != nil {
-					if err == io.EOF {
-						break
-					}
-					return err
-				}
-				diff = append(diff, &object.Change{
-					To: object.ChangeEntry{Name: file.Name, Tree: tree, TreeEntry: object.TreeEntry{
-						Name: file.Name, Mode: file.Mode, Hash: file.Hash}}})
-			}
-			return nil
-		}()
-		if err != nil {
-			return nil, err
-		}
+		diff, err = treediff.backend.ListTree(tree)
+	}
+	if err != nil {
+		return nil, err
 	}
 	treediff.previousTree = tree
 
@@ -139,7 +142,13 @@ func (treediff *TreeDiff) Consume(deps map[string]interface{}) (map[string]inter
 
 		diff = filteredDiff
 	}
-	return map[string]interface{}{DependencyTreeChanges: diff}, nil
+	kinds := ChangeKinds{}
+	for _, change := range diff {
+		if kind := ClassifyChange(change); kind != ChangeKindContent {
+			kinds[changeName(change)] = kind
+		}
+	}
+	return map[string]interface{}{DependencyTreeChanges: diff, DependencyTreeChangeKinds: kinds}, nil
 }
 
 // Fork clones this PipelineItem.
@@ -1,6 +1,7 @@
 package plumbing
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,8 +23,9 @@ func TestTreeDiffMeta(t *testing.T) {
 	td := fixtureTreeDiff()
 	assert.Equal(t, td.Name(), "TreeDiff")
 	assert.Equal(t, len(td.Requires()), 0)
-	assert.Equal(t, len(td.Provides()), 1)
+	assert.Equal(t, len(td.Provides()), 2)
 	assert.Equal(t, td.Provides()[0], DependencyTreeChanges)
+	assert.Equal(t, td.Provides()[1], DependencyTreeChangeKinds)
 	opts := td.ListConfigurationOptions()
 	assert.Len(t, opts, 2)
 }
@@ -152,4 +154,33 @@ func TestTreeDiffFork(t *testing.T) {
 	assert.Equal(t, td1.SkipDirs, td2.SkipDirs)
 	assert.Equal(t, td1.previousTree, td2.previousTree)
 	td1.Merge([]core.PipelineItem{td2})
-}
\ No newline at end of file
+}
+
+func TestTreeDiffGitBackend(t *testing.T) {
+	root, repository, commits := fixtureGitHistory(t)
+	defer os.RemoveAll(root)
+	goGit := TreeDiff{}
+	goGit.Configure(map[string]interface{}{})
+	goGit.Initialize(repository)
+	assert.IsType(t, &goGitObjectBackend{}, goGit.backend)
+	td := TreeDiff{}
+	td.Configure(map[string]interface{}{core.ConfigPipelineGitBackend: core.GitBackendExec})
+	assert.Equal(t, core.GitBackendExec, td.GitBackend)
+	td.Initialize(repository)
+	defer td.Dispose()
+	assert.IsType(t, &execObjectBackend{}, td.backend)
+	for _, commit := range commits {
+		deps := map[string]interface{}{core.DependencyCommit: commit}
+		expected, err := goGit.Consume(deps)
+		assert.Nil(t, err)
+		actual, err := td.Consume(deps)
+		assert.Nil(t, err)
+		assertEqualChanges(t, expected[DependencyTreeChanges].(object.Changes),
+			actual[DependencyTreeChanges].(object.Changes))
+	}
+	backend := td.backend.(*execObjectBackend)
+	assert.NotNil(t, backend.batch)
+	td.Dispose()
+	assert.Nil(t, backend.batch)
+	assert.Nil(t, td.backend)
+}
@@ -27,6 +27,19 @@ func FakeChangeForName(name string, hashFrom string, hashTo string) *object.Chan
 	}
 }
 
+// FakeBlob creates an artificial Git blob with the specified contents. The hash is calculated
+// the same way as Git does.
+func FakeBlob(contents string) *object.Blob {
+	obj := &plumbing.MemoryObject{}
+	obj.SetType(plumbing.BlobObject)
+	obj.Write([]byte(contents))
+	blob, err := object.DecodeBlob(obj)
+	if err != nil {
+		panic(err)
+	}
+	return blob
+}
+
 func init() {
 	cwd, err := os.Getwd()
 	if err == nil {
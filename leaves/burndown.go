@@ -6,6 +6,8 @@ import (
 	"io"
 	"log"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"unicode/utf8"
 
@@ -15,6 +17,7 @@ import (
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	"gopkg.in/src-d/hercules.v4/internal"
 	"gopkg.in/src-d/hercules.v4/internal/burndown"
 	"gopkg.in/src-d/hercules.v4/internal/core"
 	"gopkg.in/src-d/hercules.v4/internal/pb"
@@ -42,6 +45,24 @@ type BurndownAnalysis struct {
 	// The number of developers for which to collect the burndown stats. 0 disables it.
 	PeopleNumber int
 
+	// TrackTeams enables the team interaction matrix. It requires PeopleNumber > 0 and
+	// the teams file loaded by IdentityDetector.
+	TrackTeams bool
+
+	// Survival enables the estimation of the line survival curves and the code half-life.
+	// The curves are calculated for the project, and also for each file and each developer
+	// if TrackFiles and PeopleNumber allow that.
+	Survival bool
+
+	// Blame enables dumping the line intervals of each file alive in the last commit together
+	// with the authors and the days when they were written.
+	Blame bool
+
+	// IgnoredCommits are the commits which do not own the lines they change: the replaced lines
+	// keep their authors and ages and the inserted lines take them over from the neighbouring
+	// lines, see core.ConfigPipelineIgnoredCommits.
+	IgnoredCommits map[plumbing.Hash]bool
+
 	// Debug activates the debugging mode. Analyse() runs slower in this mode
 	// but it accurately checks all the intermediate states for invariant
 	// violations.
@@ -62,6 +83,8 @@ type BurndownAnalysis struct {
 	files map[string]*burndown.File
 	// matrix is the mutual deletions and self insertions.
 	matrix []map[int]int64
+	// matrixHistory is the periodic snapshots of matrix.
+	matrixHistory [][]map[int]int64
 	// people is the people's individual time stats.
 	people []map[int]int64
 	// day is the most recent day index processed.
@@ -71,6 +94,35 @@ type BurndownAnalysis struct {
 	previousDay int
 	// references IdentityDetector.ReversedPeopleDict
 	reversedPeopleDict []string
+	// teamsMatrix is the mutual deletions and self insertions of the teams.
+	teamsMatrix []map[int]int64
+	// lineTeams maps the packed author and day to the author's team on that day.
+	lineTeams map[int]int
+	// references IdentityDetector.ReversedTeamsDict
+	reversedTeamsDict []string
+	// coAuthorsPolicy references IdentityDetector.CoAuthorsPolicy. The lines always have
+	// a single owner, so they are divided between the co-authors with any policy but ignore.
+	coAuthorsPolicy string
+	// renamesWindow references RenameAnalysis.CrossCommitWindow.
+	renamesWindow int
+	// commits is the number of consumed commits.
+	commits int
+	// ignoredCommit indicates that the current commit is one of IgnoredCommits.
+	ignoredCommit bool
+	// deletedFiles are the files deleted within renamesWindow commits, they are revived
+	// if RenameAnalysis reports a rename across commits.
+	deletedFiles map[string]deletedFile
+	// blobClasses tells which blobs in the current commit are treated as binary.
+	blobClasses items.BlobClasses
+	// nonTextBlobs are the classes of all the blobs which were not text.
+	nonTextBlobs map[plumbing.Hash]items.BlobClass
+}
+
+// deletedFile is the state of a file before it was deleted.
+type deletedFile struct {
+	file *burndown.File
+	// commit is the index of the commit which deleted the file
+	commit int
 }
 
 // BurndownResult carries the result of running BurndownAnalysis - it is returned by
@@ -91,6 +143,24 @@ type BurndownResult struct {
 	// The rest of the elements are equal the number of line removals by the corresponding
 	// authors in reversedPeopleDict: 2 -> 0, 3 -> 1, etc.
 	PeopleMatrix [][]int64
+	// [number of samples][number of people]{column: value}
+	// PeopleMatrix sampled the same way as GlobalHistory. Each sample is sparse and has
+	// the same column layout. The last sample is equal to PeopleMatrix.
+	PeopleMatrixHistory [][]map[int]int64
+	// [number of teams][number of teams + 2]
+	// The same as PeopleMatrix but the lines are attributed to the teams of their authors
+	// at the moment when they were written or removed. It is nil unless
+	// BurndownAnalysis.TrackTeams was enabled.
+	TeamsMatrix [][]int64
+	// Survival contains the line survival curves estimated from the matrices above.
+	// It is nil unless BurndownAnalysis.Survival was enabled.
+	Survival *BurndownSurvival
+	// Blame is the mapping from file paths to their line intervals in the last analysed commit.
+	// It is nil unless BurndownAnalysis.Blame was enabled.
+	Blame map[string][]BlameInterval
+	// BlobClasses maps the names of the blob classes except text to the numbers of
+	// the distinct blobs which were skipped or not diffed, see items.BlobClasses.
+	BlobClasses map[string]int
 
 	// The following members are private.
 
@@ -98,12 +168,52 @@ type BurndownResult struct {
 	// Pipeline.Initialize(facts map[string]interface{}). Thus it can be obtained via
 	// facts[FactIdentityDetectorReversedPeopleDict].
 	reversedPeopleDict []string
+	// reversedTeamsDict is borrowed from IdentityDetector the same way as reversedPeopleDict.
+	reversedTeamsDict []string
 	// sampling and granularity are copied from BurndownAnalysis and stored for service purposes
 	// such as merging several results together.
 	sampling    int
 	granularity int
 }
 
+// SurvivalCurve is the Kaplan-Meier estimate of the probability of a line to survive
+// for the given number of days. Each band in the burndown matrix is treated as a cohort
+// of lines which were born at the same time.
+type SurvivalCurve struct {
+	// Days is the age axis, each value is the number of days since the lines were written.
+	// It always starts with 0.
+	Days []int
+	// Probabilities are the estimated chances of a line to live longer than the corresponding Days.
+	Probabilities []float32
+	// HalfLife is the number of days after which half of the lines are deleted.
+	// It is linearly interpolated between the neighbouring Days and equals -1 if the survival
+	// probability never dropped to 0.5.
+	HalfLife float32
+}
+
+// BlameInterval is the range of adjacent lines which were written by the same developer
+// on the same day.
+type BlameInterval struct {
+	// Begin is the index of the first line in the interval.
+	Begin int
+	// End is the index of the line after the last one in the interval.
+	End int
+	// Author is the index in reversedPeopleDict or identity.AuthorMissing.
+	Author int
+	// Day is the number of days since the beginning of the history.
+	Day int
+}
+
+// BurndownSurvival carries the survival curves calculated from BurndownResult.
+type BurndownSurvival struct {
+	// Project is calculated from BurndownResult.GlobalHistory.
+	Project SurvivalCurve
+	// Files are calculated from BurndownResult.FileHistories, the keys are the same.
+	Files map[string]SurvivalCurve
+	// People are calculated from BurndownResult.PeopleHistories, the order is the same.
+	People []SurvivalCurve
+}
+
 const (
 	// ConfigBurndownGranularity is the name of the option to set BurndownAnalysis.Granularity.
 	ConfigBurndownGranularity = "Burndown.Granularity"
@@ -113,6 +223,12 @@ const (
 	ConfigBurndownTrackFiles = "Burndown.TrackFiles"
 	// ConfigBurndownTrackPeople enables burndown collection for authors.
 	ConfigBurndownTrackPeople = "Burndown.TrackPeople"
+	// ConfigBurndownTrackTeams enables the team interaction matrix.
+	ConfigBurndownTrackTeams = "Burndown.TrackTeams"
+	// ConfigBurndownSurvival enables the estimation of the line survival curves and the half-life.
+	ConfigBurndownSurvival = "Burndown.Survival"
+	// ConfigBurndownBlame enables dumping the line intervals of each file in the last commit.
+	ConfigBurndownBlame = "Burndown.Blame"
 	// ConfigBurndownDebug enables some extra debug assertions.
 	ConfigBurndownDebug = "Burndown.Debug"
 	// DefaultBurndownGranularity is the default number of days for BurndownAnalysis.Granularity
@@ -141,7 +257,9 @@ func (analyser *BurndownAnalysis) Provides() []string {
 func (analyser *BurndownAnalysis) Requires() []string {
 	arr := [...]string{
 		items.DependencyFileDiff, items.DependencyTreeChanges, items.DependencyBlobCache,
-		items.DependencyDay, identity.DependencyAuthor}
+		items.DependencyBlobClasses, items.DependencyDay, identity.DependencyAuthor,
+		identity.DependencyTeam, identity.DependencyAuthors, identity.DependencyTeams,
+		items.DependencyTreeCopies, items.DependencyTreeChangeKinds}
 	return arr[:]
 }
 
@@ -168,6 +286,21 @@ func (analyser *BurndownAnalysis) ListConfigurationOptions() []core.Configuratio
 		Flag:        "burndown-people",
 		Type:        core.BoolConfigurationOption,
 		Default:     false}, {
+		Name:        ConfigBurndownTrackTeams,
+		Description: "Record the interaction matrix of the teams; requires --burndown-people and --teams.",
+		Flag:        "burndown-teams",
+		Type:        core.BoolConfigurationOption,
+		Default:     false}, {
+		Name:        ConfigBurndownSurvival,
+		Description: "Estimate the line survival curves and the code half-life.",
+		Flag:        "burndown-survival",
+		Type:        core.BoolConfigurationOption,
+		Default:     false}, {
+		Name:        ConfigBurndownBlame,
+		Description: "Record the authors and the ages of the lines in the last commit.",
+		Flag:        "burndown-blame",
+		Type:        core.BoolConfigurationOption,
+		Default:     false}, {
 		Name:        ConfigBurndownDebug,
 		Description: "Validate the trees on each step.",
 		Flag:        "burndown-debug",
@@ -196,9 +329,30 @@ func (analyser *BurndownAnalysis) Configure(facts map[string]interface{}) {
 	} else if exists {
 		analyser.PeopleNumber = 0
 	}
+	if val, exists := facts[ConfigBurndownTrackTeams].(bool); exists {
+		analyser.TrackTeams = val
+	}
+	if val, exists := facts[identity.FactIdentityDetectorReversedTeamsDict].([]string); exists {
+		analyser.reversedTeamsDict = val
+	}
+	if val, exists := facts[identity.FactIdentityDetectorCoAuthorsPolicy].(string); exists {
+		analyser.coAuthorsPolicy = val
+	}
+	if val, exists := facts[ConfigBurndownSurvival].(bool); exists {
+		analyser.Survival = val
+	}
+	if val, exists := facts[ConfigBurndownBlame].(bool); exists {
+		analyser.Blame = val
+	}
 	if val, exists := facts[ConfigBurndownDebug].(bool); exists {
 		analyser.Debug = val
 	}
+	if val, exists := facts[items.ConfigRenameAnalysisCrossCommitWindow].(int); exists {
+		analyser.renamesWindow = val
+	}
+	if val, exists := facts[core.ConfigPipelineIgnoredCommits].(map[plumbing.Hash]bool); exists {
+		analyser.IgnoredCommits = val
+	}
 }
 
 // Flag for the command line switch which enables this analysis.
@@ -224,6 +378,11 @@ func (analyser *BurndownAnalysis) Initialize(repository *git.Repository) {
 			analyser.Granularity)
 		analyser.Sampling = analyser.Granularity
 	}
+	if analyser.TrackTeams && (analyser.PeopleNumber == 0 || analyser.reversedTeamsDict == nil) {
+		log.Println("Warning: the team interaction matrix requires the people burndown " +
+			"and the teams file, disabled")
+		analyser.TrackTeams = false
+	}
 	analyser.repository = repository
 	analyser.globalStatus = map[int]int64{}
 	analyser.globalHistory = [][]int64{}
@@ -231,9 +390,18 @@ func (analyser *BurndownAnalysis) Initialize(repository *git.Repository) {
 	analyser.peopleHistories = make([][][]int64, analyser.PeopleNumber)
 	analyser.files = map[string]*burndown.File{}
 	analyser.matrix = make([]map[int]int64, analyser.PeopleNumber)
+	analyser.matrixHistory = [][]map[int]int64{}
 	analyser.people = make([]map[int]int64, analyser.PeopleNumber)
+	analyser.teamsMatrix = nil
+	analyser.lineTeams = map[int]int{}
+	if analyser.TrackTeams {
+		analyser.teamsMatrix = make([]map[int]int64, len(analyser.reversedTeamsDict))
+	}
 	analyser.day = 0
 	analyser.previousDay = 0
+	analyser.commits = 0
+	analyser.deletedFiles = map[string]deletedFile{}
+	analyser.nonTextBlobs = map[plumbing.Hash]items.BlobClass{}
 }
 
 // Consume runs this PipelineItem on the next commit data.
@@ -243,8 +411,15 @@ func (analyser *BurndownAnalysis) Initialize(repository *git.Repository) {
 // in Provides(). If there was an error, nil is returned.
 func (analyser *BurndownAnalysis) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
 	commit := deps[core.DependencyCommit].(*object.Commit)
-	author := deps[identity.DependencyAuthor].(int)
+	authors, teams := identity.CreditedAuthors(deps, analyser.coAuthorsPolicy)
 	day := deps[items.DependencyDay].(int)
+	analyser.ignoredCommit = analyser.IgnoredCommits[commit.Hash]
+	analyser.commits++
+	for name, deleted := range analyser.deletedFiles {
+		if analyser.commits-deleted.commit > analyser.renamesWindow {
+			delete(analyser.deletedFiles, name)
+		}
+	}
 	if len(commit.ParentHashes) <= 1 {
 		analyser.day = day
 		analyser.onNewDay()
@@ -253,19 +428,38 @@ func (analyser *BurndownAnalysis) Consume(deps map[string]interface{}) (map[stri
 		// we will analyse the conflicts resolution in Merge()
 		analyser.day = burndown.TreeMergeMark
 	}
+	if analyser.TrackTeams {
+		for i, author := range authors {
+			analyser.lineTeams[analyser.packPersonWithDay(author, day)] = teams[i]
+		}
+	}
 	cache := deps[items.DependencyBlobCache].(map[plumbing.Hash]*object.Blob)
+	analyser.blobClasses, _ = deps[items.DependencyBlobClasses].(items.BlobClasses)
+	for hash, class := range analyser.blobClasses.Classes {
+		analyser.nonTextBlobs[hash] = class
+	}
 	treeDiffs := deps[items.DependencyTreeChanges].(object.Changes)
 	fileDiffs := deps[items.DependencyFileDiff].(map[string]items.FileDiffData)
+	treeCopies, _ := deps[items.DependencyTreeCopies].([]items.FileCopy)
+	copies := map[string]items.FileCopy{}
+	for _, fileCopy := range treeCopies {
+		copies[fileCopy.To.Name] = fileCopy
+	}
+	kinds, _ := deps[items.DependencyTreeChangeKinds].(items.ChangeKinds)
 	for _, change := range treeDiffs {
-		action, _ := change.Action()
+		action, _ := kinds.LineAction(change)
 		var err error
 		switch action {
 		case merkletrie.Insert:
-			err = analyser.handleInsertion(change, author, cache)
+			if fileCopy, exists := copies[change.To.Name]; exists {
+				err = analyser.handleCopy(fileCopy, authors, cache)
+			} else {
+				err = analyser.handleInsertion(change, authors, cache)
+			}
 		case merkletrie.Delete:
-			err = analyser.handleDeletion(change, author, cache)
+			err = analyser.handleDeletion(change, authors, cache)
 		case merkletrie.Modify:
-			err = analyser.handleModification(change, author, cache, fileDiffs)
+			err = analyser.handleModification(change, authors, cache, fileDiffs)
 		}
 		if err != nil {
 			return nil, err
@@ -286,6 +480,10 @@ func (analyser *BurndownAnalysis) Fork(n int) []core.PipelineItem {
 		for key, file := range analyser.files {
 			clone.files[key] = file.Clone(false)
 		}
+		clone.deletedFiles = map[string]deletedFile{}
+		for key, deleted := range analyser.deletedFiles {
+			clone.deletedFiles[key] = deleted
+		}
 		result[i] = &clone
 	}
 	return result
@@ -323,27 +521,45 @@ func (analyser *BurndownAnalysis) Finalize() interface{} {
 		analyser.fileHistories[key] = append(padding, statuses...)
 	}
 	peopleMatrix := make([][]int64, analyser.PeopleNumber)
-	for i, row := range analyser.matrix {
-		mrow := make([]int64, analyser.PeopleNumber+2)
-		peopleMatrix[i] = mrow
-		for key, val := range row {
-			if key == identity.AuthorMissing {
-				key = -1
-			} else if key == authorSelf {
-				key = -2
-			}
-			mrow[key+2] = val
+	var peopleMatrixHistory [][]map[int]int64
+	if analyser.PeopleNumber > 0 {
+		peopleMatrix = denseInteraction(
+			analyser.matrixHistory[len(analyser.matrixHistory)-1], analyser.PeopleNumber)
+		peopleMatrixHistory = analyser.matrixHistory
+	}
+	var teamsMatrix [][]int64
+	if analyser.TrackTeams {
+		teamsMatrix = denseInteraction(
+			sparseInteraction(analyser.teamsMatrix), len(analyser.reversedTeamsDict))
+	}
+	result := BurndownResult{
+		GlobalHistory:       analyser.globalHistory,
+		FileHistories:       analyser.fileHistories,
+		PeopleHistories:     analyser.peopleHistories,
+		PeopleMatrix:        peopleMatrix,
+		PeopleMatrixHistory: peopleMatrixHistory,
+		TeamsMatrix:         teamsMatrix,
+		reversedPeopleDict:  analyser.reversedPeopleDict,
+		reversedTeamsDict:   analyser.reversedTeamsDict,
+		sampling:            analyser.Sampling,
+		granularity:         analyser.Granularity,
+	}
+	if analyser.Survival {
+		result.Survival = estimateBurndownSurvival(&result)
+	}
+	if analyser.Blame {
+		result.Blame = map[string][]BlameInterval{}
+		for name, file := range analyser.files {
+			result.Blame[name] = analyser.blameFile(file)
 		}
 	}
-	return BurndownResult{
-		GlobalHistory:      analyser.globalHistory,
-		FileHistories:      analyser.fileHistories,
-		PeopleHistories:    analyser.peopleHistories,
-		PeopleMatrix:       peopleMatrix,
-		reversedPeopleDict: analyser.reversedPeopleDict,
-		sampling:           analyser.Sampling,
-		granularity:        analyser.Granularity,
+	if len(analyser.nonTextBlobs) > 0 {
+		result.BlobClasses = map[string]int{}
+		for _, class := range analyser.nonTextBlobs {
+			result.BlobClasses[class.String()]++
+		}
 	}
+	return result
 }
 
 // Serialize converts the analysis result as returned by Finalize() to text or bytes.
@@ -395,6 +611,84 @@ func (analyser *BurndownAnalysis) Deserialize(pbmessage []byte) (interface{}, er
 			result.PeopleMatrix[i][msg.PeopleInteraction.Indices[j]] = msg.PeopleInteraction.Data[j]
 		}
 	}
+	if len(msg.PeopleInteractionHistory) > 0 {
+		result.PeopleMatrixHistory = make([][]map[int]int64, len(msg.PeopleInteractionHistory))
+	}
+	for i, mat := range msg.PeopleInteractionHistory {
+		sample := make([]map[int]int64, mat.NumberOfRows)
+		for y := range sample {
+			sample[y] = map[int]int64{}
+			for j := int(mat.Indptr[y]); j < int(mat.Indptr[y+1]); j++ {
+				sample[y][int(mat.Indices[j])] = mat.Data[j]
+			}
+		}
+		result.PeopleMatrixHistory[i] = sample
+	}
+	if msg.TeamsInteraction != nil {
+		result.reversedTeamsDict = msg.Teams
+		mat := msg.TeamsInteraction
+		result.TeamsMatrix = make([][]int64, mat.NumberOfRows)
+		for i := range result.TeamsMatrix {
+			result.TeamsMatrix[i] = make([]int64, mat.NumberOfColumns)
+			for j := int(mat.Indptr[i]); j < int(mat.Indptr[i+1]); j++ {
+				result.TeamsMatrix[i][mat.Indices[j]] = mat.Data[j]
+			}
+		}
+	}
+	if msg.Survival != nil {
+		convertCurve := func(curve *pb.SurvivalCurve) SurvivalCurve {
+			res := SurvivalCurve{
+				Days:          make([]int, len(curve.Days)),
+				Probabilities: curve.Probabilities,
+				HalfLife:      curve.HalfLife,
+			}
+			for i, day := range curve.Days {
+				res.Days[i] = int(day)
+			}
+			return res
+		}
+		result.Survival = &BurndownSurvival{}
+		if msg.Survival.Project != nil {
+			result.Survival.Project = convertCurve(msg.Survival.Project)
+		}
+		if len(msg.Survival.Files) > 0 {
+			result.Survival.Files = map[string]SurvivalCurve{}
+			for _, curve := range msg.Survival.Files {
+				result.Survival.Files[curve.Name] = convertCurve(curve)
+			}
+		}
+		if len(msg.Survival.People) > 0 {
+			result.Survival.People = make([]SurvivalCurve, len(msg.Survival.People))
+			for i, curve := range msg.Survival.People {
+				result.Survival.People[i] = convertCurve(curve)
+			}
+		}
+	}
+	if len(msg.Blame) > 0 {
+		result.Blame = map[string][]BlameInterval{}
+		for _, blame := range msg.Blame {
+			intervals := make([]BlameInterval, len(blame.Authors))
+			for i := range intervals {
+				author := int(blame.Authors[i])
+				if author < 0 {
+					author = identity.AuthorMissing
+				}
+				intervals[i] = BlameInterval{
+					Begin:  int(blame.Lines[i]),
+					End:    int(blame.Lines[i+1]),
+					Author: author,
+					Day:    int(blame.Days[i]),
+				}
+			}
+			result.Blame[blame.Name] = intervals
+		}
+	}
+	if len(msg.BlobClasses) > 0 {
+		result.BlobClasses = map[string]int{}
+		for key, val := range msg.BlobClasses {
+			result.BlobClasses[key] = int(val)
+		}
+	}
 	result.sampling = int(msg.Sampling)
 	result.granularity = int(msg.Granularity)
 	return result, nil
@@ -529,9 +823,126 @@ func (analyser *BurndownAnalysis) MergeResults(
 		}()
 	}
 	wg.Wait()
+	if len(bar1.TeamsMatrix) > 0 || len(bar2.TeamsMatrix) > 0 {
+		var teams map[string][3]int
+		teams, merged.reversedTeamsDict = identity.Detector{}.MergeReversedDicts(
+			bar1.reversedTeamsDict, bar2.reversedTeamsDict)
+		merged.TeamsMatrix = make([][]int64, len(merged.reversedTeamsDict))
+		for i := range merged.TeamsMatrix {
+			merged.TeamsMatrix[i] = make([]int64, len(merged.reversedTeamsDict)+2)
+		}
+		for _, bar := range [...]*BurndownResult{&bar1, &bar2} {
+			for i, row := range bar.TeamsMatrix {
+				mrow := merged.TeamsMatrix[teams[bar.reversedTeamsDict[i]][0]]
+				mrow[0] += row[0]
+				mrow[1] += row[1]
+				for j, val := range row[2:] {
+					mrow[2+teams[bar.reversedTeamsDict[j]][0]] += val
+				}
+			}
+		}
+	}
+	if len(bar1.PeopleMatrixHistory) > 0 || len(bar2.PeopleMatrixHistory) > 0 {
+		merged.PeopleMatrixHistory = mergeInteractionHistories(
+			&bar1, &bar2, people, len(merged.reversedPeopleDict), merged.sampling, c1, c2)
+	}
+	if len(bar1.Blame) > 0 || len(bar2.Blame) > 0 {
+		merged.Blame = map[string][]BlameInterval{}
+		commonMerged := *c1
+		commonMerged.Merge(c2)
+		mergeBlame := func(blame map[string][]BlameInterval, reversedPeopleDict []string,
+			c *core.CommonAnalysisResult) {
+			offset := int((c.BeginTime - commonMerged.BeginTime) / (3600 * 24))
+			for name, intervals := range blame {
+				shifted := make([]BlameInterval, len(intervals))
+				for i, interval := range intervals {
+					if interval.Author != identity.AuthorMissing {
+						interval.Author = people[reversedPeopleDict[interval.Author]][0]
+					}
+					interval.Day += offset
+					shifted[i] = interval
+				}
+				merged.Blame[name] = shifted
+			}
+		}
+		// the most recent state of the same file wins
+		if c1.EndTime <= c2.EndTime {
+			mergeBlame(bar1.Blame, bar1.reversedPeopleDict, c1)
+			mergeBlame(bar2.Blame, bar2.reversedPeopleDict, c2)
+		} else {
+			mergeBlame(bar2.Blame, bar2.reversedPeopleDict, c2)
+			mergeBlame(bar1.Blame, bar1.reversedPeopleDict, c1)
+		}
+	}
+	if len(bar1.BlobClasses) > 0 || len(bar2.BlobClasses) > 0 {
+		merged.BlobClasses = map[string]int{}
+		for _, bar := range [...]*BurndownResult{&bar1, &bar2} {
+			for key, val := range bar.BlobClasses {
+				merged.BlobClasses[key] += val
+			}
+		}
+	}
+	if bar1.Survival != nil || bar2.Survival != nil {
+		// the curves cannot be merged directly, so we estimate them again
+		merged.Survival = estimateBurndownSurvival(&merged)
+	}
 	return merged
 }
 
+// mergeInteractionHistories resamples the people interaction histories of two results to the
+// common timeline with the specified sampling, remaps the people indexes and sums the values.
+// Each sample takes the most recent sample of every input which is not newer than itself,
+// in the same manner as mergeMatrices() treats the sample indexes.
+func mergeInteractionHistories(
+	bar1, bar2 *BurndownResult, people map[string][3]int, peopleNumber, sampling int,
+	c1, c2 *core.CommonAnalysisResult) [][]map[int]int64 {
+	commonMerged := *c1
+	commonMerged.Merge(c2)
+	size := int((commonMerged.EndTime - commonMerged.BeginTime) / (3600 * 24))
+	result := make([][]map[int]int64, (size+sampling-1)/sampling)
+	for i := range result {
+		result[i] = make([]map[int]int64, peopleNumber)
+		for j := range result[i] {
+			result[i][j] = map[int]int64{}
+		}
+	}
+	add := func(bar *BurndownResult, c *core.CommonAnalysisResult) {
+		if len(bar.PeopleMatrixHistory) == 0 {
+			return
+		}
+		offset := int((c.BeginTime - commonMerged.BeginTime) / (3600 * 24))
+		remap := func(index int) int {
+			return people[bar.reversedPeopleDict[index]][0]
+		}
+		for i, sample := range result {
+			day := i * sampling
+			if i == len(result)-1 {
+				day = size - 1
+			}
+			day -= offset
+			if day < 0 {
+				continue
+			}
+			index := day / bar.sampling
+			if index >= len(bar.PeopleMatrixHistory) {
+				index = len(bar.PeopleMatrixHistory) - 1
+			}
+			for y, row := range bar.PeopleMatrixHistory[index] {
+				mrow := sample[remap(y)]
+				for x, val := range row {
+					if x >= 2 {
+						x = 2 + remap(x-2)
+					}
+					mrow[x] += val
+				}
+			}
+		}
+	}
+	add(bar1, c1)
+	add(bar2, c2)
+	return result
+}
+
 // mergeMatrices takes two [number of samples][number of bands] matrices,
 // resamples them to days so that they become square, sums and resamples back to the
 // least of (sampling1, sampling2) and (granularity1, granularity2).
@@ -769,7 +1180,92 @@ func (analyser *BurndownAnalysis) serializeText(result *BurndownResult, writer i
 		}
 		fmt.Fprintln(writer, "  people_interaction: |-")
 		yaml.PrintMatrix(writer, result.PeopleMatrix, 4, "", false)
+		if len(result.PeopleMatrixHistory) > 0 {
+			fmt.Fprintln(writer, "  people_interaction_history:")
+			for _, sample := range result.PeopleMatrixHistory {
+				fmt.Fprintln(writer, "    - |-")
+				yaml.PrintMatrix(writer, denseInteraction(
+					sample, len(result.reversedPeopleDict)), 6, "", false)
+			}
+		}
 	}
+	if len(result.TeamsMatrix) > 0 {
+		fmt.Fprintln(writer, "  teams_sequence:")
+		for _, team := range result.reversedTeamsDict {
+			fmt.Fprintln(writer, "    - "+yaml.SafeString(team))
+		}
+		fmt.Fprintln(writer, "  teams_interaction: |-")
+		yaml.PrintMatrix(writer, result.TeamsMatrix, 4, "", false)
+	}
+	if result.Survival != nil {
+		fmt.Fprintln(writer, "  survival:")
+		printSurvivalCurve(writer, result.Survival.Project, 4, yaml.SafeString("project"))
+		if len(result.Survival.Files) > 0 {
+			fmt.Fprintln(writer, "    files:")
+			keys := make([]string, 0, len(result.Survival.Files))
+			for key := range result.Survival.Files {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				printSurvivalCurve(writer, result.Survival.Files[key], 6, yaml.SafeString(key))
+			}
+		}
+		if len(result.Survival.People) > 0 {
+			fmt.Fprintln(writer, "    people:")
+			for key, curve := range result.Survival.People {
+				printSurvivalCurve(
+					writer, curve, 6, yaml.SafeString(result.reversedPeopleDict[key]))
+			}
+		}
+	}
+	if len(result.Blame) > 0 {
+		// each interval is [begin, end, author, day], author is -1 if unknown
+		fmt.Fprintln(writer, "  blame:")
+		keys := make([]string, 0, len(result.Blame))
+		for key := range result.Blame {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(writer, "    %s:\n", yaml.SafeString(key))
+			for _, interval := range result.Blame[key] {
+				author := interval.Author
+				if author == identity.AuthorMissing {
+					author = -1
+				}
+				fmt.Fprintf(writer, "      - [%d, %d, %d, %d]\n",
+					interval.Begin, interval.End, author, interval.Day)
+			}
+		}
+	}
+	if len(result.BlobClasses) > 0 {
+		fmt.Fprintln(writer, "  blob_classes:")
+		keys := make([]string, 0, len(result.BlobClasses))
+		for key := range result.BlobClasses {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(writer, "    %s: %d\n", key, result.BlobClasses[key])
+		}
+	}
+}
+
+func printSurvivalCurve(writer io.Writer, curve SurvivalCurve, indent int, name string) {
+	prefix := strings.Repeat(" ", indent)
+	fmt.Fprintf(writer, "%s%s:\n", prefix, name)
+	fmt.Fprintf(writer, "%s  half_life: %.2f\n", prefix, curve.HalfLife)
+	days := make([]string, len(curve.Days))
+	for i, day := range curve.Days {
+		days[i] = strconv.Itoa(day)
+	}
+	fmt.Fprintf(writer, "%s  days: [%s]\n", prefix, strings.Join(days, ", "))
+	probs := make([]string, len(curve.Probabilities))
+	for i, prob := range curve.Probabilities {
+		probs[i] = strconv.FormatFloat(float64(prob), 'f', 4, 32)
+	}
+	fmt.Fprintf(writer, "%s  probabilities: [%s]\n", prefix, strings.Join(probs, ", "))
 }
 
 func (analyser *BurndownAnalysis) serializeBinary(result *BurndownResult, writer io.Writer) error {
@@ -800,6 +1296,85 @@ func (analyser *BurndownAnalysis) serializeBinary(result *BurndownResult, writer
 			}
 		}
 		message.PeopleInteraction = pb.DenseToCompressedSparseRowMatrix(result.PeopleMatrix)
+		message.PeopleInteractionHistory = make(
+			[]*pb.CompressedSparseRowMatrix, len(result.PeopleMatrixHistory))
+		for i, sample := range result.PeopleMatrixHistory {
+			matrix := pb.MapToCompressedSparseRowMatrix(sample)
+			matrix.NumberOfColumns = int32(len(result.reversedPeopleDict) + 2)
+			message.PeopleInteractionHistory[i] = matrix
+		}
+	}
+	if len(result.TeamsMatrix) > 0 {
+		message.Teams = result.reversedTeamsDict
+		message.TeamsInteraction = pb.DenseToCompressedSparseRowMatrix(result.TeamsMatrix)
+	}
+	if result.Survival != nil {
+		convertCurve := func(curve SurvivalCurve, name string) *pb.SurvivalCurve {
+			res := &pb.SurvivalCurve{
+				Name:          name,
+				Days:          make([]int32, len(curve.Days)),
+				Probabilities: curve.Probabilities,
+				HalfLife:      curve.HalfLife,
+			}
+			for i, day := range curve.Days {
+				res.Days[i] = int32(day)
+			}
+			return res
+		}
+		message.Survival = &pb.BurndownSurvival{
+			Project: convertCurve(result.Survival.Project, "project"),
+		}
+		if len(result.Survival.Files) > 0 {
+			keys := make([]string, 0, len(result.Survival.Files))
+			for key := range result.Survival.Files {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			message.Survival.Files = make([]*pb.SurvivalCurve, len(keys))
+			for i, key := range keys {
+				message.Survival.Files[i] = convertCurve(result.Survival.Files[key], key)
+			}
+		}
+		if len(result.Survival.People) > 0 {
+			message.Survival.People = make([]*pb.SurvivalCurve, len(result.Survival.People))
+			for key, curve := range result.Survival.People {
+				message.Survival.People[key] = convertCurve(curve, result.reversedPeopleDict[key])
+			}
+		}
+	}
+	if len(result.Blame) > 0 {
+		keys := make([]string, 0, len(result.Blame))
+		for key := range result.Blame {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		message.Blame = make([]*pb.FileBlame, len(keys))
+		for i, key := range keys {
+			intervals := result.Blame[key]
+			blame := &pb.FileBlame{
+				Name:    key,
+				Lines:   make([]int32, len(intervals)+1),
+				Authors: make([]int32, len(intervals)),
+				Days:    make([]int32, len(intervals)),
+			}
+			for j, interval := range intervals {
+				blame.Lines[j] = int32(interval.Begin)
+				blame.Lines[j+1] = int32(interval.End)
+				if interval.Author == identity.AuthorMissing {
+					blame.Authors[j] = -1
+				} else {
+					blame.Authors[j] = int32(interval.Author)
+				}
+				blame.Days[j] = int32(interval.Day)
+			}
+			message.Blame[i] = blame
+		}
+	}
+	if len(result.BlobClasses) > 0 {
+		message.BlobClasses = map[string]int32{}
+		for key, val := range result.BlobClasses {
+			message.BlobClasses[key] = int32(val)
+		}
 	}
 	serialized, err := proto.Marshal(&message)
 	if err != nil {
@@ -809,6 +1384,90 @@ func (analyser *BurndownAnalysis) serializeBinary(result *BurndownResult, writer
 	return nil
 }
 
+// estimateBurndownSurvival calculates the survival curves for all the burndown matrices
+// in the specified result.
+func estimateBurndownSurvival(result *BurndownResult) *BurndownSurvival {
+	survival := &BurndownSurvival{
+		Project: estimateSurvival(result.GlobalHistory, result.granularity, result.sampling),
+	}
+	if len(result.FileHistories) > 0 {
+		survival.Files = map[string]SurvivalCurve{}
+		for key, history := range result.FileHistories {
+			survival.Files[key] = estimateSurvival(history, result.granularity, result.sampling)
+		}
+	}
+	if len(result.PeopleHistories) > 0 {
+		survival.People = make([]SurvivalCurve, len(result.PeopleHistories))
+		for key, history := range result.PeopleHistories {
+			survival.People[key] = estimateSurvival(history, result.granularity, result.sampling)
+		}
+	}
+	return survival
+}
+
+// estimateSurvival calculates the Kaplan-Meier estimate of the line survival function from
+// a [number of samples][number of bands] burndown matrix. Every band is a cohort of lines:
+// we start observing it at the first sample after the band is complete and stop at the last
+// sample (right censoring). The ages are measured in samples and converted to days.
+func estimateSurvival(matrix [][]int64, granularity, sampling int) SurvivalCurve {
+	curve := SurvivalCurve{Days: []int{0}, Probabilities: []float32{1}, HalfLife: -1}
+	if len(matrix) == 0 || granularity <= 0 || sampling <= 0 {
+		return curve
+	}
+	bands := 0
+	for _, row := range matrix {
+		if len(row) > bands {
+			bands = len(row)
+		}
+	}
+	// starts[x] is the index of the first sample in which band x is complete
+	starts := make([]int, bands)
+	for x := range starts {
+		starts[x] = ((x+1)*granularity+sampling-1)/sampling - 1
+	}
+	value := func(y, x int) int64 {
+		if x < len(matrix[y]) {
+			return matrix[y][x]
+		}
+		return 0
+	}
+	survival := 1.0
+	for age := 1; ; age++ {
+		var atRisk, deaths int64
+		for x, start := range starts {
+			if start+age >= len(matrix) {
+				// censored
+				continue
+			}
+			before := value(start+age-1, x)
+			after := value(start+age, x)
+			if before <= 0 {
+				continue
+			}
+			atRisk += before
+			if after < before {
+				deaths += before - after
+			}
+		}
+		if atRisk == 0 {
+			break
+		}
+		survival *= 1 - float64(deaths)/float64(atRisk)
+		curve.Days = append(curve.Days, age*sampling)
+		curve.Probabilities = append(curve.Probabilities, float32(survival))
+	}
+	for i := 1; i < len(curve.Probabilities); i++ {
+		if curve.Probabilities[i] > 0.5 {
+			continue
+		}
+		p1, p2 := curve.Probabilities[i-1], curve.Probabilities[i]
+		d1, d2 := float32(curve.Days[i-1]), float32(curve.Days[i])
+		curve.HalfLife = d1 + (p1-0.5)/(p1-p2)*(d2-d1)
+		break
+	}
+	return curve
+}
+
 func sortedKeys(m map[string][][]int64) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -832,11 +1491,7 @@ func (analyser *BurndownAnalysis) packPersonWithDay(person int, day int) int {
 	if analyser.PeopleNumber == 0 {
 		return day
 	}
-	result := day & burndown.TreeMergeMark
-	result |= person << burndown.TreeMaxBinPower
-	// This effectively means max (16383 - 1) days (>44 years) and (131072 - 2) devs.
-	// One day less because burndown.TreeMergeMark = ((1 << 14) - 1) is a special day.
-	return result
+	return packPersonWithDay(person, day)
 }
 
 func (analyser *BurndownAnalysis) unpackPersonWithDay(value int) (int, int) {
@@ -846,6 +1501,22 @@ func (analyser *BurndownAnalysis) unpackPersonWithDay(value int) (int, int) {
 	return value >> burndown.TreeMaxBinPower, value & burndown.TreeMergeMark
 }
 
+// blameFile converts the line interval tree of the specified file to the list of BlameInterval-s.
+func (analyser *BurndownAnalysis) blameFile(file *burndown.File) []BlameInterval {
+	intervals := []BlameInterval{}
+	file.ForEach(func(line, value int) {
+		if len(intervals) > 0 {
+			intervals[len(intervals)-1].End = line
+		}
+		if value == burndown.TreeEnd {
+			return
+		}
+		author, day := analyser.unpackPersonWithDay(value)
+		intervals = append(intervals, BlameInterval{Begin: line, Author: author, Day: day})
+	})
+	return intervals
+}
+
 func (analyser *BurndownAnalysis) onNewDay() {
 	day := analyser.day
 	sampling := analyser.Sampling
@@ -882,9 +1553,30 @@ func (analyser *BurndownAnalysis) updatePeople(
 func (analyser *BurndownAnalysis) updateMatrix(
 	matrixUncasted interface{}, currentTime int, previousTime int, delta int) {
 
-	matrix := matrixUncasted.([]map[int]int64)
 	newAuthor, _ := analyser.unpackPersonWithDay(currentTime)
 	oldAuthor, _ := analyser.unpackPersonWithDay(previousTime)
+	updateInteraction(matrixUncasted.([]map[int]int64), newAuthor, oldAuthor, delta)
+}
+
+func (analyser *BurndownAnalysis) updateTeamsMatrix(
+	matrixUncasted interface{}, currentTime int, previousTime int, delta int) {
+
+	updateInteraction(matrixUncasted.([]map[int]int64),
+		analyser.lineTeam(currentTime), analyser.lineTeam(previousTime), delta)
+}
+
+// lineTeam returns the team of the author of the packed line value or identity.TeamMissing.
+func (analyser *BurndownAnalysis) lineTeam(value int) int {
+	team, exists := analyser.lineTeams[value]
+	if !exists {
+		return identity.TeamMissing
+	}
+	return team
+}
+
+// updateInteraction records that `delta` lines of `oldAuthor` were overwritten by `newAuthor`.
+// identity.AuthorMissing and identity.TeamMissing are the same.
+func updateInteraction(matrix []map[int]int64, newAuthor int, oldAuthor int, delta int) {
 	if oldAuthor == identity.AuthorMissing {
 		return
 	}
@@ -907,6 +1599,16 @@ func (analyser *BurndownAnalysis) updateMatrix(
 func (analyser *BurndownAnalysis) newFile(
 	hash plumbing.Hash, author int, day int, size int, global map[int]int64,
 	people []map[int]int64, matrix []map[int]int64) *burndown.File {
+	statuses := analyser.newStatuses(global, people, matrix)
+	if analyser.PeopleNumber > 0 {
+		day = analyser.packPersonWithDay(author, day)
+	}
+	return burndown.NewFile(hash, day, size, statuses...)
+}
+
+// newStatuses creates the statuses which are attached to every tracked file.
+func (analyser *BurndownAnalysis) newStatuses(
+	global map[int]int64, people []map[int]int64, matrix []map[int]int64) []burndown.Status {
 	statuses := make([]burndown.Status, 1)
 	statuses[0] = burndown.NewStatus(global, analyser.updateStatus)
 	if analyser.TrackFiles {
@@ -915,112 +1617,98 @@ func (analyser *BurndownAnalysis) newFile(
 	if analyser.PeopleNumber > 0 {
 		statuses = append(statuses, burndown.NewStatus(people, analyser.updatePeople))
 		statuses = append(statuses, burndown.NewStatus(matrix, analyser.updateMatrix))
-		day = analyser.packPersonWithDay(author, day)
+		if analyser.TrackTeams {
+			statuses = append(statuses, burndown.NewStatus(
+				analyser.teamsMatrix, analyser.updateTeamsMatrix))
+		}
 	}
-	return burndown.NewFile(hash, day, size, statuses...)
+	return statuses
 }
 
-func (analyser *BurndownAnalysis) handleInsertion(
-	change *object.Change, author int, cache map[plumbing.Hash]*object.Blob) error {
-	blob := cache[change.To.TreeEntry.Hash]
-	lines, err := items.CountLines(blob)
-	if err != nil {
-		if err.Error() == "binary" {
-			return nil
-		}
-		return err
+// packAuthors packs each of the authors with the current day, see packPersonWithDay().
+func (analyser *BurndownAnalysis) packAuthors(authors []int) []int {
+	if analyser.PeopleNumber == 0 {
+		return []int{analyser.day}
 	}
-	name := change.To.Name
-	file, exists := analyser.files[name]
-	if exists {
-		return fmt.Errorf("file %s already exists", name)
+	values := make([]int, len(authors))
+	for i, author := range authors {
+		values[i] = analyser.packPersonWithDay(author, analyser.day)
 	}
-	file = analyser.newFile(
-		blob.Hash, author, analyser.day, lines,
-		analyser.globalStatus, analyser.people, analyser.matrix)
-	analyser.files[name] = file
-	return nil
+	return values
 }
 
-func (analyser *BurndownAnalysis) handleDeletion(
-	change *object.Change, author int, cache map[plumbing.Hash]*object.Blob) error {
-
-	blob := cache[change.From.TreeEntry.Hash]
-	lines, err := items.CountLines(blob)
-	if err != nil {
-		if err.Error() == "binary" {
-			return nil
-		}
-		return err
+// updateSplit calls File.Update() dividing the inserted and the deleted lines between
+// the packed `values` in contiguous chunks, see identity.SplitCredit().
+func updateSplit(file *burndown.File, values []int, pos int, insLength int, delLength int) {
+	insChunks := identity.SplitCredit(insLength, len(values))
+	delChunks := identity.SplitCredit(delLength, len(values))
+	for i, value := range values {
+		file.Update(value, pos, insChunks[i], delChunks[i])
+		pos += insChunks[i]
 	}
-	name := change.From.Name
-	file := analyser.files[name]
-	file.Update(analyser.packPersonWithDay(author, analyser.day), 0, 0, lines)
-	file.Hash = plumbing.ZeroHash
-	delete(analyser.files, name)
-	return nil
 }
 
-func (analyser *BurndownAnalysis) handleModification(
-	change *object.Change, author int, cache map[plumbing.Hash]*object.Blob,
-	diffs map[string]items.FileDiffData) error {
+// The following helpers track the lines of every file the same way in BurndownAnalysis,
+// ChurnAnalysis and OwnershipAnalysis. They differ only in what they pack into the line values
+// and what they record on each update.
 
-	file, exists := analyser.files[change.From.Name]
-	if !exists {
-		// this indeed may happen
-		return analyser.handleInsertion(change, author, cache)
-	}
-	file.Hash = change.To.TreeEntry.Hash
+// packPersonWithDay packs the developer index and the day into the value of a line.
+// This effectively means max (16383 - 1) days (>44 years) and (131072 - 2) devs.
+// One day less because burndown.TreeMergeMark = ((1 << 14) - 1) is a special day.
+func packPersonWithDay(person int, day int) int {
+	return (person << burndown.TreeMaxBinPower) | (day & burndown.TreeMergeMark)
+}
 
-	// possible rename
-	if change.To.Name != change.From.Name {
-		err := analyser.handleRename(change.From.Name, change.To.Name)
-		if err != nil {
-			return err
-		}
-	}
+// newSplitFile creates the file with `lines` lines which are split between `values`.
+// The first value receives the biggest chunk, see identity.SplitCredit().
+func newSplitFile(hash plumbing.Hash, values []int, lines int, statuses ...burndown.Status) *burndown.File {
+	first := identity.SplitCredit(lines, len(values))[0]
+	file := burndown.NewFile(hash, values[0], first, statuses...)
+	updateSplit(file, values[1:], first, lines-first, 0)
+	return file
+}
 
-	thisDiffs := diffs[change.To.Name]
-	if file.Len() != thisDiffs.OldLinesOfCode {
-		log.Printf("====TREE====\n%s", file.Dump())
-		return fmt.Errorf("%s: internal integrity error src %d != %d %s -> %s",
-			change.To.Name, thisDiffs.OldLinesOfCode, file.Len(),
-			change.From.TreeEntry.Hash.String(), change.To.TreeEntry.Hash.String())
+// applyFileDiff replays the line diff of the file named `name`. `update` changes the lines
+// of each hunk: it receives the position, the number of the inserted and the number of the deleted
+// lines, the deletions go first. The ignored commits, see core.ConfigPipelineIgnoredCommits,
+// do not own the lines they touch: the replaced lines keep their values and the inserted lines
+// take the value of the neighbouring line without calling `update`. We do not call RunesToDiffLines
+// so the number of lines equals to the rune count.
+func applyFileDiff(name string, file *burndown.File, diff items.FileDiffData, ignored bool,
+	update func(pos, insLength, delLength int)) error {
+	if file.Len() != diff.OldLinesOfCode {
+		return fmt.Errorf("%s: internal integrity error src %d != %d",
+			name, diff.OldLinesOfCode, file.Len())
 	}
-
-	// we do not call RunesToDiffLines so the number of lines equals
-	// to the rune count
 	position := 0
 	pending := diffmatchpatch.Diff{Text: ""}
-
+	insert := func(length int) {
+		if !ignored {
+			update(position, length, 0)
+			return
+		}
+		value := file.Value(position - 1)
+		if value == burndown.TreeEnd {
+			value = file.Value(position)
+		}
+		if value == burndown.TreeEnd {
+			// the file is empty and there is nothing to carry over
+			update(position, length, 0)
+			return
+		}
+		file.Update(value, position, length, 0)
+	}
 	apply := func(edit diffmatchpatch.Diff) {
 		length := utf8.RuneCountInString(edit.Text)
 		if edit.Type == diffmatchpatch.DiffInsert {
-			file.Update(analyser.packPersonWithDay(author, analyser.day), position, length, 0)
+			insert(length)
 			position += length
 		} else {
-			file.Update(analyser.packPersonWithDay(author, analyser.day), position, 0, length)
-		}
-		if analyser.Debug {
-			file.Validate()
+			update(position, 0, length)
 		}
 	}
-
-	for _, edit := range thisDiffs.Diffs {
-		dumpBefore := ""
-		if analyser.Debug {
-			dumpBefore = file.Dump()
-		}
+	for _, edit := range diff.Diffs {
 		length := utf8.RuneCountInString(edit.Text)
-		debugError := func() {
-			log.Printf("%s: internal diff error\n", change.To.Name)
-			log.Printf("Update(%d, %d, %d (0), %d (0))\n", analyser.day, position,
-				length, utf8.RuneCountInString(pending.Text))
-			if dumpBefore != "" {
-				log.Printf("====TREE BEFORE====\n%s====END====\n", dumpBefore)
-			}
-			log.Printf("====TREE AFTER====\n%s====END====\n", file.Dump())
-		}
 		switch edit.Type {
 		case diffmatchpatch.DiffEqual:
 			if pending.Text != "" {
@@ -1031,41 +1719,178 @@ func (analyser *BurndownAnalysis) handleModification(
 		case diffmatchpatch.DiffInsert:
 			if pending.Text != "" {
 				if pending.Type == diffmatchpatch.DiffInsert {
-					debugError()
 					return errors.New("DiffInsert may not appear after DiffInsert")
 				}
-				file.Update(analyser.packPersonWithDay(author, analyser.day), position, length,
-					utf8.RuneCountInString(pending.Text))
-				if analyser.Debug {
-					file.Validate()
+				insLength, delLength := length, utf8.RuneCountInString(pending.Text)
+				if ignored {
+					// the replaced lines keep their values
+					kept := internal.Min(insLength, delLength)
+					position += kept
+					if insLength > kept {
+						insert(insLength - kept)
+					} else if delLength > kept {
+						update(position, 0, delLength-kept)
+					}
+					position += insLength - kept
+				} else {
+					update(position, insLength, delLength)
+					position += insLength
 				}
-				position += length
 				pending.Text = ""
 			} else {
 				pending = edit
 			}
 		case diffmatchpatch.DiffDelete:
 			if pending.Text != "" {
-				debugError()
 				return errors.New("DiffDelete may not appear after DiffInsert/DiffDelete")
 			}
 			pending = edit
 		default:
-			debugError()
 			return fmt.Errorf("diff operation is not supported: %d", edit.Type)
 		}
 	}
 	if pending.Text != "" {
 		apply(pending)
-		pending.Text = ""
 	}
-	if file.Len() != thisDiffs.NewLinesOfCode {
+	if file.Len() != diff.NewLinesOfCode {
 		return fmt.Errorf("%s: internal integrity error dst %d != %d",
-			change.To.Name, thisDiffs.NewLinesOfCode, file.Len())
+			name, diff.NewLinesOfCode, file.Len())
+	}
+	return nil
+}
+
+// mergeFiles merges the same files in several branches, the lines which were changed
+// in the merge commit receive `value`. The merged files are copied to the branches.
+// It returns the names of the merged files.
+func mergeFiles(value int, files map[string]*burndown.File, branches []map[string]*burndown.File) []string {
+	var merged []string
+	for key, file := range files {
+		others := make([]*burndown.File, 0, len(branches))
+		for _, branch := range branches {
+			if other, exists := branch[key]; exists {
+				others = append(others, other)
+			}
+		}
+		if file.Merge(value, others...) {
+			merged = append(merged, key)
+			for _, branch := range branches {
+				branch[key] = file.Clone(false)
+			}
+		}
+	}
+	return merged
+}
+
+func (analyser *BurndownAnalysis) handleInsertion(
+	change *object.Change, authors []int, cache map[plumbing.Hash]*object.Blob) error {
+	blob := cache[change.To.TreeEntry.Hash]
+	lines, err := analyser.blobClasses.CountLines(blob)
+	if err != nil {
+		if err.Error() == "binary" {
+			return nil
+		}
+		return err
+	}
+	name := change.To.Name
+	if _, exists := analyser.files[name]; exists {
+		return fmt.Errorf("file %s already exists", name)
 	}
+	analyser.files[name] = newSplitFile(blob.Hash, analyser.packAuthors(authors), lines,
+		analyser.newStatuses(analyser.globalStatus, analyser.people, analyser.matrix)...)
 	return nil
 }
 
+// handleCopy inherits the line ages of the copied or the deleted file and then applies the changes
+// which were made to the copy. Copies in merge commits are treated as insertions.
+func (analyser *BurndownAnalysis) handleCopy(
+	fileCopy items.FileCopy, authors []int, cache map[plumbing.Hash]*object.Blob) error {
+	insertion := &object.Change{To: fileCopy.To}
+	source, exists := analyser.files[fileCopy.From.Name]
+	if fileCopy.Deleted {
+		var deleted deletedFile
+		deleted, exists = analyser.deletedFiles[fileCopy.From.Name]
+		exists = exists && deleted.file.Hash == fileCopy.From.TreeEntry.Hash
+		if exists {
+			delete(analyser.deletedFiles, fileCopy.From.Name)
+			source = deleted.file
+		}
+	}
+	if !exists || analyser.day == burndown.TreeMergeMark {
+		return analyser.handleInsertion(insertion, authors, cache)
+	}
+	if _, exists := analyser.files[fileCopy.To.Name]; exists {
+		return fmt.Errorf("file %s already exists", fileCopy.To.Name)
+	}
+	analyser.files[fileCopy.To.Name] = source.Duplicate(
+		fileCopy.From.TreeEntry.Hash,
+		analyser.newStatuses(analyser.globalStatus, analyser.people, analyser.matrix)...)
+	if fileCopy.From.TreeEntry.Hash == fileCopy.To.TreeEntry.Hash {
+		return nil
+	}
+	return analyser.handleModification(
+		&object.Change{From: fileCopy.To, To: fileCopy.To}, authors, cache,
+		map[string]items.FileDiffData{fileCopy.To.Name: fileCopy.Diff})
+}
+
+func (analyser *BurndownAnalysis) handleDeletion(
+	change *object.Change, authors []int, cache map[plumbing.Hash]*object.Blob) error {
+
+	blob := cache[change.From.TreeEntry.Hash]
+	lines, err := analyser.blobClasses.CountLines(blob)
+	name := change.From.Name
+	file, exists := analyser.files[name]
+	if err != nil {
+		if err.Error() != "binary" {
+			return err
+		}
+		if !exists {
+			return nil
+		}
+		// the file became binary after it had been inserted
+		lines = file.Len()
+	}
+	if analyser.renamesWindow > 0 {
+		analyser.deletedFiles[name] = deletedFile{file: file.Clone(true), commit: analyser.commits}
+	}
+	updateSplit(file, analyser.packAuthors(authors), 0, 0, lines)
+	file.Hash = plumbing.ZeroHash
+	delete(analyser.files, name)
+	return nil
+}
+
+func (analyser *BurndownAnalysis) handleModification(
+	change *object.Change, authors []int, cache map[plumbing.Hash]*object.Blob,
+	diffs map[string]items.FileDiffData) error {
+
+	file, exists := analyser.files[change.From.Name]
+	if !exists {
+		// this indeed may happen
+		return analyser.handleInsertion(change, authors, cache)
+	}
+	file.Hash = change.To.TreeEntry.Hash
+
+	// possible rename
+	if change.To.Name != change.From.Name {
+		err := analyser.handleRename(change.From.Name, change.To.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	values := analyser.packAuthors(authors)
+	err := applyFileDiff(change.To.Name, file, diffs[change.To.Name], analyser.ignoredCommit,
+		func(pos, insLength, delLength int) {
+			updateSplit(file, values, pos, insLength, delLength)
+			if analyser.Debug {
+				file.Validate()
+			}
+		})
+	if err != nil {
+		log.Printf("====TREE====\n%s", file.Dump())
+	}
+	return err
+}
+
 func (analyser *BurndownAnalysis) handleRename(from, to string) error {
 	file, exists := analyser.files[from]
 	if !exists {
@@ -1174,6 +1999,44 @@ func (analyser *BurndownAnalysis) updateHistories(
 		}
 		analyser.peopleHistories[key] = ph
 	}
+
+	if analyser.PeopleNumber > 0 {
+		snapshot := sparseInteraction(analyser.matrix)
+		for i := 0; i < delta; i++ {
+			analyser.matrixHistory = append(analyser.matrixHistory, snapshot)
+		}
+	}
+}
+
+// sparseInteraction copies the specified interaction matrix, either of people or of teams.
+// The column layout is the same as in BurndownResult.PeopleMatrix.
+func sparseInteraction(matrix []map[int]int64) []map[int]int64 {
+	snapshot := make([]map[int]int64, len(matrix))
+	for i, row := range matrix {
+		srow := map[int]int64{}
+		snapshot[i] = srow
+		for key, val := range row {
+			if key == identity.AuthorMissing {
+				key = -1
+			} else if key == authorSelf {
+				key = -2
+			}
+			srow[key+2] = val
+		}
+	}
+	return snapshot
+}
+
+// denseInteraction converts a sparse people interaction matrix to [number of people][number of people + 2].
+func denseInteraction(sparse []map[int]int64, peopleNumber int) [][]int64 {
+	dense := make([][]int64, len(sparse))
+	for i, row := range sparse {
+		dense[i] = make([]int64, peopleNumber+2)
+		for key, val := range row {
+			dense[i][key] = val
+		}
+	}
+	return dense
 }
 
 func init() {
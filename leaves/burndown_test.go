@@ -11,6 +11,7 @@ import (
 	"gopkg.in/src-d/hercules.v4/internal/test/fixtures"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/sergi/go-diff/diffmatchpatch"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
@@ -26,7 +27,8 @@ func TestBurndownMeta(t *testing.T) {
 	assert.Equal(t, len(burndown.Provides()), 0)
 	required := [...]string{
 		items.DependencyFileDiff, items.DependencyTreeChanges, items.DependencyBlobCache,
-		items.DependencyDay, identity.DependencyAuthor}
+		items.DependencyBlobClasses, items.DependencyDay, identity.DependencyAuthor,
+		identity.DependencyTeam, items.DependencyTreeCopies, items.DependencyTreeChangeKinds}
 	for _, name := range required {
 		assert.Contains(t, burndown.Requires(), name)
 	}
@@ -35,7 +37,8 @@ func TestBurndownMeta(t *testing.T) {
 	for _, opt := range opts {
 		switch opt.Name {
 		case ConfigBurndownGranularity, ConfigBurndownSampling, ConfigBurndownTrackFiles,
-			ConfigBurndownTrackPeople, ConfigBurndownDebug:
+			ConfigBurndownTrackPeople, ConfigBurndownTrackTeams, ConfigBurndownSurvival,
+			ConfigBurndownBlame, ConfigBurndownDebug:
 			matches++
 		}
 	}
@@ -50,14 +53,22 @@ func TestBurndownConfigure(t *testing.T) {
 	facts[ConfigBurndownSampling] = 200
 	facts[ConfigBurndownTrackFiles] = true
 	facts[ConfigBurndownTrackPeople] = true
+	facts[ConfigBurndownTrackTeams] = true
+	facts[ConfigBurndownSurvival] = true
+	facts[ConfigBurndownBlame] = true
 	facts[ConfigBurndownDebug] = true
 	facts[identity.FactIdentityDetectorPeopleCount] = 5
 	facts[identity.FactIdentityDetectorReversedPeopleDict] = burndown.Requires()
+	facts[identity.FactIdentityDetectorReversedTeamsDict] = []string{"one", "two"}
 	burndown.Configure(facts)
 	assert.Equal(t, burndown.Granularity, 100)
 	assert.Equal(t, burndown.Sampling, 200)
 	assert.Equal(t, burndown.TrackFiles, true)
 	assert.Equal(t, burndown.PeopleNumber, 5)
+	assert.Equal(t, burndown.TrackTeams, true)
+	assert.Equal(t, burndown.reversedTeamsDict, []string{"one", "two"})
+	assert.Equal(t, burndown.Survival, true)
+	assert.Equal(t, burndown.Blame, true)
 	assert.Equal(t, burndown.Debug, true)
 	assert.Equal(t, burndown.reversedPeopleDict, burndown.Requires())
 	facts[ConfigBurndownTrackPeople] = false
@@ -70,6 +81,8 @@ func TestBurndownConfigure(t *testing.T) {
 	assert.Equal(t, burndown.Sampling, 200)
 	assert.Equal(t, burndown.TrackFiles, true)
 	assert.Equal(t, burndown.PeopleNumber, 0)
+	assert.Equal(t, burndown.Survival, true)
+	assert.Equal(t, burndown.Blame, true)
 	assert.Equal(t, burndown.Debug, true)
 	assert.Equal(t, burndown.reversedPeopleDict, burndown.Requires())
 }
@@ -323,6 +336,8 @@ func TestBurndownConsumeFinalize(t *testing.T) {
 	assert.Equal(t, out.PeopleMatrix[1][1], int64(0))
 	assert.Equal(t, out.PeopleMatrix[1][2], int64(0))
 	assert.Equal(t, out.PeopleMatrix[1][3], int64(0))
+	assert.Len(t, out.PeopleMatrixHistory, 2)
+	assert.Equal(t, out.PeopleMatrixHistory[1], []map[int]int64{{0: 1145, 3: -681}, {0: 369}})
 	assert.Equal(t, len(out.PeopleHistories), 2)
 	for i := 0; i < 2; i++ {
 		assert.Equal(t, len(out.PeopleHistories[i]), 2)
@@ -506,6 +521,13 @@ func TestBurndownSerialize(t *testing.T) {
   people_interaction: |-
     1145    0    0 -681
      369    0    0    0
+  people_interaction_history:
+    - |-
+      1145    0    0    0
+         0    0    0    0
+    - |-
+      1145    0    0 -681
+       369    0    0    0
 `)
 	buffer = &bytes.Buffer{}
 	burndown.Serialize(out, true, buffer)
@@ -551,6 +573,354 @@ func TestBurndownSerialize(t *testing.T) {
 	assert.Equal(t, msg.PeopleInteraction.Indices, indices[:])
 	indptr := [...]int64{0, 2, 3}
 	assert.Equal(t, msg.PeopleInteraction.Indptr, indptr[:])
+	assert.Len(t, msg.PeopleInteractionHistory, 2)
+	assert.Equal(t, msg.PeopleInteractionHistory[0].NumberOfColumns, int32(4))
+	assert.Equal(t, msg.PeopleInteractionHistory[0].Data, []int64{1145})
+	assert.Equal(t, msg.PeopleInteractionHistory[1].Data, data[:])
+	assert.Equal(t, msg.PeopleInteractionHistory[1].Indptr, indptr[:])
+}
+
+func fixtureSurvivalMatrix() [][]int64 {
+	return [][]int64{
+		{100, 0},
+		{80, 50},
+		{40, 50},
+		{20, 25},
+	}
+}
+
+func TestBurndownEstimateSurvival(t *testing.T) {
+	curve := estimateSurvival(fixtureSurvivalMatrix(), 30, 30)
+	assert.Equal(t, curve.Days, []int{0, 30, 60, 90})
+	assert.Len(t, curve.Probabilities, 4)
+	assert.Equal(t, curve.Probabilities[0], float32(1))
+	assert.InDelta(t, curve.Probabilities[1], 1-20.0/150, 0.0001)
+	assert.InDelta(t, curve.Probabilities[2], (1-20.0/150)*0.5, 0.0001)
+	assert.InDelta(t, curve.Probabilities[3], (1-20.0/150)*0.25, 0.0001)
+	assert.InDelta(t, curve.HalfLife, 55.38, 0.01)
+	curve = estimateSurvival([][]int64{{100}, {100}, {90}}, 30, 30)
+	assert.Equal(t, curve.Days, []int{0, 30, 60})
+	assert.Equal(t, curve.HalfLife, float32(-1))
+	curve = estimateSurvival(nil, 30, 30)
+	assert.Equal(t, curve.Days, []int{0})
+	assert.Equal(t, curve.Probabilities, []float32{1})
+	assert.Equal(t, curve.HalfLife, float32(-1))
+	// each band spans two samples
+	curve = estimateSurvival([][]int64{{100}, {120}, {60, 10}, {60, 40}, {30, 20}}, 60, 30)
+	assert.Equal(t, curve.Days, []int{0, 30, 60, 90})
+	assert.Equal(t, curve.Probabilities, []float32{1, 0.5, 0.5, 0.25})
+	assert.Equal(t, curve.HalfLife, float32(30))
+}
+
+func TestBurndownSurvivalSerialize(t *testing.T) {
+	burndown := BurndownAnalysis{Survival: true}
+	result := BurndownResult{
+		GlobalHistory:      fixtureSurvivalMatrix(),
+		FileHistories:      map[string][][]int64{"file.go": fixtureSurvivalMatrix()},
+		PeopleHistories:    [][][]int64{fixtureSurvivalMatrix()},
+		PeopleMatrix:       [][]int64{{145, 0, 0}},
+		reversedPeopleDict: []string{"one@srcd"},
+		granularity:        30,
+		sampling:           30,
+	}
+	result.Survival = estimateBurndownSurvival(&result)
+	assert.Len(t, result.Survival.Files, 1)
+	assert.Len(t, result.Survival.People, 1)
+	assert.Equal(t, result.Survival.Project, result.Survival.Files["file.go"])
+	assert.Equal(t, result.Survival.Project, result.Survival.People[0])
+	buffer := &bytes.Buffer{}
+	burndown.Serialize(result, false, buffer)
+	assert.Contains(t, buffer.String(), `  survival:
+    "project":
+      half_life: 55.38
+      days: [0, 30, 60, 90]
+      probabilities: [1.0000, 0.8667, 0.4333, 0.2167]
+    files:
+      "file.go":
+        half_life: 55.38
+        days: [0, 30, 60, 90]
+        probabilities: [1.0000, 0.8667, 0.4333, 0.2167]
+    people:
+      "one@srcd":
+        half_life: 55.38
+        days: [0, 30, 60, 90]
+        probabilities: [1.0000, 0.8667, 0.4333, 0.2167]
+`)
+	buffer = &bytes.Buffer{}
+	burndown.Serialize(result, true, buffer)
+	msg := pb.BurndownAnalysisResults{}
+	proto.Unmarshal(buffer.Bytes(), &msg)
+	assert.NotNil(t, msg.Survival)
+	assert.Equal(t, msg.Survival.Project.Name, "project")
+	assert.Equal(t, msg.Survival.Project.Days, []int32{0, 30, 60, 90})
+	assert.InDelta(t, msg.Survival.Project.HalfLife, 55.38, 0.01)
+	assert.Len(t, msg.Survival.Files, 1)
+	assert.Equal(t, msg.Survival.Files[0].Name, "file.go")
+	assert.Len(t, msg.Survival.People, 1)
+	assert.Equal(t, msg.Survival.People[0].Name, "one@srcd")
+	iresult, err := burndown.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	deserialized := iresult.(BurndownResult)
+	assert.Equal(t, deserialized.Survival, result.Survival)
+	merged := burndown.MergeResults(result, BurndownResult{
+		granularity: 30, sampling: 30}, &core.CommonAnalysisResult{
+		BeginTime: 600566400, EndTime: 600566400 + 120*24*3600},
+		&core.CommonAnalysisResult{
+			BeginTime: 600566400, EndTime: 600566400 + 120*24*3600}).(BurndownResult)
+	assert.NotNil(t, merged.Survival)
+	assert.Len(t, merged.Survival.Files, 1)
+	assert.Len(t, merged.Survival.People, 1)
+}
+
+func fixtureBurndownBlame() *BurndownAnalysis {
+	burndown := BurndownAnalysis{
+		Granularity:  30,
+		Sampling:     30,
+		PeopleNumber: 2,
+		Blame:        true,
+	}
+	burndown.Initialize(test.Repository)
+	burndown.reversedPeopleDict = []string{"one@srcd", "two@srcd"}
+	file := burndown.newFile(plumbing.ZeroHash, 0, 5, 100,
+		burndown.globalStatus, burndown.people, burndown.matrix)
+	burndown.files["one.go"] = file
+	burndown.day = 10
+	file.Update(burndown.packPersonWithDay(1, 10), 20, 10, 5)
+	burndown.files["two.go"] = burndown.newFile(
+		plumbing.ZeroHash, identity.AuthorMissing, 7, 3,
+		burndown.globalStatus, burndown.people, burndown.matrix)
+	return &burndown
+}
+
+func TestBurndownBlame(t *testing.T) {
+	burndown := fixtureBurndownBlame()
+	result := burndown.Finalize().(BurndownResult)
+	assert.Len(t, result.Blame, 2)
+	assert.Equal(t, []BlameInterval{
+		{Begin: 0, End: 20, Author: 0, Day: 5},
+		{Begin: 20, End: 30, Author: 1, Day: 10},
+		{Begin: 30, End: 105, Author: 0, Day: 5},
+	}, result.Blame["one.go"])
+	assert.Equal(t, []BlameInterval{
+		{Begin: 0, End: 3, Author: identity.AuthorMissing, Day: 7},
+	}, result.Blame["two.go"])
+	burndown.Blame = false
+	result = burndown.Finalize().(BurndownResult)
+	assert.Nil(t, result.Blame)
+}
+
+func TestBurndownBlameSerialize(t *testing.T) {
+	burndown := fixtureBurndownBlame()
+	result := burndown.Finalize().(BurndownResult)
+	buffer := &bytes.Buffer{}
+	burndown.Serialize(result, false, buffer)
+	assert.Contains(t, buffer.String(), `  blame:
+    "one.go":
+      - [0, 20, 0, 5]
+      - [20, 30, 1, 10]
+      - [30, 105, 0, 5]
+    "two.go":
+      - [0, 3, -1, 7]
+`)
+	buffer = &bytes.Buffer{}
+	burndown.Serialize(result, true, buffer)
+	msg := pb.BurndownAnalysisResults{}
+	proto.Unmarshal(buffer.Bytes(), &msg)
+	assert.Len(t, msg.Blame, 2)
+	assert.Equal(t, msg.Blame[0].Name, "one.go")
+	assert.Equal(t, msg.Blame[0].Lines, []int32{0, 20, 30, 105})
+	assert.Equal(t, msg.Blame[0].Authors, []int32{0, 1, 0})
+	assert.Equal(t, msg.Blame[0].Days, []int32{5, 10, 5})
+	assert.Equal(t, msg.Blame[1].Name, "two.go")
+	assert.Equal(t, msg.Blame[1].Authors, []int32{-1})
+	iresult, err := burndown.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, result.Blame, iresult.(BurndownResult).Blame)
+}
+
+func TestBurndownBlameMerge(t *testing.T) {
+	burndown := fixtureBurndownBlame()
+	res1 := burndown.Finalize().(BurndownResult)
+	res2 := BurndownResult{
+		Blame: map[string][]BlameInterval{
+			"one.go":   {{Begin: 0, End: 10, Author: 0, Day: 1}},
+			"three.go": {{Begin: 0, End: 10, Author: 0, Day: 2}},
+		},
+		reversedPeopleDict: []string{"two@srcd"},
+		granularity:        30,
+		sampling:           30,
+	}
+	c1 := core.CommonAnalysisResult{BeginTime: 600566400, EndTime: 600566400 + 20*24*3600}
+	c2 := core.CommonAnalysisResult{
+		BeginTime: 600566400 + 10*24*3600, EndTime: 600566400 + 30*24*3600}
+	merged := burndown.MergeResults(res1, res2, &c1, &c2).(BurndownResult)
+	assert.Len(t, merged.Blame, 3)
+	assert.Equal(t, []BlameInterval{{Begin: 0, End: 10, Author: 1, Day: 11}},
+		merged.Blame["one.go"])
+	assert.Equal(t, []BlameInterval{{Begin: 0, End: 10, Author: 1, Day: 12}},
+		merged.Blame["three.go"])
+	assert.Equal(t, res1.Blame["two.go"], merged.Blame["two.go"])
+}
+
+func TestBurndownInteractionHistory(t *testing.T) {
+	burndown := BurndownAnalysis{Granularity: 30, Sampling: 30, PeopleNumber: 2}
+	burndown.Initialize(test.Repository)
+	burndown.matrix[0] = map[int]int64{authorSelf: 10, 1: -2}
+	burndown.updateHistories(2, []int64{}, map[string][]int64{}, [][]int64{{}, {}})
+	burndown.matrix[0][identity.AuthorMissing] = -3
+	burndown.matrix[1] = map[int]int64{authorSelf: 5}
+	burndown.updateHistories(1, []int64{}, map[string][]int64{}, [][]int64{{}, {}})
+	assert.Equal(t, [][]map[int]int64{
+		{{0: 10, 3: -2}, {}},
+		{{0: 10, 3: -2}, {}},
+		{{0: 10, 1: -3, 3: -2}, {0: 5}},
+	}, burndown.matrixHistory)
+	assert.Equal(t, [][]int64{{10, -3, 0, -2}, {5, 0, 0, 0}},
+		denseInteraction(burndown.matrixHistory[2], 2))
+}
+
+func TestBurndownInteractionHistorySerialize(t *testing.T) {
+	burndown := BurndownAnalysis{}
+	result := BurndownResult{
+		GlobalHistory:   [][]int64{{10, 0}, {10, 5}},
+		PeopleHistories: [][][]int64{{{10, 0}, {10, 0}}, {{0, 0}, {0, 5}}},
+		PeopleMatrix:    [][]int64{{10, 0, 0, -2}, {5, 0, 0, 0}},
+		PeopleMatrixHistory: [][]map[int]int64{
+			{{0: 10}, {}},
+			{{0: 10, 3: -2}, {0: 5}},
+		},
+		reversedPeopleDict: []string{"one@srcd", "two@srcd"},
+		granularity:        30,
+		sampling:           30,
+	}
+	buffer := &bytes.Buffer{}
+	burndown.Serialize(result, false, buffer)
+	assert.Contains(t, buffer.String(), `  people_interaction_history:
+    - |-
+      10  0  0  0
+       0  0  0  0
+    - |-
+      10  0  0 -2
+       5  0  0  0
+`)
+	buffer = &bytes.Buffer{}
+	burndown.Serialize(result, true, buffer)
+	deserialized, err := burndown.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, result.PeopleMatrixHistory, deserialized.(BurndownResult).PeopleMatrixHistory)
+}
+
+func TestBurndownInteractionHistoryMerge(t *testing.T) {
+	res1 := BurndownResult{
+		PeopleMatrixHistory: [][]map[int]int64{
+			{{0: 10}, {}},
+			{{0: 20, 3: -5}, {0: 5}},
+		},
+		reversedPeopleDict: []string{"one@srcd", "two@srcd"},
+		granularity:        10,
+		sampling:           10,
+	}
+	res2 := BurndownResult{
+		PeopleMatrixHistory: [][]map[int]int64{
+			{{0: 7, 1: -1}},
+			{{0: 8, 2: -2}},
+		},
+		reversedPeopleDict: []string{"two@srcd"},
+		granularity:        10,
+		sampling:           10,
+	}
+	c1 := core.CommonAnalysisResult{BeginTime: 600566400, EndTime: 600566400 + 20*24*3600}
+	c2 := core.CommonAnalysisResult{
+		BeginTime: 600566400 + 10*24*3600, EndTime: 600566400 + 30*24*3600}
+	merged := (&BurndownAnalysis{}).MergeResults(res1, res2, &c1, &c2).(BurndownResult)
+	assert.Equal(t, [][]map[int]int64{
+		{{0: 10}, {}},
+		{{0: 20, 3: -5}, {0: 12, 1: -1}},
+		{{0: 20, 3: -5}, {0: 13, 3: -2}},
+	}, merged.PeopleMatrixHistory)
+}
+
+func TestBurndownTeams(t *testing.T) {
+	burndown := BurndownAnalysis{
+		Granularity: 30, Sampling: 30, PeopleNumber: 3, TrackTeams: true,
+		reversedTeamsDict: []string{"one", "two"}}
+	burndown.Initialize(test.Repository)
+	assert.True(t, burndown.TrackTeams)
+	assert.Len(t, burndown.teamsMatrix, 2)
+	burndown.lineTeams[burndown.packPersonWithDay(0, 0)] = 0
+	burndown.lineTeams[burndown.packPersonWithDay(1, 1)] = 1
+	burndown.lineTeams[burndown.packPersonWithDay(2, 2)] = 0
+	file := burndown.newFile(plumbing.ZeroHash, 0, 0, 100,
+		burndown.globalStatus, burndown.people, burndown.matrix)
+	file.Update(burndown.packPersonWithDay(1, 1), 0, 10, 0)
+	file.Update(burndown.packPersonWithDay(1, 1), 10, 0, 20)
+	file.Update(burndown.packPersonWithDay(2, 2), 10, 0, 5)
+	// the team of author 2 on day 3 is unknown
+	file.Update(burndown.packPersonWithDay(2, 3), 10, 0, 5)
+	burndown.day = 3
+	result := burndown.Finalize().(BurndownResult)
+	assert.Equal(t, [][]int64{{100, -5, -5, -20}, {10, 0, 0, 0}}, result.TeamsMatrix)
+	assert.Equal(t, []string{"one", "two"}, result.reversedTeamsDict)
+	assert.Equal(t, []int64{100, 0, 0, -20, -10}, result.PeopleMatrix[0])
+
+	burndown = BurndownAnalysis{TrackTeams: true, reversedTeamsDict: []string{"one"}}
+	burndown.Initialize(test.Repository)
+	assert.False(t, burndown.TrackTeams)
+	assert.Nil(t, burndown.teamsMatrix)
+}
+
+func TestBurndownTeamsSerialize(t *testing.T) {
+	burndown := BurndownAnalysis{}
+	result := BurndownResult{
+		GlobalHistory:     [][]int64{{10, 0}, {10, 5}},
+		TeamsMatrix:       [][]int64{{10, 0, 0, -2}, {5, -1, 0, 0}},
+		reversedTeamsDict: []string{"one", "two"},
+		granularity:       30,
+		sampling:          30,
+	}
+	buffer := &bytes.Buffer{}
+	burndown.Serialize(result, false, buffer)
+	assert.Contains(t, buffer.String(), `  teams_sequence:
+    - "one"
+    - "two"
+  teams_interaction: |-
+    10  0  0 -2
+     5 -1  0  0
+`)
+	buffer = &bytes.Buffer{}
+	burndown.Serialize(result, true, buffer)
+	msg := pb.BurndownAnalysisResults{}
+	proto.Unmarshal(buffer.Bytes(), &msg)
+	assert.Equal(t, []string{"one", "two"}, msg.Teams)
+	assert.Equal(t, int32(4), msg.TeamsInteraction.NumberOfColumns)
+	deserialized, err := burndown.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, result.TeamsMatrix, deserialized.(BurndownResult).TeamsMatrix)
+	assert.Equal(t, result.reversedTeamsDict, deserialized.(BurndownResult).reversedTeamsDict)
+}
+
+func TestBurndownTeamsMerge(t *testing.T) {
+	res1 := BurndownResult{
+		TeamsMatrix:       [][]int64{{10, 0, 0, -2}, {5, -1, 0, 0}},
+		reversedTeamsDict: []string{"one", "two"},
+		granularity:       30,
+		sampling:          30,
+	}
+	res2 := BurndownResult{
+		TeamsMatrix:       [][]int64{{7, 0, -1, -3}, {4, -2, 0, 0}},
+		reversedTeamsDict: []string{"two", "three"},
+		granularity:       30,
+		sampling:          30,
+	}
+	c1 := core.CommonAnalysisResult{BeginTime: 600566400, EndTime: 600566400 + 20*24*3600}
+	c2 := core.CommonAnalysisResult{BeginTime: 600566400, EndTime: 600566400 + 20*24*3600}
+	merged := (&BurndownAnalysis{}).MergeResults(res1, res2, &c1, &c2).(BurndownResult)
+	assert.Equal(t, []string{"one", "two", "three"}, merged.reversedTeamsDict)
+	assert.Equal(t, [][]int64{
+		{10, 0, 0, -2, 0},
+		{12, -1, 0, -1, -3},
+		{4, -2, 0, 0, 0},
+	}, merged.TeamsMatrix)
 }
 
 type panickingCloser struct {
@@ -1083,3 +1453,226 @@ func TestBurndownDeserialize(t *testing.T) {
 	assert.Equal(t, result.granularity, 30)
 	assert.Equal(t, result.sampling, 30)
 }
+
+func TestBurndownCoAuthors(t *testing.T) {
+	burndown := BurndownAnalysis{Granularity: 30, Sampling: 30, PeopleNumber: 3}
+	burndown.Configure(map[string]interface{}{
+		identity.FactIdentityDetectorCoAuthorsPolicy: identity.CoAuthorsSplit,
+	})
+	assert.Equal(t, identity.CoAuthorsSplit, burndown.coAuthorsPolicy)
+	burndown.Initialize(test.Repository)
+	file := burndown.newFile(plumbing.ZeroHash, 0, 0, 10,
+		burndown.globalStatus, burndown.people, burndown.matrix)
+	burndown.day = 1
+	// 5 inserted lines and 3 deleted lines are divided as 3+2 and 2+1
+	updateSplit(file, burndown.packAuthors([]int{1, 2}), 5, 5, 3)
+	assert.Equal(t, 12, file.Len())
+	intervals := burndown.blameFile(file)
+	assert.Equal(t, []BlameInterval{
+		{Begin: 0, End: 5, Author: 0, Day: 0},
+		{Begin: 5, End: 8, Author: 1, Day: 1},
+		{Begin: 8, End: 10, Author: 2, Day: 1},
+		{Begin: 10, End: 12, Author: 0, Day: 0},
+	}, intervals)
+	result := burndown.Finalize().(BurndownResult)
+	assert.Equal(t, []int64{10, 0, 0, -2, -1}, result.PeopleMatrix[0])
+	assert.Equal(t, []int64{3, 0, 0, 0, 0}, result.PeopleMatrix[1])
+	assert.Equal(t, []int64{2, 0, 0, 0, 0}, result.PeopleMatrix[2])
+
+	burndown = BurndownAnalysis{}
+	burndown.Initialize(test.Repository)
+	burndown.day = 7
+	assert.Equal(t, []int{7}, burndown.packAuthors([]int{1, 2}))
+}
+
+func TestBurndownCopies(t *testing.T) {
+	burndown := BurndownAnalysis{Granularity: 30, Sampling: 30, PeopleNumber: 2, TrackFiles: true}
+	burndown.Initialize(test.Repository)
+	burndown.files["source.go"] = burndown.newFile(plumbing.ZeroHash, 0, 0, 10,
+		burndown.globalStatus, burndown.people, burndown.matrix)
+	burndown.day = 5
+	sourceHash := plumbing.NewHash("1111111111111111111111111111111111111111")
+	fileCopy := items.FileCopy{
+		From: object.ChangeEntry{Name: "source.go", TreeEntry: object.TreeEntry{Hash: sourceHash}},
+		To:   object.ChangeEntry{Name: "exact.go", TreeEntry: object.TreeEntry{Hash: sourceHash}},
+	}
+	assert.Nil(t, burndown.handleCopy(fileCopy, []int{1}, nil))
+	assert.Equal(t, []BlameInterval{{Begin: 0, End: 10, Author: 0, Day: 0}},
+		burndown.blameFile(burndown.files["exact.go"]))
+	fileCopy.To = object.ChangeEntry{Name: "similar.go", TreeEntry: object.TreeEntry{
+		Hash: plumbing.NewHash("2222222222222222222222222222222222222222")}}
+	// every rune is a line
+	fileCopy.Diff = items.FileDiffData{OldLinesOfCode: 10, NewLinesOfCode: 10, Diffs: []diffmatchpatch.Diff{
+		{Type: diffmatchpatch.DiffEqual, Text: "aaaa"},
+		{Type: diffmatchpatch.DiffDelete, Text: "b"},
+		{Type: diffmatchpatch.DiffInsert, Text: "c"},
+		{Type: diffmatchpatch.DiffEqual, Text: "ddddd"},
+	}}
+	assert.Nil(t, burndown.handleCopy(fileCopy, []int{1}, nil))
+	assert.Equal(t, []BlameInterval{
+		{Begin: 0, End: 4, Author: 0, Day: 0},
+		{Begin: 4, End: 5, Author: 1, Day: 5},
+		{Begin: 5, End: 10, Author: 0, Day: 0},
+	}, burndown.blameFile(burndown.files["similar.go"]))
+	assert.Equal(t, fileCopy.To.TreeEntry.Hash, burndown.files["similar.go"].Hash)
+	assert.NotNil(t, burndown.handleCopy(fileCopy, []int{1}, nil))
+	assert.Equal(t, map[int]int64{0: 29, 5: 1}, burndown.globalStatus)
+	assert.Equal(t, map[int]int64{0: 9, 5: 1}, burndown.files["similar.go"].Status(1))
+	assert.Equal(t, map[int]int64{0: 29}, burndown.people[0])
+	assert.Equal(t, map[int]int64{5: 1}, burndown.people[1])
+	// the unknown source is the same as the insertion
+	blob := test.FakeBlob("one\ntwo\n")
+	fileCopy.From.Name = "missing.go"
+	fileCopy.To = object.ChangeEntry{Name: "new.go", TreeEntry: object.TreeEntry{Hash: blob.Hash}}
+	assert.Nil(t, burndown.handleCopy(
+		fileCopy, []int{1}, map[plumbing.Hash]*object.Blob{blob.Hash: blob}))
+	assert.Equal(t, []BlameInterval{{Begin: 0, End: 2, Author: 1, Day: 5}},
+		burndown.blameFile(burndown.files["new.go"]))
+}
+
+func TestBurndownCrossCommitRenames(t *testing.T) {
+	burndown := BurndownAnalysis{Granularity: 30, Sampling: 30, PeopleNumber: 2}
+	burndown.Configure(map[string]interface{}{items.ConfigRenameAnalysisCrossCommitWindow: 3})
+	assert.Equal(t, 3, burndown.renamesWindow)
+	burndown.Initialize(test.Repository)
+	blob := test.FakeBlob("one\ntwo\nthree\n")
+	cache := map[plumbing.Hash]*object.Blob{blob.Hash: blob}
+	burndown.files["old.go"] = burndown.newFile(blob.Hash, 0, 0, 3,
+		burndown.globalStatus, burndown.people, burndown.matrix)
+	burndown.day = 5
+	burndown.commits = 1
+	entry := object.ChangeEntry{Name: "old.go", TreeEntry: object.TreeEntry{Hash: blob.Hash}}
+	assert.Nil(t, burndown.handleDeletion(&object.Change{From: entry}, []int{1}, cache))
+	assert.NotContains(t, burndown.files, "old.go")
+	assert.Contains(t, burndown.deletedFiles, "old.go")
+	assert.Equal(t, map[int]int64{0: 0, 5: 0}, burndown.globalStatus)
+	burndown.day = 7
+	fileCopy := items.FileCopy{From: entry, Deleted: true, To: object.ChangeEntry{
+		Name: "new.go", TreeEntry: object.TreeEntry{Hash: blob.Hash}}}
+	assert.Nil(t, burndown.handleCopy(fileCopy, []int{1}, cache))
+	assert.NotContains(t, burndown.deletedFiles, "old.go")
+	assert.Equal(t, []BlameInterval{{Begin: 0, End: 3, Author: 0, Day: 0}},
+		burndown.blameFile(burndown.files["new.go"]))
+	assert.Equal(t, map[int]int64{0: 3, 5: 0}, burndown.globalStatus)
+	// the unknown deletion is the same as the insertion
+	fileCopy.To.Name = "again.go"
+	assert.Nil(t, burndown.handleCopy(fileCopy, []int{1}, cache))
+	assert.Equal(t, []BlameInterval{{Begin: 0, End: 3, Author: 1, Day: 7}},
+		burndown.blameFile(burndown.files["again.go"]))
+	clones := burndown.Fork(2)
+	clone := clones[0].(*BurndownAnalysis)
+	burndown.deletedFiles["old.go"] = deletedFile{}
+	assert.NotContains(t, clone.deletedFiles, "old.go")
+}
+
+func TestBurndownIgnoredCommits(t *testing.T) {
+	hash := plumbing.NewHash("1111111111111111111111111111111111111111")
+	burndown := BurndownAnalysis{Granularity: 30, Sampling: 30, PeopleNumber: 2}
+	burndown.Configure(map[string]interface{}{
+		core.ConfigPipelineIgnoredCommits: map[plumbing.Hash]bool{hash: true}})
+	assert.Equal(t, map[plumbing.Hash]bool{hash: true}, burndown.IgnoredCommits)
+	burndown.Initialize(test.Repository)
+	// every rune is a line
+	replace := []diffmatchpatch.Diff{
+		{Type: diffmatchpatch.DiffEqual, Text: "aaaa"},
+		{Type: diffmatchpatch.DiffDelete, Text: "bb"},
+		{Type: diffmatchpatch.DiffInsert, Text: "ccc"},
+		{Type: diffmatchpatch.DiffEqual, Text: "dddd"},
+	}
+	modify := func(ignored bool, newLines int, diffs []diffmatchpatch.Diff) []BlameInterval {
+		burndown.files["test.go"] = burndown.newFile(plumbing.ZeroHash, 0, 0, 10,
+			burndown.globalStatus, burndown.people, burndown.matrix)
+		burndown.day = 5
+		burndown.ignoredCommit = ignored
+		entry := object.ChangeEntry{Name: "test.go"}
+		assert.Nil(t, burndown.handleModification(
+			&object.Change{From: entry, To: entry}, []int{1}, nil, map[string]items.FileDiffData{
+				"test.go": {OldLinesOfCode: 10, NewLinesOfCode: newLines, Diffs: diffs}}))
+		return burndown.blameFile(burndown.files["test.go"])
+	}
+	assert.Equal(t, []BlameInterval{
+		{Begin: 0, End: 4, Author: 0, Day: 0},
+		{Begin: 4, End: 7, Author: 1, Day: 5},
+		{Begin: 7, End: 11, Author: 0, Day: 0},
+	}, modify(false, 11, replace))
+	// the replaced lines are kept, the extra line takes over the previous line
+	assert.Equal(t, []BlameInterval{
+		{Begin: 0, End: 11, Author: 0, Day: 0},
+	}, modify(true, 11, replace))
+	// the ignored commit only inserts lines
+	insert := []diffmatchpatch.Diff{
+		{Type: diffmatchpatch.DiffInsert, Text: "cc"},
+		{Type: diffmatchpatch.DiffEqual, Text: "aaaaa"},
+		{Type: diffmatchpatch.DiffInsert, Text: "ccc"},
+		{Type: diffmatchpatch.DiffEqual, Text: "aaaaa"},
+		{Type: diffmatchpatch.DiffInsert, Text: "c"},
+	}
+	assert.Equal(t, []BlameInterval{
+		{Begin: 0, End: 2, Author: 1, Day: 5},
+		{Begin: 2, End: 7, Author: 0, Day: 0},
+		{Begin: 7, End: 10, Author: 1, Day: 5},
+		{Begin: 10, End: 15, Author: 0, Day: 0},
+		{Begin: 15, End: 16, Author: 1, Day: 5},
+	}, modify(false, 16, insert))
+	owned, total := burndown.people[1][5], burndown.globalStatus[0]
+	assert.Equal(t, []BlameInterval{
+		{Begin: 0, End: 15, Author: 0, Day: 0},
+		{Begin: 15, End: 16, Author: 0, Day: 0},
+	}, modify(true, 16, insert))
+	// the inserted lines are not attributed to the author of the ignored commit
+	assert.Equal(t, owned, burndown.people[1][5])
+	assert.Equal(t, total+16, burndown.globalStatus[0])
+}
+
+func TestBurndownBlobClasses(t *testing.T) {
+	burndown := BurndownAnalysis{Granularity: 30, Sampling: 30}
+	burndown.Initialize(test.Repository)
+	text, large := test.FakeBlob("one\ntwo\n"), test.FakeBlob("three\nfour\nfive\n")
+	binary := test.FakeBlob("\x00")
+	cache := map[plumbing.Hash]*object.Blob{
+		text.Hash: text, large.Hash: large, binary.Hash: binary}
+	classes := items.BlobClasses{Classes: map[plumbing.Hash]items.BlobClass{
+		large.Hash: items.BlobTooLarge, binary.Hash: items.BlobBinary,
+	}, Policy: items.BlobPolicySkip}
+	burndown.blobClasses = classes
+	for hash, class := range classes.Classes {
+		burndown.nonTextBlobs[hash] = class
+	}
+	entry := func(name string, blob *object.Blob) object.ChangeEntry {
+		return object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{Hash: blob.Hash}}
+	}
+	// the skipped blobs are not tracked
+	assert.Nil(t, burndown.handleInsertion(
+		&object.Change{To: entry("large.txt", large)}, []int{0}, cache))
+	assert.NotContains(t, burndown.files, "large.txt")
+	assert.Nil(t, burndown.handleDeletion(
+		&object.Change{From: entry("large.txt", large)}, []int{0}, cache))
+	assert.Nil(t, burndown.handleInsertion(
+		&object.Change{To: entry("file.txt", text)}, []int{0}, cache))
+	assert.Equal(t, 2, burndown.files["file.txt"].Len())
+	// the file became too large and then it was deleted
+	assert.Nil(t, burndown.handleDeletion(
+		&object.Change{From: entry("file.txt", large)}, []int{0}, cache))
+	assert.NotContains(t, burndown.files, "file.txt")
+	assert.Equal(t, map[int]int64{0: 0}, burndown.globalStatus)
+	result := burndown.Finalize().(BurndownResult)
+	assert.Equal(t, map[string]int{"too_large": 1, "binary": 1}, result.BlobClasses)
+	buffer := &bytes.Buffer{}
+	burndown.Serialize(result, false, buffer)
+	assert.Contains(t, buffer.String(), `  blob_classes:
+    binary: 1
+    too_large: 1
+`)
+	buffer = &bytes.Buffer{}
+	burndown.Serialize(result, true, buffer)
+	iresult, err := burndown.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, result.BlobClasses, iresult.(BurndownResult).BlobClasses)
+	merged := burndown.MergeResults(result, BurndownResult{
+		granularity: 30, sampling: 30, BlobClasses: map[string]int{"lfs_pointer": 2}},
+		&core.CommonAnalysisResult{BeginTime: 600566400, EndTime: 600566400 + 24*3600},
+		&core.CommonAnalysisResult{BeginTime: 600566400, EndTime: 600566400 + 24*3600},
+	).(BurndownResult)
+	assert.Equal(t, map[string]int{"too_large": 1, "binary": 1, "lfs_pointer": 2},
+		merged.BlobClasses)
+}
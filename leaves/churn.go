@@ -0,0 +1,582 @@
+package leaves
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+
+	"github.com/gogo/protobuf/proto"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	"gopkg.in/src-d/hercules.v4/internal"
+	"gopkg.in/src-d/hercules.v4/internal/burndown"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+	"gopkg.in/src-d/hercules.v4/internal/pb"
+	items "gopkg.in/src-d/hercules.v4/internal/plumbing"
+	"gopkg.in/src-d/hercules.v4/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v4/yaml"
+)
+
+// ChurnAnalysis counts the inserted, removed and rewritten lines per day, per developer and
+// per file. Besides, it tracks the age of each line to find out how much code is removed
+// shortly after it was written. It is a LeafPipelineItem.
+type ChurnAnalysis struct {
+	// Merges are recorded only once
+	core.OneShotMergeProcessor
+	// RecentThreshold is the maximum age of a removed line in days to count it as recent churn.
+	RecentThreshold int
+	// PeopleNumber is the number of identified developers.
+	PeopleNumber int
+	// IgnoredCommits are the commits which do not own the lines they replace or insert, those lines
+	// keep or take over the ages of the neighbouring lines and are not counted,
+	// see core.ConfigPipelineIgnoredCommits.
+	IgnoredCommits map[plumbing.Hash]bool
+
+	// files is the mapping <file path> -> *File. The values are packed the same way
+	// as in BurndownAnalysis.
+	files map[string]*burndown.File
+	// global is the mapping <day> -> statistics.
+	global map[int]ChurnStats
+	// people is the same as global for each developer; the last item is the unidentified developers.
+	people []map[int]ChurnStats
+	// perFile is the mapping <file path> -> statistics.
+	perFile map[string]ChurnStats
+	// recent accumulates the recently written lines which were removed by the last File.Update().
+	recent *int64
+	// day is the most recent day index processed.
+	day int
+	// mergeAuthor is the author of the most recent merge commit.
+	mergeAuthor int
+	// blobClasses tells which blobs in the current commit are treated as binary.
+	blobClasses items.BlobClasses
+	// ignoredCommit indicates that the current commit is one of IgnoredCommits.
+	ignoredCommit bool
+	// references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+	// coAuthorsPolicy references IdentityDetector.CoAuthorsPolicy. The per-developer statistics
+	// are either divided between the co-authors or recorded for each of them in full;
+	// the lines belong to the commit author.
+	coAuthorsPolicy string
+}
+
+// ChurnStats is the number of changed lines of each kind.
+type ChurnStats struct {
+	// Additions is the number of inserted lines.
+	Additions int64
+	// Deletions is the number of removed lines.
+	Deletions int64
+	// Rewrites is the number of removed lines which were replaced with new lines in the same hunk.
+	Rewrites int64
+	// Recent is the number of removed lines which were written at most
+	// ChurnAnalysis.RecentThreshold days before.
+	Recent int64
+}
+
+// ChurnResult is returned by ChurnAnalysis.Finalize() and represents the analysis result.
+type ChurnResult struct {
+	// Global maps days to the line statistics.
+	Global map[int]ChurnStats
+	// People maps days to the line statistics for each developer. The indexes match
+	// reversedPeopleDict, the last item belongs to the unidentified developers.
+	People []map[int]ChurnStats
+	// Files maps file paths to the overall line statistics.
+	Files map[string]ChurnStats
+	// RecentThreshold is copied from ChurnAnalysis.
+	RecentThreshold int
+
+	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+}
+
+const (
+	// ConfigChurnRecentThreshold is the name of the option to set ChurnAnalysis.RecentThreshold.
+	ConfigChurnRecentThreshold = "Churn.RecentThreshold"
+	// DefaultChurnRecentThreshold is the default value of ChurnAnalysis.RecentThreshold - three weeks.
+	DefaultChurnRecentThreshold = 21
+)
+
+// Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
+func (churn *ChurnAnalysis) Name() string {
+	return "Churn"
+}
+
+// Provides returns the list of names of entities which are produced by this PipelineItem.
+// Each produced entity will be inserted into `deps` of dependent Consume()-s according
+// to this list. Also used by core.Registry to build the global map of providers.
+func (churn *ChurnAnalysis) Provides() []string {
+	return []string{}
+}
+
+// Requires returns the list of names of entities which are needed by this PipelineItem.
+// Each requested entity will be inserted into `deps` of Consume(). In turn, those
+// entities are Provides() upstream.
+func (churn *ChurnAnalysis) Requires() []string {
+	arr := [...]string{
+		items.DependencyFileDiff, items.DependencyTreeChanges, items.DependencyBlobCache,
+		items.DependencyBlobClasses, items.DependencyDay, identity.DependencyAuthor,
+		identity.DependencyAuthors, items.DependencyTreeChangeKinds}
+	return arr[:]
+}
+
+// ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
+func (churn *ChurnAnalysis) ListConfigurationOptions() []core.ConfigurationOption {
+	options := [...]core.ConfigurationOption{{
+		Name:        ConfigChurnRecentThreshold,
+		Description: "The maximum age of a removed line in days to count it as recent churn.",
+		Flag:        "churn-recent",
+		Type:        core.IntConfigurationOption,
+		Default:     DefaultChurnRecentThreshold},
+	}
+	return options[:]
+}
+
+// Configure sets the properties previously published by ListConfigurationOptions().
+func (churn *ChurnAnalysis) Configure(facts map[string]interface{}) {
+	if val, exists := facts[ConfigChurnRecentThreshold].(int); exists {
+		churn.RecentThreshold = val
+	}
+	if val, exists := facts[identity.FactIdentityDetectorPeopleCount].(int); exists {
+		churn.PeopleNumber = val
+		churn.reversedPeopleDict = facts[identity.FactIdentityDetectorReversedPeopleDict].([]string)
+	}
+	if val, exists := facts[identity.FactIdentityDetectorCoAuthorsPolicy].(string); exists {
+		churn.coAuthorsPolicy = val
+	}
+	if val, exists := facts[core.ConfigPipelineIgnoredCommits].(map[plumbing.Hash]bool); exists {
+		churn.IgnoredCommits = val
+	}
+}
+
+// Flag for the command line switch which enables this analysis.
+func (churn *ChurnAnalysis) Flag() string {
+	return "code-churn"
+}
+
+// Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
+// calls. The repository which is going to be analysed is supplied as an argument.
+func (churn *ChurnAnalysis) Initialize(repository *git.Repository) {
+	if churn.RecentThreshold < 0 {
+		log.Printf("Warning: adjusted the recent churn threshold to %d days\n",
+			DefaultChurnRecentThreshold)
+		churn.RecentThreshold = DefaultChurnRecentThreshold
+	}
+	churn.files = map[string]*burndown.File{}
+	churn.global = map[int]ChurnStats{}
+	churn.people = make([]map[int]ChurnStats, churn.PeopleNumber+1)
+	for i := range churn.people {
+		churn.people[i] = map[int]ChurnStats{}
+	}
+	churn.perFile = map[string]ChurnStats{}
+	churn.recent = new(int64)
+	churn.day = 0
+	churn.mergeAuthor = churn.PeopleNumber
+	churn.OneShotMergeProcessor.Initialize()
+}
+
+// Consume runs this PipelineItem on the next commit data.
+// `deps` contain all the results from upstream PipelineItem-s as requested by Requires().
+// Additionally, DependencyCommit is always present there and represents the analysed *object.Commit.
+// This function returns the mapping with analysis results. The keys must be the same as
+// in Provides(). If there was an error, nil is returned.
+func (churn *ChurnAnalysis) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	commit := deps[core.DependencyCommit].(*object.Commit)
+	credited, _ := identity.CreditedAuthors(deps, churn.coAuthorsPolicy)
+	authors := make([]int, len(credited))
+	for i, author := range credited {
+		if author == identity.AuthorMissing {
+			author = churn.PeopleNumber
+		}
+		authors[i] = author
+	}
+	author := authors[0]
+	day := deps[items.DependencyDay].(int)
+	churn.day = day
+	churn.ignoredCommit = churn.IgnoredCommits[commit.Hash]
+	value := packPersonWithDay(author, day)
+	if len(commit.ParentHashes) > 1 {
+		// the lines will be resolved in Merge()
+		value = packPersonWithDay(author, burndown.TreeMergeMark)
+		churn.mergeAuthor = author
+	}
+	// every branch must apply the merge to keep the line ages consistent,
+	// but the statistics are recorded only once
+	record := churn.ShouldConsumeCommit(deps)
+	cache := deps[items.DependencyBlobCache].(map[plumbing.Hash]*object.Blob)
+	churn.blobClasses, _ = deps[items.DependencyBlobClasses].(items.BlobClasses)
+	treeDiffs := deps[items.DependencyTreeChanges].(object.Changes)
+	fileDiffs := deps[items.DependencyFileDiff].(map[string]items.FileDiffData)
+	kinds, _ := deps[items.DependencyTreeChangeKinds].(items.ChangeKinds)
+	for _, change := range treeDiffs {
+		action, _ := kinds.LineAction(change)
+		var stats ChurnStats
+		var name string
+		var err error
+		switch action {
+		case merkletrie.Insert:
+			name = change.To.Name
+			stats, err = churn.handleInsertion(change, value, cache)
+		case merkletrie.Delete:
+			name = change.From.Name
+			stats = churn.handleDeletion(change, value)
+		case merkletrie.Modify:
+			name = change.To.Name
+			stats, err = churn.handleModification(change, value, cache, fileDiffs)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record && stats != (ChurnStats{}) {
+			churn.global[day] = churn.global[day].add(stats)
+			for i, share := range churn.shareStats(stats, len(authors)) {
+				if share != (ChurnStats{}) {
+					churn.people[authors[i]][day] = churn.people[authors[i]][day].add(share)
+				}
+			}
+			churn.perFile[name] = churn.perFile[name].add(stats)
+		}
+	}
+	return nil, nil
+}
+
+// Fork clones this item. The files are copied by value, the statistics are shared.
+func (churn *ChurnAnalysis) Fork(n int) []core.PipelineItem {
+	result := make([]core.PipelineItem, n)
+	for i := range result {
+		clone := *churn
+		clone.files = map[string]*burndown.File{}
+		for key, file := range churn.files {
+			clone.files[key] = file.Clone(false)
+		}
+		result[i] = &clone
+	}
+	return result
+}
+
+// Merge combines several items together. We apply the same file merging logic as
+// in BurndownAnalysis.
+func (churn *ChurnAnalysis) Merge(branches []core.PipelineItem) {
+	files := make([]map[string]*burndown.File, len(branches))
+	for i, branch := range branches {
+		files[i] = branch.(*ChurnAnalysis).files
+	}
+	mergeFiles(packPersonWithDay(churn.mergeAuthor, churn.day), churn.files, files)
+}
+
+// Finalize returns the result of the analysis. Further Consume() calls are not expected.
+func (churn *ChurnAnalysis) Finalize() interface{} {
+	return ChurnResult{
+		Global:             churn.global,
+		People:             churn.people,
+		Files:              churn.perFile,
+		RecentThreshold:    churn.RecentThreshold,
+		reversedPeopleDict: churn.reversedPeopleDict,
+	}
+}
+
+// Serialize converts the analysis result as returned by Finalize() to text or bytes.
+// The text format is YAML and the bytes format is Protocol Buffers.
+func (churn *ChurnAnalysis) Serialize(result interface{}, binary bool, writer io.Writer) error {
+	churnResult := result.(ChurnResult)
+	if binary {
+		return churn.serializeBinary(&churnResult, writer)
+	}
+	churn.serializeText(&churnResult, writer)
+	return nil
+}
+
+// Deserialize converts the specified protobuf bytes to ChurnResult.
+func (churn *ChurnAnalysis) Deserialize(pbmessage []byte) (interface{}, error) {
+	message := pb.ChurnAnalysisResults{}
+	err := proto.Unmarshal(pbmessage, &message)
+	if err != nil {
+		return nil, err
+	}
+	result := ChurnResult{
+		Global:             convertChurnTimeline(message.Global),
+		People:             make([]map[int]ChurnStats, len(message.PeopleTimelines)),
+		Files:              map[string]ChurnStats{},
+		RecentThreshold:    int(message.RecentThreshold),
+		reversedPeopleDict: message.People,
+	}
+	for i, timeline := range message.PeopleTimelines {
+		result.People[i] = convertChurnTimeline(timeline)
+	}
+	for key, stats := range message.Files {
+		result.Files[key] = convertChurnStats(stats)
+	}
+	return result, nil
+}
+
+// MergeResults combines two ChurnResult-s together.
+func (churn *ChurnAnalysis) MergeResults(r1, r2 interface{}, c1, c2 *core.CommonAnalysisResult) interface{} {
+	cr1 := r1.(ChurnResult)
+	cr2 := r2.(ChurnResult)
+	merged := ChurnResult{
+		Global:          map[int]ChurnStats{},
+		Files:           map[string]ChurnStats{},
+		RecentThreshold: cr1.RecentThreshold,
+	}
+	if cr2.RecentThreshold < merged.RecentThreshold {
+		merged.RecentThreshold = cr2.RecentThreshold
+	}
+	var people map[string][3]int
+	people, merged.reversedPeopleDict = identity.Detector{}.MergeReversedDicts(
+		cr1.reversedPeopleDict, cr2.reversedPeopleDict)
+	merged.People = make([]map[int]ChurnStats, len(merged.reversedPeopleDict)+1)
+	for i := range merged.People {
+		merged.People[i] = map[int]ChurnStats{}
+	}
+	commonMerged := *c1
+	commonMerged.Merge(c2)
+	mergeResult := func(result *ChurnResult, c *core.CommonAnalysisResult) {
+		offset := int((c.BeginTime - commonMerged.BeginTime) / (3600 * 24))
+		for day, stats := range result.Global {
+			merged.Global[day+offset] = merged.Global[day+offset].add(stats)
+		}
+		for i, timeline := range result.People {
+			mi := len(merged.reversedPeopleDict)
+			if i < len(result.reversedPeopleDict) {
+				mi = people[result.reversedPeopleDict[i]][0]
+			}
+			for day, stats := range timeline {
+				merged.People[mi][day+offset] = merged.People[mi][day+offset].add(stats)
+			}
+		}
+		for key, stats := range result.Files {
+			merged.Files[key] = merged.Files[key].add(stats)
+		}
+	}
+	mergeResult(&cr1, c1)
+	mergeResult(&cr2, c2)
+	return merged
+}
+
+func (churn *ChurnAnalysis) serializeText(result *ChurnResult, writer io.Writer) {
+	fmt.Fprintln(writer, "  recent_threshold:", result.RecentThreshold)
+	fmt.Fprintln(writer, "  global:")
+	for _, day := range sortedChurnDays(result.Global) {
+		fmt.Fprintf(writer, "    %d: %s\n", day, result.Global[day].String())
+	}
+	fmt.Fprintln(writer, "  people_sequence:")
+	for _, person := range result.reversedPeopleDict {
+		fmt.Fprintln(writer, "    - "+yaml.SafeString(person))
+	}
+	fmt.Fprintln(writer, "  people:")
+	for _, timeline := range result.People {
+		if len(timeline) == 0 {
+			fmt.Fprintln(writer, "    - {}")
+			continue
+		}
+		fmt.Fprintln(writer, "    -")
+		for _, day := range sortedChurnDays(timeline) {
+			fmt.Fprintf(writer, "      %d: %s\n", day, timeline[day].String())
+		}
+	}
+	fmt.Fprintln(writer, "  files:")
+	keys := make([]string, 0, len(result.Files))
+	for key := range result.Files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(writer, "    %s: %s\n", yaml.SafeString(key), result.Files[key].String())
+	}
+}
+
+func (churn *ChurnAnalysis) serializeBinary(result *ChurnResult, writer io.Writer) error {
+	message := pb.ChurnAnalysisResults{
+		RecentThreshold: int32(result.RecentThreshold),
+		Global:          toChurnTimelineMessage(result.Global),
+		People:          result.reversedPeopleDict,
+		PeopleTimelines: make([]*pb.ChurnTimeline, len(result.People)),
+		Files:           map[string]*pb.ChurnStats{},
+	}
+	for i, timeline := range result.People {
+		message.PeopleTimelines[i] = toChurnTimelineMessage(timeline)
+	}
+	for key, stats := range result.Files {
+		message.Files[key] = stats.toMessage()
+	}
+	serialized, err := proto.Marshal(&message)
+	if err != nil {
+		return err
+	}
+	writer.Write(serialized)
+	return nil
+}
+
+// shareStats returns the statistics credited to each of the `parts` authors of a commit.
+func (churn *ChurnAnalysis) shareStats(stats ChurnStats, parts int) []ChurnStats {
+	shares := make([]ChurnStats, parts)
+	if churn.coAuthorsPolicy != identity.CoAuthorsSplit {
+		for i := range shares {
+			shares[i] = stats
+		}
+		return shares
+	}
+	split := func(total int64, field func(*ChurnStats) *int64) {
+		for i, share := range identity.SplitCredit(int(total), parts) {
+			*field(&shares[i]) = int64(share)
+		}
+	}
+	split(stats.Additions, func(s *ChurnStats) *int64 { return &s.Additions })
+	split(stats.Deletions, func(s *ChurnStats) *int64 { return &s.Deletions })
+	split(stats.Rewrites, func(s *ChurnStats) *int64 { return &s.Rewrites })
+	split(stats.Recent, func(s *ChurnStats) *int64 { return &s.Recent })
+	return shares
+}
+
+func (stats ChurnStats) add(other ChurnStats) ChurnStats {
+	stats.Additions += other.Additions
+	stats.Deletions += other.Deletions
+	stats.Rewrites += other.Rewrites
+	stats.Recent += other.Recent
+	return stats
+}
+
+// String formats ChurnStats as a YAML list [additions, deletions, rewrites, recent].
+func (stats ChurnStats) String() string {
+	return fmt.Sprintf("[%d, %d, %d, %d]",
+		stats.Additions, stats.Deletions, stats.Rewrites, stats.Recent)
+}
+
+func (stats ChurnStats) toMessage() *pb.ChurnStats {
+	return &pb.ChurnStats{
+		Additions: int32(stats.Additions),
+		Deletions: int32(stats.Deletions),
+		Rewrites:  int32(stats.Rewrites),
+		Recent:    int32(stats.Recent),
+	}
+}
+
+func convertChurnStats(message *pb.ChurnStats) ChurnStats {
+	return ChurnStats{
+		Additions: int64(message.Additions),
+		Deletions: int64(message.Deletions),
+		Rewrites:  int64(message.Rewrites),
+		Recent:    int64(message.Recent),
+	}
+}
+
+func sortedChurnDays(timeline map[int]ChurnStats) []int {
+	days := make([]int, 0, len(timeline))
+	for day := range timeline {
+		days = append(days, day)
+	}
+	sort.Ints(days)
+	return days
+}
+
+func toChurnTimelineMessage(timeline map[int]ChurnStats) *pb.ChurnTimeline {
+	days := sortedChurnDays(timeline)
+	message := &pb.ChurnTimeline{
+		Days:  make([]int32, len(days)),
+		Stats: make([]*pb.ChurnStats, len(days)),
+	}
+	for i, day := range days {
+		message.Days[i] = int32(day)
+		message.Stats[i] = timeline[day].toMessage()
+	}
+	return message
+}
+
+func convertChurnTimeline(message *pb.ChurnTimeline) map[int]ChurnStats {
+	timeline := map[int]ChurnStats{}
+	if message == nil {
+		return timeline
+	}
+	for i, day := range message.Days {
+		timeline[int(day)] = convertChurnStats(message.Stats[i])
+	}
+	return timeline
+}
+
+// updateRecent is bound to every File and counts the removed lines which are not older than
+// RecentThreshold days.
+func (churn *ChurnAnalysis) updateRecent(
+	recent interface{}, currentValue int, previousValue int, delta int) {
+
+	if delta >= 0 {
+		return
+	}
+	currentDay := currentValue & burndown.TreeMergeMark
+	previousDay := previousValue & burndown.TreeMergeMark
+	if previousDay == burndown.TreeMergeMark {
+		return
+	}
+	if currentDay-previousDay <= churn.RecentThreshold {
+		*recent.(*int64) -= int64(delta)
+	}
+}
+
+// update applies File.Update() and returns the number of the removed recent lines.
+func (churn *ChurnAnalysis) update(file *burndown.File, value, pos, insLength, delLength int) int64 {
+	*churn.recent = 0
+	file.Update(value, pos, insLength, delLength)
+	return *churn.recent
+}
+
+func (churn *ChurnAnalysis) handleInsertion(
+	change *object.Change, value int, cache map[plumbing.Hash]*object.Blob) (ChurnStats, error) {
+	blob := cache[change.To.TreeEntry.Hash]
+	lines, err := churn.blobClasses.CountLines(blob)
+	if err != nil {
+		if err.Error() == "binary" {
+			return ChurnStats{}, nil
+		}
+		return ChurnStats{}, err
+	}
+	name := change.To.Name
+	if _, exists := churn.files[name]; exists {
+		return ChurnStats{}, fmt.Errorf("file %s already exists", name)
+	}
+	churn.files[name] = burndown.NewFile(
+		blob.Hash, value, lines, burndown.NewStatus(churn.recent, churn.updateRecent))
+	return ChurnStats{Additions: int64(lines)}, nil
+}
+
+func (churn *ChurnAnalysis) handleDeletion(change *object.Change, value int) ChurnStats {
+	name := change.From.Name
+	file, exists := churn.files[name]
+	if !exists {
+		// binary files are not tracked
+		return ChurnStats{}
+	}
+	lines := file.Len()
+	recent := churn.update(file, value, 0, 0, lines)
+	delete(churn.files, name)
+	return ChurnStats{Deletions: int64(lines), Recent: recent}
+}
+
+func (churn *ChurnAnalysis) handleModification(
+	change *object.Change, value int, cache map[plumbing.Hash]*object.Blob,
+	diffs map[string]items.FileDiffData) (ChurnStats, error) {
+
+	file, exists := churn.files[change.From.Name]
+	if !exists {
+		return churn.handleInsertion(change, value, cache)
+	}
+	file.Hash = change.To.TreeEntry.Hash
+	if change.To.Name != change.From.Name {
+		churn.files[change.To.Name] = file
+		delete(churn.files, change.From.Name)
+	}
+
+	stats := ChurnStats{}
+	// the lines of the ignored commits keep or take over the ages and are not counted
+	err := applyFileDiff(change.To.Name, file, diffs[change.To.Name], churn.ignoredCommit,
+		func(pos, insLength, delLength int) {
+			stats.Recent += churn.update(file, value, pos, insLength, delLength)
+			stats.Additions += int64(insLength)
+			stats.Deletions += int64(delLength)
+			stats.Rewrites += int64(internal.Min(insLength, delLength))
+		})
+	return stats, err
+}
+
+func init() {
+	core.Registry.Register(&ChurnAnalysis{})
+}
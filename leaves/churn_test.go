@@ -0,0 +1,274 @@
+package leaves
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+	"gopkg.in/src-d/hercules.v4/internal/pb"
+	items "gopkg.in/src-d/hercules.v4/internal/plumbing"
+	"gopkg.in/src-d/hercules.v4/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v4/internal/test"
+)
+
+func fixtureChurn() *ChurnAnalysis {
+	churn := ChurnAnalysis{RecentThreshold: 10, PeopleNumber: 2}
+	churn.Initialize(test.Repository)
+	churn.reversedPeopleDict = []string{"one@srcd", "two@srcd"}
+	return &churn
+}
+
+func TestChurnMeta(t *testing.T) {
+	churn := ChurnAnalysis{}
+	assert.Equal(t, churn.Name(), "Churn")
+	assert.Len(t, churn.Provides(), 0)
+	required := [...]string{
+		items.DependencyFileDiff, items.DependencyTreeChanges, items.DependencyBlobCache,
+		items.DependencyBlobClasses, items.DependencyDay, identity.DependencyAuthor,
+		items.DependencyTreeChangeKinds}
+	for _, name := range required {
+		assert.Contains(t, churn.Requires(), name)
+	}
+	opts := churn.ListConfigurationOptions()
+	assert.Len(t, opts, 1)
+	assert.Equal(t, opts[0].Name, ConfigChurnRecentThreshold)
+	assert.Equal(t, churn.Flag(), "code-churn")
+}
+
+func TestChurnConfigure(t *testing.T) {
+	churn := ChurnAnalysis{}
+	facts := map[string]interface{}{}
+	facts[ConfigChurnRecentThreshold] = 7
+	facts[identity.FactIdentityDetectorPeopleCount] = 3
+	facts[identity.FactIdentityDetectorReversedPeopleDict] = churn.Requires()
+	churn.Configure(facts)
+	assert.Equal(t, churn.RecentThreshold, 7)
+	assert.Equal(t, churn.PeopleNumber, 3)
+	assert.Equal(t, churn.reversedPeopleDict, churn.Requires())
+	churn.Configure(map[string]interface{}{})
+	assert.Equal(t, churn.RecentThreshold, 7)
+	assert.Equal(t, churn.PeopleNumber, 3)
+}
+
+func TestChurnRegistration(t *testing.T) {
+	summoned := core.Registry.Summon((&ChurnAnalysis{}).Name())
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, summoned[0].Name(), "Churn")
+	leaves := core.Registry.GetLeaves()
+	matched := false
+	for _, tp := range leaves {
+		if tp.Flag() == (&ChurnAnalysis{}).Flag() {
+			matched = true
+			break
+		}
+	}
+	assert.True(t, matched)
+}
+
+func TestChurnInitialize(t *testing.T) {
+	churn := ChurnAnalysis{RecentThreshold: -1, PeopleNumber: 2}
+	churn.Initialize(test.Repository)
+	assert.Equal(t, churn.RecentThreshold, DefaultChurnRecentThreshold)
+	assert.Len(t, churn.people, 3)
+	assert.NotNil(t, churn.files)
+	assert.NotNil(t, churn.global)
+	assert.NotNil(t, churn.perFile)
+	assert.NotNil(t, churn.recent)
+}
+
+func fixtureChurnResult(t *testing.T) (*ChurnAnalysis, ChurnResult) {
+	churn := fixtureChurn()
+	consumeFakeCommit(t, churn, 0, 0, 1, map[string][2]string{
+		"x.go": {"", "1\n2\n3\n4\n"},
+		"b.go": {"", "1\n2\n"},
+	})
+	consumeFakeCommit(t, churn, 1, 5, 1, map[string][2]string{
+		"x.go": {"1\n2\n3\n4\n", "1\n2\nX\nY\n4\n"},
+		"b.go": {"1\n2\n", ""},
+	})
+	consumeFakeCommit(t, churn, 0, 12, 1, map[string][2]string{
+		"x.go": {"1\n2\nX\nY\n4\n", "1\nY\n4\n"},
+	})
+	return churn, churn.Finalize().(ChurnResult)
+}
+
+func TestChurnConsumeFinalize(t *testing.T) {
+	_, result := fixtureChurnResult(t)
+	assert.Equal(t, 10, result.RecentThreshold)
+	assert.Equal(t, map[int]ChurnStats{
+		0:  {Additions: 6},
+		5:  {Additions: 2, Deletions: 3, Rewrites: 1, Recent: 3},
+		12: {Deletions: 2, Recent: 1},
+	}, result.Global)
+	assert.Equal(t, []map[int]ChurnStats{
+		{0: {Additions: 6}, 12: {Deletions: 2, Recent: 1}},
+		{5: {Additions: 2, Deletions: 3, Rewrites: 1, Recent: 3}},
+		{},
+	}, result.People)
+	assert.Equal(t, map[string]ChurnStats{
+		"x.go": {Additions: 6, Deletions: 3, Rewrites: 1, Recent: 2},
+		"b.go": {Additions: 2, Deletions: 2, Recent: 2},
+	}, result.Files)
+}
+
+func TestChurnForkMerge(t *testing.T) {
+	churn := fixtureChurn()
+	consumeFakeCommit(t, churn, 0, 0, 1, map[string][2]string{
+		"b.go": {"", "1\n2\n"},
+	})
+	clones := churn.Fork(1)
+	assert.Len(t, clones, 1)
+	clone := clones[0].(*ChurnAnalysis)
+	consumeFakeCommit(t, clone, 1, 5, 1, map[string][2]string{
+		"b.go": {"1\n2\n", "1\n2\n3\n"},
+	})
+	assert.Equal(t, 2, churn.files["b.go"].Len())
+	assert.Equal(t, 3, clone.files["b.go"].Len())
+	// the merge commit as seen from both parents, it must be recorded only once
+	consumeFakeCommit(t, churn, 0, 6, 2, map[string][2]string{
+		"b.go": {"1\n2\n", "1\n2\n3\n"},
+		"c.go": {"", "c\n"},
+	})
+	consumeFakeCommit(t, clone, 0, 6, 2, map[string][2]string{
+		"c.go": {"", "c\n"},
+	})
+	churn.files["b.go"].Hash = plumbing.ZeroHash
+	churn.Merge([]core.PipelineItem{clone})
+	// the line was written by the second developer on day 5
+	consumeFakeCommit(t, churn, 0, 10, 1, map[string][2]string{
+		"b.go": {"1\n2\n3\n", "1\n2\n"},
+	})
+	result := churn.Finalize().(ChurnResult)
+	assert.Equal(t, map[int]ChurnStats{
+		0:  {Additions: 2},
+		5:  {Additions: 1},
+		6:  {Additions: 2},
+		10: {Deletions: 1, Recent: 1},
+	}, result.Global)
+	assert.Equal(t, map[string]ChurnStats{
+		"b.go": {Additions: 4, Deletions: 1, Recent: 1},
+		"c.go": {Additions: 1},
+	}, result.Files)
+}
+
+func TestChurnSerialize(t *testing.T) {
+	churn, result := fixtureChurnResult(t)
+	buffer := &bytes.Buffer{}
+	churn.Serialize(result, false, buffer)
+	assert.Equal(t, `  recent_threshold: 10
+  global:
+    0: [6, 0, 0, 0]
+    5: [2, 3, 1, 3]
+    12: [0, 2, 0, 1]
+  people_sequence:
+    - "one@srcd"
+    - "two@srcd"
+  people:
+    -
+      0: [6, 0, 0, 0]
+      12: [0, 2, 0, 1]
+    -
+      5: [2, 3, 1, 3]
+    - {}
+  files:
+    "b.go": [2, 2, 0, 2]
+    "x.go": [6, 3, 1, 2]
+`, buffer.String())
+	buffer = &bytes.Buffer{}
+	churn.Serialize(result, true, buffer)
+	msg := pb.ChurnAnalysisResults{}
+	proto.Unmarshal(buffer.Bytes(), &msg)
+	assert.Equal(t, int32(10), msg.RecentThreshold)
+	assert.Equal(t, []int32{0, 5, 12}, msg.Global.Days)
+	assert.Equal(t, &pb.ChurnStats{Additions: 2, Deletions: 3, Rewrites: 1, Recent: 3},
+		msg.Global.Stats[1])
+	assert.Equal(t, []string{"one@srcd", "two@srcd"}, msg.People)
+	assert.Len(t, msg.PeopleTimelines, 3)
+	assert.Equal(t, []int32{5}, msg.PeopleTimelines[1].Days)
+	assert.Len(t, msg.PeopleTimelines[2].Days, 0)
+	assert.Equal(t, &pb.ChurnStats{Additions: 2, Deletions: 2, Recent: 2}, msg.Files["b.go"])
+	deserialized, err := churn.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, result, deserialized)
+}
+
+func TestChurnMergeResults(t *testing.T) {
+	churn, res1 := fixtureChurnResult(t)
+	res2 := ChurnResult{
+		Global: map[int]ChurnStats{0: {Additions: 1}, 2: {Deletions: 1}},
+		People: []map[int]ChurnStats{
+			{0: {Additions: 1}},
+			{2: {Deletions: 1}},
+		},
+		Files:              map[string]ChurnStats{"x.go": {Additions: 1, Deletions: 1}},
+		RecentThreshold:    20,
+		reversedPeopleDict: []string{"two@srcd"},
+	}
+	c1 := core.CommonAnalysisResult{BeginTime: 600566400, EndTime: 600566400 + 20*24*3600}
+	c2 := core.CommonAnalysisResult{
+		BeginTime: 600566400 + 10*24*3600, EndTime: 600566400 + 30*24*3600}
+	merged := churn.MergeResults(res1, res2, &c1, &c2).(ChurnResult)
+	assert.Equal(t, 10, merged.RecentThreshold)
+	assert.Equal(t, []string{"one@srcd", "two@srcd"}, merged.reversedPeopleDict)
+	assert.Equal(t, map[int]ChurnStats{
+		0:  {Additions: 6},
+		5:  {Additions: 2, Deletions: 3, Rewrites: 1, Recent: 3},
+		10: {Additions: 1},
+		12: {Deletions: 3, Recent: 1},
+	}, merged.Global)
+	assert.Equal(t, []map[int]ChurnStats{
+		{0: {Additions: 6}, 12: {Deletions: 2, Recent: 1}},
+		{5: {Additions: 2, Deletions: 3, Rewrites: 1, Recent: 3}, 10: {Additions: 1}},
+		{12: {Deletions: 1}},
+	}, merged.People)
+	assert.Equal(t, ChurnStats{Additions: 7, Deletions: 4, Rewrites: 1, Recent: 2},
+		merged.Files["x.go"])
+}
+
+func TestChurnCoAuthors(t *testing.T) {
+	churn := ChurnAnalysis{}
+	churn.Configure(map[string]interface{}{
+		identity.FactIdentityDetectorCoAuthorsPolicy: identity.CoAuthorsSplit,
+	})
+	stats := ChurnStats{Additions: 5, Deletions: 2, Rewrites: 1, Recent: 0}
+	assert.Equal(t, []ChurnStats{
+		{Additions: 3, Deletions: 1, Rewrites: 1},
+		{Additions: 2, Deletions: 1},
+	}, churn.shareStats(stats, 2))
+	assert.Equal(t, []ChurnStats{stats}, churn.shareStats(stats, 1))
+	churn.coAuthorsPolicy = identity.CoAuthorsDuplicate
+	assert.Equal(t, []ChurnStats{stats, stats}, churn.shareStats(stats, 2))
+}
+
+func TestChurnIgnoredCommits(t *testing.T) {
+	churn := fixtureChurn()
+	churn.Configure(map[string]interface{}{
+		core.ConfigPipelineIgnoredCommits: map[plumbing.Hash]bool{plumbing.ZeroHash: false}})
+	assert.Equal(t, map[plumbing.Hash]bool{plumbing.ZeroHash: false}, churn.IgnoredCommits)
+	consumeFakeCommit(t, churn, 0, 0, 1, map[string][2]string{
+		"x.go": {"", "1\n2\n3\n4\n"},
+	})
+	// the fake commits have the zero hash
+	churn.IgnoredCommits[plumbing.ZeroHash] = true
+	consumeFakeCommit(t, churn, 1, 5, 1, map[string][2]string{
+		"x.go": {"1\n2\n3\n4\n", "1\n2\nX\nY\n4\n"},
+	})
+	// the ignored commit only inserts lines
+	consumeFakeCommit(t, churn, 1, 6, 1, map[string][2]string{
+		"x.go": {"1\n2\nX\nY\n4\n", "0\n1\n2\nX\nY\n4\nZ\n"},
+	})
+	churn.IgnoredCommits[plumbing.ZeroHash] = false
+	consumeFakeCommit(t, churn, 0, 12, 1, map[string][2]string{
+		"x.go": {"0\n1\n2\nX\nY\n4\nZ\n", "1\n2\n4\n"},
+	})
+	result := churn.Finalize().(ChurnResult)
+	// "X" replaced "3" and kept its age, "Y", "0" and "Z" took over the ages of the neighbours
+	assert.Equal(t, ChurnStats{}, result.Global[5])
+	assert.Equal(t, ChurnStats{}, result.Global[6])
+	assert.Equal(t, ChurnStats{}, result.People[1][5])
+	// none of the deleted lines are recent
+	assert.Equal(t, ChurnStats{Deletions: 4}, result.Global[12])
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gogo/protobuf/proto"
 	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
 	"gopkg.in/src-d/hercules.v4/internal/core"
@@ -24,15 +25,24 @@ type CouplesAnalysis struct {
 	core.OneShotMergeProcessor
 	// PeopleNumber is the number of developers for which to build the matrix. 0 disables this analysis.
 	PeopleNumber int
+	// IgnoredCommits are the commits which are not counted as co-changes,
+	// see core.ConfigPipelineIgnoredCommits.
+	IgnoredCommits map[plumbing.Hash]bool
 
 	// people store how many times every developer committed to every file.
 	people []map[string]int
 	// peopleCommits is the number of commits each author made.
 	peopleCommits []int
+	// teams store how many times every team committed to every file.
+	teams []map[string]int
 	// files store every file occurred in the same commit with every other file.
 	files map[string]map[string]int
 	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
 	reversedPeopleDict []string
+	// reversedTeamsDict references IdentityDetector.ReversedTeamsDict
+	reversedTeamsDict []string
+	// coAuthorsPolicy references IdentityDetector.CoAuthorsPolicy
+	coAuthorsPolicy string
 }
 
 // CouplesResult is returned by CouplesAnalysis.Finalize() and carries couples matrices from
@@ -42,9 +52,14 @@ type CouplesResult struct {
 	PeopleFiles  [][]int
 	FilesMatrix  []map[int]int64
 	Files        []string
+	// TeamsMatrix is the same as PeopleMatrix but for the teams, the last row and column
+	// belong to the developers without a team. It is nil if there were no teams.
+	TeamsMatrix []map[int]int64
 
 	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
 	reversedPeopleDict []string
+	// reversedTeamsDict references IdentityDetector.ReversedTeamsDict
+	reversedTeamsDict []string
 }
 
 // Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
@@ -63,7 +78,10 @@ func (couples *CouplesAnalysis) Provides() []string {
 // Each requested entity will be inserted into `deps` of Consume(). In turn, those
 // entities are Provides() upstream.
 func (couples *CouplesAnalysis) Requires() []string {
-	arr := [...]string{identity.DependencyAuthor, items.DependencyTreeChanges}
+	arr := [...]string{
+		identity.DependencyAuthor, identity.DependencyTeam, identity.DependencyAuthors,
+		identity.DependencyTeams, items.DependencyTreeChanges, items.DependencyTreeCopies,
+		items.DependencyTreeChangeKinds}
 	return arr[:]
 }
 
@@ -78,6 +96,15 @@ func (couples *CouplesAnalysis) Configure(facts map[string]interface{}) {
 		couples.PeopleNumber = val
 		couples.reversedPeopleDict = facts[identity.FactIdentityDetectorReversedPeopleDict].([]string)
 	}
+	if val, exists := facts[identity.FactIdentityDetectorReversedTeamsDict].([]string); exists {
+		couples.reversedTeamsDict = val
+	}
+	if val, exists := facts[identity.FactIdentityDetectorCoAuthorsPolicy].(string); exists {
+		couples.coAuthorsPolicy = val
+	}
+	if val, exists := facts[core.ConfigPipelineIgnoredCommits].(map[plumbing.Hash]bool); exists {
+		couples.IgnoredCommits = val
+	}
 }
 
 // Flag for the command line switch which enables this analysis.
@@ -93,6 +120,13 @@ func (couples *CouplesAnalysis) Initialize(repository *git.Repository) {
 		couples.people[i] = map[string]int{}
 	}
 	couples.peopleCommits = make([]int, couples.PeopleNumber+1)
+	couples.teams = nil
+	if couples.reversedTeamsDict != nil {
+		couples.teams = make([]map[string]int, len(couples.reversedTeamsDict)+1)
+		for i := range couples.teams {
+			couples.teams[i] = map[string]int{}
+		}
+	}
 	couples.files = map[string]map[string]int{}
 	couples.OneShotMergeProcessor.Initialize()
 }
@@ -106,11 +140,44 @@ func (couples *CouplesAnalysis) Consume(deps map[string]interface{}) (map[string
 	if !couples.ShouldConsumeCommit(deps) {
 		return nil, nil
 	}
-	author := deps[identity.DependencyAuthor].(int)
-	if author == identity.AuthorMissing {
-		author = couples.PeopleNumber
+	// the ignored commits only rename and delete the files
+	ignored := couples.IgnoredCommits[deps[core.DependencyCommit].(*object.Commit).Hash]
+	// the co-occurrence counts cannot be divided, so every co-author is counted in full
+	credited, creditedTeams := identity.CreditedAuthors(deps, couples.coAuthorsPolicy)
+	authors := make([]int, len(credited))
+	for i, author := range credited {
+		if author == identity.AuthorMissing {
+			author = couples.PeopleNumber
+		}
+		authors[i] = author
+		if !ignored {
+			couples.peopleCommits[author]++
+		}
+	}
+	var teams []int
+	if couples.teams != nil {
+		seen := map[int]bool{}
+		for _, team := range creditedTeams {
+			if team == identity.TeamMissing {
+				team = len(couples.reversedTeamsDict)
+			}
+			if !seen[team] {
+				seen[team] = true
+				teams = append(teams, team)
+			}
+		}
+	}
+	touch := func(name string) {
+		if ignored {
+			return
+		}
+		for _, author := range authors {
+			couples.people[author][name]++
+		}
+		for _, team := range teams {
+			couples.teams[team][name]++
+		}
 	}
-	couples.peopleCommits[author]++
 	treeDiff := deps[items.DependencyTreeChanges].(object.Changes)
 	context := make([]string, 0)
 	deleteFile := func(name string) {
@@ -120,20 +187,25 @@ func (couples *CouplesAnalysis) Consume(deps map[string]interface{}) (map[string
 			delete(otherFiles, name)
 		}
 	}
+	kinds, _ := deps[items.DependencyTreeChangeKinds].(items.ChangeKinds)
 	for _, change := range treeDiff {
 		action, err := change.Action()
 		if err != nil {
 			return nil, err
 		}
+		if kinds.Kind(change) == items.ChangeKindMode {
+			// flipping the executable bit does not couple the files
+			continue
+		}
 		toName := change.To.Name
 		fromName := change.From.Name
 		switch action {
 		case merkletrie.Insert:
 			context = append(context, toName)
-			couples.people[author][toName]++
+			touch(toName)
 		case merkletrie.Delete:
 			deleteFile(fromName)
-			couples.people[author][fromName]++
+			touch(fromName)
 		case merkletrie.Modify:
 			if fromName != toName {
 				// renamed
@@ -145,27 +217,45 @@ func (couples *CouplesAnalysis) Consume(deps map[string]interface{}) (map[string
 					}
 				}
 				deleteFile(fromName)
-				for _, authorFiles := range couples.people {
-					val, exists := authorFiles[fromName]
-					if exists {
-						authorFiles[toName] = val
-						delete(authorFiles, fromName)
+				for _, touches := range [...][]map[string]int{couples.people, couples.teams} {
+					for _, authorFiles := range touches {
+						val, exists := authorFiles[fromName]
+						if exists {
+							authorFiles[toName] = val
+							delete(authorFiles, fromName)
+						}
 					}
 				}
 			}
 			context = append(context, toName)
-			couples.people[author][toName]++
+			touch(toName)
 		}
 	}
+	if ignored {
+		return nil, nil
+	}
+	link := func(file, otherFile string) {
+		lane, exists := couples.files[file]
+		if !exists {
+			lane = map[string]int{}
+			couples.files[file] = lane
+		}
+		lane[otherFile]++
+	}
 	for _, file := range context {
 		for _, otherFile := range context {
-			lane, exists := couples.files[file]
-			if !exists {
-				lane = map[string]int{}
-				couples.files[file] = lane
-			}
-			lane[otherFile]++
+			link(file, otherFile)
+		}
+	}
+	// the copies are coupled with their unchanged sources, the renames across commits
+	// have no source anymore
+	copies, _ := deps[items.DependencyTreeCopies].([]items.FileCopy)
+	for _, fileCopy := range copies {
+		if fileCopy.Deleted {
+			continue
 		}
+		link(fileCopy.From.Name, fileCopy.To.Name)
+		link(fileCopy.To.Name, fileCopy.From.Name)
 	}
 	return nil, nil
 }
@@ -184,28 +274,21 @@ func (couples *CouplesAnalysis) Finalize() interface{} {
 		filesIndex[file] = i
 	}
 
-	peopleMatrix := make([]map[int]int64, couples.PeopleNumber+1)
+	peopleMatrix := coupleDevelopers(couples.people)
 	peopleFiles := make([][]int, couples.PeopleNumber+1)
-	for i := range peopleMatrix {
-		peopleMatrix[i] = map[int]int64{}
-		for file, commits := range couples.people[i] {
+	for i := range peopleFiles {
+		for file := range couples.people[i] {
 			fi, exists := filesIndex[file]
 			if exists {
 				peopleFiles[i] = append(peopleFiles[i], fi)
 			}
-			for j, otherFiles := range couples.people {
-				otherCommits := otherFiles[file]
-				delta := otherCommits
-				if otherCommits > commits {
-					delta = commits
-				}
-				if delta > 0 {
-					peopleMatrix[i][j] += int64(delta)
-				}
-			}
 		}
 		sort.Ints(peopleFiles[i])
 	}
+	var teamsMatrix []map[int]int64
+	if couples.teams != nil {
+		teamsMatrix = coupleDevelopers(couples.teams)
+	}
 
 	filesMatrix := make([]map[int]int64, len(filesIndex))
 	for i := range filesMatrix {
@@ -219,10 +302,34 @@ func (couples *CouplesAnalysis) Finalize() interface{} {
 		PeopleFiles:        peopleFiles,
 		Files:              filesSequence,
 		FilesMatrix:        filesMatrix,
+		TeamsMatrix:        teamsMatrix,
 		reversedPeopleDict: couples.reversedPeopleDict,
+		reversedTeamsDict:  couples.reversedTeamsDict,
 	}
 }
 
+// coupleDevelopers calculates the matrix of the common files of every pair of developers
+// or teams. `touches` is the number of commits of every developer to every file.
+func coupleDevelopers(touches []map[string]int) []map[int]int64 {
+	matrix := make([]map[int]int64, len(touches))
+	for i := range matrix {
+		matrix[i] = map[int]int64{}
+		for file, commits := range touches[i] {
+			for j, otherFiles := range touches {
+				otherCommits := otherFiles[file]
+				delta := otherCommits
+				if otherCommits > commits {
+					delta = commits
+				}
+				if delta > 0 {
+					matrix[i][j] += int64(delta)
+				}
+			}
+		}
+	}
+	return matrix
+}
+
 // Fork clones this pipeline item.
 func (couples *CouplesAnalysis) Fork(n int) []core.PipelineItem {
 	return core.ForkSamePipelineItem(couples, n)
@@ -272,6 +379,11 @@ func (couples *CouplesAnalysis) Deserialize(pbmessage []byte) (interface{}, erro
 	}
 	convertCSR(result.FilesMatrix, message.FileCouples.Matrix)
 	convertCSR(result.PeopleMatrix, message.PeopleCouples.Matrix)
+	if message.TeamCouples != nil {
+		result.reversedTeamsDict = message.TeamCouples.Index
+		result.TeamsMatrix = make([]map[int]int64, message.TeamCouples.Matrix.NumberOfRows)
+		convertCSR(result.TeamsMatrix, message.TeamCouples.Matrix)
+	}
 	return result, nil
 }
 
@@ -349,6 +461,30 @@ func (couples *CouplesAnalysis) MergeResults(r1, r2 interface{}, c1, c2 *core.Co
 	}
 	addFiles(cr1.FilesMatrix, cr1.Files)
 	addFiles(cr2.FilesMatrix, cr2.Files)
+	if len(cr1.TeamsMatrix) > 0 || len(cr2.TeamsMatrix) > 0 {
+		var teams map[string][3]int
+		teams, merged.reversedTeamsDict = identity.Detector{}.MergeReversedDicts(
+			cr1.reversedTeamsDict, cr2.reversedTeamsDict)
+		merged.TeamsMatrix = make([]map[int]int64, len(merged.reversedTeamsDict)+1)
+		for i := range merged.TeamsMatrix {
+			merged.TeamsMatrix[i] = map[int]int64{}
+		}
+		// the last index belongs to the developers without a team
+		remap := func(index int, reversedTeamsDict []string) int {
+			if index < len(reversedTeamsDict) {
+				return teams[reversedTeamsDict[index]][0]
+			}
+			return len(merged.reversedTeamsDict)
+		}
+		for _, cr := range [...]*CouplesResult{&cr1, &cr2} {
+			for ti, tc := range cr.TeamsMatrix {
+				m := merged.TeamsMatrix[remap(ti, cr.reversedTeamsDict)]
+				for other, val := range tc {
+					m[remap(other, cr.reversedTeamsDict)] += val
+				}
+			}
+		}
+	}
 	return merged
 }
 
@@ -359,22 +495,7 @@ func (couples *CouplesAnalysis) serializeText(result *CouplesResult, writer io.W
 		fmt.Fprintf(writer, "      - %s\n", yaml.SafeString(file))
 	}
 
-	fmt.Fprintln(writer, "    matrix:")
-	for _, files := range result.FilesMatrix {
-		fmt.Fprint(writer, "      - {")
-		indices := []int{}
-		for file := range files {
-			indices = append(indices, file)
-		}
-		sort.Ints(indices)
-		for i, file := range indices {
-			fmt.Fprintf(writer, "%d: %d", file, files[file])
-			if i < len(indices)-1 {
-				fmt.Fprint(writer, ", ")
-			}
-		}
-		fmt.Fprintln(writer, "}")
-	}
+	printCouplesMatrix(writer, result.FilesMatrix)
 
 	fmt.Fprintln(writer, "  people_coocc:")
 	fmt.Fprintln(writer, "    index:")
@@ -382,32 +503,46 @@ func (couples *CouplesAnalysis) serializeText(result *CouplesResult, writer io.W
 		fmt.Fprintf(writer, "      - %s\n", yaml.SafeString(person))
 	}
 
+	printCouplesMatrix(writer, result.PeopleMatrix)
+
+	fmt.Fprintln(writer, "    author_files:") // sorted by number of files each author changed
+	peopleFiles := sortByNumberOfFiles(result.PeopleFiles, couples.reversedPeopleDict, result.Files)
+	for _, authorFiles := range peopleFiles {
+		fmt.Fprintf(writer, "      - %s:\n", yaml.SafeString(authorFiles.Author))
+		sort.Strings(authorFiles.Files)
+		for _, file := range authorFiles.Files {
+			fmt.Fprintf(writer, "        - %s\n", yaml.SafeString(file)) // sorted by path
+		}
+	}
+
+	if result.TeamsMatrix != nil {
+		fmt.Fprintln(writer, "  teams_coocc:")
+		fmt.Fprintln(writer, "    index:")
+		for _, team := range result.reversedTeamsDict {
+			fmt.Fprintf(writer, "      - %s\n", yaml.SafeString(team))
+		}
+		printCouplesMatrix(writer, result.TeamsMatrix)
+	}
+}
+
+// printCouplesMatrix writes the sparse square matrix in YAML, one row per line.
+func printCouplesMatrix(writer io.Writer, matrix []map[int]int64) {
 	fmt.Fprintln(writer, "    matrix:")
-	for _, people := range result.PeopleMatrix {
+	for _, row := range matrix {
 		fmt.Fprint(writer, "      - {")
 		indices := []int{}
-		for file := range people {
-			indices = append(indices, file)
+		for index := range row {
+			indices = append(indices, index)
 		}
 		sort.Ints(indices)
-		for i, person := range indices {
-			fmt.Fprintf(writer, "%d: %d", person, people[person])
+		for i, index := range indices {
+			fmt.Fprintf(writer, "%d: %d", index, row[index])
 			if i < len(indices)-1 {
 				fmt.Fprint(writer, ", ")
 			}
 		}
 		fmt.Fprintln(writer, "}")
 	}
-
-	fmt.Fprintln(writer, "    author_files:") // sorted by number of files each author changed
-	peopleFiles := sortByNumberOfFiles(result.PeopleFiles, couples.reversedPeopleDict, result.Files)
-	for _, authorFiles := range peopleFiles {
-		fmt.Fprintf(writer, "      - %s:\n", yaml.SafeString(authorFiles.Author))
-		sort.Strings(authorFiles.Files)
-		for _, file := range authorFiles.Files {
-			fmt.Fprintf(writer, "        - %s\n", yaml.SafeString(file)) // sorted by path
-		}
-	}
 }
 
 func sortByNumberOfFiles(
@@ -466,6 +601,13 @@ func (couples *CouplesAnalysis) serializeBinary(result *CouplesResult, writer io
 		}
 	}
 
+	if result.TeamsMatrix != nil {
+		message.TeamCouples = &pb.Couples{
+			Index:  result.reversedTeamsDict,
+			Matrix: pb.MapToCompressedSparseRowMatrix(result.TeamsMatrix),
+		}
+	}
+
 	serialized, err := proto.Marshal(&message)
 	if err != nil {
 		return err
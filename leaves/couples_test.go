@@ -28,9 +28,14 @@ func TestCouplesMeta(t *testing.T) {
 	c := fixtureCouples()
 	assert.Equal(t, c.Name(), "Couples")
 	assert.Equal(t, len(c.Provides()), 0)
-	assert.Equal(t, len(c.Requires()), 2)
+	assert.Equal(t, len(c.Requires()), 7)
 	assert.Equal(t, c.Requires()[0], identity.DependencyAuthor)
-	assert.Equal(t, c.Requires()[1], plumbing.DependencyTreeChanges)
+	assert.Equal(t, c.Requires()[1], identity.DependencyTeam)
+	assert.Equal(t, c.Requires()[2], identity.DependencyAuthors)
+	assert.Equal(t, c.Requires()[3], identity.DependencyTeams)
+	assert.Equal(t, c.Requires()[4], plumbing.DependencyTreeChanges)
+	assert.Equal(t, c.Requires()[5], plumbing.DependencyTreeCopies)
+	assert.Equal(t, c.Requires()[6], plumbing.DependencyTreeChangeKinds)
 	assert.Equal(t, c.Flag(), "couples")
 	assert.Len(t, c.ListConfigurationOptions(), 0)
 }
@@ -353,6 +358,76 @@ func TestCouplesMerge(t *testing.T) {
 	assert.Equal(t, merged.FilesMatrix[2], getCouplesMap(1, 200))
 }
 
+func TestCouplesTeams(t *testing.T) {
+	c := CouplesAnalysis{PeopleNumber: 3}
+	c.Configure(map[string]interface{}{
+		identity.FactIdentityDetectorReversedTeamsDict: []string{"one", "two"},
+	})
+	c.Initialize(test.Repository)
+	assert.Len(t, c.teams, 3)
+	deps := map[string]interface{}{}
+	deps[core.DependencyCommit] = &object.Commit{}
+	deps[identity.DependencyAuthor] = 0
+	deps[identity.DependencyTeam] = 0
+	deps[plumbing.DependencyTreeChanges] = generateChanges("+a", "+b")
+	c.Consume(deps)
+	deps[identity.DependencyAuthor] = 1
+	deps[plumbing.DependencyTreeChanges] = generateChanges("=a")
+	c.Consume(deps)
+	deps[identity.DependencyAuthor] = 2
+	deps[identity.DependencyTeam] = 1
+	deps[plumbing.DependencyTreeChanges] = generateChanges("=a", ">b>c")
+	c.Consume(deps)
+	deps[identity.DependencyTeam] = identity.TeamMissing
+	deps[plumbing.DependencyTreeChanges] = generateChanges("=c")
+	c.Consume(deps)
+	assert.Equal(t, []map[string]int{{"a": 2, "c": 1}, {"a": 1, "c": 1}, {"c": 1}}, c.teams)
+	result := c.Finalize().(CouplesResult)
+	assert.Equal(t, []map[int]int64{
+		getCouplesMap(0, 3, 1, 2, 2, 1),
+		getCouplesMap(0, 2, 1, 2, 2, 1),
+		getCouplesMap(0, 1, 1, 1, 2, 1),
+	}, result.TeamsMatrix)
+	assert.Equal(t, []string{"one", "two"}, result.reversedTeamsDict)
+
+	buffer := &bytes.Buffer{}
+	c.Serialize(result, false, buffer)
+	assert.True(t, strings.HasSuffix(buffer.String(), `  teams_coocc:
+    index:
+      - "one"
+      - "two"
+    matrix:
+      - {0: 3, 1: 2, 2: 1}
+      - {0: 2, 1: 2, 2: 1}
+      - {0: 1, 1: 1, 2: 1}
+`))
+	buffer = &bytes.Buffer{}
+	c.Serialize(result, true, buffer)
+	deserialized, err := c.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, result.TeamsMatrix, deserialized.(CouplesResult).TeamsMatrix)
+	assert.Equal(t, result.reversedTeamsDict, deserialized.(CouplesResult).reversedTeamsDict)
+}
+
+func TestCouplesTeamsMerge(t *testing.T) {
+	r1 := CouplesResult{
+		TeamsMatrix:       []map[int]int64{getCouplesMap(0, 3, 2, 1), {}, getCouplesMap(0, 1)},
+		reversedTeamsDict: []string{"one", "two"},
+	}
+	r2 := CouplesResult{
+		TeamsMatrix:       []map[int]int64{getCouplesMap(0, 2, 1, 1), getCouplesMap(0, 1), {}},
+		reversedTeamsDict: []string{"two", "three"},
+	}
+	merged := (&CouplesAnalysis{}).MergeResults(r1, r2, nil, nil).(CouplesResult)
+	assert.Equal(t, []string{"one", "two", "three"}, merged.reversedTeamsDict)
+	assert.Equal(t, []map[int]int64{
+		getCouplesMap(0, 3, 3, 1),
+		getCouplesMap(1, 2, 2, 1),
+		getCouplesMap(1, 1),
+		getCouplesMap(0, 1),
+	}, merged.TeamsMatrix)
+}
+
 func getSlice(vals ...int) []int {
 	return vals
 }
@@ -364,3 +439,73 @@ func getCouplesMap(vals ...int) map[int]int64 {
 	}
 	return res
 }
+
+func TestCouplesCoAuthors(t *testing.T) {
+	c := CouplesAnalysis{PeopleNumber: 3}
+	c.Configure(map[string]interface{}{
+		identity.FactIdentityDetectorReversedTeamsDict: []string{"one", "two"},
+		identity.FactIdentityDetectorCoAuthorsPolicy:   identity.CoAuthorsSplit,
+	})
+	c.Initialize(test.Repository)
+	deps := map[string]interface{}{}
+	deps[core.DependencyCommit] = &object.Commit{}
+	deps[identity.DependencyAuthor] = 0
+	deps[identity.DependencyTeam] = 0
+	deps[identity.DependencyAuthors] = []int{0, 2, identity.AuthorMissing}
+	deps[identity.DependencyTeams] = []int{0, 0, identity.TeamMissing}
+	deps[plumbing.DependencyTreeChanges] = generateChanges("+a", "+b")
+	c.Consume(deps)
+	assert.Equal(t, []int{1, 0, 1, 1}, c.peopleCommits)
+	assert.Equal(t, []map[string]int{{"a": 1, "b": 1}, {}, {"a": 1, "b": 1}, {"a": 1, "b": 1}},
+		c.people)
+	assert.Equal(t, []map[string]int{{"a": 1, "b": 1}, {}, {"a": 1, "b": 1}}, c.teams)
+}
+
+func TestCouplesCopies(t *testing.T) {
+	c := fixtureCouples()
+	deps := map[string]interface{}{}
+	deps[core.DependencyCommit] = &object.Commit{}
+	deps[identity.DependencyAuthor] = 0
+	deps[identity.DependencyTeam] = 0
+	deps[identity.DependencyAuthors] = []int{0}
+	deps[identity.DependencyTeams] = []int{0}
+	deps[plumbing.DependencyTreeChanges] = generateChanges("+a", "+b")
+	deps[plumbing.DependencyTreeCopies] = []plumbing.FileCopy{{
+		From: object.ChangeEntry{Name: "source"}, To: object.ChangeEntry{Name: "b"}}}
+	c.Consume(deps)
+	assert.Equal(t, map[string]int{"a": 1, "b": 1}, c.files["a"])
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "source": 1}, c.files["b"])
+	assert.Equal(t, map[string]int{"b": 1}, c.files["source"])
+	assert.Equal(t, map[string]int{"a": 1, "b": 1}, c.people[0])
+	// a rename across commits does not resurrect the deleted file
+	deps[plumbing.DependencyTreeChanges] = generateChanges("+c")
+	deps[plumbing.DependencyTreeCopies] = []plumbing.FileCopy{{
+		From: object.ChangeEntry{Name: "deleted"}, To: object.ChangeEntry{Name: "c"}, Deleted: true}}
+	c.Consume(deps)
+	assert.NotContains(t, c.files, "deleted")
+	assert.Equal(t, map[string]int{"c": 1}, c.files["c"])
+}
+
+func TestCouplesIgnoredCommits(t *testing.T) {
+	c := fixtureCouples()
+	c.Configure(map[string]interface{}{
+		core.ConfigPipelineIgnoredCommits: map[gitplumbing.Hash]bool{gitplumbing.ZeroHash: true}})
+	deps := map[string]interface{}{}
+	deps[core.DependencyCommit] = &object.Commit{}
+	deps[identity.DependencyAuthor] = 0
+	deps[identity.DependencyTeam] = 0
+	deps[identity.DependencyAuthors] = []int{0}
+	deps[identity.DependencyTeams] = []int{0}
+	deps[plumbing.DependencyTreeChanges] = generateChanges("+a", "+b")
+	c.IgnoredCommits[gitplumbing.ZeroHash] = false
+	c.Consume(deps)
+	// the ignored commit renames "b" but is not a co-change
+	c.IgnoredCommits[gitplumbing.ZeroHash] = true
+	deps[plumbing.DependencyTreeChanges] = generateChanges("=a", ">b>c")
+	c.Consume(deps)
+	assert.Equal(t, 1, c.peopleCommits[0])
+	assert.Equal(t, map[string]int{"a": 1, "c": 1}, c.files["a"])
+	assert.Equal(t, map[string]int{"a": 1, "c": 1}, c.files["c"])
+	assert.NotContains(t, c.files, "b")
+	assert.Equal(t, map[string]int{"a": 1, "c": 1}, c.people[0])
+}
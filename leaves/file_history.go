@@ -22,6 +22,10 @@ type FileHistory struct {
 	core.NoopMerger
 	core.OneShotMergeProcessor
 	files map[string][]plumbing.Hash
+	// renamesWindow references RenameAnalysis.CrossCommitWindow.
+	renamesWindow int
+	// deleted are the histories of the deleted files which can be renamed across commits.
+	deleted map[string][]plumbing.Hash
 }
 
 // FileHistoryResult is returned by Finalize() and represents the analysis result.
@@ -45,7 +49,8 @@ func (history *FileHistory) Provides() []string {
 // Each requested entity will be inserted into `deps` of Consume(). In turn, those
 // entities are Provides() upstream.
 func (history *FileHistory) Requires() []string {
-	arr := [...]string{items.DependencyTreeChanges}
+	arr := [...]string{
+		items.DependencyTreeChanges, items.DependencyTreeCopies, items.DependencyTreeChangeKinds}
 	return arr[:]
 }
 
@@ -61,12 +66,16 @@ func (history *FileHistory) Flag() string {
 
 // Configure sets the properties previously published by ListConfigurationOptions().
 func (history *FileHistory) Configure(facts map[string]interface{}) {
+	if val, exists := facts[items.ConfigRenameAnalysisCrossCommitWindow].(int); exists {
+		history.renamesWindow = val
+	}
 }
 
 // Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
 // calls. The repository which is going to be analysed is supplied as an argument.
 func (history *FileHistory) Initialize(repository *git.Repository) {
 	history.files = map[string][]plumbing.Hash{}
+	history.deleted = map[string][]plumbing.Hash{}
 	history.OneShotMergeProcessor.Initialize()
 }
 
@@ -81,14 +90,33 @@ func (history *FileHistory) Consume(deps map[string]interface{}) (map[string]int
 	}
 	commit := deps[core.DependencyCommit].(*object.Commit).Hash
 	changes := deps[items.DependencyTreeChanges].(object.Changes)
+	renames := map[string]string{}
+	copies, _ := deps[items.DependencyTreeCopies].([]items.FileCopy)
+	for _, fileCopy := range copies {
+		if fileCopy.Deleted {
+			renames[fileCopy.To.Name] = fileCopy.From.Name
+		}
+	}
+	kinds, _ := deps[items.DependencyTreeChangeKinds].(items.ChangeKinds)
 	for _, change := range changes {
+		if kinds.Kind(change) == items.ChangeKindMode {
+			continue
+		}
 		action, _ := change.Action()
 		switch action {
 		case merkletrie.Insert:
-			hashes := make([]plumbing.Hash, 1)
-			hashes[0] = commit
+			var hashes []plumbing.Hash
+			if from, exists := renames[change.To.Name]; exists {
+				hashes = append(hashes, history.deleted[from]...)
+				delete(history.deleted, from)
+			}
+			hashes = append(hashes, commit)
 			history.files[change.To.Name] = hashes
 		case merkletrie.Delete:
+			if history.renamesWindow > 0 {
+				// the deletion belongs to the history if the file is renamed later
+				history.deleted[change.From.Name] = append(history.files[change.From.Name], commit)
+			}
 			delete(history.files, change.From.Name)
 		case merkletrie.Modify:
 			hashes := history.files[change.From.Name]
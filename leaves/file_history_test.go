@@ -7,6 +7,7 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"gopkg.in/src-d/hercules.v4/internal/core"
 	"gopkg.in/src-d/hercules.v4/internal/pb"
@@ -24,10 +25,14 @@ func TestFileHistoryMeta(t *testing.T) {
 	fh := fixtureFileHistory()
 	assert.Equal(t, fh.Name(), "FileHistory")
 	assert.Equal(t, len(fh.Provides()), 0)
-	assert.Equal(t, len(fh.Requires()), 1)
+	assert.Equal(t, len(fh.Requires()), 3)
 	assert.Equal(t, fh.Requires()[0], items.DependencyTreeChanges)
+	assert.Equal(t, fh.Requires()[1], items.DependencyTreeCopies)
+	assert.Equal(t, fh.Requires()[2], items.DependencyTreeChangeKinds)
 	assert.Len(t, fh.ListConfigurationOptions(), 0)
 	fh.Configure(nil)
+	fh.Configure(map[string]interface{}{items.ConfigRenameAnalysisCrossCommitWindow: 2})
+	assert.Equal(t, 2, fh.renamesWindow)
 }
 
 func TestFileHistoryRegistration(t *testing.T) {
@@ -113,6 +118,60 @@ func TestFileHistoryConsume(t *testing.T) {
 	assert.Equal(t, fh.files, res.Files)
 }
 
+func TestFileHistoryCrossCommitRenames(t *testing.T) {
+	fh := fixtureFileHistory()
+	fh.renamesWindow = 2
+	entry := func(name string) object.ChangeEntry {
+		return object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+			Name: name, Hash: plumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe")}}
+	}
+	commit1 := plumbing.NewHash("1111111111111111111111111111111111111111")
+	commit2 := plumbing.NewHash("2222222222222222222222222222222222222222")
+	fh.files["old.go"] = []plumbing.Hash{commit1}
+	fh.Consume(map[string]interface{}{
+		core.DependencyCommit:       &object.Commit{Hash: commit2},
+		items.DependencyTreeChanges: object.Changes{&object.Change{From: entry("old.go")}},
+		items.DependencyTreeCopies:  []items.FileCopy{},
+	})
+	assert.Len(t, fh.files, 0)
+	commit3 := plumbing.NewHash("3333333333333333333333333333333333333333")
+	fh.Consume(map[string]interface{}{
+		core.DependencyCommit: &object.Commit{Hash: commit3},
+		items.DependencyTreeChanges: object.Changes{
+			&object.Change{To: entry("new.go")}, &object.Change{To: entry("other.go")}},
+		items.DependencyTreeCopies: []items.FileCopy{
+			{From: entry("old.go"), To: entry("new.go"), Deleted: true}},
+	})
+	assert.Equal(t, []plumbing.Hash{commit1, commit2, commit3}, fh.files["new.go"])
+	assert.Equal(t, []plumbing.Hash{commit3}, fh.files["other.go"])
+	assert.Len(t, fh.deleted, 0)
+}
+
+func TestFileHistoryModeChanges(t *testing.T) {
+	fh := fixtureFileHistory()
+	entry := func(name string, mode filemode.FileMode) object.ChangeEntry {
+		return object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+			Name: name, Mode: mode, Hash: plumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe")}}
+	}
+	commit1 := plumbing.NewHash("1111111111111111111111111111111111111111")
+	commit2 := plumbing.NewHash("2222222222222222222222222222222222222222")
+	fh.files["run.sh"] = []plumbing.Hash{commit1}
+	fh.files["link"] = []plumbing.Hash{commit1}
+	changes := object.Changes{
+		&object.Change{From: entry("run.sh", filemode.Regular), To: entry("run.sh", filemode.Executable)},
+		&object.Change{From: entry("link", filemode.Symlink), To: entry("link", filemode.Regular)},
+	}
+	fh.Consume(map[string]interface{}{
+		core.DependencyCommit:           &object.Commit{Hash: commit2},
+		items.DependencyTreeChanges:     changes,
+		items.DependencyTreeCopies:      []items.FileCopy{},
+		items.DependencyTreeChangeKinds: items.ChangeKinds{"run.sh": items.ChangeKindMode},
+	})
+	// flipping the executable bit is not a change in the history, replacing a symlink is
+	assert.Equal(t, []plumbing.Hash{commit1}, fh.files["run.sh"])
+	assert.Equal(t, []plumbing.Hash{commit1, commit2}, fh.files["link"])
+}
+
 func TestFileHistoryFork(t *testing.T) {
 	fh1 := fixtureFileHistory()
 	clones := fh1.Fork(1)
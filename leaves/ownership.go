@@ -0,0 +1,542 @@
+package leaves
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	"gopkg.in/src-d/hercules.v4/internal/burndown"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+	"gopkg.in/src-d/hercules.v4/internal/pb"
+	items "gopkg.in/src-d/hercules.v4/internal/plumbing"
+	"gopkg.in/src-d/hercules.v4/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v4/yaml"
+)
+
+// OwnershipAnalysis tracks which developer owns each line of every file, samples the line
+// shares per file and per directory through time and calculates the bus factor and
+// the knowledge concentration. It is a LeafPipelineItem.
+type OwnershipAnalysis struct {
+	// Sampling sets the size of the interval in days between consecutive measurements.
+	Sampling int
+	// DepartureThreshold is the number of days without commits after which a developer
+	// is considered to have left the project.
+	DepartureThreshold int
+	// PeopleNumber is the number of identified developers.
+	PeopleNumber int
+
+	// files is the mapping <file path> -> *File. The values are packed the same way
+	// as in BurndownAnalysis.
+	files map[string]*burndown.File
+	// current caches the line counts per developer of each file, the values are not mutated.
+	current map[string]map[int]int64
+	// snapshots are the periodic measurements: <path> -> <developer> -> <number of lines>.
+	snapshots []map[string]map[int]int64
+	// lastActivity is the day of the last commit of each developer.
+	lastActivity []int
+	// day is the most recent day index processed.
+	day int
+	// previousDay is the day from the previous sample period.
+	previousDay int
+	// mergeAuthor is the author of the most recent merge commit.
+	mergeAuthor int
+	// blobClasses tells which blobs in the current commit are treated as binary.
+	blobClasses items.BlobClasses
+	// references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+	// coAuthorsPolicy references IdentityDetector.CoAuthorsPolicy. The lines are divided
+	// between the co-authors with any policy but ignore, the same as in BurndownAnalysis.
+	coAuthorsPolicy string
+}
+
+// OwnershipMetrics are the code ownership statistics of a single file or directory.
+type OwnershipMetrics struct {
+	// BusFactor is the minimum number of developers who own more than half of the lines.
+	BusFactor int
+	// Concentration is the sum of the squared line shares of the developers
+	// (Herfindahl-Hirschman index). It equals 1 if there is a single owner.
+	Concentration float32
+	// DepartedShare is the share of the lines owned by the departed developers.
+	DepartedShare float32
+	// AtRisk indicates that the departed developers own most of the lines.
+	AtRisk bool
+}
+
+// OwnershipResult is returned by OwnershipAnalysis.Finalize() and represents the analysis result.
+type OwnershipResult struct {
+	// Ownership maps each file path and each directory (with the trailing slash) to the number
+	// of lines owned by every developer: [number of samples]{developer: lines}. The developer
+	// indexes match reversedPeopleDict, len(reversedPeopleDict) stands for the unidentified
+	// developers. A nil sample means that the path did not exist at that moment.
+	Ownership map[string][]map[int]int64
+	// Metrics are calculated for each path in Ownership at the last sample.
+	Metrics map[string]OwnershipMetrics
+	// LastActivity is the day of the last commit of each developer, -1 if there were no commits.
+	LastActivity []int
+	// Departed indicates whether each developer did not commit for longer than
+	// OwnershipAnalysis.DepartureThreshold days before the last analysed commit.
+	Departed []bool
+
+	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+	// sampling is copied from OwnershipAnalysis
+	sampling int
+}
+
+const (
+	// ConfigOwnershipSampling is the name of the option to set OwnershipAnalysis.Sampling.
+	ConfigOwnershipSampling = "Ownership.Sampling"
+	// ConfigOwnershipDepartureThreshold is the name of the option to set
+	// OwnershipAnalysis.DepartureThreshold.
+	ConfigOwnershipDepartureThreshold = "Ownership.DepartureThreshold"
+	// DefaultOwnershipSampling is the default value of OwnershipAnalysis.Sampling.
+	DefaultOwnershipSampling = 30
+	// DefaultOwnershipDepartureThreshold is the default value of
+	// OwnershipAnalysis.DepartureThreshold - half a year.
+	DefaultOwnershipDepartureThreshold = 180
+)
+
+// Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
+func (ownership *OwnershipAnalysis) Name() string {
+	return "Ownership"
+}
+
+// Provides returns the list of names of entities which are produced by this PipelineItem.
+// Each produced entity will be inserted into `deps` of dependent Consume()-s according
+// to this list. Also used by core.Registry to build the global map of providers.
+func (ownership *OwnershipAnalysis) Provides() []string {
+	return []string{}
+}
+
+// Requires returns the list of names of entities which are needed by this PipelineItem.
+// Each requested entity will be inserted into `deps` of Consume(). In turn, those
+// entities are Provides() upstream.
+func (ownership *OwnershipAnalysis) Requires() []string {
+	arr := [...]string{
+		items.DependencyFileDiff, items.DependencyTreeChanges, items.DependencyBlobCache,
+		items.DependencyBlobClasses, items.DependencyDay, identity.DependencyAuthor,
+		identity.DependencyAuthors, items.DependencyTreeChangeKinds}
+	return arr[:]
+}
+
+// ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
+func (ownership *OwnershipAnalysis) ListConfigurationOptions() []core.ConfigurationOption {
+	options := [...]core.ConfigurationOption{{
+		Name:        ConfigOwnershipSampling,
+		Description: "How frequently to record the code ownership in days.",
+		Flag:        "ownership-sampling",
+		Type:        core.IntConfigurationOption,
+		Default:     DefaultOwnershipSampling}, {
+		Name:        ConfigOwnershipDepartureThreshold,
+		Description: "The number of days without commits after which a developer is considered departed.",
+		Flag:        "ownership-departure",
+		Type:        core.IntConfigurationOption,
+		Default:     DefaultOwnershipDepartureThreshold},
+	}
+	return options[:]
+}
+
+// Configure sets the properties previously published by ListConfigurationOptions().
+func (ownership *OwnershipAnalysis) Configure(facts map[string]interface{}) {
+	if val, exists := facts[ConfigOwnershipSampling].(int); exists {
+		ownership.Sampling = val
+	}
+	if val, exists := facts[ConfigOwnershipDepartureThreshold].(int); exists {
+		ownership.DepartureThreshold = val
+	}
+	if val, exists := facts[identity.FactIdentityDetectorPeopleCount].(int); exists {
+		ownership.PeopleNumber = val
+		ownership.reversedPeopleDict = facts[identity.FactIdentityDetectorReversedPeopleDict].([]string)
+	}
+	if val, exists := facts[identity.FactIdentityDetectorCoAuthorsPolicy].(string); exists {
+		ownership.coAuthorsPolicy = val
+	}
+}
+
+// Flag for the command line switch which enables this analysis.
+func (ownership *OwnershipAnalysis) Flag() string {
+	return "ownership"
+}
+
+// Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
+// calls. The repository which is going to be analysed is supplied as an argument.
+func (ownership *OwnershipAnalysis) Initialize(repository *git.Repository) {
+	if ownership.Sampling <= 0 {
+		log.Printf("Warning: adjusted the ownership sampling to %d days\n",
+			DefaultOwnershipSampling)
+		ownership.Sampling = DefaultOwnershipSampling
+	}
+	if ownership.DepartureThreshold <= 0 {
+		log.Printf("Warning: adjusted the departure threshold to %d days\n",
+			DefaultOwnershipDepartureThreshold)
+		ownership.DepartureThreshold = DefaultOwnershipDepartureThreshold
+	}
+	ownership.files = map[string]*burndown.File{}
+	ownership.current = map[string]map[int]int64{}
+	ownership.snapshots = []map[string]map[int]int64{}
+	ownership.lastActivity = make([]int, ownership.PeopleNumber+1)
+	for i := range ownership.lastActivity {
+		ownership.lastActivity[i] = -1
+	}
+	ownership.day = 0
+	ownership.previousDay = 0
+	ownership.mergeAuthor = ownership.PeopleNumber
+}
+
+// Consume runs this PipelineItem on the next commit data.
+// `deps` contain all the results from upstream PipelineItem-s as requested by Requires().
+// Additionally, DependencyCommit is always present there and represents the analysed *object.Commit.
+// This function returns the mapping with analysis results. The keys must be the same as
+// in Provides(). If there was an error, nil is returned.
+func (ownership *OwnershipAnalysis) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	commit := deps[core.DependencyCommit].(*object.Commit)
+	authors, _ := identity.CreditedAuthors(deps, ownership.coAuthorsPolicy)
+	day := deps[items.DependencyDay].(int)
+	ownership.day = day
+	ownership.onNewDay()
+	values := make([]int, len(authors))
+	for i, author := range authors {
+		if author == identity.AuthorMissing {
+			author = ownership.PeopleNumber
+		}
+		if day > ownership.lastActivity[author] {
+			ownership.lastActivity[author] = day
+		}
+		values[i] = packPersonWithDay(author, day)
+		if len(commit.ParentHashes) > 1 {
+			// the lines will be resolved in Merge()
+			values[i] = packPersonWithDay(author, burndown.TreeMergeMark)
+			if i == 0 {
+				ownership.mergeAuthor = author
+			}
+		}
+	}
+	cache := deps[items.DependencyBlobCache].(map[plumbing.Hash]*object.Blob)
+	ownership.blobClasses, _ = deps[items.DependencyBlobClasses].(items.BlobClasses)
+	treeDiffs := deps[items.DependencyTreeChanges].(object.Changes)
+	fileDiffs := deps[items.DependencyFileDiff].(map[string]items.FileDiffData)
+	kinds, _ := deps[items.DependencyTreeChangeKinds].(items.ChangeKinds)
+	for _, change := range treeDiffs {
+		action, _ := kinds.LineAction(change)
+		var err error
+		switch action {
+		case merkletrie.Insert:
+			err = ownership.handleInsertion(change, values, cache)
+		case merkletrie.Delete:
+			delete(ownership.files, change.From.Name)
+			delete(ownership.current, change.From.Name)
+		case merkletrie.Modify:
+			err = ownership.handleModification(change, values, cache, fileDiffs)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// Fork clones this item. Everything is copied by reference except the files
+// which are copied by value.
+func (ownership *OwnershipAnalysis) Fork(n int) []core.PipelineItem {
+	result := make([]core.PipelineItem, n)
+	for i := range result {
+		clone := *ownership
+		clone.files = map[string]*burndown.File{}
+		for key, file := range ownership.files {
+			clone.files[key] = file.Clone(false)
+		}
+		clone.current = map[string]map[int]int64{}
+		for key, val := range ownership.current {
+			clone.current[key] = val
+		}
+		clone.snapshots = append([]map[string]map[int]int64{}, ownership.snapshots...)
+		clone.lastActivity = append([]int{}, ownership.lastActivity...)
+		result[i] = &clone
+	}
+	return result
+}
+
+// Merge combines several items together. We apply the same file merging logic as
+// in BurndownAnalysis.
+func (ownership *OwnershipAnalysis) Merge(branches []core.PipelineItem) {
+	files := make([]map[string]*burndown.File, len(branches))
+	for i, branch := range branches {
+		files[i] = branch.(*OwnershipAnalysis).files
+	}
+	merged := mergeFiles(packPersonWithDay(ownership.mergeAuthor, ownership.day), ownership.files, files)
+	for _, key := range merged {
+		delete(ownership.current, key)
+		for _, branch := range branches {
+			delete(branch.(*OwnershipAnalysis).current, key)
+		}
+	}
+	for _, branch := range branches {
+		for i, day := range branch.(*OwnershipAnalysis).lastActivity {
+			if day > ownership.lastActivity[i] {
+				ownership.lastActivity[i] = day
+			}
+		}
+	}
+}
+
+// Finalize returns the result of the analysis. Further Consume() calls are not expected.
+func (ownership *OwnershipAnalysis) Finalize() interface{} {
+	snapshots := append(ownership.snapshots, ownership.snapshot())
+	result := OwnershipResult{
+		Ownership:          map[string][]map[int]int64{},
+		Metrics:            map[string]OwnershipMetrics{},
+		LastActivity:       make([]int, ownership.PeopleNumber),
+		Departed:           make([]bool, ownership.PeopleNumber),
+		reversedPeopleDict: ownership.reversedPeopleDict,
+		sampling:           ownership.Sampling,
+	}
+	copy(result.LastActivity, ownership.lastActivity)
+	for i, day := range result.LastActivity {
+		result.Departed[i] = day >= 0 && ownership.day-day > ownership.DepartureThreshold
+	}
+	for i, snapshot := range snapshots {
+		for path, owners := range snapshot {
+			series := result.Ownership[path]
+			if series == nil {
+				series = make([]map[int]int64, len(snapshots))
+				result.Ownership[path] = series
+			}
+			series[i] = owners
+		}
+	}
+	for path, owners := range snapshots[len(snapshots)-1] {
+		result.Metrics[path] = calculateOwnershipMetrics(owners, result.Departed)
+	}
+	return result
+}
+
+// Serialize converts the analysis result as returned by Finalize() to text or bytes.
+// The text format is YAML and the bytes format is Protocol Buffers.
+func (ownership *OwnershipAnalysis) Serialize(result interface{}, binary bool, writer io.Writer) error {
+	ownershipResult := result.(OwnershipResult)
+	if binary {
+		return ownership.serializeBinary(&ownershipResult, writer)
+	}
+	ownership.serializeText(&ownershipResult, writer)
+	return nil
+}
+
+func (ownership *OwnershipAnalysis) serializeText(result *OwnershipResult, writer io.Writer) {
+	fmt.Fprintln(writer, "  sampling:", result.sampling)
+	fmt.Fprintln(writer, "  people_sequence:")
+	for _, person := range result.reversedPeopleDict {
+		fmt.Fprintln(writer, "    - "+yaml.SafeString(person))
+	}
+	activity := make([]string, len(result.LastActivity))
+	for i, day := range result.LastActivity {
+		activity[i] = fmt.Sprint(day)
+	}
+	fmt.Fprintf(writer, "  last_activity: [%s]\n", strings.Join(activity, ", "))
+	departed := make([]string, len(result.Departed))
+	for i, val := range result.Departed {
+		departed[i] = fmt.Sprint(val)
+	}
+	fmt.Fprintf(writer, "  departed: [%s]\n", strings.Join(departed, ", "))
+	fmt.Fprintln(writer, "  paths:")
+	for _, path := range sortedOwnershipPaths(result.Ownership) {
+		metrics := result.Metrics[path]
+		fmt.Fprintf(writer, "    %s:\n", yaml.SafeString(path))
+		fmt.Fprintf(writer, "      bus_factor: %d\n", metrics.BusFactor)
+		fmt.Fprintf(writer, "      concentration: %.4f\n", metrics.Concentration)
+		fmt.Fprintf(writer, "      departed_share: %.4f\n", metrics.DepartedShare)
+		fmt.Fprintf(writer, "      at_risk: %t\n", metrics.AtRisk)
+		fmt.Fprintln(writer, "      ownership:")
+		for _, owners := range result.Ownership[path] {
+			keys := make([]int, 0, len(owners))
+			for key := range owners {
+				keys = append(keys, key)
+			}
+			sort.Ints(keys)
+			pairs := make([]string, len(keys))
+			for i, key := range keys {
+				pairs[i] = fmt.Sprintf("%d: %d", key, owners[key])
+			}
+			fmt.Fprintf(writer, "        - {%s}\n", strings.Join(pairs, ", "))
+		}
+	}
+}
+
+func (ownership *OwnershipAnalysis) serializeBinary(result *OwnershipResult, writer io.Writer) error {
+	message := pb.OwnershipAnalysisResults{
+		Sampling:     int32(result.sampling),
+		People:       result.reversedPeopleDict,
+		LastActivity: make([]int32, len(result.LastActivity)),
+		Departed:     result.Departed,
+	}
+	for i, day := range result.LastActivity {
+		message.LastActivity[i] = int32(day)
+	}
+	paths := sortedOwnershipPaths(result.Ownership)
+	message.Paths = make([]*pb.PathOwnership, len(paths))
+	for i, path := range paths {
+		metrics := result.Metrics[path]
+		matrix := pb.MapToCompressedSparseRowMatrix(result.Ownership[path])
+		matrix.NumberOfColumns = int32(len(result.reversedPeopleDict) + 1)
+		message.Paths[i] = &pb.PathOwnership{
+			Path:          path,
+			Ownership:     matrix,
+			BusFactor:     int32(metrics.BusFactor),
+			Concentration: metrics.Concentration,
+			DepartedShare: metrics.DepartedShare,
+			AtRisk:        metrics.AtRisk,
+		}
+	}
+	serialized, err := proto.Marshal(&message)
+	if err != nil {
+		return err
+	}
+	writer.Write(serialized)
+	return nil
+}
+
+// calculateOwnershipMetrics derives the bus factor, the knowledge concentration and the departed
+// developers' share from the number of lines owned by each developer. The unidentified developers
+// are ignored.
+func calculateOwnershipMetrics(owners map[int]int64, departed []bool) OwnershipMetrics {
+	metrics := OwnershipMetrics{}
+	lines := make([]int64, 0, len(owners))
+	var total, departedTotal int64
+	for author, count := range owners {
+		if author >= len(departed) || count <= 0 {
+			continue
+		}
+		lines = append(lines, count)
+		total += count
+		if departed[author] {
+			departedTotal += count
+		}
+	}
+	if total == 0 {
+		return metrics
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i] > lines[j] })
+	var accumulated int64
+	for _, count := range lines {
+		share := float32(count) / float32(total)
+		metrics.Concentration += share * share
+		if accumulated*2 <= total {
+			metrics.BusFactor++
+		}
+		accumulated += count
+	}
+	metrics.DepartedShare = float32(departedTotal) / float32(total)
+	metrics.AtRisk = departedTotal*2 > total
+	return metrics
+}
+
+func sortedOwnershipPaths(ownership map[string][]map[int]int64) []string {
+	paths := make([]string, 0, len(ownership))
+	for path := range ownership {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (ownership *OwnershipAnalysis) onNewDay() {
+	delta := (ownership.day / ownership.Sampling) - (ownership.previousDay / ownership.Sampling)
+	if delta > 0 {
+		ownership.previousDay = ownership.day
+		snapshot := ownership.snapshot()
+		for i := 0; i < delta; i++ {
+			ownership.snapshots = append(ownership.snapshots, snapshot)
+		}
+	}
+}
+
+// snapshot measures the current number of lines owned by each developer in every file
+// and directory.
+func (ownership *OwnershipAnalysis) snapshot() map[string]map[int]int64 {
+	result := map[string]map[int]int64{}
+	for name, file := range ownership.files {
+		owners, exists := ownership.current[name]
+		if !exists {
+			owners = ownership.countLines(file)
+			ownership.current[name] = owners
+		}
+		result[name] = owners
+		for i := strings.LastIndex(name, "/"); i >= 0; i = strings.LastIndex(name[:i], "/") {
+			dir := name[:i+1]
+			dirOwners := result[dir]
+			if dirOwners == nil {
+				dirOwners = map[int]int64{}
+				result[dir] = dirOwners
+			}
+			for author, lines := range owners {
+				dirOwners[author] += lines
+			}
+		}
+	}
+	return result
+}
+
+// countLines returns the number of lines owned by each developer in the specified file.
+func (ownership *OwnershipAnalysis) countLines(file *burndown.File) map[int]int64 {
+	owners := map[int]int64{}
+	previousLine := 0
+	previousValue := burndown.TreeEnd
+	file.ForEach(func(line, value int) {
+		if previousValue != burndown.TreeEnd && line > previousLine {
+			owners[previousValue>>burndown.TreeMaxBinPower] += int64(line - previousLine)
+		}
+		previousLine = line
+		previousValue = value
+	})
+	return owners
+}
+
+func (ownership *OwnershipAnalysis) handleInsertion(
+	change *object.Change, values []int, cache map[plumbing.Hash]*object.Blob) error {
+	blob := cache[change.To.TreeEntry.Hash]
+	lines, err := ownership.blobClasses.CountLines(blob)
+	if err != nil {
+		if err.Error() == "binary" {
+			return nil
+		}
+		return err
+	}
+	name := change.To.Name
+	if _, exists := ownership.files[name]; exists {
+		return fmt.Errorf("file %s already exists", name)
+	}
+	ownership.files[name] = newSplitFile(blob.Hash, values, lines)
+	delete(ownership.current, name)
+	return nil
+}
+
+func (ownership *OwnershipAnalysis) handleModification(
+	change *object.Change, values []int, cache map[plumbing.Hash]*object.Blob,
+	diffs map[string]items.FileDiffData) error {
+
+	file, exists := ownership.files[change.From.Name]
+	if !exists {
+		return ownership.handleInsertion(change, values, cache)
+	}
+	file.Hash = change.To.TreeEntry.Hash
+	delete(ownership.current, change.From.Name)
+	if change.To.Name != change.From.Name {
+		ownership.files[change.To.Name] = file
+		delete(ownership.files, change.From.Name)
+	}
+
+	return applyFileDiff(change.To.Name, file, diffs[change.To.Name], false,
+		func(pos, insLength, delLength int) {
+			updateSplit(file, values, pos, insLength, delLength)
+		})
+}
+
+func init() {
+	core.Registry.Register(&OwnershipAnalysis{})
+}
@@ -0,0 +1,301 @@
+package leaves
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v4/internal/burndown"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+	"gopkg.in/src-d/hercules.v4/internal/pb"
+	items "gopkg.in/src-d/hercules.v4/internal/plumbing"
+	"gopkg.in/src-d/hercules.v4/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v4/internal/test"
+)
+
+func fixtureOwnership() *OwnershipAnalysis {
+	ownership := OwnershipAnalysis{Sampling: 10, DepartureThreshold: 20, PeopleNumber: 2}
+	ownership.Initialize(test.Repository)
+	ownership.reversedPeopleDict = []string{"one@srcd", "two@srcd"}
+	return &ownership
+}
+
+// consumeFakeCommit feeds the commit which changes the files from the old to the new contents
+// to the specified PipelineItem. Empty contents mean that the file does not exist.
+func consumeFakeCommit(t *testing.T, item core.PipelineItem, author int, day int, parents int,
+	files map[string][2]string) {
+	cache := map[plumbing.Hash]*object.Blob{}
+	changes := object.Changes{}
+	for name, contents := range files {
+		change := &object.Change{}
+		if contents[0] != "" {
+			blob := test.FakeBlob(contents[0])
+			cache[blob.Hash] = blob
+			change.From = object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+				Name: name, Mode: 0100644, Hash: blob.Hash}}
+		}
+		if contents[1] != "" {
+			blob := test.FakeBlob(contents[1])
+			cache[blob.Hash] = blob
+			change.To = object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+				Name: name, Mode: 0100644, Hash: blob.Hash}}
+		}
+		changes = append(changes, change)
+	}
+	deps := map[string]interface{}{
+		core.DependencyCommit:       &object.Commit{ParentHashes: make([]plumbing.Hash, parents)},
+		identity.DependencyAuthor:   author,
+		items.DependencyDay:         day,
+		items.DependencyBlobCache:   cache,
+		items.DependencyTreeChanges: changes,
+	}
+	fd := &items.FileDiff{}
+	fd.Initialize(test.Repository)
+	result, err := fd.Consume(deps)
+	assert.Nil(t, err)
+	deps[items.DependencyFileDiff] = result[items.DependencyFileDiff]
+	_, err = item.Consume(deps)
+	assert.Nil(t, err)
+}
+
+func TestOwnershipMeta(t *testing.T) {
+	ownership := OwnershipAnalysis{}
+	assert.Equal(t, ownership.Name(), "Ownership")
+	assert.Len(t, ownership.Provides(), 0)
+	required := [...]string{
+		items.DependencyFileDiff, items.DependencyTreeChanges, items.DependencyBlobCache,
+		items.DependencyBlobClasses, items.DependencyDay, identity.DependencyAuthor,
+		items.DependencyTreeChangeKinds}
+	for _, name := range required {
+		assert.Contains(t, ownership.Requires(), name)
+	}
+	opts := ownership.ListConfigurationOptions()
+	matches := 0
+	for _, opt := range opts {
+		switch opt.Name {
+		case ConfigOwnershipSampling, ConfigOwnershipDepartureThreshold:
+			matches++
+		}
+	}
+	assert.Len(t, opts, matches)
+	assert.Equal(t, ownership.Flag(), "ownership")
+}
+
+func TestOwnershipConfigure(t *testing.T) {
+	ownership := OwnershipAnalysis{}
+	facts := map[string]interface{}{}
+	facts[ConfigOwnershipSampling] = 10
+	facts[ConfigOwnershipDepartureThreshold] = 20
+	facts[identity.FactIdentityDetectorPeopleCount] = 3
+	facts[identity.FactIdentityDetectorReversedPeopleDict] = ownership.Requires()
+	ownership.Configure(facts)
+	assert.Equal(t, ownership.Sampling, 10)
+	assert.Equal(t, ownership.DepartureThreshold, 20)
+	assert.Equal(t, ownership.PeopleNumber, 3)
+	assert.Equal(t, ownership.reversedPeopleDict, ownership.Requires())
+	ownership.Configure(map[string]interface{}{})
+	assert.Equal(t, ownership.Sampling, 10)
+	assert.Equal(t, ownership.DepartureThreshold, 20)
+	assert.Equal(t, ownership.PeopleNumber, 3)
+}
+
+func TestOwnershipRegistration(t *testing.T) {
+	summoned := core.Registry.Summon((&OwnershipAnalysis{}).Name())
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, summoned[0].Name(), "Ownership")
+	leaves := core.Registry.GetLeaves()
+	matched := false
+	for _, tp := range leaves {
+		if tp.Flag() == (&OwnershipAnalysis{}).Flag() {
+			matched = true
+			break
+		}
+	}
+	assert.True(t, matched)
+}
+
+func TestOwnershipInitialize(t *testing.T) {
+	ownership := OwnershipAnalysis{PeopleNumber: 2}
+	ownership.Initialize(test.Repository)
+	assert.Equal(t, ownership.Sampling, DefaultOwnershipSampling)
+	assert.Equal(t, ownership.DepartureThreshold, DefaultOwnershipDepartureThreshold)
+	assert.Equal(t, ownership.lastActivity, []int{-1, -1, -1})
+	assert.NotNil(t, ownership.files)
+	assert.NotNil(t, ownership.current)
+	assert.Len(t, ownership.snapshots, 0)
+}
+
+func fixtureOwnershipResult(t *testing.T) (*OwnershipAnalysis, OwnershipResult) {
+	ownership := fixtureOwnership()
+	consumeFakeCommit(t, ownership, 0, 0, 1, map[string][2]string{
+		"a/x.go": {"", "1\n2\n3\n4\n"},
+		"b.go":   {"", "1\n2\n"},
+	})
+	consumeFakeCommit(t, ownership, 1, 15, 1, map[string][2]string{
+		"a/x.go": {"1\n2\n3\n4\n", "1\n2\nX\nY\n4\n"},
+		"a/y.go": {"", "z\n"},
+	})
+	consumeFakeCommit(t, ownership, 1, 40, 1, map[string][2]string{
+		"b.go": {"1\n2\n", "1\nQ\n"},
+	})
+	return ownership, ownership.Finalize().(OwnershipResult)
+}
+
+func TestOwnershipConsumeFinalize(t *testing.T) {
+	_, result := fixtureOwnershipResult(t)
+	assert.Len(t, result.Ownership, 4)
+	for _, series := range result.Ownership {
+		assert.Len(t, series, 5)
+	}
+	assert.Equal(t, map[int]int64{0: 4}, result.Ownership["a/x.go"][0])
+	assert.Equal(t, map[int]int64{0: 3, 1: 2}, result.Ownership["a/x.go"][1])
+	assert.Equal(t, map[int]int64{0: 3, 1: 2}, result.Ownership["a/x.go"][4])
+	assert.Nil(t, result.Ownership["a/y.go"][0])
+	assert.Equal(t, map[int]int64{1: 1}, result.Ownership["a/y.go"][4])
+	assert.Equal(t, map[int]int64{0: 2}, result.Ownership["b.go"][3])
+	assert.Equal(t, map[int]int64{0: 1, 1: 1}, result.Ownership["b.go"][4])
+	assert.Equal(t, map[int]int64{0: 4}, result.Ownership["a/"][0])
+	assert.Equal(t, map[int]int64{0: 3, 1: 3}, result.Ownership["a/"][4])
+	assert.Equal(t, []int{0, 40}, result.LastActivity)
+	assert.Equal(t, []bool{true, false}, result.Departed)
+	assert.Equal(t, 1, result.Metrics["a/x.go"].BusFactor)
+	assert.InDelta(t, 0.52, result.Metrics["a/x.go"].Concentration, 0.0001)
+	assert.InDelta(t, 0.6, result.Metrics["a/x.go"].DepartedShare, 0.0001)
+	assert.True(t, result.Metrics["a/x.go"].AtRisk)
+	assert.Equal(t, 2, result.Metrics["a/"].BusFactor)
+	assert.InDelta(t, 0.5, result.Metrics["a/"].Concentration, 0.0001)
+	assert.False(t, result.Metrics["a/"].AtRisk)
+	assert.Equal(t, OwnershipMetrics{BusFactor: 1, Concentration: 1}, result.Metrics["a/y.go"])
+}
+
+func TestOwnershipMetrics(t *testing.T) {
+	metrics := calculateOwnershipMetrics(map[int]int64{}, []bool{false})
+	assert.Equal(t, OwnershipMetrics{}, metrics)
+	// the unidentified developers are ignored
+	metrics = calculateOwnershipMetrics(map[int]int64{0: 10, 1: 100}, []bool{true})
+	assert.Equal(t, OwnershipMetrics{
+		BusFactor: 1, Concentration: 1, DepartedShare: 1, AtRisk: true}, metrics)
+	metrics = calculateOwnershipMetrics(
+		map[int]int64{0: 10, 1: 10, 2: 10, 3: 70}, []bool{false, false, false, false})
+	assert.Equal(t, 1, metrics.BusFactor)
+	metrics = calculateOwnershipMetrics(
+		map[int]int64{0: 30, 1: 30, 2: 20, 3: 20}, []bool{false, true, true, false})
+	assert.Equal(t, 2, metrics.BusFactor)
+	assert.InDelta(t, 0.26, metrics.Concentration, 0.0001)
+	assert.InDelta(t, 0.5, metrics.DepartedShare, 0.0001)
+	assert.False(t, metrics.AtRisk)
+}
+
+func TestOwnershipForkMerge(t *testing.T) {
+	ownership := fixtureOwnership()
+	consumeFakeCommit(t, ownership, 0, 0, 1, map[string][2]string{
+		"b.go": {"", "1\n2\n"},
+	})
+	clones := ownership.Fork(1)
+	assert.Len(t, clones, 1)
+	clone := clones[0].(*OwnershipAnalysis)
+	consumeFakeCommit(t, clone, 1, 5, 1, map[string][2]string{
+		"b.go": {"1\n2\n", "1\n2\n3\n"},
+	})
+	assert.Equal(t, 2, ownership.files["b.go"].Len())
+	assert.Equal(t, 3, clone.files["b.go"].Len())
+	assert.Equal(t, []int{0, -1, -1}, ownership.lastActivity)
+	assert.Equal(t, []int{0, 5, -1}, clone.lastActivity)
+	// the merge commit as seen from the first parent
+	consumeFakeCommit(t, ownership, 0, 6, 2, map[string][2]string{
+		"b.go": {"1\n2\n", "1\n2\n3\n"},
+	})
+	ownership.files["b.go"].Hash = plumbing.ZeroHash
+	ownership.Merge([]core.PipelineItem{clone})
+	assert.Equal(t, map[int]int64{0: 2, 1: 1}, ownership.countLines(ownership.files["b.go"]))
+	assert.Equal(t, map[int]int64{0: 2, 1: 1}, clone.countLines(clone.files["b.go"]))
+	assert.Equal(t, []int{6, 5, -1}, ownership.lastActivity)
+}
+
+func TestOwnershipCountLines(t *testing.T) {
+	ownership := fixtureOwnership()
+	file := burndown.NewFile(plumbing.ZeroHash, packPersonWithDay(1, 10), 10)
+	file.Update(packPersonWithDay(0, 20), 5, 3, 0)
+	assert.Equal(t, map[int]int64{0: 3, 1: 10}, ownership.countLines(file))
+	file = burndown.NewFile(plumbing.ZeroHash, packPersonWithDay(1, 10), 0)
+	assert.Equal(t, map[int]int64{}, ownership.countLines(file))
+}
+
+func TestOwnershipSerialize(t *testing.T) {
+	ownership, result := fixtureOwnershipResult(t)
+	buffer := &bytes.Buffer{}
+	ownership.Serialize(result, false, buffer)
+	assert.Equal(t, `  sampling: 10
+  people_sequence:
+    - "one@srcd"
+    - "two@srcd"
+  last_activity: [0, 40]
+  departed: [true, false]
+  paths:
+    "a/":
+      bus_factor: 2
+      concentration: 0.5000
+      departed_share: 0.5000
+      at_risk: false
+      ownership:
+        - {0: 4}
+        - {0: 3, 1: 3}
+        - {0: 3, 1: 3}
+        - {0: 3, 1: 3}
+        - {0: 3, 1: 3}
+    "a/x.go":
+      bus_factor: 1
+      concentration: 0.5200
+      departed_share: 0.6000
+      at_risk: true
+      ownership:
+        - {0: 4}
+        - {0: 3, 1: 2}
+        - {0: 3, 1: 2}
+        - {0: 3, 1: 2}
+        - {0: 3, 1: 2}
+    "a/y.go":
+      bus_factor: 1
+      concentration: 1.0000
+      departed_share: 0.0000
+      at_risk: false
+      ownership:
+        - {}
+        - {1: 1}
+        - {1: 1}
+        - {1: 1}
+        - {1: 1}
+    "b.go":
+      bus_factor: 2
+      concentration: 0.5000
+      departed_share: 0.5000
+      at_risk: false
+      ownership:
+        - {0: 2}
+        - {0: 2}
+        - {0: 2}
+        - {0: 2}
+        - {0: 1, 1: 1}
+`, buffer.String())
+	buffer = &bytes.Buffer{}
+	ownership.Serialize(result, true, buffer)
+	msg := pb.OwnershipAnalysisResults{}
+	proto.Unmarshal(buffer.Bytes(), &msg)
+	assert.Equal(t, int32(10), msg.Sampling)
+	assert.Equal(t, []string{"one@srcd", "two@srcd"}, msg.People)
+	assert.Equal(t, []int32{0, 40}, msg.LastActivity)
+	assert.Equal(t, []bool{true, false}, msg.Departed)
+	assert.Len(t, msg.Paths, 4)
+	assert.Equal(t, "a/", msg.Paths[0].Path)
+	assert.Equal(t, int32(2), msg.Paths[0].BusFactor)
+	assert.Equal(t, "a/x.go", msg.Paths[1].Path)
+	assert.True(t, msg.Paths[1].AtRisk)
+	assert.Equal(t, int32(5), msg.Paths[1].Ownership.NumberOfRows)
+	assert.Equal(t, int32(3), msg.Paths[1].Ownership.NumberOfColumns)
+	assert.Equal(t, []int64{4, 3, 2, 3, 2, 3, 2, 3, 2}, msg.Paths[1].Ownership.Data)
+	assert.Equal(t, []int32{0, 0, 1, 0, 1, 0, 1, 0, 1}, msg.Paths[1].Ownership.Indices)
+	assert.Equal(t, []int64{0, 1, 3, 5, 7, 9}, msg.Paths[1].Ownership.Indptr)
+}
@@ -0,0 +1,448 @@
+package leaves
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+	"gopkg.in/src-d/hercules.v4/internal/pb"
+	items "gopkg.in/src-d/hercules.v4/internal/plumbing"
+)
+
+// SubmodulesAnalysis records the changes of the commits pinned by the submodules ("bumps").
+// Optionally, it runs the whole pipeline over the history of each submodule which is available
+// locally. It implements LeafPipelineItem.
+type SubmodulesAnalysis struct {
+	core.NoopMerger
+	core.OneShotMergeProcessor
+	// Recurse enables the analysis of the submodule histories. It requires Runner.
+	Recurse bool
+	// Runner analyses the history of a submodule, see FactSubmodulesRunner.
+	Runner SubmoduleRunner
+
+	repository *git.Repository
+	// bumps are the pinned commit changes, the keys are the submodule paths.
+	bumps map[string][]SubmoduleBump
+	// names maps the submodule paths to the names in .gitmodules.
+	names map[string]string
+	// started indicates whether the first commit has been consumed.
+	started bool
+}
+
+// SubmoduleRunner runs the analysis over the sequence of commits in a submodule repository.
+// It returns the deployed leaves and the results of Pipeline.Run().
+type SubmoduleRunner func(repository *git.Repository, commits []*object.Commit) (
+	[]core.LeafPipelineItem, map[core.LeafPipelineItem]interface{}, error)
+
+// SubmoduleBump is the change of the commit pinned by a submodule.
+type SubmoduleBump struct {
+	// Commit is the hash of the commit in the analysed repository.
+	Commit plumbing.Hash
+	// Day is the day of Commit.
+	Day int
+	// From is the previously pinned commit. It is plumbing.ZeroHash if the submodule was added.
+	From plumbing.Hash
+	// To is the pinned commit. It is plumbing.ZeroHash if the submodule was removed.
+	To plumbing.Hash
+}
+
+// SubmoduleAnalysis is the result of the pipeline run over the history of a submodule.
+type SubmoduleAnalysis struct {
+	Common  *core.CommonAnalysisResult
+	Leaves  []core.LeafPipelineItem
+	Results map[core.LeafPipelineItem]interface{}
+}
+
+// SubmodulesResult is returned by Finalize() and represents the analysis result.
+type SubmodulesResult struct {
+	// Bumps maps the submodule paths to the pinned commit changes.
+	Bumps map[string][]SubmoduleBump
+	// Analyses maps the submodule paths to the analyses of their histories.
+	Analyses map[string]SubmoduleAnalysis
+}
+
+const (
+	// ConfigSubmodulesRecurse is the name of the option to set SubmodulesAnalysis.Recurse.
+	ConfigSubmodulesRecurse = "Submodules.Recurse"
+	// FactSubmodulesRunner is the name of the fact with the SubmoduleRunner which analyses
+	// the submodule histories. The command line tool runs the same analyses as for the main
+	// repository.
+	FactSubmodulesRunner = "Submodules.Runner"
+)
+
+// Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
+func (analyser *SubmodulesAnalysis) Name() string {
+	return "Submodules"
+}
+
+// Provides returns the list of names of entities which are produced by this PipelineItem.
+// Each produced entity will be inserted into `deps` of dependent Consume()-s according
+// to this list. Also used by core.Registry to build the global map of providers.
+func (analyser *SubmodulesAnalysis) Provides() []string {
+	return []string{}
+}
+
+// Requires returns the list of names of entities which are needed by this PipelineItem.
+// Each requested entity will be inserted into `deps` of Consume(). In turn, those
+// entities are Provides() upstream.
+func (analyser *SubmodulesAnalysis) Requires() []string {
+	arr := [...]string{items.DependencyTreeChanges, items.DependencyDay}
+	return arr[:]
+}
+
+// ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
+func (analyser *SubmodulesAnalysis) ListConfigurationOptions() []core.ConfigurationOption {
+	options := [...]core.ConfigurationOption{{
+		Name: ConfigSubmodulesRecurse,
+		Description: "Analyse the histories of the submodules which are cloned locally " +
+			"between the first and the last pinned commits.",
+		Flag:    "recurse-submodules",
+		Type:    core.BoolConfigurationOption,
+		Default: false},
+	}
+	return options[:]
+}
+
+// Flag for the command line switch which enables this analysis.
+func (analyser *SubmodulesAnalysis) Flag() string {
+	return "submodules"
+}
+
+// Configure sets the properties previously published by ListConfigurationOptions().
+func (analyser *SubmodulesAnalysis) Configure(facts map[string]interface{}) {
+	if val, exists := facts[ConfigSubmodulesRecurse].(bool); exists {
+		analyser.Recurse = val
+	}
+	if val, exists := facts[FactSubmodulesRunner].(SubmoduleRunner); exists {
+		analyser.Runner = val
+	}
+}
+
+// Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
+// calls. The repository which is going to be analysed is supplied as an argument.
+func (analyser *SubmodulesAnalysis) Initialize(repository *git.Repository) {
+	analyser.repository = repository
+	analyser.bumps = map[string][]SubmoduleBump{}
+	analyser.names = map[string]string{}
+	analyser.started = false
+	analyser.OneShotMergeProcessor.Initialize()
+}
+
+// Consume runs this PipelineItem on the next commit data.
+// `deps` contain all the results from upstream PipelineItem-s as requested by Requires().
+// Additionally, DependencyCommit is always present there and represents the analysed *object.Commit.
+// This function returns the mapping with analysis results. The keys must be the same as
+// in Provides(). If there was an error, nil is returned.
+func (analyser *SubmodulesAnalysis) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	if !analyser.ShouldConsumeCommit(deps) {
+		return nil, nil
+	}
+	commit := deps[core.DependencyCommit].(*object.Commit)
+	day := deps[items.DependencyDay].(int)
+	var bumps []SubmoduleBump
+	var paths []string
+	if !analyser.started {
+		analyser.started = true
+		// TreeDiff does not list the submodules in the first commit
+		tree, err := commit.Tree()
+		if err != nil {
+			return nil, err
+		}
+		walker := object.NewTreeWalker(tree, true, nil)
+		defer walker.Close()
+		for {
+			name, entry, err := walker.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if entry.Mode == filemode.Submodule {
+				bumps = append(bumps, SubmoduleBump{Commit: commit.Hash, Day: day, To: entry.Hash})
+				paths = append(paths, name)
+			}
+		}
+	} else {
+		for _, change := range deps[items.DependencyTreeChanges].(object.Changes) {
+			bump := SubmoduleBump{Commit: commit.Hash, Day: day}
+			var path string
+			if change.From.TreeEntry.Mode == filemode.Submodule {
+				bump.From = change.From.TreeEntry.Hash
+				path = change.From.Name
+			}
+			if change.To.TreeEntry.Mode == filemode.Submodule {
+				bump.To = change.To.TreeEntry.Hash
+				path = change.To.Name
+			}
+			if path == "" || bump.From == bump.To {
+				continue
+			}
+			bumps = append(bumps, bump)
+			paths = append(paths, path)
+		}
+	}
+	for i, path := range paths {
+		analyser.bumps[path] = append(analyser.bumps[path], bumps[i])
+	}
+	if analyser.Recurse && len(bumps) > 0 {
+		analyser.readNames(commit)
+	}
+	return nil, nil
+}
+
+// Finalize returns the result of the analysis. Further Consume() calls are not expected.
+func (analyser *SubmodulesAnalysis) Finalize() interface{} {
+	result := SubmodulesResult{Bumps: analyser.bumps, Analyses: map[string]SubmoduleAnalysis{}}
+	if !analyser.Recurse {
+		return result
+	}
+	if analyser.Runner == nil {
+		log.Println("Cannot analyse the submodules: the runner is not set")
+		return result
+	}
+	paths := make([]string, 0, len(analyser.bumps))
+	for path := range analyser.bumps {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		analysis, err := analyser.analyseSubmodule(path)
+		if err != nil {
+			log.Printf("Failed to analyse the submodule %s: %v\n", path, err)
+			continue
+		}
+		result.Analyses[path] = analysis
+	}
+	return result
+}
+
+// Fork clones this PipelineItem.
+func (analyser *SubmodulesAnalysis) Fork(n int) []core.PipelineItem {
+	return core.ForkSamePipelineItem(analyser, n)
+}
+
+// Serialize converts the analysis result as returned by Finalize() to text or bytes.
+// The text format is YAML and the bytes format is Protocol Buffers.
+func (analyser *SubmodulesAnalysis) Serialize(result interface{}, binary bool, writer io.Writer) error {
+	submodulesResult := result.(SubmodulesResult)
+	if binary {
+		return analyser.serializeBinary(&submodulesResult, writer)
+	}
+	return analyser.serializeText(&submodulesResult, writer)
+}
+
+// readNames updates the mapping from the submodule paths to their names from .gitmodules.
+func (analyser *SubmodulesAnalysis) readNames(commit *object.Commit) {
+	file, err := commit.File(".gitmodules")
+	if err != nil {
+		return
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return
+	}
+	modules := config.NewModules()
+	if err = modules.Unmarshal([]byte(contents)); err != nil {
+		log.Printf("Failed to parse .gitmodules in %s: %v\n", commit.Hash.String(), err)
+		return
+	}
+	for name, module := range modules.Submodules {
+		analyser.names[module.Path] = name
+	}
+}
+
+// analyseSubmodule opens the submodule repository and runs Runner over the commits
+// between the first and the last pinned commits.
+func (analyser *SubmodulesAnalysis) analyseSubmodule(path string) (SubmoduleAnalysis, error) {
+	name, exists := analyser.names[path]
+	if !exists {
+		// Git names the submodules by their paths by default
+		name = path
+	}
+	repository, err := openSubmodule(analyser.repository, name, path)
+	if err != nil {
+		return SubmoduleAnalysis{}, err
+	}
+	var first, last plumbing.Hash
+	for _, bump := range analyser.bumps[path] {
+		if bump.To.IsZero() {
+			continue
+		}
+		if first.IsZero() {
+			first = bump.To
+		}
+		last = bump.To
+	}
+	if last.IsZero() {
+		return SubmoduleAnalysis{}, errors.New("no pinned commits")
+	}
+	commits, err := firstParentHistory(repository, first, last)
+	if err != nil {
+		return SubmoduleAnalysis{}, err
+	}
+	leaves, results, err := analyser.Runner(repository, commits)
+	if err != nil {
+		return SubmoduleAnalysis{}, err
+	}
+	return SubmoduleAnalysis{
+		Common:  results[nil].(*core.CommonAnalysisResult),
+		Leaves:  leaves,
+		Results: results,
+	}, nil
+}
+
+// openSubmodule loads the repository of the submodule which is cloned inside the analysed
+// repository: either in .git/modules or in the working tree.
+func openSubmodule(repository *git.Repository, name, path string) (*git.Repository, error) {
+	if repository == nil {
+		return nil, errors.New("the repository is not on disk")
+	}
+	storage, ok := repository.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, errors.New("the repository is not on disk")
+	}
+	gitDir := storage.Filesystem().Root()
+	modules, err := filesystem.NewStorage(osfs.New(filepath.Join(gitDir, "modules", name)))
+	if err == nil {
+		if submodule, err := git.Open(modules, nil); err == nil {
+			return submodule, nil
+		}
+	}
+	if filepath.Base(gitDir) == git.GitDirName {
+		if submodule, err := git.PlainOpen(
+			filepath.Join(filepath.Dir(gitDir), filepath.FromSlash(path))); err == nil {
+			return submodule, nil
+		}
+	}
+	return nil, errors.New("the submodule is not cloned")
+}
+
+// firstParentHistory returns the commits from `first` to `last` following the first parents,
+// from the oldest to the newest. If `first` is not the ancestor, the history starts at the root.
+func firstParentHistory(repository *git.Repository, first, last plumbing.Hash) (
+	[]*object.Commit, error) {
+	commit, err := repository.CommitObject(last)
+	if err != nil {
+		return nil, err
+	}
+	var result []*object.Commit
+	for ; err != io.EOF; commit, err = commit.Parents().Next() {
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, commit)
+		if commit.Hash == first {
+			break
+		}
+	}
+	// reverse the order
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
+
+func (analyser *SubmodulesAnalysis) serializeText(result *SubmodulesResult, writer io.Writer) error {
+	paths := make([]string, 0, len(result.Bumps))
+	for path := range result.Bumps {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(writer, "  \"%s\":\n", path)
+		fmt.Fprintln(writer, "    bumps:")
+		for _, bump := range result.Bumps[path] {
+			fmt.Fprintf(writer, "      - commit: \"%s\"\n", bump.Commit.String())
+			fmt.Fprintf(writer, "        day: %d\n", bump.Day)
+			fmt.Fprintf(writer, "        from: \"%s\"\n", hashOrEmpty(bump.From))
+			fmt.Fprintf(writer, "        to: \"%s\"\n", hashOrEmpty(bump.To))
+		}
+		analysis, exists := result.Analyses[path]
+		if !exists {
+			continue
+		}
+		fmt.Fprintln(writer, "    analysis:")
+		fmt.Fprintln(writer, "      hercules:")
+		fmt.Fprintln(writer, "        begin_unix_time:", analysis.Common.BeginTime)
+		fmt.Fprintln(writer, "        end_unix_time:", analysis.Common.EndTime)
+		fmt.Fprintln(writer, "        commits:", analysis.Common.CommitsNumber)
+		fmt.Fprintln(writer, "        run_time:", analysis.Common.RunTime.Nanoseconds()/1e6)
+		fmt.Fprintln(writer, "        signature:", analysis.Common.Signature)
+		for _, item := range analysis.Leaves {
+			buffer := &bytes.Buffer{}
+			if err := item.Serialize(analysis.Results[item], false, buffer); err != nil {
+				return err
+			}
+			fmt.Fprintf(writer, "      %s:\n", item.Name())
+			// nest the output of the item
+			for _, line := range strings.SplitAfter(buffer.String(), "\n") {
+				if line != "" && line != "\n" {
+					line = "      " + line
+				}
+				io.WriteString(writer, line)
+			}
+		}
+	}
+	return nil
+}
+
+func (analyser *SubmodulesAnalysis) serializeBinary(result *SubmodulesResult, writer io.Writer) error {
+	message := pb.SubmodulesAnalysisResults{Submodules: map[string]*pb.SubmoduleResults{}}
+	for path, bumps := range result.Bumps {
+		submodule := &pb.SubmoduleResults{Bumps: make([]*pb.SubmoduleBump, len(bumps))}
+		for i, bump := range bumps {
+			submodule.Bumps[i] = &pb.SubmoduleBump{
+				Commit: bump.Commit.String(),
+				Day:    int32(bump.Day),
+				From:   hashOrEmpty(bump.From),
+				To:     hashOrEmpty(bump.To),
+			}
+		}
+		if analysis, exists := result.Analyses[path]; exists {
+			header := &pb.Metadata{Repository: path}
+			analysis.Common.FillMetadata(header)
+			submodule.Analysis = &pb.AnalysisResults{Header: header, Contents: map[string][]byte{}}
+			for _, item := range analysis.Leaves {
+				buffer := &bytes.Buffer{}
+				if err := item.Serialize(analysis.Results[item], true, buffer); err != nil {
+					return err
+				}
+				submodule.Analysis.Contents[item.Name()] = buffer.Bytes()
+			}
+		}
+		message.Submodules[path] = submodule
+	}
+	serialized, err := proto.Marshal(&message)
+	if err != nil {
+		return err
+	}
+	writer.Write(serialized)
+	return nil
+}
+
+// hashOrEmpty returns the hex string of the hash or an empty string if the hash is zero.
+func hashOrEmpty(hash plumbing.Hash) string {
+	if hash.IsZero() {
+		return ""
+	}
+	return hash.String()
+}
+
+func init() {
+	core.Registry.Register(&SubmodulesAnalysis{})
+}
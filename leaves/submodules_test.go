@@ -0,0 +1,303 @@
+package leaves
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+	"gopkg.in/src-d/hercules.v4/internal/core"
+	"gopkg.in/src-d/hercules.v4/internal/pb"
+	items "gopkg.in/src-d/hercules.v4/internal/plumbing"
+	"gopkg.in/src-d/hercules.v4/internal/test"
+)
+
+func fixtureSubmodules() *SubmodulesAnalysis {
+	submodules := SubmodulesAnalysis{}
+	submodules.Initialize(test.Repository)
+	return &submodules
+}
+
+// fixtureCommitWithSubmodule creates an in-memory repository with a single commit which pins
+// the submodule "libs/sub" to `pinned`.
+func fixtureCommitWithSubmodule(pinned plumbing.Hash) *object.Commit {
+	storage := memory.NewStorage()
+	repository, err := git.Init(storage, nil)
+	if err != nil {
+		panic(err)
+	}
+	store := func(encoder interface {
+		Encode(plumbing.EncodedObject) error
+	}) plumbing.Hash {
+		obj := storage.NewEncodedObject()
+		if err := encoder.Encode(obj); err != nil {
+			panic(err)
+		}
+		hash, err := storage.SetEncodedObject(obj)
+		if err != nil {
+			panic(err)
+		}
+		return hash
+	}
+	libs := &object.Tree{Entries: []object.TreeEntry{
+		{Name: "sub", Mode: filemode.Submodule, Hash: pinned}}}
+	root := &object.Tree{Entries: []object.TreeEntry{
+		{Name: "libs", Mode: filemode.Dir, Hash: store(libs)}}}
+	commit := &object.Commit{TreeHash: store(root), Message: "test"}
+	commit, err = repository.CommitObject(store(commit))
+	if err != nil {
+		panic(err)
+	}
+	return commit
+}
+
+func TestSubmodulesMeta(t *testing.T) {
+	submodules := fixtureSubmodules()
+	assert.Equal(t, submodules.Name(), "Submodules")
+	assert.Equal(t, submodules.Flag(), "submodules")
+	assert.Len(t, submodules.Provides(), 0)
+	assert.Equal(t, []string{items.DependencyTreeChanges, items.DependencyDay},
+		submodules.Requires())
+	opts := submodules.ListConfigurationOptions()
+	assert.Len(t, opts, 1)
+	assert.Equal(t, opts[0].Name, ConfigSubmodulesRecurse)
+	assert.Equal(t, opts[0].Flag, "recurse-submodules")
+	submodules.Configure(map[string]interface{}{})
+	assert.False(t, submodules.Recurse)
+	assert.Nil(t, submodules.Runner)
+	runner := SubmoduleRunner(func(*git.Repository, []*object.Commit) (
+		[]core.LeafPipelineItem, map[core.LeafPipelineItem]interface{}, error) {
+		return nil, nil, nil
+	})
+	submodules.Configure(map[string]interface{}{
+		ConfigSubmodulesRecurse: true,
+		FactSubmodulesRunner:    runner,
+	})
+	assert.True(t, submodules.Recurse)
+	assert.NotNil(t, submodules.Runner)
+}
+
+func TestSubmodulesRegistration(t *testing.T) {
+	summoned := core.Registry.Summon((&SubmodulesAnalysis{}).Name())
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, summoned[0].Name(), "Submodules")
+	leaves := core.Registry.GetLeaves()
+	matched := false
+	for _, tp := range leaves {
+		if tp.Flag() == (&SubmodulesAnalysis{}).Flag() {
+			matched = true
+			break
+		}
+	}
+	assert.True(t, matched)
+}
+
+func TestSubmodulesConsume(t *testing.T) {
+	submodules := fixtureSubmodules()
+	pin1 := plumbing.NewHash("1111111111111111111111111111111111111111")
+	pin2 := plumbing.NewHash("2222222222222222222222222222222222222222")
+	pin3 := plumbing.NewHash("3333333333333333333333333333333333333333")
+	commit := fixtureCommitWithSubmodule(pin1)
+	// the first commit is read from the tree
+	deps := map[string]interface{}{
+		core.DependencyCommit:       commit,
+		items.DependencyDay:         0,
+		items.DependencyTreeChanges: object.Changes{},
+	}
+	result, err := submodules.Consume(deps)
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]SubmoduleBump{
+		"libs/sub": {{Commit: commit.Hash, Day: 0, To: pin1}},
+	}, submodules.bumps)
+	gitlink := func(name string, hash plumbing.Hash) object.ChangeEntry {
+		return object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+			Name: filepath.Base(name), Mode: filemode.Submodule, Hash: hash}}
+	}
+	file := func(name string, hash plumbing.Hash) object.ChangeEntry {
+		return object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+			Name: name, Mode: filemode.Regular, Hash: hash}}
+	}
+	deps[items.DependencyDay] = 3
+	deps[items.DependencyTreeChanges] = object.Changes{
+		{From: gitlink("libs/sub", pin1), To: gitlink("libs/sub", pin2)},
+		{To: gitlink("other", pin3)},
+		{From: file("file.go", pin1), To: file("file.go", pin2)},
+		// the file replaced the submodule
+		{From: gitlink("gone", pin1), To: file("gone", pin3)},
+	}
+	_, err = submodules.Consume(deps)
+	assert.Nil(t, err)
+	deps[items.DependencyDay] = 5
+	deps[items.DependencyTreeChanges] = object.Changes{{From: gitlink("other", pin3)}}
+	_, err = submodules.Consume(deps)
+	assert.Nil(t, err)
+	result2 := submodules.Finalize().(SubmodulesResult)
+	assert.Equal(t, map[string][]SubmoduleBump{
+		"libs/sub": {
+			{Commit: commit.Hash, Day: 0, To: pin1},
+			{Commit: commit.Hash, Day: 3, From: pin1, To: pin2},
+		},
+		"other": {
+			{Commit: commit.Hash, Day: 3, To: pin3},
+			{Commit: commit.Hash, Day: 5, From: pin3},
+		},
+		"gone": {{Commit: commit.Hash, Day: 3, From: pin1}},
+	}, result2.Bumps)
+	assert.Len(t, result2.Analyses, 0)
+}
+
+func TestSubmodulesRecurse(t *testing.T) {
+	root, err := ioutil.TempDir("", "hercules-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+	repository, err := git.PlainInit(root, false)
+	assert.Nil(t, err)
+	subRepository, err := git.PlainInit(filepath.Join(root, "libs", "sub"), false)
+	assert.Nil(t, err)
+	worktree, err := subRepository.Worktree()
+	assert.Nil(t, err)
+	var pins []plumbing.Hash
+	for i, contents := range []string{"a\n", "a\nb\n", "a\nb\nc\n", "a\nb\nc\nd\n"} {
+		assert.Nil(t, ioutil.WriteFile(
+			filepath.Join(root, "libs", "sub", "file.txt"), []byte(contents), 0644))
+		_, err = worktree.Add("file.txt")
+		assert.Nil(t, err)
+		hash, err := worktree.Commit("test", &git.CommitOptions{Author: &object.Signature{
+			Name: "test", Email: "test@test", When: time.Unix(int64(i)*3600, 0)}})
+		assert.Nil(t, err)
+		pins = append(pins, hash)
+	}
+	submodules := SubmodulesAnalysis{Recurse: true}
+	submodules.Initialize(repository)
+	submodules.bumps["libs/sub"] = []SubmoduleBump{
+		{To: pins[1]}, {From: pins[1], To: pins[3]}, {From: pins[3]}}
+	submodules.bumps["missing"] = []SubmoduleBump{{To: pins[0]}}
+	// no runner
+	assert.Len(t, submodules.Finalize().(SubmodulesResult).Analyses, 0)
+	history := &FileHistory{}
+	var analysed []plumbing.Hash
+	submodules.Runner = func(repository *git.Repository, commits []*object.Commit) (
+		[]core.LeafPipelineItem, map[core.LeafPipelineItem]interface{}, error) {
+		for _, commit := range commits {
+			analysed = append(analysed, commit.Hash)
+		}
+		return []core.LeafPipelineItem{history}, map[core.LeafPipelineItem]interface{}{
+			nil:     &core.CommonAnalysisResult{CommitsNumber: len(commits)},
+			history: FileHistoryResult{Files: map[string][]plumbing.Hash{"file.txt": pins[1:]}},
+		}, nil
+	}
+	result := submodules.Finalize().(SubmodulesResult)
+	// the history starts at the first pinned commit
+	assert.Equal(t, pins[1:], analysed)
+	assert.Len(t, result.Analyses, 1)
+	analysis := result.Analyses["libs/sub"]
+	assert.Equal(t, 3, analysis.Common.CommitsNumber)
+	assert.Equal(t, []core.LeafPipelineItem{history}, analysis.Leaves)
+	// the first pinned commit is not the ancestor
+	analysed = nil
+	submodules.bumps["libs/sub"] = []SubmoduleBump{{To: pins[3]}, {From: pins[3], To: pins[2]}}
+	submodules.Finalize()
+	assert.Equal(t, pins[:3], analysed)
+}
+
+func TestSubmodulesOpen(t *testing.T) {
+	_, err := openSubmodule(nil, "sub", "sub")
+	assert.NotNil(t, err)
+	repository, err := git.Init(memory.NewStorage(), nil)
+	assert.Nil(t, err)
+	_, err = openSubmodule(repository, "sub", "sub")
+	assert.NotNil(t, err)
+	root, err := ioutil.TempDir("", "hercules-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+	repository, err = git.PlainInit(root, false)
+	assert.Nil(t, err)
+	_, err = openSubmodule(repository, "sub", "libs/sub")
+	assert.NotNil(t, err)
+	// git clones the submodules to .git/modules/<name>
+	_, err = git.PlainInit(filepath.Join(root, ".git", "modules", "sub"), true)
+	assert.Nil(t, err)
+	submodule, err := openSubmodule(repository, "sub", "libs/sub")
+	assert.Nil(t, err)
+	assert.NotNil(t, submodule)
+}
+
+func fixtureSubmodulesResult() SubmodulesResult {
+	commit := plumbing.NewHash("1111111111111111111111111111111111111111")
+	pin := plumbing.NewHash("2222222222222222222222222222222222222222")
+	history := &FileHistory{}
+	return SubmodulesResult{
+		Bumps: map[string][]SubmoduleBump{
+			"b": {{Commit: commit, Day: 1, To: pin}},
+			"a": {{Commit: commit, Day: 2, From: pin}},
+		},
+		Analyses: map[string]SubmoduleAnalysis{"b": {
+			Common: &core.CommonAnalysisResult{
+				BeginTime: 100, EndTime: 200, CommitsNumber: 2, Signature: "author"},
+			Leaves: []core.LeafPipelineItem{history},
+			Results: map[core.LeafPipelineItem]interface{}{
+				history: FileHistoryResult{Files: map[string][]plumbing.Hash{"file.txt": {pin}}},
+			},
+		}},
+	}
+}
+
+func TestSubmodulesSerializeText(t *testing.T) {
+	submodules := fixtureSubmodules()
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, submodules.Serialize(fixtureSubmodulesResult(), false, buffer))
+	assert.Equal(t, `  "a":
+    bumps:
+      - commit: "1111111111111111111111111111111111111111"
+        day: 2
+        from: "2222222222222222222222222222222222222222"
+        to: ""
+  "b":
+    bumps:
+      - commit: "1111111111111111111111111111111111111111"
+        day: 1
+        from: ""
+        to: "2222222222222222222222222222222222222222"
+    analysis:
+      hercules:
+        begin_unix_time: 100
+        end_unix_time: 200
+        commits: 2
+        run_time: 0
+        signature: author
+      FileHistory:
+        - file.txt: ["2222222222222222222222222222222222222222"]
+`, buffer.String())
+}
+
+func TestSubmodulesSerializeBinary(t *testing.T) {
+	submodules := fixtureSubmodules()
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, submodules.Serialize(fixtureSubmodulesResult(), true, buffer))
+	msg := pb.SubmodulesAnalysisResults{}
+	assert.Nil(t, proto.Unmarshal(buffer.Bytes(), &msg))
+	assert.Len(t, msg.Submodules, 2)
+	assert.Equal(t, []*pb.SubmoduleBump{{
+		Commit: "1111111111111111111111111111111111111111", Day: 2,
+		From: "2222222222222222222222222222222222222222",
+	}}, msg.Submodules["a"].Bumps)
+	assert.Nil(t, msg.Submodules["a"].Analysis)
+	analysis := msg.Submodules["b"].Analysis
+	assert.Equal(t, "b", analysis.Header.Repository)
+	assert.Equal(t, int32(2), analysis.Header.Commits)
+	assert.Equal(t, int64(100), analysis.Header.BeginUnixTime)
+	history := pb.FileHistoryResultMessage{}
+	assert.Nil(t, proto.Unmarshal(analysis.Contents["FileHistory"], &history))
+	assert.Equal(t, []string{"2222222222222222222222222222222222222222"},
+		history.Files["file.txt"].Commits)
+}